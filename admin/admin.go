@@ -0,0 +1,226 @@
+// Package admin serves an HTTP admin API over a resource.FetchCache, as
+// used by cmd/cachectl.
+//
+// All responses are JSON, changes are made with POST:
+//
+//	GET  /keys              the cached keys
+//	GET  /stats             resource.Stats
+//	GET  /stats/delta       resource.Stats of the interval since the last
+//	                        call, see resource.FetchCache.StatsDelta
+//	GET  /entry?id=ID       the metadata of an entry
+//	POST /invalidate?id=ID  clear one or more ids, repeat id for a set
+//	POST /flush             flush the cache
+//	GET  /snapshot          a dump of the cache, with the values
+//	GET  /inflight          the Fetcher calls in progress
+//	GET  /evictions         the last evictions, see resource.WithEvictionLog
+//	GET  /ready             resource.WarmupStatus, with status 503 until
+//	                        the cache is ready, see resource.WithReadiness
+//
+// The X-Cache-Actor request header names who made a change, see
+// resource.WithAuditHook.
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	resource "github.com/hieunmce/cache"
+)
+
+// ActorHeader is the request header naming who made a change.
+const ActorHeader = "X-Cache-Actor"
+
+// Entry is the response of /entry.
+type Entry struct {
+	Key          string     `json:"key"`
+	Version      uint64     `json:"version"`
+	Created      time.Time  `json:"created"`
+	LastAccessed time.Time  `json:"last_accessed"`
+	Expires      *time.Time `json:"expires,omitempty"`
+	FetchCost    string     `json:"fetch_cost"`
+	Size         int        `json:"size"`
+	Source       string     `json:"source"`
+}
+
+// InFlight is an element of the response of /inflight.
+type InFlight struct {
+	Key     string `json:"key"`
+	Running string `json:"running"`
+	Waiters int    `json:"waiters"`
+}
+
+// Eviction is an element of the response of /evictions.
+type Eviction struct {
+	Key    string    `json:"key"`
+	Reason string    `json:"reason"`
+	Time   time.Time `json:"time"`
+	Age    string    `json:"age"`
+	Hits   uint64    `json:"hits"`
+	Cost   string    `json:"cost"`
+}
+
+// InvalidateResponse is the response of /invalidate.
+type InvalidateResponse struct {
+	Invalidated []string `json:"invalidated"`
+}
+
+// Handler serves the admin API of a cache.
+type Handler struct {
+	fc  *resource.FetchCache
+	mux *http.ServeMux
+}
+
+// NewHandler creates a Handler over fc. Mount it with http.StripPrefix,
+// e.g. under "/debug/cache".
+func NewHandler(fc *resource.FetchCache) *Handler {
+	h := &Handler{fc: fc, mux: http.NewServeMux()}
+	h.mux.HandleFunc("/keys", h.get(h.keys))
+	h.mux.HandleFunc("/stats", h.get(h.stats))
+	h.mux.HandleFunc("/stats/delta", h.get(h.statsDelta))
+	h.mux.HandleFunc("/entry", h.get(h.entry))
+	h.mux.HandleFunc("/invalidate", h.post(h.invalidate))
+	h.mux.HandleFunc("/flush", h.post(h.flush))
+	h.mux.HandleFunc("/snapshot", h.get(h.snapshot))
+	h.mux.HandleFunc("/inflight", h.get(h.inflight))
+	h.mux.HandleFunc("/evictions", h.get(h.evictions))
+	h.mux.HandleFunc("/ready", h.get(h.ready))
+	return h
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+func (h *Handler) get(fn http.HandlerFunc) http.HandlerFunc {
+	return h.method(http.MethodGet, fn)
+}
+
+func (h *Handler) post(fn http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		actor := r.Header.Get(ActorHeader)
+		if actor == "" {
+			actor = "admin " + r.RemoteAddr
+		}
+		h.method(http.MethodPost, fn)(w, r.WithContext(resource.WithActor(r.Context(), actor)))
+	}
+}
+
+func (h *Handler) method(method string, fn http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != method {
+			w.Header().Set("Allow", method)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		fn(w, r)
+	}
+}
+
+func (h *Handler) keys(w http.ResponseWriter, r *http.Request) {
+	s := h.fc.Snapshot(false)
+	keys := make([]string, 0, s.Len())
+	s.Range(func(e resource.SnapshotEntry) bool {
+		keys = append(keys, e.Key)
+		return true
+	})
+	writeJSON(w, keys)
+}
+
+func (h *Handler) stats(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, h.fc.Stats())
+}
+
+func (h *Handler) statsDelta(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, h.fc.StatsDelta())
+}
+
+func (h *Handler) entry(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	info, found := h.fc.Entry(id)
+	if !found {
+		http.Error(w, "not cached", http.StatusNotFound)
+		return
+	}
+	e := Entry{
+		Key:          id,
+		Version:      info.Version,
+		Created:      info.Created,
+		LastAccessed: info.LastAccessed,
+		FetchCost:    info.FetchCost.String(),
+		Source:       info.Source.String(),
+	}
+	if !info.Expires.IsZero() {
+		e.Expires = &info.Expires
+	}
+	if info.Model != nil {
+		e.Size = len(info.Model.Name) + len(info.Model.Data)
+	}
+	writeJSON(w, e)
+}
+
+func (h *Handler) invalidate(w http.ResponseWriter, r *http.Request) {
+	ids := r.URL.Query()["id"]
+	switch len(ids) {
+	case 0:
+		http.Error(w, "missing id", http.StatusBadRequest)
+		return
+	case 1:
+		h.fc.ClearContext(r.Context(), ids[0])
+	default:
+		h.fc.InvalidateSet(r.Context(), ids...)
+	}
+	writeJSON(w, InvalidateResponse{Invalidated: ids})
+}
+
+func (h *Handler) flush(w http.ResponseWriter, r *http.Request) {
+	h.fc.FlushContext(r.Context())
+	writeJSON(w, struct{}{})
+}
+
+func (h *Handler) snapshot(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = h.fc.Snapshot(false).WriteTo(w)
+}
+
+func (h *Handler) inflight(w http.ResponseWriter, r *http.Request) {
+	fetches := h.fc.InFlight()
+	out := make([]InFlight, len(fetches))
+	for i, f := range fetches {
+		out[i] = InFlight{Key: f.ID, Running: f.Running.String(), Waiters: f.Waiters}
+	}
+	writeJSON(w, out)
+}
+
+func (h *Handler) evictions(w http.ResponseWriter, r *http.Request) {
+	evictions := h.fc.Evictions()
+	out := make([]Eviction, len(evictions))
+	for i, e := range evictions {
+		out[i] = Eviction{
+			Key:    e.Key,
+			Reason: e.Reason.String(),
+			Time:   e.Time,
+			Age:    e.Age.String(),
+			Hits:   e.Hits,
+			Cost:   e.Cost.String(),
+		}
+	}
+	writeJSON(w, out)
+}
+
+func (h *Handler) ready(w http.ResponseWriter, r *http.Request) {
+	s := h.fc.Warmup()
+	if !s.Ready {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	writeJSON(w, s)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
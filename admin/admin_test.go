@@ -0,0 +1,162 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	resource "github.com/hieunmce/cache"
+)
+
+// fetcherFunc adapts a func to resource.Fetcher
+type fetcherFunc func(ctx context.Context, id string) (*resource.Model, error)
+
+func (f fetcherFunc) Fetch(ctx context.Context, id string) (*resource.Model, error) {
+	return f(ctx, id)
+}
+
+func TestHandler(t *testing.T) {
+	var (
+		fakeFetchID     = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+		notExistModelID = "5634aeed-2106-43de-ab7d-c0ad4b1e195e"
+	)
+
+	tests := []struct {
+		name        string
+		method      string
+		target      string
+		wantStatus  int
+		wantCached  bool
+		wantActions []resource.AuditAction
+	}{
+		{
+			name:       "success keys",
+			method:     http.MethodGet,
+			target:     "/keys",
+			wantStatus: http.StatusOK,
+			wantCached: true,
+		},
+		{
+			name:       "success stats",
+			method:     http.MethodGet,
+			target:     "/stats",
+			wantStatus: http.StatusOK,
+			wantCached: true,
+		},
+		{
+			name:       "success stats delta",
+			method:     http.MethodGet,
+			target:     "/stats/delta",
+			wantStatus: http.StatusOK,
+			wantCached: true,
+		},
+		{
+			name:       "success evictions",
+			method:     http.MethodGet,
+			target:     "/evictions",
+			wantStatus: http.StatusOK,
+			wantCached: true,
+		},
+		{
+			name:       "success ready",
+			method:     http.MethodGet,
+			target:     "/ready",
+			wantStatus: http.StatusOK,
+			wantCached: true,
+		},
+		{
+			name:       "success entry",
+			method:     http.MethodGet,
+			target:     "/entry?id=" + fakeFetchID,
+			wantStatus: http.StatusOK,
+			wantCached: true,
+		},
+		{
+			name:       "failed entry not cached",
+			method:     http.MethodGet,
+			target:     "/entry?id=" + notExistModelID,
+			wantStatus: http.StatusNotFound,
+			wantCached: true,
+		},
+		{
+			name:        "success invalidate",
+			method:      http.MethodPost,
+			target:      "/invalidate?id=" + fakeFetchID,
+			wantStatus:  http.StatusOK,
+			wantActions: []resource.AuditAction{resource.AuditClear},
+		},
+		{
+			name:        "success invalidate set",
+			method:      http.MethodPost,
+			target:      "/invalidate?id=" + fakeFetchID + "&id=" + notExistModelID,
+			wantStatus:  http.StatusOK,
+			wantActions: []resource.AuditAction{resource.AuditInvalidateSet},
+		},
+		{
+			name:       "failed invalidate with get",
+			method:     http.MethodGet,
+			target:     "/invalidate?id=" + fakeFetchID,
+			wantStatus: http.StatusMethodNotAllowed,
+			wantCached: true,
+		},
+		{
+			name:        "success flush",
+			method:      http.MethodPost,
+			target:      "/flush",
+			wantStatus:  http.StatusOK,
+			wantActions: []resource.AuditAction{resource.AuditFlush},
+		},
+		{
+			name:       "success snapshot",
+			method:     http.MethodGet,
+			target:     "/snapshot",
+			wantStatus: http.StatusOK,
+			wantCached: true,
+		},
+		{
+			name:       "success inflight",
+			method:     http.MethodGet,
+			target:     "/inflight",
+			wantStatus: http.StatusOK,
+			wantCached: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var records []resource.AuditRecord
+			fc := resource.NewCache(fetcherFunc(func(ctx context.Context, id string) (*resource.Model, error) {
+				return &resource.Model{Name: id}, nil
+			}), resource.WithAuditHook(func(r resource.AuditRecord) {
+				records = append(records, r)
+			}))
+			if _, err := fc.Fetch(context.Background(), fakeFetchID); err != nil {
+				t.Fatalf("FetchCache.Fetch() error = %v", err)
+			}
+
+			req := httptest.NewRequest(tt.method, tt.target, nil)
+			req.Header.Set(ActorHeader, "alice")
+			rec := httptest.NewRecorder()
+			NewHandler(fc).ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("Handler.ServeHTTP() expect status %v, have %v", tt.wantStatus, rec.Code)
+			}
+			if tt.wantStatus == http.StatusOK && !json.Valid(rec.Body.Bytes()) {
+				t.Errorf("Handler.ServeHTTP() expect JSON, have %s", rec.Body.Bytes())
+			}
+			if _, found := fc.Entry(fakeFetchID); found != tt.wantCached {
+				t.Errorf("Handler.ServeHTTP() expect cached = %v, have %v", tt.wantCached, found)
+			}
+			if len(records) != len(tt.wantActions) {
+				t.Fatalf("Handler.ServeHTTP() expect %v audit records, have %v", len(tt.wantActions), len(records))
+			}
+			for i, r := range records {
+				if r.Action != tt.wantActions[i] || r.Actor != "alice" {
+					t.Errorf("Handler.ServeHTTP() expect %v by alice, have %v by %v", tt.wantActions[i], r.Action, r.Actor)
+				}
+			}
+		})
+	}
+}
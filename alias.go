@@ -0,0 +1,172 @@
+package resource
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// ResolverFunc returns the canonical id of alias, alias itself when it is
+// canonical, see WithAliasResolver.
+type ResolverFunc func(ctx context.Context, alias string) (string, error)
+
+// WithAliasResolver resolves the id of every fetch not registered as an
+// alias with fn, so models with several identifiers, such as a UUID, a slug
+// and a legacy numeric id, are fetched and cached once under their
+// canonical id. The aliases fn resolves are registered, see RegisterAlias,
+// and its errors are returned by the fetch.
+func WithAliasResolver(fn ResolverFunc) Option {
+	return func(o *options) {
+		o.aliasResolver = fn
+	}
+}
+
+// WithAliasCacheSize bounds the number of aliases resolved by the resolver
+// of WithAliasResolver kept apart from those of RegisterAlias, the least
+// recently used are resolved again. A n of 0 means DefaultAliasCacheSize,
+// below 0 the resolver is called on every fetch of an alias.
+func WithAliasCacheSize(n int) Option {
+	return func(o *options) {
+		o.aliasCacheSize = n
+	}
+}
+
+// RegisterAlias makes alias an alias of canonical: fetches, clears and
+// updates of alias apply to canonical, the Fetcher is only called with
+// canonical and clearing any alias clears the single cached copy.
+func (fc *FetchCache) RegisterAlias(alias, canonical string) {
+	fc.aliases.set(fc.normalize(alias), fc.normalize(canonical))
+}
+
+// RemoveAlias forgets alias, which becomes an id of its own.
+func (fc *FetchCache) RemoveAlias(alias string) {
+	fc.aliases.remove(fc.normalize(alias))
+}
+
+// canonical returns the canonical id of id normalized, among the registered
+// aliases
+func (fc *FetchCache) canonical(id string) string {
+	id = fc.normalize(id)
+	if canonical, ok := fc.aliases.get(id); ok {
+		return canonical
+	}
+	return id
+}
+
+// resolve returns the canonical id of id normalized, resolving it with the
+// resolver of WithAliasResolver if it isn't registered
+func (fc *FetchCache) resolve(ctx context.Context, id string) (string, error) {
+	id = fc.normalize(id)
+	if canonical, ok := fc.aliases.get(id); ok {
+		return canonical, nil
+	}
+	if fc.opts.aliasResolver == nil {
+		return id, nil
+	}
+	canonical, err := fc.opts.aliasResolver(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	canonical = fc.normalize(canonical)
+	if canonical != id {
+		fc.aliases.setResolved(id, canonical)
+	}
+	return canonical, nil
+}
+
+// aliasTable maps aliases to canonical ids, the registered ones and up to
+// max resolved ones in least recently used order
+type aliasTable struct {
+	// n is the number of aliases, so fetches take no lock without any
+	n        int32 // accessed atomically
+	mu       sync.Mutex
+	aliases  map[string]string
+	resolved map[string]*list.Element
+	lru      list.List
+	max      int
+}
+
+// resolvedAlias is the value of the elements of aliasTable.lru
+type resolvedAlias struct {
+	alias, canonical string
+}
+
+func newAliasTable(max int) *aliasTable {
+	if max == 0 {
+		max = DefaultAliasCacheSize
+	}
+	return &aliasTable{max: max}
+}
+
+func (t *aliasTable) get(alias string) (string, bool) {
+	if atomic.LoadInt32(&t.n) == 0 {
+		return "", false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if canonical, ok := t.aliases[alias]; ok {
+		return canonical, true
+	}
+	if e, ok := t.resolved[alias]; ok {
+		t.lru.MoveToFront(e)
+		return e.Value.(resolvedAlias).canonical, true
+	}
+	return "", false
+}
+
+func (t *aliasTable) set(alias, canonical string) {
+	t.mu.Lock()
+	if t.aliases == nil {
+		t.aliases = make(map[string]string)
+	}
+	t.aliases[alias] = canonical
+	t.removeResolvedLocked(alias)
+	t.countLocked()
+	t.mu.Unlock()
+}
+
+// setResolved keeps alias resolved to canonical, dropping the least recently
+// used resolved alias when full
+func (t *aliasTable) setResolved(alias, canonical string) {
+	if t.max < 0 {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.aliases[alias]; ok {
+		return
+	}
+	if e, ok := t.resolved[alias]; ok {
+		e.Value = resolvedAlias{alias: alias, canonical: canonical}
+		t.lru.MoveToFront(e)
+		return
+	}
+	if t.resolved == nil {
+		t.resolved = make(map[string]*list.Element)
+	}
+	if t.lru.Len() >= t.max {
+		t.removeResolvedLocked(t.lru.Back().Value.(resolvedAlias).alias)
+	}
+	t.resolved[alias] = t.lru.PushFront(resolvedAlias{alias: alias, canonical: canonical})
+	t.countLocked()
+}
+
+func (t *aliasTable) remove(alias string) {
+	t.mu.Lock()
+	delete(t.aliases, alias)
+	t.removeResolvedLocked(alias)
+	t.countLocked()
+	t.mu.Unlock()
+}
+
+func (t *aliasTable) removeResolvedLocked(alias string) {
+	if e, ok := t.resolved[alias]; ok {
+		t.lru.Remove(e)
+		delete(t.resolved, alias)
+	}
+}
+
+func (t *aliasTable) countLocked() {
+	atomic.StoreInt32(&t.n, int32(len(t.aliases)+len(t.resolved)))
+}
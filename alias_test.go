@@ -0,0 +1,150 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestFetchCache_RegisterAlias(t *testing.T) {
+	fakeFetchID := "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	errResolve := errors.New("resolve failed")
+
+	tests := []struct {
+		name             string
+		register         bool
+		resolver         ResolverFunc
+		wantErr          error
+		wantFetchedIDs   []string
+		serviceCallCount int
+	}{
+		{
+			name:             "success registered aliases",
+			register:         true,
+			wantFetchedIDs:   []string{fakeFetchID, fakeFetchID},
+			serviceCallCount: 2,
+		},
+		{
+			name: "success resolver",
+			resolver: func(ctx context.Context, alias string) (string, error) {
+				return fakeFetchID, nil
+			},
+			wantFetchedIDs:   []string{fakeFetchID, fakeFetchID},
+			serviceCallCount: 2,
+		},
+		{
+			name:             "success without aliases",
+			wantFetchedIDs:   []string{"lorem-slug", "42", fakeFetchID},
+			serviceCallCount: 3,
+		},
+		{
+			name: "fail resolver error",
+			resolver: func(ctx context.Context, alias string) (string, error) {
+				return "", errResolve
+			},
+			wantErr: errResolve,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fetchedIDs []string
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					fetchedIDs = append(fetchedIDs, id)
+					return &Model{Name: id}, nil
+				},
+			}
+			var opts []Option
+			if tt.resolver != nil {
+				opts = append(opts, WithAliasResolver(tt.resolver))
+			}
+			fc := NewCache(mockedFetcher, opts...)
+			defer fc.Close(context.Background())
+			if tt.register {
+				fc.RegisterAlias("lorem-slug", fakeFetchID)
+				fc.RegisterAlias("42", fakeFetchID)
+			}
+
+			for _, id := range []string{"lorem-slug", "42", fakeFetchID} {
+				if _, err := fc.Fetch(context.Background(), id); err != tt.wantErr {
+					t.Errorf("FetchCache.Fetch() expect error = %v, have %v", tt.wantErr, err)
+				}
+			}
+			// clearing an alias clears the cached copy
+			fc.Clear("42")
+			_, _ = fc.Fetch(context.Background(), fakeFetchID)
+
+			if len(fetchedIDs) != tt.serviceCallCount {
+				t.Errorf("FetchCache.Fetch() expect service call count = %v, have %v", tt.serviceCallCount, len(fetchedIDs))
+			}
+			for i, id := range tt.wantFetchedIDs {
+				if i < len(fetchedIDs) && fetchedIDs[i] != id {
+					t.Errorf("FetchCache.Fetch() expect fetched id = %v, have %v", id, fetchedIDs[i])
+				}
+			}
+		})
+	}
+}
+
+func TestFetchCache_AliasCacheSize(t *testing.T) {
+	fakeFetchID := "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+
+	tests := []struct {
+		name              string
+		size              int
+		wantResolverCalls int
+		wantResolved      int
+	}{
+		{
+			name:              "success default size",
+			wantResolverCalls: 2,
+			wantResolved:      2,
+		},
+		{
+			name:              "success least recently used dropped",
+			size:              1,
+			wantResolverCalls: 3,
+			wantResolved:      1,
+		},
+		{
+			name:              "success caching disabled",
+			size:              -1,
+			wantResolverCalls: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					return &Model{Name: id}, nil
+				},
+			}
+			resolverCalls := 0
+			fc := NewCache(mockedFetcher,
+				WithAliasCacheSize(tt.size),
+				WithAliasResolver(func(ctx context.Context, alias string) (string, error) {
+					resolverCalls++
+					return fakeFetchID, nil
+				}),
+			)
+			defer fc.Close(context.Background())
+			fc.RegisterAlias("42", fakeFetchID)
+
+			for _, id := range []string{"lorem-slug", "ipsum-slug", "lorem-slug", "42"} {
+				if _, err := fc.Fetch(context.Background(), id); err != nil {
+					t.Fatalf("FetchCache.Fetch() expect error = %v, have %v", nil, err)
+				}
+			}
+
+			if resolverCalls != tt.wantResolverCalls {
+				t.Errorf("FetchCache.Fetch() expect resolver call count = %v, have %v", tt.wantResolverCalls, resolverCalls)
+			}
+			if n := len(fc.aliases.resolved); n != tt.wantResolved {
+				t.Errorf("FetchCache.Fetch() expect resolved alias count = %v, have %v", tt.wantResolved, n)
+			}
+			if _, ok := fc.aliases.get("42"); !ok {
+				t.Errorf("FetchCache.Fetch() expect registered alias = %v, have %v", true, ok)
+			}
+		})
+	}
+}
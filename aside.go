@@ -0,0 +1,101 @@
+package resource
+
+import "context"
+
+// WriteFunc persists model as the new resource of id, e.g. to the database
+// the Fetcher reads from.
+type WriteFunc func(ctx context.Context, id string, model *Model) error
+
+// CacheAside pairs a cache with the write path of its resources, so writes
+// invalidate the cached entries without every caller repeating it.
+type CacheAside struct {
+	// Update caches the written model instead of clearing the entry.
+	Update bool
+	// Broadcast publishes the invalidation to the other caches, see
+	// WithInvalidator.
+	Broadcast bool
+
+	fc    *FetchCache
+	write WriteFunc
+}
+
+// NewCacheAside creates a CacheAside writing the resources of fc with
+// write.
+func NewCacheAside(fc *FetchCache, write WriteFunc) *CacheAside {
+	return &CacheAside{fc: fc, write: write}
+}
+
+// Write writes the model returned by mutate for id, then clears its entry,
+// or caches the model with Update, and clears the ids depending on it.
+//
+// mutate is called with the model of the Fetcher, nil if ErrNotFound, so it
+// never applies to a stale cached copy. The lock of id is held until the
+// entry is updated so no fetch caches the old resource meanwhile. If mutate
+// returns an error or a nil model nothing is written. If write fails the
+// entry is cleared as the resource may have been written anyway. The L2
+// store is always cleared and the actor of ctx is recorded.
+func (a *CacheAside) Write(ctx context.Context, id string, mutate func(current *Model) (*Model, error)) (*Model, error) {
+	fc := a.fc
+	id = fc.canonical(id)
+	key := fc.key(id)
+	if _, err := fc.lockContext(ctx, key); err != nil {
+		return nil, err
+	}
+
+	fc.inflight.start(key, id, nil)
+	current, err := fc.f.Fetch(ctx, id)
+	fc.inflight.done(key)
+	if err == ErrNotFound {
+		current, err = nil, nil
+	}
+	var model *Model
+	if err == nil {
+		model, err = mutate(current)
+	}
+	if err != nil || model == nil {
+		fc.unlock(key)
+		return current, err
+	}
+	if err := a.write(ctx, id, model); err != nil {
+		fc.unlock(key)
+		a.invalidate(ctx, id, false)
+		return nil, err
+	}
+	if a.Update {
+		fc.cacheitem(key, id, model, SourceSet, fc.defaultTTL(), 0)
+	}
+	fc.unlock(key)
+	a.invalidate(ctx, id, a.Update)
+	return model, nil
+}
+
+// invalidate clears the ids depending on id, and id unless kept, from the
+// cache and the L2 store and broadcasts them if enabled
+func (a *CacheAside) invalidate(ctx context.Context, id string, keep bool) {
+	fc := a.fc
+	id = fc.canonical(id)
+	removed := 0
+	for _, dep := range fc.deps.closure(id) {
+		if fc.opts.l2 != nil {
+			_ = fc.opts.l2.Delete(context.Background(), dep)
+		}
+		if a.Broadcast {
+			fc.broadcast(Invalidation{Key: dep})
+		}
+		if keep && dep == id {
+			continue
+		}
+		key := fc.key(dep)
+		fc.lock(key)
+		if fc.removeitem(key) {
+			removed++
+		}
+		fc.negative.remove(key)
+		fc.unlock(key)
+	}
+	action := AuditClear
+	if keep {
+		action = AuditSet
+	}
+	fc.audit(action, ActorFromContext(ctx), fc.traceID(ctx), []string{id}, removed)
+}
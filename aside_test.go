@@ -0,0 +1,104 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestCacheAside_Write(t *testing.T) {
+	fakeFetchID := "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	errWrite := errors.New("write failed")
+
+	tests := []struct {
+		name             string
+		update           bool
+		broadcast        bool
+		writeErr         error
+		wantErr          error
+		wantName         string
+		wantOtherName    string
+		serviceCallCount int
+	}{
+		{
+			name:             "success invalidate",
+			wantName:         "v2",
+			wantOtherName:    "v1",
+			serviceCallCount: 2,
+		},
+		{
+			name:             "success update",
+			update:           true,
+			wantName:         "v2",
+			wantOtherName:    "v1",
+			serviceCallCount: 1,
+		},
+		{
+			name:             "success broadcast",
+			broadcast:        true,
+			wantName:         "v2",
+			wantOtherName:    "v2",
+			serviceCallCount: 3,
+		},
+		{
+			name:             "fail write clears the entry",
+			update:           true,
+			writeErr:         errWrite,
+			wantErr:          errWrite,
+			wantName:         "v1",
+			wantOtherName:    "v1",
+			serviceCallCount: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := map[string]string{fakeFetchID: "v1"}
+			serviceCallCount := 0
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					serviceCallCount++
+					name, ok := db[id]
+					if !ok {
+						return nil, ErrNotFound
+					}
+					return &Model{Name: name}, nil
+				},
+			}
+			inv := &localInvalidator{}
+			fc := NewCache(mockedFetcher, WithInvalidator(inv))
+			defer fc.Close(context.Background())
+			other := NewCache(mockedFetcher, WithInvalidator(inv))
+			defer other.Close(context.Background())
+			_, _ = fc.Fetch(context.Background(), fakeFetchID)
+			_, _ = other.Fetch(context.Background(), fakeFetchID)
+
+			a := NewCacheAside(fc, func(ctx context.Context, id string, model *Model) error {
+				if tt.writeErr != nil {
+					return tt.writeErr
+				}
+				db[id] = model.Name
+				return nil
+			})
+			a.Update, a.Broadcast = tt.update, tt.broadcast
+			serviceCallCount = 0
+			_, err := a.Write(context.Background(), fakeFetchID, func(current *Model) (*Model, error) {
+				return &Model{Name: "v2"}, nil
+			})
+			if err != tt.wantErr {
+				t.Errorf("CacheAside.Write() expect error = %v, have %v", tt.wantErr, err)
+			}
+
+			model, err := fc.Fetch(context.Background(), fakeFetchID)
+			if err != nil || model.Name != tt.wantName {
+				t.Errorf("FetchCache.Fetch() expect = %v, have %v, %v", tt.wantName, model, err)
+			}
+			model, err = other.Fetch(context.Background(), fakeFetchID)
+			if err != nil || model.Name != tt.wantOtherName {
+				t.Errorf("FetchCache.Fetch() expect other = %v, have %v, %v", tt.wantOtherName, model, err)
+			}
+			if serviceCallCount != tt.serviceCallCount {
+				t.Errorf("CacheAside.Write() expect service call count = %v, have %v", tt.serviceCallCount, serviceCallCount)
+			}
+		})
+	}
+}
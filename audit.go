@@ -0,0 +1,91 @@
+package resource
+
+import (
+	"context"
+	"time"
+)
+
+// AuditAction is the kind of change of an AuditRecord.
+type AuditAction int
+
+// Audit action list
+const (
+	AuditClear AuditAction = iota + 1
+	AuditFlush
+	AuditInvalidateSet
+	AuditSet
+	// AuditInvalidation is a Clear or Flush received from another cache,
+	// the actor is the instance it came from.
+	AuditInvalidation
+)
+
+var auditActionNames = map[AuditAction]string{
+	AuditClear:         "clear",
+	AuditFlush:         "flush",
+	AuditInvalidateSet: "invalidate_set",
+	AuditSet:           "set",
+	AuditInvalidation:  "invalidation",
+}
+
+// String returns the lower case name of the action.
+func (a AuditAction) String() string {
+	if name, ok := auditActionNames[a]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// AuditRecord describes a change of the cached items made through the
+// cache API, see WithAuditHook.
+type AuditRecord struct {
+	Time   time.Time
+	Action AuditAction
+	// Actor is who made the change, see WithActor.
+	Actor string
+	// TraceID is the id of the request which made the change, see
+	// WithTraceID.
+	TraceID string
+	// Keys are the ids the change was requested for, empty for a flush.
+	Keys []string
+	// Removed is the number of items the change dropped from the cache,
+	// including dependents and flushed items.
+	Removed int
+}
+
+// WithAuditHook calls fn with a record of every Clear, Flush,
+// InvalidateSet, CompareAndSwap and received invalidation. fn is called
+// synchronously and must not block.
+func WithAuditHook(fn func(AuditRecord)) Option {
+	return func(o *options) {
+		o.audit = fn
+	}
+}
+
+type actorKey struct{}
+
+// WithActor returns a copy of ctx naming actor as the author of the changes
+// made with it, as recorded by WithAuditHook.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the actor of ctx set by WithActor, if any.
+func ActorFromContext(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
+
+// audit records a change if there is an audit hook
+func (fc *FetchCache) audit(action AuditAction, actor, trace string, keys []string, removed int) {
+	if fc.opts.audit == nil {
+		return
+	}
+	fc.opts.audit(AuditRecord{
+		Time:    time.Now(),
+		Action:  action,
+		Actor:   actor,
+		TraceID: trace,
+		Keys:    keys,
+		Removed: removed,
+	})
+}
@@ -0,0 +1,81 @@
+package resource
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestFetchCache_WithAuditHook(t *testing.T) {
+	var (
+		fakeFetchID     = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+		notExistModelID = "5634aeed-2106-43de-ab7d-c0ad4b1e195e"
+	)
+
+	tests := []struct {
+		name   string
+		change func(ctx context.Context, fc *FetchCache)
+		want   AuditRecord
+	}{
+		{
+			name:   "success clear",
+			change: func(ctx context.Context, fc *FetchCache) { fc.ClearContext(ctx, fakeFetchID) },
+			want:   AuditRecord{Action: AuditClear, Actor: "alice", Keys: []string{fakeFetchID}, Removed: 1},
+		},
+		{
+			name:   "success clear not cached",
+			change: func(ctx context.Context, fc *FetchCache) { fc.ClearContext(ctx, notExistModelID) },
+			want:   AuditRecord{Action: AuditClear, Actor: "alice", Keys: []string{notExistModelID}},
+		},
+		{
+			name:   "success flush",
+			change: func(ctx context.Context, fc *FetchCache) { fc.FlushContext(ctx) },
+			want:   AuditRecord{Action: AuditFlush, Actor: "alice", Removed: 2},
+		},
+		{
+			name: "success invalidate set",
+			change: func(ctx context.Context, fc *FetchCache) {
+				fc.InvalidateSet(ctx, fakeFetchID, "other", notExistModelID)
+			},
+			want: AuditRecord{Action: AuditInvalidateSet, Actor: "alice", Keys: []string{fakeFetchID, "other", notExistModelID}, Removed: 2},
+		},
+		{
+			name: "success set",
+			change: func(ctx context.Context, fc *FetchCache) {
+				fc.CompareAndSwap(ctx, notExistModelID, 0, &Model{Name: "lorem"})
+			},
+			want: AuditRecord{Action: AuditSet, Actor: "alice", Keys: []string{notExistModelID}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					return &Model{Name: id}, nil
+				},
+			}
+			var records []AuditRecord
+			fc := NewCache(mockedFetcher, WithAuditHook(func(r AuditRecord) {
+				records = append(records, r)
+			}))
+			for _, id := range []string{fakeFetchID, "other"} {
+				if _, err := fc.Fetch(context.Background(), id); err != nil {
+					t.Fatalf("FetchCache.Fetch() error = %v", err)
+				}
+			}
+
+			tt.change(WithActor(context.Background(), "alice"), fc)
+			if len(records) != 1 {
+				t.Fatalf("WithAuditHook() expect 1 record, have %v", len(records))
+			}
+			got := records[0]
+			if got.Time.IsZero() {
+				t.Errorf("WithAuditHook() expect record time set")
+			}
+			got.Time = tt.want.Time
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("WithAuditHook() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Error list
+var (
+	ErrDenied = errors.New("fetch denied")
+)
+
+// DeniedError is returned in place of calling the Fetcher for a fetch
+// denied by the authorizer of WithFetchAuthorizer. It matches ErrDenied.
+type DeniedError struct {
+	ID string
+	// Err is the error of the authorizer.
+	Err error
+}
+
+// Error implements error.
+func (e *DeniedError) Error() string {
+	return fmt.Sprintf("fetch of %q denied: %v", e.ID, e.Err)
+}
+
+// Is makes the error match ErrDenied.
+func (e *DeniedError) Is(target error) bool {
+	return target == ErrDenied
+}
+
+// Unwrap returns the error of the authorizer.
+func (e *DeniedError) Unwrap() error {
+	return e.Err
+}
+
+// WithFetchAuthorizer calls authorize with the context of every fetch
+// about to call the Fetcher, which fails with a DeniedError when it returns
+// an error, so the credentials or the quota of a caller can bound the
+// fetches it makes to the origin. Hits are always served and the
+// background refreshes aren't authorized.
+func WithFetchAuthorizer(authorize func(ctx context.Context, id string) error) Option {
+	return func(o *options) {
+		o.authorize = authorize
+	}
+}
+
+// authorizeFetch returns the DeniedError of a fetch of id denied by the
+// authorizer
+func (fc *FetchCache) authorizeFetch(ctx context.Context, id string) error {
+	if fc.opts.authorize == nil {
+		return nil
+	}
+	if err := fc.opts.authorize(ctx, id); err != nil {
+		return &DeniedError{ID: id, Err: err}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type tokenKey struct{}
+
+func TestWithFetchAuthorizer(t *testing.T) {
+	var (
+		fakeFetchID     = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+		notExistModelID = "5634aeed-2106-43de-ab7d-c0ad4b1e195e"
+	)
+	errNoToken := errors.New("no token")
+	mockedFetcher := &FetcherMock{
+		FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+			return &Model{Name: id}, nil
+		},
+	}
+	fc := NewCache(mockedFetcher, WithFetchAuthorizer(func(ctx context.Context, id string) error {
+		if ctx.Value(tokenKey{}) == nil {
+			return errNoToken
+		}
+		return nil
+	}))
+	authorized := context.WithValue(context.Background(), tokenKey{}, "secret")
+	if _, err := fc.Fetch(authorized, fakeFetchID); err != nil {
+		t.Fatalf("FetchCache.Fetch() expect no error, have %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		id      string
+		wantErr error
+	}{
+		{
+			name: "success hit without token",
+			ctx:  context.Background(),
+			id:   fakeFetchID,
+		},
+		{
+			name:    "failed miss without token",
+			ctx:     context.Background(),
+			id:      notExistModelID,
+			wantErr: errNoToken,
+		},
+		{
+			name: "success miss with token",
+			ctx:  authorized,
+			id:   notExistModelID,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := fc.Fetch(tt.ctx, tt.id)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("FetchCache.Fetch() expect error = %v, have %v", tt.wantErr, err)
+			}
+			var denied *DeniedError
+			if tt.wantErr != nil && (!errors.Is(err, ErrDenied) || !errors.As(err, &denied) || denied.ID != tt.id) {
+				t.Errorf("FetchCache.Fetch() expect a DeniedError of %v, have %v", tt.id, err)
+			}
+		})
+	}
+}
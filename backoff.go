@@ -0,0 +1,92 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// BackoffError is returned in place of calling the Fetcher for an id whose
+// last fetches failed, until RetryAt, see WithFailureBackoff and
+// RateLimitedError.
+type BackoffError struct {
+	ID string
+	// Err is the error of the last fetch.
+	Err     error
+	RetryAt time.Time
+}
+
+// Error implements error.
+func (e *BackoffError) Error() string {
+	return fmt.Sprintf("fetch of %q backing off until %s: %v", e.ID, e.RetryAt.Format(time.RFC3339Nano), e.Err)
+}
+
+// Unwrap returns the error of the last fetch.
+func (e *BackoffError) Unwrap() error {
+	return e.Err
+}
+
+// WithFailureBackoff stops calling the Fetcher for an id whose fetch
+// failed: fetches of it fail with a BackoffError for min after the first
+// failure, twice as long after every further one, up to max, and go back
+// to the Fetcher as soon as one succeeds. A broken id then can't hammer
+// the backend with a retry per request. Fetches aborted by their ctx are
+// not failures, and with WithErrorPolicy only ErrorBackoff errors are.
+func WithFailureBackoff(min, max time.Duration) Option {
+	return func(o *options) {
+		if max < min {
+			max = min
+		}
+		o.backoffMin = min
+		o.backoffMax = max
+	}
+}
+
+// errBox holds an error in an atomic.Value, which needs a consistent type
+type errBox struct {
+	err error
+}
+
+// backingOff returns the BackoffError of id while it is backing off
+func (fc *FetchCache) backingOff(key, id string, now time.Time) error {
+	if fc.opts.backoffMin <= 0 && atomic.LoadInt32(&fc.retryHints) == 0 {
+		return nil
+	}
+	v, ok := fc.stats.m.Load(key)
+	if !ok {
+		return nil
+	}
+	ks := v.(*keyStats)
+	retryAt := atomic.LoadInt64(&ks.retryAt)
+	if retryAt == 0 || now.UnixNano() >= retryAt {
+		return nil
+	}
+	box, _ := ks.lastErr.Load().(errBox)
+	return &BackoffError{ID: id, Err: box.err, RetryAt: time.Unix(0, retryAt)}
+}
+
+// backoff updates the failure backoff of key after a Fetcher call
+func (fc *FetchCache) backoff(ctx context.Context, key string, err error) {
+	if fc.opts.backoffMin <= 0 || ctx.Err() != nil {
+		return
+	}
+	ks := fc.stats.get(key)
+	if err == nil {
+		if atomic.LoadInt64(&ks.failures) != 0 {
+			atomic.StoreInt64(&ks.failures, 0)
+			atomic.StoreInt64(&ks.retryAt, 0)
+		}
+		return
+	}
+	n := atomic.AddInt64(&ks.failures, 1)
+	d := fc.opts.backoffMin
+	for i := int64(1); i < n && d < fc.opts.backoffMax; i++ {
+		d *= 2
+	}
+	if d > fc.opts.backoffMax {
+		d = fc.opts.backoffMax
+	}
+	ks.lastErr.Store(errBox{err})
+	atomic.StoreInt64(&ks.retryAt, time.Now().Add(d).UnixNano())
+}
@@ -0,0 +1,92 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFetchCache_WithFailureBackoff(t *testing.T) {
+	var (
+		fakeFetchID = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+		errBackend  = errors.New("backend down")
+	)
+
+	tests := []struct {
+		name             string
+		fails            []bool
+		serviceCallCount int
+		wantFailures     int
+		wantBackoff      time.Duration
+	}{
+		{
+			name:             "success first failure",
+			fails:            []bool{true},
+			serviceCallCount: 1,
+			wantFailures:     1,
+			wantBackoff:      time.Minute,
+		},
+		{
+			name:             "success doubled up to max",
+			fails:            []bool{true, true, true},
+			serviceCallCount: 3,
+			wantFailures:     3,
+			wantBackoff:      3 * time.Minute,
+		},
+		{
+			name:             "success reset by a success",
+			fails:            []bool{true, false},
+			serviceCallCount: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serviceCallCount := 0
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					fail := tt.fails[serviceCallCount]
+					serviceCallCount++
+					if fail {
+						return nil, errBackend
+					}
+					return &Model{Name: "lorem"}, nil
+				},
+			}
+			fc := NewCache(mockedFetcher, WithFailureBackoff(time.Minute, 3*time.Minute))
+			defer fc.Close(context.Background())
+
+			for i := range tt.fails {
+				if i > 0 {
+					// end the backoff of the previous failure
+					atomic.StoreInt64(&fc.stats.get(fakeFetchID).retryAt, 1)
+				}
+				_, _ = fc.Fetch(context.Background(), fakeFetchID)
+			}
+			start := time.Now()
+			_, err := fc.Fetch(context.Background(), fakeFetchID)
+			if serviceCallCount != tt.serviceCallCount {
+				t.Errorf("FetchCache.Fetch() expect service call count = %v, have %v", tt.serviceCallCount, serviceCallCount)
+			}
+
+			ks, _ := fc.KeyStats(fakeFetchID)
+			if ks.Failures != tt.wantFailures {
+				t.Errorf("FetchCache.KeyStats() expect failures = %v, have %v", tt.wantFailures, ks.Failures)
+			}
+			if tt.wantFailures == 0 {
+				if err != nil || !ks.RetryAt.IsZero() {
+					t.Errorf("FetchCache.Fetch() expect no backoff, have %v until %v", err, ks.RetryAt)
+				}
+				return
+			}
+			var be *BackoffError
+			if !errors.As(err, &be) || !errors.Is(err, errBackend) {
+				t.Fatalf("FetchCache.Fetch() expect BackoffError of %v, have %v", errBackend, err)
+			}
+			if d := ks.RetryAt.Sub(start); d > tt.wantBackoff || d < tt.wantBackoff-time.Second {
+				t.Errorf("FetchCache.KeyStats() expect backoff = %v, have %v", tt.wantBackoff, d)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package resource
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// BatchFetcher is an interface that defines the FetchBatch method.
+type BatchFetcher interface {
+	// FetchBatch retrieves the Models for the given ids. Ids missing from
+	// the returned map are reported as ErrNotFound.
+	FetchBatch(ctx context.Context, ids []string) (map[string]*Model, error)
+}
+
+type batchResult struct {
+	model *Model
+	err   error
+}
+
+// batcher coalesces single id fetches into FetchBatch calls
+type batcher struct {
+	f       BatchFetcher
+	window  time.Duration
+	max     int
+	mu      sync.Mutex
+	pending map[string][]chan batchResult
+	timer   *time.Timer
+	// synchronous fetches every id alone in the calling goroutine
+	synchronous bool
+	// recoverPanics fails the batch with a PanicError given to
+	// panicReport when FetchBatch panics, see WithPanicRecovery
+	recoverPanics bool
+	panicReport   func(*PanicError)
+}
+
+func newBatcher(f BatchFetcher, window time.Duration, max int) *batcher {
+	return &batcher{
+		f:       f,
+		window:  window,
+		max:     max,
+		pending: make(map[string][]chan batchResult),
+	}
+}
+
+// Fetch implements Fetcher by adding id to the next batch.
+func (b *batcher) Fetch(ctx context.Context, id string) (*Model, error) {
+	if b.synchronous {
+		models, err := b.f.FetchBatch(ctx, []string{id})
+		if err != nil {
+			return nil, err
+		}
+		if models[id] == nil {
+			return nil, ErrNotFound
+		}
+		return models[id], nil
+	}
+	ch := make(chan batchResult, 1)
+
+	b.mu.Lock()
+	b.pending[id] = append(b.pending[id], ch)
+	switch {
+	case b.max > 0 && len(b.pending) >= b.max:
+		b.flushLocked()
+	case b.timer == nil:
+		b.timer = time.AfterFunc(b.window, b.flush)
+	}
+	b.mu.Unlock()
+
+	select {
+	case r := <-ch:
+		return r.model, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (b *batcher) flush() {
+	b.mu.Lock()
+	b.flushLocked()
+	b.mu.Unlock()
+}
+
+// flushLocked issues the pending batch, b.mu must be held
+func (b *batcher) flushLocked() {
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	if len(b.pending) == 0 {
+		return
+	}
+
+	pending := b.pending
+	b.pending = make(map[string][]chan batchResult)
+	go b.run(pending)
+}
+
+func (b *batcher) run(pending map[string][]chan batchResult) {
+	ids := make([]string, 0, len(pending))
+	for id := range pending {
+		ids = append(ids, id)
+	}
+
+	var (
+		models map[string]*Model
+		err    error
+	)
+	withLabels(context.Background(), "fetch-batch", "", func(ctx context.Context) {
+		if !b.recoverPanics {
+			models, err = b.f.FetchBatch(ctx, ids)
+			return
+		}
+		if perr := recoverFetch("", b.panicReport, func() { models, err = b.f.FetchBatch(ctx, ids) }); perr != nil {
+			models, err = nil, perr
+		}
+	})
+	for id, chs := range pending {
+		r := batchResult{err: err}
+		if err == nil {
+			r.model = models[id]
+			if r.model == nil {
+				r.err = ErrNotFound
+			}
+		}
+		for _, ch := range chs {
+			ch <- r
+		}
+	}
+}
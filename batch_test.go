@@ -0,0 +1,81 @@
+package resource
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+type batchFetcherFunc func(ctx context.Context, ids []string) (map[string]*Model, error)
+
+func (f batchFetcherFunc) FetchBatch(ctx context.Context, ids []string) (map[string]*Model, error) {
+	return f(ctx, ids)
+}
+
+func TestFetchCache_Fetch_Batching(t *testing.T) {
+	var (
+		notExistModelID = "5634aeed-2106-43de-ab7d-c0ad4b1e195e"
+	)
+
+	tests := []struct {
+		name           string
+		ids            []string
+		max            int
+		batchCallCount int
+	}{
+		{
+			name:           "success collect misses in one batch",
+			ids:            []string{"a", "b", "c", "d", "e", notExistModelID},
+			batchCallCount: 1,
+		},
+		{
+			name:           "success split batch by max size",
+			ids:            []string{"a", "b", "c", "d"},
+			max:            2,
+			batchCallCount: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			batchCallCount := 0
+			bf := batchFetcherFunc(func(ctx context.Context, ids []string) (map[string]*Model, error) {
+				mu.Lock()
+				batchCallCount++
+				mu.Unlock()
+				models := make(map[string]*Model)
+				for _, id := range ids {
+					if id != notExistModelID {
+						models[id] = &Model{Name: id}
+					}
+				}
+				return models, nil
+			})
+			fc := NewCache(nil, WithBatchFetcher(bf, 20*time.Millisecond, tt.max))
+
+			var wg sync.WaitGroup
+			wg.Add(len(tt.ids))
+			for _, id := range tt.ids {
+				go func(id string) {
+					defer wg.Done()
+					got, err := fc.Fetch(context.Background(), id)
+					if id == notExistModelID {
+						if err != ErrNotFound {
+							t.Errorf("FetchCache.Fetch() error = %v, want %v", err, ErrNotFound)
+						}
+						return
+					}
+					if err != nil || got.Name != id {
+						t.Errorf("FetchCache.Fetch() = %v, %v, want %v", got, err, id)
+					}
+				}(id)
+			}
+			wg.Wait()
+
+			if batchCallCount != tt.batchCallCount {
+				t.Errorf("FetchCache.Fetch() expect batch call count = %v, have %v", tt.batchCallCount, batchCallCount)
+			}
+		})
+	}
+}
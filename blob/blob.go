@@ -0,0 +1,144 @@
+// Package blob implements resource.Fetcher over Google Cloud Storage and
+// Azure Blob Storage, behind small client interfaces which the SDK clients
+// are adapted to in a few lines, so the cache doesn't depend on the SDKs.
+//
+// Both fetchers read the object named Prefix+id and return it as the Data
+// of a model named id, with its ETag as the origin version, see
+// resource.ResultFetcher. Missing objects are reported as
+// resource.ErrNotFound.
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	resource "github.com/hieunmce/cache"
+)
+
+// Error list
+var (
+	ErrTooLarge = errors.New("blob: object too large")
+)
+
+// Object is an object read by a client.
+type Object struct {
+	// Body is the content of the object, closed by the fetcher.
+	Body io.ReadCloser
+	// ETag identifies the content of the object, if known.
+	ETag string
+}
+
+// GCSClient reads objects from Google Cloud Storage, e.g. with
+// storage.Client.Bucket(bucket).Object(name).NewReader.
+type GCSClient interface {
+	// ReadObject returns the object name of bucket.
+	ReadObject(ctx context.Context, bucket, name string) (Object, error)
+}
+
+// AzureClient reads blobs from Azure Blob Storage, e.g. with
+// azblob.Client.DownloadStream.
+type AzureClient interface {
+	// DownloadBlob returns the blob name of container.
+	DownloadBlob(ctx context.Context, container, name string) (Object, error)
+}
+
+// GCS is a resource.Fetcher of the objects of a Google Cloud Storage
+// bucket.
+type GCS struct {
+	Client GCSClient
+	Bucket string
+	// Prefix is prepended to the ids to name the objects.
+	Prefix string
+	// NotFound reports whether an error of Client is for a missing object,
+	// errors wrapping resource.ErrNotFound or os.ErrNotExist if nil.
+	NotFound func(err error) bool
+	// MaxSize fails the objects larger than MaxSize bytes with
+	// ErrTooLarge, no limit if 0.
+	MaxSize int64
+}
+
+// NewGCS creates a GCS fetcher of the objects of bucket.
+func NewGCS(c GCSClient, bucket string) *GCS {
+	return &GCS{Client: c, Bucket: bucket}
+}
+
+// Fetch returns the object of id.
+func (f *GCS) Fetch(ctx context.Context, id string) (*resource.Model, error) {
+	r, err := f.FetchResult(ctx, id)
+	return r.Model, err
+}
+
+// FetchResult returns the object of id with its ETag.
+func (f *GCS) FetchResult(ctx context.Context, id string) (resource.Result, error) {
+	return fetch(ctx, id, f.NotFound, f.MaxSize, func(ctx context.Context) (Object, error) {
+		return f.Client.ReadObject(ctx, f.Bucket, f.Prefix+id)
+	})
+}
+
+// Azure is a resource.Fetcher of the blobs of an Azure Blob Storage
+// container.
+type Azure struct {
+	Client    AzureClient
+	Container string
+	// Prefix, NotFound and MaxSize are as for GCS.
+	Prefix   string
+	NotFound func(err error) bool
+	MaxSize  int64
+}
+
+// NewAzure creates an Azure fetcher of the blobs of container.
+func NewAzure(c AzureClient, container string) *Azure {
+	return &Azure{Client: c, Container: container}
+}
+
+// Fetch returns the blob of id.
+func (f *Azure) Fetch(ctx context.Context, id string) (*resource.Model, error) {
+	r, err := f.FetchResult(ctx, id)
+	return r.Model, err
+}
+
+// FetchResult returns the blob of id with its ETag.
+func (f *Azure) FetchResult(ctx context.Context, id string) (resource.Result, error) {
+	return fetch(ctx, id, f.NotFound, f.MaxSize, func(ctx context.Context) (Object, error) {
+		return f.Client.DownloadBlob(ctx, f.Container, f.Prefix+id)
+	})
+}
+
+// fetch reads the object of id with read, the same way for every store
+func fetch(ctx context.Context, id string, notFound func(error) bool, maxSize int64, read func(context.Context) (Object, error)) (resource.Result, error) {
+	if notFound == nil {
+		notFound = isNotFound
+	}
+	obj, err := read(ctx)
+	if err != nil {
+		if notFound(err) {
+			return resource.Result{}, fmt.Errorf("blob: %s: %w", id, resource.ErrNotFound)
+		}
+		return resource.Result{}, err
+	}
+	defer obj.Body.Close()
+
+	body := io.Reader(obj.Body)
+	if maxSize > 0 {
+		body = io.LimitReader(body, maxSize+1)
+	}
+	data, err := ioutil.ReadAll(body)
+	if err != nil {
+		return resource.Result{}, err
+	}
+	if maxSize > 0 && int64(len(data)) > maxSize {
+		return resource.Result{}, ErrTooLarge
+	}
+	return resource.Result{
+		Model:   &resource.Model{Name: id, Data: data},
+		Version: obj.ETag,
+	}, nil
+}
+
+func isNotFound(err error) bool {
+	return errors.Is(err, resource.ErrNotFound) || errors.Is(err, os.ErrNotExist)
+}
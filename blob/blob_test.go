@@ -0,0 +1,113 @@
+package blob
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	resource "github.com/hieunmce/cache"
+)
+
+// fakeStore is a GCSClient and an AzureClient over objects by name
+type fakeStore struct {
+	objects map[string]string
+	err     error
+	names   []string
+}
+
+func (s *fakeStore) read(name string) (Object, error) {
+	s.names = append(s.names, name)
+	if s.err != nil {
+		return Object{}, s.err
+	}
+	data, ok := s.objects[name]
+	if !ok {
+		return Object{}, os.ErrNotExist
+	}
+	return Object{Body: ioutil.NopCloser(bytes.NewBufferString(data)), ETag: "etag-" + name}, nil
+}
+
+func (s *fakeStore) ReadObject(ctx context.Context, bucket, name string) (Object, error) {
+	return s.read(bucket + "/" + name)
+}
+
+func (s *fakeStore) DownloadBlob(ctx context.Context, container, name string) (Object, error) {
+	return s.read(container + "/" + name)
+}
+
+func TestFetchers(t *testing.T) {
+	var (
+		fakeFetchID     = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+		notExistModelID = "5634aeed-2106-43de-ab7d-c0ad4b1e195e"
+		errBackend      = errors.New("backend down")
+	)
+	store := func() *fakeStore {
+		return &fakeStore{objects: map[string]string{"data/models/" + fakeFetchID: "content"}}
+	}
+
+	tests := []struct {
+		name     string
+		id       string
+		maxSize  int64
+		err      error
+		wantData string
+		wantErr  error
+	}{
+		{
+			name:     "success read object",
+			id:       fakeFetchID,
+			wantData: "content",
+		},
+		{
+			name:    "failed not found",
+			id:      notExistModelID,
+			wantErr: resource.ErrNotFound,
+		},
+		{
+			name:    "failed client error",
+			id:      fakeFetchID,
+			err:     errBackend,
+			wantErr: errBackend,
+		},
+		{
+			name:    "failed too large",
+			id:      fakeFetchID,
+			maxSize: 3,
+			wantErr: ErrTooLarge,
+		},
+	}
+	for _, tt := range tests {
+		for _, kind := range []string{"gcs", "azure"} {
+			t.Run(kind+" "+tt.name, func(t *testing.T) {
+				s := store()
+				s.err = tt.err
+				var f resource.ResultFetcher
+				if kind == "gcs" {
+					g := NewGCS(s, "data")
+					g.Prefix, g.MaxSize = "models/", tt.maxSize
+					f = g
+				} else {
+					a := NewAzure(s, "data")
+					a.Prefix, a.MaxSize = "models/", tt.maxSize
+					f = a
+				}
+				r, err := f.FetchResult(context.Background(), tt.id)
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("FetchResult() expect error = %v, have %v", tt.wantErr, err)
+				}
+				if tt.wantErr != nil {
+					return
+				}
+				if r.Model.Name != tt.id || string(r.Model.Data) != tt.wantData {
+					t.Errorf("FetchResult() expect model %v %q, have %v %q", tt.id, tt.wantData, r.Model.Name, r.Model.Data)
+				}
+				if want := "etag-data/models/" + tt.id; r.Version != want {
+					t.Errorf("FetchResult() expect version = %v, have %v", want, r.Version)
+				}
+			})
+		}
+	}
+}
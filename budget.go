@@ -0,0 +1,41 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Error list
+var (
+	ErrFetchBudget = errors.New("fetch budget exceeded")
+)
+
+// WithFetchBudget bounds every miss to d from the wait for a Fetcher call
+// slot to the last attempt: the Fetcher calls made again for WithValidator
+// and the L2 store and origin calls share a single deadline, so the worst
+// case latency of a miss doesn't grow with the attempts. A miss out of
+// budget fails with ErrFetchBudget unless the caller's context is done.
+func WithFetchBudget(d time.Duration) Option {
+	return func(o *options) {
+		o.fetchBudget = d
+	}
+}
+
+// budgetContext returns ctx bounded by the fetch budget, cancel is to be
+// called once the miss is done
+func (fc *FetchCache) budgetContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if fc.opts.fetchBudget <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, fc.opts.fetchBudget)
+}
+
+// budgetErr returns ErrFetchBudget for err if the budget of ctx ran out
+// before the caller context
+func budgetErr(caller, ctx context.Context, err error) error {
+	if err != nil && ctx.Err() == context.DeadlineExceeded && caller.Err() == nil {
+		return ErrFetchBudget
+	}
+	return err
+}
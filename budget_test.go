@@ -0,0 +1,66 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithFetchBudget(t *testing.T) {
+	var fakeFetchID = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	reject := func(id string, m *Model) error { return errors.New("invalid") }
+	tests := []struct {
+		name     string
+		opts     []Option
+		ctx      func() (context.Context, context.CancelFunc)
+		wantErr  error
+		maxCalls int64
+	}{
+		{
+			name:     "failed budget shared by the attempts",
+			opts:     []Option{WithValidator(reject, 10), WithFetchBudget(100 * time.Millisecond)},
+			wantErr:  ErrFetchBudget,
+			maxCalls: 4,
+		},
+		{
+			name: "failed caller deadline first",
+			opts: []Option{WithValidator(reject, 10), WithFetchBudget(time.Second)},
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), 100*time.Millisecond)
+			},
+			wantErr:  context.DeadlineExceeded,
+			maxCalls: 4,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int64
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					atomic.AddInt64(&calls, 1)
+					select {
+					case <-ctx.Done():
+						return nil, ctx.Err()
+					case <-time.After(40 * time.Millisecond):
+						return &Model{Name: id}, nil
+					}
+				},
+			}
+			ctx, cancel := context.Background(), context.CancelFunc(func() {})
+			if tt.ctx != nil {
+				ctx, cancel = tt.ctx()
+			}
+			defer cancel()
+			fc := NewCache(mockedFetcher, tt.opts...)
+			_, err := fc.Fetch(ctx, fakeFetchID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("FetchCache.Fetch() expect error = %v, have %v", tt.wantErr, err)
+			}
+			if calls > tt.maxCalls {
+				t.Errorf("FetchCache.Fetch() expect at most %v Fetcher calls, have %v", tt.maxCalls, calls)
+			}
+		})
+	}
+}
@@ -0,0 +1,266 @@
+// Package cachegrpc exposes a FetchCache as the Cache gRPC service defined
+// in cache.proto, and provides a client implementing resource.Fetcher, as
+// well as UnaryFetcher to fetch models with any unary call.
+//
+// The package doesn't depend on grpc-go: messages are plain structs sent
+// with the JSON Codec, Server.Invoke dispatches a call by its full method
+// name and Client talks through the Invoker interface. Wiring them to a
+// *grpc.Server and *grpc.ClientConn takes a ServiceDesc whose handlers call
+// Server.Invoke, and an Invoker calling ClientConn.Invoke with
+// grpc.ForceCodec(cachegrpc.Codec{}).
+package cachegrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	resource "github.com/hieunmce/cache"
+)
+
+// Full method names of the Cache service
+const (
+	ServiceName     = "resource.Cache"
+	MethodFetch     = "/resource.Cache/Fetch"
+	MethodFetchMany = "/resource.Cache/FetchMany"
+	MethodClear     = "/resource.Cache/Clear"
+	MethodStats     = "/resource.Cache/Stats"
+)
+
+// Error list
+var (
+	ErrUnknownMethod = errors.New("unknown method")
+)
+
+// Model is the wire form of resource.Model.
+type Model struct {
+	Name string `json:"name"`
+	Data []byte `json:"data,omitempty"`
+}
+
+// FetchRequest is the request of Fetch.
+type FetchRequest struct {
+	ID string `json:"id"`
+}
+
+// FetchResponse is the response of Fetch.
+type FetchResponse struct {
+	Model *Model `json:"model"`
+}
+
+// FetchManyRequest is the request of FetchMany.
+type FetchManyRequest struct {
+	IDs []string `json:"ids"`
+}
+
+// FetchManyResponse is the response of FetchMany.
+type FetchManyResponse struct {
+	Models map[string]*Model `json:"models"`
+	Errors map[string]string `json:"errors"`
+}
+
+// ClearRequest is the request of Clear.
+type ClearRequest struct {
+	ID string `json:"id"`
+}
+
+// ClearResponse is the response of Clear.
+type ClearResponse struct{}
+
+// StatsRequest is the request of Stats.
+type StatsRequest struct{}
+
+// StatsResponse is the response of Stats.
+type StatsResponse struct {
+	Items  int64  `json:"items"`
+	Hits   uint64 `json:"hits"`
+	Misses uint64 `json:"misses"`
+}
+
+// Codec encodes messages as JSON, it implements grpc's encoding.Codec.
+type Codec struct{}
+
+// Marshal returns the JSON encoding of v.
+func (Codec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal decodes the JSON data into v.
+func (Codec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// Name returns the name of the codec.
+func (Codec) Name() string {
+	return "json"
+}
+
+// Server serves the Cache service over a FetchCache.
+//
+// Fetch returns resource.ErrNotFound unchanged, the transport should map it
+// to codes.NotFound.
+type Server struct {
+	fc *resource.FetchCache
+}
+
+// NewServer creates a Server over fc.
+func NewServer(fc *resource.FetchCache) *Server {
+	return &Server{fc: fc}
+}
+
+// Fetch implements the Fetch method.
+func (s *Server) Fetch(ctx context.Context, req *FetchRequest) (*FetchResponse, error) {
+	model, err := s.fc.Fetch(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &FetchResponse{Model: toWire(model)}, nil
+}
+
+// FetchMany implements the FetchMany method.
+func (s *Server) FetchMany(ctx context.Context, req *FetchManyRequest) (*FetchManyResponse, error) {
+	models, errs := s.fc.FetchMany(ctx, req.IDs)
+	resp := &FetchManyResponse{
+		Models: make(map[string]*Model, len(models)),
+		Errors: make(map[string]string, len(errs)),
+	}
+	for id, model := range models {
+		resp.Models[id] = toWire(model)
+	}
+	for id, err := range errs {
+		resp.Errors[id] = err.Error()
+	}
+	return resp, nil
+}
+
+// Clear implements the Clear method.
+func (s *Server) Clear(ctx context.Context, req *ClearRequest) (*ClearResponse, error) {
+	s.fc.ClearContext(ctx, req.ID)
+	return &ClearResponse{}, nil
+}
+
+// Stats implements the Stats method.
+func (s *Server) Stats(ctx context.Context, req *StatsRequest) (*StatsResponse, error) {
+	stats := s.fc.Stats()
+	return &StatsResponse{
+		Items:  int64(stats.Items),
+		Hits:   stats.Hits,
+		Misses: stats.Misses,
+	}, nil
+}
+
+// Invoke decodes the request of method with dec and calls it.
+func (s *Server) Invoke(ctx context.Context, method string, dec func(interface{}) error) (interface{}, error) {
+	switch method {
+	case MethodFetch:
+		req := &FetchRequest{}
+		if err := dec(req); err != nil {
+			return nil, err
+		}
+		return s.Fetch(ctx, req)
+	case MethodFetchMany:
+		req := &FetchManyRequest{}
+		if err := dec(req); err != nil {
+			return nil, err
+		}
+		return s.FetchMany(ctx, req)
+	case MethodClear:
+		req := &ClearRequest{}
+		if err := dec(req); err != nil {
+			return nil, err
+		}
+		return s.Clear(ctx, req)
+	case MethodStats:
+		req := &StatsRequest{}
+		if err := dec(req); err != nil {
+			return nil, err
+		}
+		return s.Stats(ctx, req)
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnknownMethod, method)
+}
+
+// Invoker is an interface that defines the Invoke method.
+//
+// It is the subset of *grpc.ClientConn used by Client.
+type Invoker interface {
+	// Invoke calls method with args and decodes the response into reply.
+	Invoke(ctx context.Context, method string, args, reply interface{}) error
+}
+
+// Client is a Cache service client, it implements resource.Fetcher.
+type Client struct {
+	conn Invoker
+	// IsNotFound reports whether an error returned by the connection means
+	// the model doesn't exist, so Fetch can return resource.ErrNotFound.
+	// With grpc-go it should check for codes.NotFound.
+	IsNotFound func(error) bool
+}
+
+// NewClient creates a new Client using conn.
+func NewClient(conn Invoker) *Client {
+	return &Client{
+		conn: conn,
+		IsNotFound: func(err error) bool {
+			return errors.Is(err, resource.ErrNotFound)
+		},
+	}
+}
+
+// Fetch implements resource.Fetcher.
+func (c *Client) Fetch(ctx context.Context, id string) (*resource.Model, error) {
+	resp := &FetchResponse{}
+	if err := c.conn.Invoke(ctx, MethodFetch, &FetchRequest{ID: id}, resp); err != nil {
+		if c.IsNotFound != nil && c.IsNotFound(err) {
+			return nil, resource.ErrNotFound
+		}
+		return nil, err
+	}
+	if resp.Model == nil {
+		return nil, resource.ErrNotFound
+	}
+	return fromWire(resp.Model), nil
+}
+
+// FetchMany calls the FetchMany method, see resource.FetchCache.FetchMany.
+func (c *Client) FetchMany(ctx context.Context, ids []string) (map[string]*resource.Model, map[string]error, error) {
+	resp := &FetchManyResponse{}
+	if err := c.conn.Invoke(ctx, MethodFetchMany, &FetchManyRequest{IDs: ids}, resp); err != nil {
+		return nil, nil, err
+	}
+	models := make(map[string]*resource.Model, len(resp.Models))
+	for id, model := range resp.Models {
+		models[id] = fromWire(model)
+	}
+	errs := make(map[string]error, len(resp.Errors))
+	for id, msg := range resp.Errors {
+		errs[id] = errors.New(msg)
+	}
+	return models, errs, nil
+}
+
+// Clear calls the Clear method.
+func (c *Client) Clear(ctx context.Context, id string) error {
+	return c.conn.Invoke(ctx, MethodClear, &ClearRequest{ID: id}, &ClearResponse{})
+}
+
+// Stats calls the Stats method.
+func (c *Client) Stats(ctx context.Context) (*StatsResponse, error) {
+	resp := &StatsResponse{}
+	if err := c.conn.Invoke(ctx, MethodStats, &StatsRequest{}, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func toWire(m *resource.Model) *Model {
+	if m == nil {
+		return nil
+	}
+	return &Model{Name: m.Name, Data: m.Data}
+}
+
+func fromWire(m *Model) *resource.Model {
+	return &resource.Model{Name: m.Name, Data: m.Data}
+}
@@ -0,0 +1,82 @@
+package cachegrpc
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	resource "github.com/hieunmce/cache"
+)
+
+type fetcherFunc func(ctx context.Context, id string) (*resource.Model, error)
+
+func (f fetcherFunc) Fetch(ctx context.Context, id string) (*resource.Model, error) {
+	return f(ctx, id)
+}
+
+// localConn sends calls to a Server in-process through the Codec
+type localConn struct {
+	s *Server
+}
+
+func (c localConn) Invoke(ctx context.Context, method string, args, reply interface{}) error {
+	var codec Codec
+	data, err := codec.Marshal(args)
+	if err != nil {
+		return err
+	}
+	resp, err := c.s.Invoke(ctx, method, func(v interface{}) error {
+		return codec.Unmarshal(data, v)
+	})
+	if err != nil {
+		return err
+	}
+	out, err := codec.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	return codec.Unmarshal(out, reply)
+}
+
+func TestClient(t *testing.T) {
+	var (
+		fakeFetchID     = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+		notExistModelID = "5634aeed-2106-43de-ab7d-c0ad4b1e195e"
+	)
+
+	fc := resource.NewCache(fetcherFunc(func(ctx context.Context, id string) (*resource.Model, error) {
+		if id == fakeFetchID {
+			return &resource.Model{Name: "lorem"}, nil
+		}
+		return nil, resource.ErrNotFound
+	}))
+	client := NewClient(localConn{s: NewServer(fc)})
+	ctx := context.Background()
+
+	got, err := client.Fetch(ctx, fakeFetchID)
+	if err != nil || !reflect.DeepEqual(got, &resource.Model{Name: "lorem"}) {
+		t.Errorf("Client.Fetch() = %v, %v, want %v", got, err, "lorem")
+	}
+	if _, err := client.Fetch(ctx, notExistModelID); err != resource.ErrNotFound {
+		t.Errorf("Client.Fetch() error = %v, want %v", err, resource.ErrNotFound)
+	}
+
+	models, errs, err := client.FetchMany(ctx, []string{fakeFetchID, notExistModelID})
+	if err != nil || len(models) != 1 || len(errs) != 1 {
+		t.Errorf("Client.FetchMany() = %v, %v, %v, want 1 model and 1 error", models, errs, err)
+	}
+
+	if err := client.Clear(ctx, fakeFetchID); err != nil {
+		t.Errorf("Client.Clear() error = %v", err)
+	}
+	stats, err := client.Stats(ctx)
+	if err != nil || stats.Items != 0 || stats.Hits != 1 {
+		t.Errorf("Client.Stats() = %+v, %v, want 0 items and 1 hit", stats, err)
+	}
+
+	err = localConn{s: NewServer(fc)}.Invoke(ctx, "/resource.Cache/Unknown", &StatsRequest{}, &StatsResponse{})
+	if !errors.Is(err, ErrUnknownMethod) {
+		t.Errorf("Server.Invoke() error = %v, want %v", err, ErrUnknownMethod)
+	}
+}
@@ -0,0 +1,62 @@
+package cachegrpc
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	resource "github.com/hieunmce/cache"
+)
+
+// UnaryFunc calls a unary method of any gRPC service for id, typically a
+// closure over a generated client converting the reply to a model.
+type UnaryFunc func(ctx context.Context, id string) (*resource.Model, error)
+
+// UnaryFetcher is a resource.Fetcher over a UnaryFunc, so the models
+// served by gRPC services go through the negative caching and error
+// policies of the cache like any other.
+type UnaryFetcher struct {
+	call UnaryFunc
+	// IsNotFound reports whether an error of the call means the model
+	// doesn't exist, Fetch then returns resource.ErrNotFound. With grpc-go
+	// it should check status.Code(err) == codes.NotFound.
+	IsNotFound func(error) bool
+	// Timeout is the deadline of the calls made with a context without
+	// one, none if 0. The deadline of the context is propagated by the
+	// ClientConn.
+	Timeout time.Duration
+}
+
+// NewUnaryFetcher creates a UnaryFetcher calling call.
+func NewUnaryFetcher(call UnaryFunc) *UnaryFetcher {
+	return &UnaryFetcher{
+		call: call,
+		IsNotFound: func(err error) bool {
+			return errors.Is(err, resource.ErrNotFound)
+		},
+	}
+}
+
+// Fetch implements resource.Fetcher. The errors of calls whose context is
+// done are reported as the error of the context, so codes.DeadlineExceeded
+// and codes.Canceled read as context.DeadlineExceeded and
+// context.Canceled.
+func (f *UnaryFetcher) Fetch(ctx context.Context, id string) (*resource.Model, error) {
+	if _, ok := ctx.Deadline(); !ok && f.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, f.Timeout)
+		defer cancel()
+	}
+	model, err := f.call(ctx, id)
+	switch {
+	case err == nil && model == nil:
+		return nil, resource.ErrNotFound
+	case err == nil:
+		return model, nil
+	case f.IsNotFound != nil && f.IsNotFound(err):
+		return nil, resource.ErrNotFound
+	case ctx.Err() != nil:
+		return nil, ctx.Err()
+	}
+	return nil, err
+}
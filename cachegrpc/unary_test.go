@@ -0,0 +1,90 @@
+package cachegrpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	resource "github.com/hieunmce/cache"
+)
+
+func TestUnaryFetcher_Fetch(t *testing.T) {
+	var (
+		fakeFetchID = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+		errNotFound = errors.New("rpc error: code = NotFound")
+		errBackend  = errors.New("rpc error: code = Unavailable")
+	)
+
+	tests := []struct {
+		name         string
+		call         UnaryFunc
+		timeout      time.Duration
+		wantDeadline bool
+		wantErr      error
+	}{
+		{
+			name: "success fetch model",
+			call: func(ctx context.Context, id string) (*resource.Model, error) {
+				return &resource.Model{Name: id}, nil
+			},
+		},
+		{
+			name: "success timeout sets a deadline",
+			call: func(ctx context.Context, id string) (*resource.Model, error) {
+				if _, ok := ctx.Deadline(); !ok {
+					return nil, errBackend
+				}
+				return &resource.Model{Name: id}, nil
+			},
+			timeout: time.Second,
+		},
+		{
+			name: "failed not found code",
+			call: func(ctx context.Context, id string) (*resource.Model, error) {
+				return nil, errNotFound
+			},
+			wantErr: resource.ErrNotFound,
+		},
+		{
+			name: "failed nil reply",
+			call: func(ctx context.Context, id string) (*resource.Model, error) {
+				return nil, nil
+			},
+			wantErr: resource.ErrNotFound,
+		},
+		{
+			name: "failed deadline exceeded",
+			call: func(ctx context.Context, id string) (*resource.Model, error) {
+				<-ctx.Done()
+				return nil, errBackend
+			},
+			timeout: time.Millisecond,
+			wantErr: context.DeadlineExceeded,
+		},
+		{
+			name: "failed backend error",
+			call: func(ctx context.Context, id string) (*resource.Model, error) {
+				return nil, errBackend
+			},
+			wantErr: errBackend,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := NewUnaryFetcher(tt.call)
+			f.Timeout = tt.timeout
+			defaultNotFound := f.IsNotFound
+			f.IsNotFound = func(err error) bool {
+				return err == errNotFound || defaultNotFound(err)
+			}
+			model, err := f.Fetch(context.Background(), fakeFetchID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("UnaryFetcher.Fetch() expect error = %v, have %v", tt.wantErr, err)
+			}
+			if tt.wantErr == nil && (model == nil || model.Name != fakeFetchID) {
+				t.Errorf("UnaryFetcher.Fetch() expect model %v, have %v", fakeFetchID, model)
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package resource
+
+import "errors"
+
+// Error list
+var (
+	ErrFetchCanceled = errors.New("fetch canceled by an invalidation")
+)
+
+// WithCancelOnClear cancels the context of the Fetcher calls in flight for
+// the ids removed by Clear, InvalidateSet and the invalidations of other
+// caches, and of all the calls on Flush, so a correction of the data isn't
+// followed by a fetch of the old data running to completion. The fetches
+// canceled fail with ErrFetchCanceled, the fetches waiting for them call
+// the Fetcher again.
+func WithCancelOnClear() Option {
+	return func(o *options) {
+		o.cancelOnClear = true
+	}
+}
@@ -0,0 +1,69 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWithCancelOnClear(t *testing.T) {
+	var fakeFetchID = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	tests := []struct {
+		name       string
+		invalidate func(fc *FetchCache)
+	}{
+		{
+			name:       "success canceled by Clear",
+			invalidate: func(fc *FetchCache) { fc.Clear(fakeFetchID) },
+		},
+		{
+			name:       "success canceled by InvalidateSet",
+			invalidate: func(fc *FetchCache) { fc.InvalidateSet(context.Background(), fakeFetchID) },
+		},
+		{
+			name:       "success canceled by Flush",
+			invalidate: func(fc *FetchCache) { fc.Flush() },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			started := make(chan struct{}, 1)
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					started <- struct{}{}
+					select {
+					case <-ctx.Done():
+						return nil, ctx.Err()
+					case <-time.After(10 * time.Second):
+						return &Model{Name: "old"}, nil
+					}
+				},
+			}
+			fc := NewCache(mockedFetcher, WithCancelOnClear())
+			errc := make(chan error, 1)
+			go func() {
+				_, err := fc.Fetch(context.Background(), fakeFetchID)
+				errc <- err
+			}()
+			<-started
+
+			begin := time.Now()
+			tt.invalidate(fc)
+			select {
+			case err := <-errc:
+				if !errors.Is(err, ErrFetchCanceled) {
+					t.Errorf("FetchCache.Fetch() expect error = %v, have %v", ErrFetchCanceled, err)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("FetchCache.Fetch() expect the fetch canceled")
+			}
+			if d := time.Since(begin); d > 5*time.Second {
+				t.Errorf("invalidation expect not to wait for the fetch, took %v", d)
+			}
+			if _, ok := fc.Entry(fakeFetchID); ok {
+				t.Errorf("FetchCache.Entry() expect nothing cached")
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package resource
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// WithCardinalityGuard caches at most perMinute keys which weren't cached
+// in every minute: past it the models of new keys are returned to the
+// caller without being cached, counted in Stats, and alert, if not nil, is
+// called with the first key rejected in the minute. It protects the cache
+// from request patterns embedding timestamps or request ids in the keys.
+func WithCardinalityGuard(perMinute int, alert func(key string)) Option {
+	return func(o *options) {
+		o.newKeysPerMinute = perMinute
+		o.cardinalityAlert = alert
+	}
+}
+
+// cardinalityGuard counts the new keys cached in the current minute
+type cardinalityGuard struct {
+	mu      sync.Mutex
+	start   time.Time
+	n       int
+	alerted bool
+}
+
+// admit reports whether a new key may be cached at now under limit, and
+// whether it is the first key rejected in the minute
+func (g *cardinalityGuard) admit(now time.Time, limit int) (bool, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if now.Sub(g.start) >= time.Minute {
+		g.start, g.n, g.alerted = now, 0, false
+	}
+	if g.n < limit {
+		g.n++
+		return true, false
+	}
+	first := !g.alerted
+	g.alerted = true
+	return false, first
+}
+
+// overCardinality reports whether key isn't cached and too many new keys
+// were cached in the current minute
+func (fc *FetchCache) overCardinality(key string) bool {
+	if fc.opts.newKeysPerMinute <= 0 {
+		return false
+	}
+	if _, found := fc.peekitem(key); found {
+		return false
+	}
+	ok, first := fc.cardinality.admit(time.Now(), fc.opts.newKeysPerMinute)
+	if ok {
+		return false
+	}
+	atomic.AddUint64(&fc.metrics.cardinalityRejected, 1)
+	if first && fc.opts.cardinalityAlert != nil {
+		fc.opts.cardinalityAlert(key)
+	}
+	return true
+}
@@ -0,0 +1,64 @@
+package resource
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestWithCardinalityGuard(t *testing.T) {
+	mockedFetcher := &FetcherMock{
+		FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+			return &Model{Name: id}, nil
+		},
+	}
+	var alerts []string
+	fc := NewCache(mockedFetcher, WithCardinalityGuard(3, func(key string) {
+		alerts = append(alerts, key)
+	}))
+
+	for i := 0; i < 5; i++ {
+		model, err := fc.Fetch(context.Background(), strconv.Itoa(i))
+		if err != nil || model.Name != strconv.Itoa(i) {
+			t.Fatalf("FetchCache.Fetch() expect model %v, have %v, %v", i, model, err)
+		}
+	}
+	// cached keys are still stored
+	if _, err := fc.FetchWithOptions(context.Background(), "0", BypassCache()); err != nil {
+		t.Fatalf("FetchCache.FetchWithOptions() expect no error, have %v", err)
+	}
+
+	s := fc.Stats()
+	if s.Items != 3 || s.CardinalityRejected != 2 {
+		t.Errorf("FetchCache.Stats() expect 3 items and 2 rejected, have %v and %v", s.Items, s.CardinalityRejected)
+	}
+	if len(alerts) != 1 || alerts[0] != "3" {
+		t.Errorf("WithCardinalityGuard() expect a single alert of 3, have %v", alerts)
+	}
+}
+
+func Test_cardinalityGuard_admit(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name      string
+		at        time.Duration
+		wantOK    bool
+		wantFirst bool
+	}{
+		{name: "success first key", at: 0, wantOK: true},
+		{name: "success second key", at: time.Second, wantOK: true},
+		{name: "failed over the limit", at: 2 * time.Second, wantFirst: true},
+		{name: "failed still over the limit", at: 3 * time.Second},
+		{name: "success next minute", at: time.Minute, wantOK: true},
+	}
+	g := &cardinalityGuard{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, first := g.admit(now.Add(tt.at), 2)
+			if ok != tt.wantOK || first != tt.wantFirst {
+				t.Errorf("cardinalityGuard.admit() expect %v, %v, have %v, %v", tt.wantOK, tt.wantFirst, ok, first)
+			}
+		})
+	}
+}
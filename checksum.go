@@ -0,0 +1,87 @@
+package resource
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+)
+
+// CorruptionError is returned by reads of a value of a disk or off-heap
+// tier which doesn't match its checksum, see WithChecksums.
+type CorruptionError struct {
+	ID string
+	// Tier is where the value was stored, "l2" or "stream".
+	Tier string
+}
+
+// Error implements error.
+func (e *CorruptionError) Error() string {
+	return fmt.Sprintf("%s value of %q corrupted: checksum mismatch", e.Tier, e.ID)
+}
+
+// WithChecksums stores a CRC-32C checksum with the values written to the
+// L2 store and the files of a StreamCache, and verifies it on read so bit
+// rot can't propagate corrupted models. A corrupted L2 value is deleted
+// and fetched again from the Fetcher. A corrupted stream file fails its
+// read with a CorruptionError once the whole file is read, and is cleared
+// so the next Open fetches it again.
+//
+// L2 values written with checksums can't be read by caches without them.
+func WithChecksums() Option {
+	return func(o *options) {
+		o.checksums = true
+	}
+}
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// checksumSize is the size of a checksum
+const checksumSize = 4
+
+// appendChecksum returns data prefixed with its checksum
+func appendChecksum(data []byte) []byte {
+	out := make([]byte, checksumSize, checksumSize+len(data))
+	binary.BigEndian.PutUint32(out, crc32.Checksum(data, castagnoli))
+	return append(out, data...)
+}
+
+// verifyChecksum returns the data prefixed by appendChecksum and whether it
+// matches its checksum
+func verifyChecksum(b []byte) ([]byte, bool) {
+	if len(b) < checksumSize {
+		return nil, false
+	}
+	data := b[checksumSize:]
+	return data, binary.BigEndian.Uint32(b) == crc32.Checksum(data, castagnoli)
+}
+
+// checksumReader verifies the checksum of r when reaching its end, calling
+// corrupted and failing with a CorruptionError when it doesn't match
+type checksumReader struct {
+	r         io.ReadCloser
+	h         hash.Hash32
+	want      uint32
+	err       *CorruptionError
+	corrupted func()
+}
+
+// Read implements io.Reader.
+func (c *checksumReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.h.Write(p[:n])
+	if err == io.EOF && c.h.Sum32() != c.want {
+		if c.corrupted != nil {
+			c.corrupted()
+			c.corrupted = nil
+		}
+		return n, c.err
+	}
+	return n, err
+}
+
+// Close implements io.Closer.
+func (c *checksumReader) Close() error {
+	return c.r.Close()
+}
@@ -0,0 +1,141 @@
+package resource
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"sync/atomic"
+	"testing"
+)
+
+func TestFetchCache_WithChecksums_L2(t *testing.T) {
+	fakeFetchID := "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+
+	tests := []struct {
+		name             string
+		corrupt          bool
+		serviceCallCount int
+		wantCorrupted    uint64
+	}{
+		{
+			name:             "success verified",
+			serviceCallCount: 0,
+		},
+		{
+			name:             "success corrupted refetched",
+			corrupt:          true,
+			serviceCallCount: 1,
+			wantCorrupted:    1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMapL2Store()
+			writer := NewCache(&FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					return &Model{Name: "lorem"}, nil
+				},
+			}, WithL2(store, nil), WithChecksums())
+			defer writer.Close(context.Background())
+			_, _ = writer.Fetch(context.Background(), fakeFetchID)
+			if tt.corrupt {
+				store.data[fakeFetchID][len(store.data[fakeFetchID])-2] ^= 1
+			}
+
+			serviceCallCount := 0
+			fc := NewCache(&FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					serviceCallCount++
+					return &Model{Name: "lorem"}, nil
+				},
+			}, WithL2(store, nil), WithChecksums())
+			defer fc.Close(context.Background())
+
+			model, err := fc.Fetch(context.Background(), fakeFetchID)
+			if err != nil || model.Name != "lorem" {
+				t.Fatalf("FetchCache.Fetch() expect lorem, have %v, %v", model, err)
+			}
+			if serviceCallCount != tt.serviceCallCount {
+				t.Errorf("FetchCache.Fetch() expect service call count = %v, have %v", tt.serviceCallCount, serviceCallCount)
+			}
+			if have := fc.Stats().Corrupted; have != tt.wantCorrupted {
+				t.Errorf("FetchCache.Stats() expect corrupted = %v, have %v", tt.wantCorrupted, have)
+			}
+			if _, valid := verifyChecksum(store.data[fakeFetchID]); !valid {
+				t.Errorf("FetchCache.Fetch() expect valid L2 value, have %q", store.data[fakeFetchID])
+			}
+		})
+	}
+}
+
+func TestStreamCache_OpenChecksums(t *testing.T) {
+	fakeFetchID := "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	payload := bytes.Repeat([]byte("lorem ipsum "), 1000)
+
+	tests := []struct {
+		name      string
+		corrupt   bool
+		wantErr   bool
+		wantCalls int32
+	}{
+		{
+			name:      "success verified",
+			wantCalls: 1,
+		},
+		{
+			name:      "failed corrupted",
+			corrupt:   true,
+			wantErr:   true,
+			wantCalls: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+			f := streamingFetcherFunc(func(ctx context.Context, id string) (io.ReadCloser, int64, error) {
+				atomic.AddInt32(&calls, 1)
+				return io.NopCloser(bytes.NewReader(payload)), int64(len(payload)), nil
+			})
+			sc, err := NewStreamCache(f, t.TempDir(), WithChecksums())
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer sc.Close(context.Background())
+
+			model, _ := sc.Cache().Fetch(context.Background(), fakeFetchID)
+			if tt.corrupt {
+				b, _ := os.ReadFile(model.Name)
+				b[10] ^= 1
+				if err := os.WriteFile(model.Name, b, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			r, _, err := sc.Open(context.Background(), fakeFetchID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = io.ReadAll(r)
+			r.Close()
+			var cerr *CorruptionError
+			if errors.As(err, &cerr) != tt.wantErr {
+				t.Fatalf("StreamCache.Open() expect CorruptionError = %v, have %v", tt.wantErr, err)
+			}
+
+			r, _, err = sc.Open(context.Background(), fakeFetchID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			have, err := io.ReadAll(r)
+			r.Close()
+			if err != nil || !bytes.Equal(have, payload) {
+				t.Errorf("StreamCache.Open() expect payload, have %d bytes, %v", len(have), err)
+			}
+			if have := atomic.LoadInt32(&calls); have != tt.wantCalls {
+				t.Errorf("StreamCache.Open() expect calls = %v, have %v", tt.wantCalls, have)
+			}
+		})
+	}
+}
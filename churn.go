@@ -0,0 +1,65 @@
+package resource
+
+import (
+	"math/bits"
+	"sync/atomic"
+)
+
+// maxChurnWindow is the most refreshes a churn window holds
+const maxChurnWindow = 64
+
+// WithChurnTracking compares every model fetched for a cached key with the
+// model it replaces using equal and keeps whether it changed over the last
+// window refreshes of the key, at most 64, see KeyStats.Churn. A rising
+// churn tells models supposed to be static which started changing, and can
+// drive an adaptive TTL.
+func WithChurnTracking(equal func(prev, next *Model) bool, window int) Option {
+	return func(o *options) {
+		if window < 1 || window > maxChurnWindow {
+			window = maxChurnWindow
+		}
+		o.churnEqual = equal
+		o.churnWindow = window
+	}
+}
+
+// trackChurn records whether model changed from the model cached for key
+func (fc *FetchCache) trackChurn(key string, model *Model) {
+	if fc.opts.churnEqual == nil {
+		return
+	}
+	fc.rlockItems()
+	old, found := fc.items.Get(key)
+	fc.itemsLock.RUnlock()
+	if !found || old.Object == nil {
+		return
+	}
+	fc.stats.get(key).churned(!fc.opts.churnEqual(old.Object, model), fc.opts.churnWindow)
+}
+
+// churned shifts a refresh in the churn window of ks, the fetches of a key
+// being serialized by its lock
+func (ks *keyStats) churned(changed bool, window int) {
+	b := atomic.LoadUint64(&ks.churn) << 1
+	if changed {
+		b |= 1
+	}
+	if window < maxChurnWindow {
+		b &= 1<<uint(window) - 1
+	}
+	atomic.StoreUint64(&ks.churn, b)
+	if n := atomic.LoadInt64(&ks.churnSamples); n < int64(window) {
+		atomic.StoreInt64(&ks.churnSamples, n+1)
+	}
+}
+
+// churnRate returns the fraction of the refreshes in the churn window which
+// changed the model and the number of refreshes in the window
+func (ks *keyStats) churnRate() (float64, int) {
+	n := atomic.LoadInt64(&ks.churnSamples)
+	if n == 0 {
+		return 0, 0
+	}
+	changes := bits.OnesCount64(atomic.LoadUint64(&ks.churn))
+	return float64(changes) / float64(n), int(n)
+}
@@ -0,0 +1,69 @@
+package resource
+
+import (
+	"context"
+	"testing"
+)
+
+func TestWithChurnTracking(t *testing.T) {
+	fakeFetchID := "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	equal := func(prev, next *Model) bool { return prev.Name == next.Name }
+
+	tests := []struct {
+		name        string
+		equal       func(prev, next *Model) bool
+		window      int
+		names       []string
+		wantChurn   float64
+		wantSamples int
+	}{
+		{
+			name:        "success changing model",
+			equal:       equal,
+			window:      8,
+			names:       []string{"a", "a", "b", "b", "c"},
+			wantChurn:   0.5,
+			wantSamples: 4,
+		},
+		{
+			name:        "success sliding window",
+			equal:       equal,
+			window:      3,
+			names:       []string{"a", "a", "b", "b", "c"},
+			wantChurn:   2.0 / 3,
+			wantSamples: 3,
+		},
+		{
+			name:        "success static model",
+			equal:       equal,
+			names:       []string{"a", "a", "a"},
+			wantSamples: 2,
+		},
+		{
+			name:  "success not tracked",
+			names: []string{"a", "b"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serviceCallCount := 0
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					name := tt.names[serviceCallCount]
+					serviceCallCount++
+					return &Model{Name: name}, nil
+				},
+			}
+			fc := NewCache(mockedFetcher, WithChurnTracking(tt.equal, tt.window))
+			defer fc.Close(context.Background())
+
+			for range tt.names {
+				_, _ = fc.FetchWithOptions(context.Background(), fakeFetchID, BypassCache())
+			}
+			s, _ := fc.KeyStats(fakeFetchID)
+			if s.Churn != tt.wantChurn || s.ChurnSamples != tt.wantSamples {
+				t.Errorf("FetchCache.KeyStats() expect churn = %v over %v, have %v over %v", tt.wantChurn, tt.wantSamples, s.Churn, s.ChurnSamples)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+// Command cachectl talks to the admin API of a running cache, see package
+// admin.
+//
+// Usage:
+//
+//	cachectl [-addr URL] [-actor NAME] command [args]
+//
+// The commands are:
+//
+//	keys                list the cached keys
+//	stats               show the cache statistics
+//	get ID              show the metadata of an entry
+//	invalidate ID...    clear ids, several ids are cleared atomically
+//	flush               flush the cache
+//	snapshot            print a dump of the cache
+//	inflight            list the fetches in progress
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hieunmce/cache/admin"
+)
+
+// Error list
+var (
+	errUsage = errors.New("usage: cachectl [-addr URL] [-actor NAME] keys|stats|get ID|invalidate ID...|flush|snapshot|inflight")
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("cachectl", flag.ContinueOnError)
+	addr := flags.String("addr", envOr("CACHECTL_ADDR", "http://localhost:8080/debug/cache"), "base URL of the admin API")
+	actor := flags.String("actor", envOr("USER", "cachectl"), "name recorded in the audit trail")
+	timeout := flags.Duration("timeout", 10*time.Second, "request timeout")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		return errUsage
+	}
+
+	cmd, rest := flags.Arg(0), flags.Args()[1:]
+	var (
+		method = http.MethodGet
+		path   string
+		query  = url.Values{}
+	)
+	switch {
+	case cmd == "keys" && len(rest) == 0:
+		path = "/keys"
+	case cmd == "stats" && len(rest) == 0:
+		path = "/stats"
+	case cmd == "get" && len(rest) == 1:
+		path = "/entry"
+		query.Set("id", rest[0])
+	case cmd == "invalidate" && len(rest) > 0:
+		method, path = http.MethodPost, "/invalidate"
+		query["id"] = rest
+	case cmd == "flush" && len(rest) == 0:
+		method, path = http.MethodPost, "/flush"
+	case cmd == "snapshot" && len(rest) == 0:
+		path = "/snapshot"
+	case cmd == "inflight" && len(rest) == 0:
+		path = "/inflight"
+	default:
+		return errUsage
+	}
+
+	u := strings.TrimSuffix(*addr, "/") + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequest(method, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set(admin.ActorHeader, *actor)
+
+	client := &http.Client{Timeout: *timeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("cachectl: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	_, err = io.Copy(stdout, resp.Body)
+	return err
+}
+
+func envOr(name, fallback string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return fallback
+}
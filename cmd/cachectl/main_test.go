@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	resource "github.com/hieunmce/cache"
+	"github.com/hieunmce/cache/admin"
+)
+
+// fetcherFunc adapts a func to resource.Fetcher
+type fetcherFunc func(ctx context.Context, id string) (*resource.Model, error)
+
+func (f fetcherFunc) Fetch(ctx context.Context, id string) (*resource.Model, error) {
+	return f(ctx, id)
+}
+
+func TestRun(t *testing.T) {
+	var (
+		fakeFetchID     = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+		notExistModelID = "5634aeed-2106-43de-ab7d-c0ad4b1e195e"
+	)
+
+	tests := []struct {
+		name       string
+		args       []string
+		wantOutput string
+		wantErr    bool
+	}{
+		{
+			name:       "success keys",
+			args:       []string{"keys"},
+			wantOutput: fakeFetchID,
+		},
+		{
+			name:       "success stats",
+			args:       []string{"stats"},
+			wantOutput: `"items": 1`,
+		},
+		{
+			name:       "success get",
+			args:       []string{"get", fakeFetchID},
+			wantOutput: `"version": 1`,
+		},
+		{
+			name:    "failed get not cached",
+			args:    []string{"get", notExistModelID},
+			wantErr: true,
+		},
+		{
+			name:       "success invalidate",
+			args:       []string{"-actor", "alice", "invalidate", fakeFetchID, notExistModelID},
+			wantOutput: notExistModelID,
+		},
+		{
+			name:       "success inflight",
+			args:       []string{"inflight"},
+			wantOutput: "[]",
+		},
+		{
+			name:       "success flush",
+			args:       []string{"flush"},
+			wantOutput: "{}",
+		},
+		{
+			name:       "success snapshot",
+			args:       []string{"snapshot"},
+			wantOutput: `"entries"`,
+		},
+		{
+			name:    "failed unknown command",
+			args:    []string{"drop"},
+			wantErr: true,
+		},
+		{
+			name:    "failed missing id",
+			args:    []string{"get"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fc := resource.NewCache(fetcherFunc(func(ctx context.Context, id string) (*resource.Model, error) {
+				return &resource.Model{Name: id}, nil
+			}))
+			if _, err := fc.Fetch(context.Background(), fakeFetchID); err != nil {
+				t.Fatalf("FetchCache.Fetch() error = %v", err)
+			}
+			srv := httptest.NewServer(admin.NewHandler(fc))
+			defer srv.Close()
+
+			var out bytes.Buffer
+			err := run(append([]string{"-addr", srv.URL}, tt.args...), &out)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !strings.Contains(out.String(), tt.wantOutput) {
+				t.Errorf("run() expect output containing %v, have %v", tt.wantOutput, out.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+// Command cacheseed builds a seed, the read-only cache artifact opened with
+// resource.OpenSeed and layered under a cache with resource.WithSeed, from
+// a dump written by FetchCache.Dump with the values, e.g. by
+// "cachectl snapshot".
+//
+// Usage:
+//
+//	cacheseed -o FILE [DUMP]
+//
+// The dump is read from stdin when DUMP is omitted. The expired entries of
+// the dump are left out.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	resource "github.com/hieunmce/cache"
+)
+
+// Error list
+var (
+	errUsage    = errors.New("usage: cacheseed -o FILE [DUMP]")
+	errNoOrigin = errors.New("cacheseed has no origin")
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	flags := flag.NewFlagSet("cacheseed", flag.ContinueOnError)
+	out := flags.String("o", "", "seed file to write")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *out == "" || flags.NArg() > 1 {
+		return errUsage
+	}
+	in := stdin
+	if flags.NArg() == 1 {
+		file, err := os.Open(flags.Arg(0))
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		in = file
+	}
+
+	fc := resource.NewCache(noOrigin{}, resource.WithSynchronous())
+	defer fc.Close(context.Background())
+	if _, err := fc.Hydrate(in); err != nil {
+		return err
+	}
+	file, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	n, err := fc.BuildSeed(file)
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(*out)
+		return err
+	}
+	fmt.Fprintf(stdout, "%d models written to %s\n", n, *out)
+	return nil
+}
+
+// noOrigin is the Fetcher of the cache holding the dump, which is never
+// called
+type noOrigin struct{}
+
+func (noOrigin) Fetch(ctx context.Context, id string) (*resource.Model, error) {
+	return nil, errNoOrigin
+}
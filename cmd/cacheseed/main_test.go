@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	resource "github.com/hieunmce/cache"
+)
+
+// fetcherFunc adapts a func to resource.Fetcher
+type fetcherFunc func(ctx context.Context, id string) (*resource.Model, error)
+
+func (f fetcherFunc) Fetch(ctx context.Context, id string) (*resource.Model, error) {
+	return f(ctx, id)
+}
+
+func TestRun(t *testing.T) {
+	fakeFetchID := "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+
+	fc := resource.NewCache(fetcherFunc(func(ctx context.Context, id string) (*resource.Model, error) {
+		return &resource.Model{Name: id, Data: []byte("lorem")}, nil
+	}))
+	fc.Fetch(context.Background(), fakeFetchID)
+	var dump bytes.Buffer
+	if err := fc.Dump(&dump, true); err != nil {
+		t.Fatalf("FetchCache.Dump() expect error = nil, have %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		args       func(dir string) []string
+		wantOutput string
+		wantErr    bool
+	}{
+		{
+			name:       "success build seed from stdin",
+			args:       func(dir string) []string { return []string{"-o", filepath.Join(dir, "seed")} },
+			wantOutput: "1 models written",
+		},
+		{
+			name:    "failed build without output",
+			args:    func(dir string) []string { return nil },
+			wantErr: true,
+		},
+		{
+			name: "failed build from missing dump",
+			args: func(dir string) []string {
+				return []string{"-o", filepath.Join(dir, "seed"), filepath.Join(dir, "missing")}
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			var out bytes.Buffer
+			err := run(tt.args(dir), bytes.NewReader(dump.Bytes()), &out)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("run() expect error = %v, have %v", tt.wantErr, err)
+			}
+			if !strings.Contains(out.String(), tt.wantOutput) {
+				t.Errorf("run() expect output containing %q, have %q", tt.wantOutput, out.String())
+			}
+			if tt.wantErr {
+				return
+			}
+			s, err := resource.OpenSeed(filepath.Join(dir, "seed"))
+			if err != nil {
+				t.Fatalf("OpenSeed() expect error = nil, have %v", err)
+			}
+			defer s.Close()
+			m, found, err := s.Get(fakeFetchID)
+			if err != nil || !found || string(m.Data) != "lorem" {
+				t.Errorf("Seed.Get() expect lorem, have %v, %v, %v", m, found, err)
+			}
+		})
+	}
+}
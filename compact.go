@@ -0,0 +1,83 @@
+package resource
+
+import "time"
+
+// defaultCompactLoad is the load below which a map is rebuilt
+const defaultCompactLoad = 0.25
+
+// WithCompaction calls Compact every interval, rebuilding the maps holding
+// less than minLoad of the items they held at their peak, 0.25 if minLoad
+// isn't in (0, 1).
+func WithCompaction(interval time.Duration, minLoad float64) Option {
+	return func(o *options) {
+		o.compactInterval = interval
+		o.compactLoad = minLoad
+	}
+}
+
+// Compact rebuilds the maps of the items holding less than the minLoad of
+// WithCompaction of the items they held at their peak, so the memory of
+// their buckets is released: a Go map never shrinks, it keeps the buckets
+// of its peak after a flush or a churn. It returns the number of maps
+// rebuilt. A ShardedStore is rebuilt shard by shard, each one under the
+// items lock, other Stores are left alone.
+func (fc *FetchCache) Compact() int {
+	minLoad := fc.opts.compactLoad
+	if minLoad <= 0 || minLoad >= 1 {
+		minLoad = defaultCompactLoad
+	}
+	fc.rlockItems()
+	s, sharded := fc.items.(*ShardedStore)
+	fc.itemsLock.RUnlock()
+	if !sharded {
+		fc.lockItems()
+		defer fc.itemsLock.Unlock()
+		store, ok := fc.items.(mapStore)
+		if !ok {
+			return 0
+		}
+		m, ok := compactMap(store, fc.peak, minLoad)
+		if !ok {
+			return 0
+		}
+		fc.items, fc.peak = m, len(m)
+		return 1
+	}
+
+	compacted := 0
+	for n := range s.shards {
+		fc.lockItems()
+		if s.compact(n, minLoad) {
+			compacted++
+		}
+		fc.itemsLock.Unlock()
+	}
+	return compacted
+}
+
+// compactMap returns a copy of m sized to its items and true if m holds
+// less than minLoad of peak
+func compactMap(m mapStore, peak int, minLoad float64) (mapStore, bool) {
+	if float64(len(m)) >= float64(peak)*minLoad {
+		return nil, false
+	}
+	c := make(mapStore, len(m))
+	for key, i := range m {
+		c[key] = i
+	}
+	return c, true
+}
+
+// runCompaction compacts every interval until stop
+func (fc *FetchCache) runCompaction(stop <-chan struct{}) {
+	ticker := time.NewTicker(fc.opts.compactInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+		fc.Compact()
+	}
+}
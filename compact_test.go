@@ -0,0 +1,79 @@
+package resource
+
+import (
+	"context"
+	"strconv"
+	"testing"
+)
+
+func TestFetchCache_Compact(t *testing.T) {
+	mockedFetcher := &FetcherMock{
+		FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+			return &Model{Name: id}, nil
+		},
+	}
+	tests := []struct {
+		name string
+		opts []Option
+		keep int
+		want int
+	}{
+		{
+			name: "success map store",
+			keep: 10,
+			want: 1,
+		},
+		{
+			name: "success sharded store",
+			opts: []Option{WithStore(NewShardedStore(4, nil, nil))},
+			keep: 10,
+			want: 4,
+		},
+		{
+			name: "success above min load",
+			opts: []Option{WithCompaction(0, 0.5)},
+			keep: 600,
+			want: 0,
+		},
+		{
+			name: "success custom store",
+			opts: []Option{WithStore(&countingStore{mapStore: mapStore{}})},
+			keep: 10,
+			want: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fc := NewCache(mockedFetcher, tt.opts...)
+			ctx := context.Background()
+			var removed []string
+			for i := 0; i < 1000; i++ {
+				_, _ = fc.Fetch(ctx, strconv.Itoa(i))
+				if i >= tt.keep {
+					removed = append(removed, strconv.Itoa(i))
+				}
+			}
+			fc.InvalidateSet(ctx, removed...)
+			before := fc.EstimateMemory()
+
+			if have := fc.Compact(); have != tt.want {
+				t.Errorf("FetchCache.Compact() expect %v, have %v", tt.want, have)
+			}
+			if have := fc.Compact(); have != 0 {
+				t.Errorf("FetchCache.Compact() expect 0 once compacted, have %v", have)
+			}
+			after := fc.EstimateMemory()
+			if after.Items != tt.keep {
+				t.Errorf("FetchCache.Compact() expect %v items, have %v", tt.keep, after.Items)
+			}
+			if tt.want > 0 && after.LoadFactor <= before.LoadFactor {
+				t.Errorf("FetchCache.Compact() expect the load factor to grow from %v, have %v", before.LoadFactor, after.LoadFactor)
+			}
+			for i := 0; i < tt.keep; i++ {
+				if _, _, found := fc.GetWithExpiration(strconv.Itoa(i)); !found {
+					t.Errorf("FetchCache.Compact() expect %v kept", i)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package resource
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ErrInvalidConfig is returned for a Config which fails validation.
+var ErrInvalidConfig = errors.New("invalid config")
+
+// Duration is a time.Duration which is written as "10m", "1h30m" etc. in
+// JSON and YAML config files. Plain JSON numbers are read as nanoseconds.
+type Duration time.Duration
+
+// MarshalText implements encoding.TextMarshaler.
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var ns int64
+	if err := json.Unmarshal(data, &ns); err == nil {
+		*d = Duration(ns)
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	return d.UnmarshalText([]byte(s))
+}
+
+// Config captures the cache settings which can be driven from config
+// files, see FromConfig. The settings which take code, such as a Store
+// other than the sharded one, the L2 store, the hash of the shards or the
+// predicates of WithErrorCache, are left out and given as options to
+// FromConfig.
+type Config struct {
+	// TTL is how long fetched models are cached, 0 means forever.
+	TTL Duration `json:"ttl" yaml:"ttl"`
+	// TTLJitter randomly shortens each TTL by up to this fraction.
+	TTLJitter float64 `json:"ttl_jitter" yaml:"ttl_jitter"`
+	// MaxEntries bounds the number of cached items, 0 means no limit.
+	MaxEntries int `json:"max_entries" yaml:"max_entries"`
+	// MaxConcurrency bounds concurrent Fetcher calls, 0 means no limit.
+	MaxConcurrency int `json:"max_concurrency" yaml:"max_concurrency"`
+	// BatchWindow enables batching when the Fetcher is a BatchFetcher.
+	BatchWindow Duration `json:"batch_window" yaml:"batch_window"`
+	// BatchMax is the largest batch issued, 0 means no limit.
+	BatchMax int `json:"batch_max" yaml:"batch_max"`
+	// ErrorTTL is how long Fetcher errors are cached, 0 disables it.
+	ErrorTTL Duration `json:"error_ttl" yaml:"error_ttl"`
+	// ErrorMaxEntries bounds the number of cached errors, 0 means no limit.
+	ErrorMaxEntries int `json:"error_max_entries" yaml:"error_max_entries"`
+	// EvictionPolicy is "oldest", the default, "cost_aware" or "sampled",
+	// see WithEvictionPolicy.
+	EvictionPolicy string `json:"eviction_policy" yaml:"eviction_policy"`
+	// Shards holds the items in a ShardedStore of that many shards, routed
+	// by FNV-1a modulo the shards, 0 means a single map.
+	Shards int `json:"shards" yaml:"shards"`
+	// HotKeysFile persists the HotKeys most hit ids to this file every
+	// HotKeysInterval and on Close, and warms the cache with them on
+	// startup, see WithHotKeys and FileHotKeys. Empty disables it.
+	HotKeysFile     string   `json:"hot_keys_file" yaml:"hot_keys_file"`
+	HotKeys         int      `json:"hot_keys" yaml:"hot_keys"`
+	HotKeysInterval Duration `json:"hot_keys_interval" yaml:"hot_keys_interval"`
+}
+
+// evictionPolicies are the names of the eviction policies in a Config
+var evictionPolicies = map[string]EvictionPolicy{
+	"":           EvictOldest,
+	"oldest":     EvictOldest,
+	"cost_aware": EvictCostAware,
+	"sampled":    EvictSampled,
+}
+
+// Validate returns an ErrInvalidConfig error describing the first invalid
+// setting of c.
+func (c Config) Validate() error {
+	switch {
+	case c.TTL < 0:
+		return fmt.Errorf("%w: ttl must not be negative", ErrInvalidConfig)
+	case c.TTLJitter < 0 || c.TTLJitter >= 1:
+		return fmt.Errorf("%w: ttl_jitter must be in [0, 1)", ErrInvalidConfig)
+	case c.MaxEntries < 0:
+		return fmt.Errorf("%w: max_entries must not be negative", ErrInvalidConfig)
+	case c.MaxConcurrency < 0:
+		return fmt.Errorf("%w: max_concurrency must not be negative", ErrInvalidConfig)
+	case c.BatchWindow < 0:
+		return fmt.Errorf("%w: batch_window must not be negative", ErrInvalidConfig)
+	case c.BatchMax < 0:
+		return fmt.Errorf("%w: batch_max must not be negative", ErrInvalidConfig)
+	case c.ErrorTTL < 0:
+		return fmt.Errorf("%w: error_ttl must not be negative", ErrInvalidConfig)
+	case c.ErrorMaxEntries < 0:
+		return fmt.Errorf("%w: error_max_entries must not be negative", ErrInvalidConfig)
+	case c.Shards < 0:
+		return fmt.Errorf("%w: shards must not be negative", ErrInvalidConfig)
+	case c.HotKeys < 0 || c.HotKeysInterval < 0:
+		return fmt.Errorf("%w: hot_keys and hot_keys_interval must not be negative", ErrInvalidConfig)
+	case c.HotKeysFile != "" && c.HotKeys == 0:
+		return fmt.Errorf("%w: hot_keys_file requires hot_keys", ErrInvalidConfig)
+	}
+	if _, ok := evictionPolicies[c.EvictionPolicy]; !ok {
+		return fmt.Errorf("%w: unknown eviction_policy %q", ErrInvalidConfig, c.EvictionPolicy)
+	}
+	return nil
+}
+
+// Options returns the options equivalent to c for fetcher f.
+func (c Config) Options(f Fetcher) []Option {
+	opts := []Option{
+		WithTTL(time.Duration(c.TTL)),
+		WithTTLJitter(c.TTLJitter),
+		WithMaxEntries(c.MaxEntries),
+		WithMaxConcurrency(c.MaxConcurrency),
+		WithErrorCache(ErrorCache{TTL: time.Duration(c.ErrorTTL), MaxEntries: c.ErrorMaxEntries}),
+		WithEvictionPolicy(evictionPolicies[c.EvictionPolicy]),
+	}
+	if c.Shards > 0 {
+		opts = append(opts, WithStore(NewShardedStore(c.Shards, nil, nil)))
+	}
+	if c.HotKeysFile != "" {
+		opts = append(opts, WithHotKeys(FileHotKeys(c.HotKeysFile), c.HotKeys, time.Duration(c.HotKeysInterval), c.MaxConcurrency))
+	}
+	if bf, ok := f.(BatchFetcher); ok && c.BatchWindow > 0 {
+		opts = append(opts, WithBatchFetcher(bf, time.Duration(c.BatchWindow), c.BatchMax))
+	}
+	return opts
+}
+
+// LoadConfig reads a JSON encoded Config from r, unknown fields are
+// rejected so typos don't go unnoticed. For YAML files decode the Config
+// with a YAML library honoring the yaml tags and encoding.TextUnmarshaler,
+// which Duration implements, then call Validate.
+func LoadConfig(r io.Reader) (Config, error) {
+	var c Config
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&c); err != nil {
+		return Config{}, err
+	}
+	return c, c.Validate()
+}
+
+// FromConfig validates c and creates a new cache for f from it with New,
+// opts are applied after the settings of c.
+func FromConfig(f Fetcher, c Config, opts ...Option) (*FetchCache, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return New(f, append(c.Options(f), opts...)...)
+}
@@ -0,0 +1,113 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Config
+		wantErr error
+	}{
+		{
+			name:  "success load durations as strings and numbers",
+			input: `{"ttl": "10m", "ttl_jitter": 0.1, "max_entries": 100, "batch_window": 5000000}`,
+			want: Config{
+				TTL:         Duration(10 * time.Minute),
+				TTLJitter:   0.1,
+				MaxEntries:  100,
+				BatchWindow: Duration(5 * time.Millisecond),
+			},
+		},
+		{
+			name:  "success load policy, shards and hot keys",
+			input: `{"eviction_policy": "cost_aware", "shards": 16, "hot_keys_file": "/tmp/hot", "hot_keys": 100, "hot_keys_interval": "1m"}`,
+			want: Config{
+				EvictionPolicy:  "cost_aware",
+				Shards:          16,
+				HotKeysFile:     "/tmp/hot",
+				HotKeys:         100,
+				HotKeysInterval: Duration(time.Minute),
+			},
+		},
+		{
+			name:    "failed validate unknown eviction policy",
+			input:   `{"eviction_policy": "lru"}`,
+			wantErr: ErrInvalidConfig,
+		},
+		{
+			name:    "failed validate hot keys file without hot keys",
+			input:   `{"hot_keys_file": "/tmp/hot"}`,
+			wantErr: ErrInvalidConfig,
+		},
+		{
+			name:    "failed validate negative max entries",
+			input:   `{"max_entries": -1}`,
+			wantErr: ErrInvalidConfig,
+		},
+		{
+			name:    "failed validate jitter out of range",
+			input:   `{"ttl_jitter": 1.5}`,
+			wantErr: ErrInvalidConfig,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := LoadConfig(strings.NewReader(tt.input))
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("LoadConfig() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr == nil && got != tt.want {
+				t.Errorf("LoadConfig() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := LoadConfig(strings.NewReader(`{"tll": "10m"}`)); err == nil {
+		t.Errorf("LoadConfig() expect error for unknown field")
+	}
+}
+
+func TestFromConfig(t *testing.T) {
+	mockedFetcher := &FetcherMock{
+		FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+			return &Model{Name: id}, nil
+		},
+	}
+
+	fc, err := FromConfig(mockedFetcher, Config{TTL: Duration(time.Minute), TTLJitter: 0.5, MaxEntries: 1})
+	if err != nil {
+		t.Fatalf("FromConfig() error = %v", err)
+	}
+	_, _ = fc.Fetch(context.Background(), "a")
+	_, _ = fc.Fetch(context.Background(), "b")
+
+	if fc.items.Len() != 1 {
+		t.Errorf("FromConfig() expect item count = %v, have %v", 1, fc.items.Len())
+	}
+	i, _ := fc.items.Get("b")
+	ttl := time.Duration(i.Expiration - i.Created)
+	if ttl < 30*time.Second || ttl > time.Minute {
+		t.Errorf("FromConfig() expect jittered ttl in [30s, 1m], have %v", ttl)
+	}
+
+	if _, err := FromConfig(mockedFetcher, Config{TTL: -1}); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("FromConfig() error = %v, want %v", err, ErrInvalidConfig)
+	}
+
+	sharded, err := FromConfig(mockedFetcher, Config{Shards: 4, EvictionPolicy: "cost_aware"})
+	if err != nil {
+		t.Fatalf("FromConfig() error = %v", err)
+	}
+	defer sharded.Close(context.Background())
+	if _, ok := sharded.items.(*ShardedStore); !ok || sharded.opts.evictionPolicy != EvictCostAware {
+		t.Errorf("FromConfig() expect sharded store and cost aware eviction, have %T and %v", sharded.items, sharded.opts.evictionPolicy)
+	}
+}
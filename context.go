@@ -0,0 +1,87 @@
+package resource
+
+import (
+	"context"
+	"time"
+)
+
+type fetchOptionsKey struct{}
+
+// WithFetchOptions returns a copy of ctx carrying opts, which are applied to
+// every Fetch made with the returned context. Options passed directly to
+// FetchWithOptions are applied after the ones carried by ctx.
+func WithFetchOptions(ctx context.Context, opts ...FetchOption) context.Context {
+	prev := fetchOptionsFromContext(ctx)
+	all := make([]FetchOption, 0, len(prev)+len(opts))
+	all = append(all, prev...)
+	all = append(all, opts...)
+	return context.WithValue(ctx, fetchOptionsKey{}, all)
+}
+
+// WithBypass returns a copy of ctx which makes Fetch skip the cache.
+// See BypassCache.
+func WithBypass(ctx context.Context) context.Context {
+	return WithFetchOptions(ctx, BypassCache())
+}
+
+// WithNoStore returns a copy of ctx which makes Fetch not cache results.
+// See NoStore.
+func WithNoStore(ctx context.Context) context.Context {
+	return WithFetchOptions(ctx, NoStore())
+}
+
+// WithMaxAge returns a copy of ctx which makes Fetch treat cached items older
+// than d as misses. See MinFreshness.
+func WithMaxAge(ctx context.Context, d time.Duration) context.Context {
+	return WithFetchOptions(ctx, MinFreshness(d))
+}
+
+func fetchOptionsFromContext(ctx context.Context) []FetchOption {
+	if ctx == nil {
+		return nil
+	}
+	opts, _ := ctx.Value(fetchOptionsKey{}).([]FetchOption)
+	return opts
+}
+
+// ContextFactory returns the context carrying the values, such as auth
+// tokens and trace spans, of the background refreshes of id.
+type ContextFactory func(id string) context.Context
+
+// WithRefreshContext makes the background refreshes, eager, scheduled,
+// queued in WithRefreshPool or run by Tick, call the Fetcher with a context
+// carrying the values of f(id). The deadline and cancellation of the
+// refreshes stay those of the cache, so a context of f canceled or past its
+// deadline doesn't stop them.
+func WithRefreshContext(f ContextFactory) Option {
+	return func(o *options) {
+		o.refreshContext = f
+	}
+}
+
+// refreshContext returns ctx carrying the values of the context of
+// WithRefreshContext for id
+func (fc *FetchCache) refreshContext(ctx context.Context, id string) context.Context {
+	if fc.opts.refreshContext == nil {
+		return ctx
+	}
+	values := fc.opts.refreshContext(id)
+	if values == nil {
+		return ctx
+	}
+	return valuesContext{Context: ctx, values: values}
+}
+
+// valuesContext is canceled with its Context and looks up values in values
+// first
+type valuesContext struct {
+	context.Context
+	values context.Context
+}
+
+func (c valuesContext) Value(key interface{}) interface{} {
+	if v := c.values.Value(key); v != nil {
+		return v
+	}
+	return c.Context.Value(key)
+}
@@ -0,0 +1,133 @@
+package resource
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestFetchCache_Fetch_ContextDirectives(t *testing.T) {
+	var (
+		fakeFetchID = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	)
+
+	tests := []struct {
+		name             string
+		ctx              func() context.Context
+		wait             time.Duration
+		serviceCallCount int
+	}{
+		{
+			name:             "success get from cache with plain context",
+			ctx:              context.Background,
+			serviceCallCount: 1,
+		},
+		{
+			name: "success bypass cache",
+			ctx: func() context.Context {
+				return WithBypass(context.Background())
+			},
+			serviceCallCount: 2,
+		},
+		{
+			name: "success combine directives",
+			ctx: func() context.Context {
+				return WithNoStore(WithBypass(context.Background()))
+			},
+			serviceCallCount: 2,
+		},
+		{
+			name: "success treat old item as miss",
+			ctx: func() context.Context {
+				return WithMaxAge(context.Background(), time.Millisecond)
+			},
+			wait:             5 * time.Millisecond,
+			serviceCallCount: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serviceCallCount := 0
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					serviceCallCount++
+					return &Model{Name: "lorem"}, nil
+				},
+			}
+			fc := NewCache(mockedFetcher)
+			_, _ = fc.Fetch(context.Background(), fakeFetchID)
+			time.Sleep(tt.wait)
+
+			_, _ = fc.Fetch(tt.ctx(), fakeFetchID)
+			if tt.serviceCallCount != serviceCallCount {
+				t.Errorf("FetchCache.Fetch() expect service call count = %v, have %v", tt.serviceCallCount, serviceCallCount)
+			}
+		})
+	}
+}
+
+func TestWithRefreshContext(t *testing.T) {
+	type tokenKey struct{}
+	var (
+		fakeFetchID = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	)
+
+	tests := []struct {
+		name      string
+		factory   ContextFactory
+		canceled  bool
+		wantToken interface{}
+		wantErr   bool
+	}{
+		{
+			name: "success without factory",
+		},
+		{
+			name: "success refresh carries the factory values",
+			factory: func(id string) context.Context {
+				ctx, cancel := context.WithCancel(context.WithValue(context.Background(), tokenKey{}, "token-"+id))
+				cancel()
+				return ctx
+			},
+			wantToken: "token-" + fakeFetchID,
+		},
+		{
+			name: "failed refresh canceled with the cache",
+			factory: func(id string) context.Context {
+				return context.WithValue(context.Background(), tokenKey{}, "token")
+			},
+			canceled:  true,
+			wantToken: "token",
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				token interface{}
+				err   error
+			)
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					token, err = ctx.Value(tokenKey{}), ctx.Err()
+					return &Model{Name: id}, nil
+				},
+			}
+			fc := NewCache(mockedFetcher, WithRefreshContext(tt.factory))
+			defer fc.Close(context.Background())
+
+			ctx, cancel := context.WithCancel(context.Background())
+			if tt.canceled {
+				cancel()
+			}
+			defer cancel()
+			_ = fc.reload(ctx, fakeFetchID)
+			if token != tt.wantToken {
+				t.Errorf("FetchCache.reload() expect token = %v, have %v", tt.wantToken, token)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("FetchCache.reload() expect canceled = %v, have %v", tt.wantErr, err)
+			}
+		})
+	}
+}
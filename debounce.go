@@ -0,0 +1,43 @@
+package resource
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// WithRefreshDebounce calls the Fetcher for a key at most once per
+// interval: a miss within interval of the last call, such as the next
+// fetch after a Clear when invalidations and fetches of a flapping key
+// interleave, waits for the end of the interval holding the key lock, so
+// the fetches of the key in the meantime are served its result.
+func WithRefreshDebounce(interval time.Duration) Option {
+	return func(o *options) {
+		o.refreshDebounce = interval
+	}
+}
+
+// debounce waits until the Fetcher may be called again for key, see
+// WithRefreshDebounce
+func (fc *FetchCache) debounce(ctx context.Context, key string) error {
+	if fc.opts.refreshDebounce <= 0 {
+		return nil
+	}
+	v, ok := fc.stats.m.Load(key)
+	if !ok {
+		return nil
+	}
+	last := atomic.LoadInt64(&v.(*keyStats).lastFetch)
+	wait := time.Until(time.Unix(0, last).Add(fc.opts.refreshDebounce))
+	if last == 0 || wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,65 @@
+package resource
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithRefreshDebounce(t *testing.T) {
+	var fakeFetchID = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	tests := []struct {
+		name      string
+		opts      []Option
+		wantCalls int64
+		minWait   time.Duration
+	}{
+		{
+			name:      "success refetch debounced and coalesced",
+			opts:      []Option{WithRefreshDebounce(100 * time.Millisecond)},
+			wantCalls: 2,
+			minWait:   90 * time.Millisecond,
+		},
+		{
+			name:      "success refetch without debounce",
+			wantCalls: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int64
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					atomic.AddInt64(&calls, 1)
+					return &Model{Name: id}, nil
+				},
+			}
+			fc := NewCache(mockedFetcher, tt.opts...)
+			start := time.Now()
+			if _, err := fc.Fetch(context.Background(), fakeFetchID); err != nil {
+				t.Fatalf("FetchCache.Fetch() expect no error, have %v", err)
+			}
+			fc.Clear(fakeFetchID)
+
+			var wg sync.WaitGroup
+			for i := 0; i < 5; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					if _, err := fc.Fetch(context.Background(), fakeFetchID); err != nil {
+						t.Errorf("FetchCache.Fetch() expect no error, have %v", err)
+					}
+				}()
+			}
+			wg.Wait()
+			if calls != tt.wantCalls {
+				t.Errorf("FetchCache.Fetch() expect %v Fetcher calls, have %v", tt.wantCalls, calls)
+			}
+			if d := time.Since(start); d < tt.minWait {
+				t.Errorf("FetchCache.Fetch() expect the refetch delayed by %v, have %v", tt.minWait, d)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package resource
+
+import (
+	"context"
+	"sync"
+)
+
+// DependencyFunc returns the ids the model fetched for id was built from.
+type DependencyFunc func(id string, model *Model) []string
+
+// WithDependencies declares the dependencies of every fetched model with
+// fn, replacing those of its id, see AddDependency.
+func WithDependencies(fn DependencyFunc) Option {
+	return func(o *options) {
+		o.dependencies = fn
+	}
+}
+
+// AddDependency declares that the model of id depends on those of
+// dependsOn, so clearing any of them clears id as well, transitively.
+// Dependencies are kept until RemoveDependencies or Flush.
+func (fc *FetchCache) AddDependency(id string, dependsOn ...string) {
+	ids := make([]string, len(dependsOn))
+	for i, dep := range dependsOn {
+		ids[i] = fc.canonical(dep)
+	}
+	fc.deps.add(fc.canonical(id), ids)
+}
+
+// RemoveDependencies forgets the dependencies of id.
+func (fc *FetchCache) RemoveDependencies(id string) {
+	fc.deps.set(fc.canonical(id), nil)
+}
+
+// clear removes id and the ids depending on it from the cache and returns
+// how many were cached. When local they are also deleted from the L2 store
+// and the other caches.
+func (fc *FetchCache) clear(id string, local bool) int {
+	removed := 0
+	for _, id := range fc.deps.closure(id) {
+		key := fc.key(id)
+		if fc.opts.cancelOnClear {
+			fc.inflight.cancel(key)
+		}
+		fc.lock(key)
+		if local && fc.opts.l2 != nil {
+			_ = fc.opts.l2.Delete(context.Background(), id)
+		}
+		if fc.removeitem(key) {
+			removed++
+		}
+		fc.negative.remove(key)
+		fc.unlock(key)
+		if local {
+			fc.broadcast(Invalidation{Key: id})
+		}
+	}
+	return removed
+}
+
+// depGraph holds the dependencies between ids
+type depGraph struct {
+	mu sync.Mutex
+	// dependsOn maps an id to the ids it depends on and dependents an id
+	// to the ids depending on it
+	dependsOn  map[string]map[string]struct{}
+	dependents map[string]map[string]struct{}
+}
+
+func newDepGraph() *depGraph {
+	return &depGraph{
+		dependsOn:  make(map[string]map[string]struct{}),
+		dependents: make(map[string]map[string]struct{}),
+	}
+}
+
+func (g *depGraph) add(id string, dependsOn []string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.addLocked(id, dependsOn)
+}
+
+func (g *depGraph) addLocked(id string, dependsOn []string) {
+	for _, dep := range dependsOn {
+		if g.dependsOn[id] == nil {
+			g.dependsOn[id] = make(map[string]struct{})
+		}
+		g.dependsOn[id][dep] = struct{}{}
+		if g.dependents[dep] == nil {
+			g.dependents[dep] = make(map[string]struct{})
+		}
+		g.dependents[dep][id] = struct{}{}
+	}
+}
+
+// set replaces the dependencies of id
+func (g *depGraph) set(id string, dependsOn []string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for dep := range g.dependsOn[id] {
+		delete(g.dependents[dep], id)
+		if len(g.dependents[dep]) == 0 {
+			delete(g.dependents, dep)
+		}
+	}
+	delete(g.dependsOn, id)
+	g.addLocked(id, dependsOn)
+}
+
+// closure returns id followed by every id depending on it, transitively
+func (g *depGraph) closure(id string) []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	ids := []string{id}
+	seen := map[string]struct{}{id: {}}
+	for i := 0; i < len(ids); i++ {
+		for dependent := range g.dependents[ids[i]] {
+			if _, ok := seen[dependent]; !ok {
+				seen[dependent] = struct{}{}
+				ids = append(ids, dependent)
+			}
+		}
+	}
+	return ids
+}
+
+func (g *depGraph) reset() {
+	g.mu.Lock()
+	g.dependsOn = make(map[string]map[string]struct{})
+	g.dependents = make(map[string]map[string]struct{})
+	g.mu.Unlock()
+}
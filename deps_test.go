@@ -0,0 +1,86 @@
+package resource
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestFetchCache_Clear_Dependencies(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []Option
+		deps        map[string][]string
+		clear       string
+		wantCleared []string
+		wantCached  []string
+	}{
+		{
+			name:        "success cascade transitively",
+			deps:        map[string][]string{"page": {"section"}, "section": {"part"}},
+			clear:       "part",
+			wantCleared: []string{"part", "section", "page"},
+			wantCached:  []string{"other"},
+		},
+		{
+			name:        "success clear dependent only",
+			deps:        map[string][]string{"page": {"section"}},
+			clear:       "page",
+			wantCleared: []string{"page"},
+			wantCached:  []string{"section", "part", "other"},
+		},
+		{
+			name:        "success cycle",
+			deps:        map[string][]string{"page": {"section"}, "section": {"page"}},
+			clear:       "page",
+			wantCleared: []string{"page", "section"},
+			wantCached:  []string{"part", "other"},
+		},
+		{
+			name: "success dependency func",
+			opts: []Option{WithDependencies(func(id string, model *Model) []string {
+				// composite models name their parts
+				if i := strings.IndexByte(model.Name, '+'); i >= 0 {
+					return strings.Split(model.Name[i+1:], ",")
+				}
+				return nil
+			})},
+			clear:       "part",
+			wantCleared: []string{"part", "page"},
+			wantCached:  []string{"section", "other"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					if id == "page" {
+						return &Model{Name: "page+part"}, nil
+					}
+					return &Model{Name: id}, nil
+				},
+			}
+			fc := NewCache(mockedFetcher, tt.opts...)
+			for id, deps := range tt.deps {
+				fc.AddDependency(id, deps...)
+			}
+			for _, id := range []string{"page", "section", "part", "other"} {
+				if _, err := fc.Fetch(context.Background(), id); err != nil {
+					t.Fatalf("FetchCache.Fetch() error = %v", err)
+				}
+			}
+
+			fc.Clear(tt.clear)
+			for _, id := range tt.wantCleared {
+				if _, found := fc.Entry(id); found {
+					t.Errorf("FetchCache.Clear(%v) expect %v cleared", tt.clear, id)
+				}
+			}
+			for _, id := range tt.wantCached {
+				if _, found := fc.Entry(id); !found {
+					t.Errorf("FetchCache.Clear(%v) expect %v still cached", tt.clear, id)
+				}
+			}
+		})
+	}
+}
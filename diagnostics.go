@@ -0,0 +1,92 @@
+package resource
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// diagnosticsTopKeys is the number of keys listed by DumpDiagnostics
+const diagnosticsTopKeys = 10
+
+// WithDiagnosticsSignal writes DumpDiagnostics to w every time the process
+// receives one of sigs, typically syscall.SIGUSR1.
+func WithDiagnosticsSignal(w io.Writer, sigs ...os.Signal) Option {
+	return func(o *options) {
+		o.diagnosticsWriter = w
+		o.diagnosticsSignals = sigs
+	}
+}
+
+// DumpDiagnostics writes a human readable report of the state of the cache
+// to w: its statistics, the most hit keys, the Fetcher calls in flight and
+// the callers waiting on key locks, to tell what a blocked cache waits on.
+func (fc *FetchCache) DumpDiagnostics(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	s := fc.Stats()
+	fmt.Fprintf(bw, "cache diagnostics at %s\n\n", time.Now().Format(time.RFC3339Nano))
+	fmt.Fprintf(bw, "items %d, hits %d, stale hits %d, misses %d, enabled %t, degraded %t\n",
+		s.Items, s.Hits, s.StaleHits, s.Misses, fc.Enabled(), fc.Degraded())
+	fmt.Fprintf(bw, "hit latency p50 %v p99 %v, fetch latency p50 %v p99 %v\n",
+		s.HitLatency.P50, s.HitLatency.P99, s.FetchLatency.P50, s.FetchLatency.P99)
+	fmt.Fprintf(bw, "served age p50 %v p99 %v, stale served age p50 %v p99 %v\n",
+		s.ServedAge.P50, s.ServedAge.P99, s.StaleServedAge.P50, s.StaleServedAge.P99)
+	fmt.Fprintf(bw, "key lock wait p99 %v, contended %d, items lock wait p99 %v\n",
+		s.KeyLockWait.P99, s.KeyLockContended, s.ItemsLockWait.P99)
+	fmt.Fprintf(bw, "miss queue depth %d, rejected %d\n", s.QueueDepth, s.QueueRejected)
+
+	type keyHits struct {
+		key          string
+		hits, misses uint64
+	}
+	var top []keyHits
+	fc.stats.m.Range(func(k, v interface{}) bool {
+		ks := v.(*keyStats)
+		top = append(top, keyHits{k.(string), atomic.LoadUint64(&ks.hits), atomic.LoadUint64(&ks.misses)})
+		return true
+	})
+	sort.Slice(top, func(a, b int) bool { return top[a].hits > top[b].hits })
+	if len(top) > diagnosticsTopKeys {
+		top = top[:diagnosticsTopKeys]
+	}
+	fmt.Fprintf(bw, "\ntop keys by hits:\n")
+	for _, k := range top {
+		fmt.Fprintf(bw, "  %q hits %d misses %d\n", k.key, k.hits, k.misses)
+	}
+
+	fmt.Fprintf(bw, "\nfetches in flight:\n")
+	for _, f := range s.InFlight {
+		fmt.Fprintf(bw, "  %q running %v waiters %d\n", f.ID, f.Running, f.Waiters)
+	}
+	fetches, waiters := fc.inflight.snapshot()
+	keys := make([]string, 0, len(waiters))
+	fmt.Fprintf(bw, "\nlock waiters without a fetch in flight:\n")
+	for key := range waiters {
+		if _, ok := fetches[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(bw, "  %q waiters %d\n", key, waiters[key])
+	}
+	return bw.Flush()
+}
+
+// runDiagnosticsSignal writes the diagnostics on every signal until stop
+func (fc *FetchCache) runDiagnosticsSignal(ch chan os.Signal, stop <-chan struct{}) {
+	defer signal.Stop(ch)
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ch:
+			_ = fc.DumpDiagnostics(fc.opts.diagnosticsWriter)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package resource
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestFetchCache_DumpDiagnostics(t *testing.T) {
+	var (
+		fakeFetchID = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	)
+
+	tests := []struct {
+		name     string
+		inFlight bool
+		want     []string
+	}{
+		{
+			name: "success idle",
+			want: []string{"items 1, hits 1", "top keys by hits:\n  \"" + fakeFetchID + "\" hits 1 misses 1"},
+		},
+		{
+			name:     "success fetch in flight",
+			inFlight: true,
+			want:     []string{"fetches in flight:\n  \"5634aeed-2106-43de-ab7d-c0ad4b1e195e\" running"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entered, release := make(chan struct{}), make(chan struct{})
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					if id != fakeFetchID {
+						close(entered)
+						<-release
+					}
+					return &Model{Name: "lorem"}, nil
+				},
+			}
+			fc := NewCache(mockedFetcher)
+			defer fc.Close(context.Background())
+			for i := 0; i < 2; i++ {
+				if _, err := fc.Fetch(context.Background(), fakeFetchID); err != nil {
+					t.Fatalf("FetchCache.Fetch() error = %v", err)
+				}
+			}
+			done := make(chan struct{})
+			if tt.inFlight {
+				go func() {
+					defer close(done)
+					_, _ = fc.Fetch(context.Background(), "5634aeed-2106-43de-ab7d-c0ad4b1e195e")
+				}()
+				<-entered
+			}
+
+			var buf bytes.Buffer
+			if err := fc.DumpDiagnostics(&buf); err != nil {
+				t.Fatalf("FetchCache.DumpDiagnostics() error = %v", err)
+			}
+			if tt.inFlight {
+				close(release)
+				<-done
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("FetchCache.DumpDiagnostics() expect to contain %q, have %q", want, buf.String())
+				}
+			}
+		})
+	}
+}
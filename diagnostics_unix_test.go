@@ -0,0 +1,39 @@
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package resource
+
+import (
+	"context"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestFetchCache_WithDiagnosticsSignal(t *testing.T) {
+	w := &syncWriter{written: make(chan struct{}, 1)}
+	fc := NewCache(&FetcherMock{}, WithDiagnosticsSignal(w, syscall.SIGUSR1))
+	defer fc.Close(context.Background())
+
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGUSR1); err != nil {
+		t.Fatalf("syscall.Kill() error = %v", err)
+	}
+	select {
+	case <-w.written:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("FetchCache.WithDiagnosticsSignal() expect diagnostics written")
+	}
+}
+
+// syncWriter signals every write
+type syncWriter struct {
+	written chan struct{}
+}
+
+func (w *syncWriter) Write(p []byte) (int, error) {
+	select {
+	case w.written <- struct{}{}:
+	default:
+	}
+	return len(p), nil
+}
@@ -0,0 +1,87 @@
+package resource
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// WithDriftCheck fetches sample random cached items from the Fetcher every
+// interval and compares them with the cached models using equal, counting
+// the differences in Stats. With repair a cached model which differs is
+// replaced, unless it was refreshed meanwhile. The comparisons tell whether
+// the TTL is short enough for the data.
+func WithDriftCheck(equal func(cached, fetched *Model) bool, interval time.Duration, sample int, repair bool) Option {
+	return func(o *options) {
+		o.driftEqual = equal
+		o.driftInterval = interval
+		o.driftSample = sample
+		o.driftRepair = repair
+	}
+}
+
+// runDriftCheck checks a sample of the items every interval until stop
+func (fc *FetchCache) runDriftCheck(stop <-chan struct{}) {
+	ctx, cancel := fc.life.context()
+	defer cancel()
+
+	ticker := time.NewTicker(fc.opts.driftInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+		if !fc.Degraded() {
+			fc.checkDrift(ctx)
+		}
+	}
+}
+
+// checkDrift compares a random sample of the cached items with the Fetcher.
+// It holds the lock of each id while calling the Fetcher like a fetch and
+// skips the ids being fetched, whose result is about to be cached anyway.
+func (fc *FetchCache) checkDrift(ctx context.Context) {
+	ids := fc.cachedIDs()
+	rand.Shuffle(len(ids), func(i, j int) { ids[i], ids[j] = ids[j], ids[i] })
+	if len(ids) > fc.opts.driftSample {
+		ids = ids[:fc.opts.driftSample]
+	}
+
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			return
+		}
+		cached, found := fc.Entry(id)
+		if !found {
+			continue
+		}
+		key := fc.key(id)
+		if !fc.tryLock(key) {
+			continue
+		}
+		if err := fc.acquireFetch(ctx, id); err != nil {
+			fc.unlock(key)
+			return
+		}
+		var (
+			model *Model
+			err   error
+		)
+		fc.inflight.start(key, id, nil)
+		withLabels(ctx, "drift-check", id, func(ctx context.Context) { model, err = fc.f.Fetch(ctx, id) })
+		fc.inflight.done(key)
+		fc.fetchLimit.release()
+		fc.unlock(key)
+		if err != nil {
+			continue
+		}
+
+		drifted := !fc.opts.driftEqual(cached.Model, model)
+		fc.metrics.driftCheck(drifted)
+		if drifted && fc.opts.driftRepair {
+			fc.CompareAndSwap(WithActor(ctx, "drift-check"), id, cached.Version, model)
+		}
+	}
+}
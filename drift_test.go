@@ -0,0 +1,87 @@
+package resource
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFetchCache_WithDriftCheck(t *testing.T) {
+	var (
+		fakeFetchID = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	)
+
+	tests := []struct {
+		name       string
+		changed    bool
+		repair     bool
+		locked     bool
+		wantChecks uint64
+		wantDrifts uint64
+		wantModel  string
+	}{
+		{
+			name:       "success no drift",
+			wantChecks: 1,
+			wantModel:  "v1",
+		},
+		{
+			name:       "success drift counted",
+			changed:    true,
+			wantChecks: 1,
+			wantDrifts: 1,
+			wantModel:  "v1",
+		},
+		{
+			name:       "success drift repaired",
+			changed:    true,
+			repair:     true,
+			wantChecks: 1,
+			wantDrifts: 1,
+			wantModel:  "v2",
+		},
+		{
+			name:      "success skip id being fetched",
+			changed:   true,
+			repair:    true,
+			locked:    true,
+			wantModel: "v1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var callCount int32
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					if atomic.AddInt32(&callCount, 1) > 1 && tt.changed {
+						return &Model{Name: "v2"}, nil
+					}
+					return &Model{Name: "v1"}, nil
+				},
+			}
+			equal := func(cached, fetched *Model) bool { return cached.Name == fetched.Name }
+			fc := NewCache(mockedFetcher, WithSynchronous(), WithDriftCheck(equal, time.Millisecond, 10, tt.repair))
+			if _, err := fc.Fetch(context.Background(), fakeFetchID); err != nil {
+				t.Fatalf("FetchCache.Fetch() error = %v", err)
+			}
+
+			time.Sleep(2 * time.Millisecond)
+			if tt.locked {
+				fc.Lock(fakeFetchID)
+			}
+			fc.Tick(context.Background())
+			if tt.locked {
+				fc.Unlock(fakeFetchID)
+			}
+			stats := fc.Stats()
+			if stats.DriftChecks != tt.wantChecks || stats.Drifts != tt.wantDrifts {
+				t.Errorf("FetchCache.Stats() expect %v drift checks and %v drifts, have %v and %v", tt.wantChecks, tt.wantDrifts, stats.DriftChecks, stats.Drifts)
+			}
+			model, _ := fc.Fetch(context.Background(), fakeFetchID)
+			if model.Name != tt.wantModel {
+				t.Errorf("FetchCache.Fetch() expect model = %v, have %v", tt.wantModel, model.Name)
+			}
+		})
+	}
+}
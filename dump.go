@@ -0,0 +1,225 @@
+package resource
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"time"
+)
+
+// Error list
+var (
+	ErrSnapshotVersion   = errors.New("unsupported snapshot version")
+	ErrSnapshotCorrupted = errors.New("snapshot corrupted")
+)
+
+// dumpVersion is the version of the format written by Dump, version 2
+// added the checksum
+const dumpVersion = 2
+
+// migrations upgrade the entries of a document of the version they are
+// indexed by to the next version, a change of DumpEntry must bump
+// dumpVersion and add the migration from the previous one
+var migrations = map[int]func(entries []json.RawMessage) ([]json.RawMessage, error){
+	1: func(entries []json.RawMessage) ([]json.RawMessage, error) {
+		return entries, nil
+	},
+}
+
+// SnapshotMigration is called by Hydrate with every entry read and the
+// version of the format it was written with, once migrated to the current
+// format. It can upgrade the models of older snapshots, a nil Value skips
+// the entry and an error fails Hydrate. See WithSnapshotMigration.
+type SnapshotMigration func(version int, e *DumpEntry) error
+
+// WithSnapshotMigration makes Hydrate call fn with the entries it reads.
+func WithSnapshotMigration(fn SnapshotMigration) Option {
+	return func(o *options) {
+		o.snapshotMigration = fn
+	}
+}
+
+// DumpEntry is a cached item as written by Dump.
+type DumpEntry struct {
+	Key     string     `json:"key"`
+	Created time.Time  `json:"created"`
+	Expires *time.Time `json:"expires,omitempty"`
+	// FetchCost is how long the item took to fetch.
+	FetchCost Duration `json:"fetch_cost,omitempty"`
+	Value     *Model   `json:"value,omitempty"`
+	// Sealed is the encrypted JSON of the value with WithEncrypter,
+	// authenticated with Key.
+	Sealed []byte `json:"sealed,omitempty"`
+}
+
+// dump is the document written by Dump, Checksum is the CRC-32C of the
+// compact JSON of Entries, Stats the lifetime stats of the cache and Quota
+// the usage of WithOriginQuota
+type dump struct {
+	Version  int            `json:"version"`
+	Time     time.Time      `json:"time"`
+	Entries  []DumpEntry    `json:"entries"`
+	Checksum string         `json:"checksum"`
+	Stats    *LifetimeStats `json:"stats,omitempty"`
+	Quota    *QuotaUsage    `json:"quota,omitempty"`
+}
+
+// rawDump is a dump whose entries are yet to be verified and migrated
+type rawDump struct {
+	Version  int             `json:"version"`
+	Time     time.Time       `json:"time"`
+	Entries  json.RawMessage `json:"entries"`
+	Checksum *string         `json:"checksum"`
+	Stats    *LifetimeStats  `json:"stats"`
+	Quota    *QuotaUsage     `json:"quota"`
+}
+
+// dumpChecksum returns the checksum of entries, the JSON of the entries of
+// a dump
+func dumpChecksum(entries []byte) (string, error) {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, entries); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%08x", crc32.Checksum(buf.Bytes(), castagnoli)), nil
+}
+
+// Dump writes the cached items to w as indented JSON sorted by key, with
+// their models when withValues is true. Expired items are left out. The
+// models are encrypted with WithEncrypter.
+func (fc *FetchCache) Dump(w io.Writer, withValues bool) error {
+	_, err := fc.Snapshot(false).write(w, withValues)
+	return err
+}
+
+// Hydrate reads a document written by Dump from r and caches its entries,
+// keeping their original creation and expiration times. Entries without a
+// value, already expired or with times which don't make sense are skipped.
+// The lifetime stats of the document, if any, replace those of the cache,
+// see LifetimeStats, and its quota usage is restored, see WithOriginQuota.
+// Encrypted values are decrypted with WithEncrypter, and fail with
+// ErrNoEncrypter without. It returns the number of items cached.
+//
+// Documents written by older versions of the package are migrated to the
+// current format, see WithSnapshotMigration. It fails with
+// ErrSnapshotVersion on documents of newer versions and with
+// ErrSnapshotCorrupted when the checksum doesn't match, caching nothing.
+func (fc *FetchCache) Hydrate(r io.Reader) (int, error) {
+	var d rawDump
+	if err := json.NewDecoder(r).Decode(&d); err != nil {
+		return 0, err
+	}
+	entries, err := fc.migrate(d)
+	if err != nil {
+		return 0, err
+	}
+	if d.Stats != nil {
+		fc.metrics.restoreLifetime(*d.Stats)
+	}
+	if d.Quota != nil {
+		fc.quota.restore(*d.Quota)
+	}
+
+	n := 0
+	for _, raw := range entries {
+		var e DumpEntry
+		if err := json.Unmarshal(raw, &e); err != nil {
+			return n, fmt.Errorf("%w: %v", ErrSnapshotCorrupted, err)
+		}
+		if e.Sealed != nil {
+			if fc.opts.encrypter == nil {
+				return n, ErrNoEncrypter
+			}
+			value, err := unseal(e.Key, e.Sealed, fc.opts.encrypter)
+			if err != nil {
+				return n, err
+			}
+			e.Value = value
+		}
+		if fc.opts.snapshotMigration != nil {
+			if err := fc.opts.snapshotMigration(d.Version, &e); err != nil {
+				return n, err
+			}
+		}
+		if e.Value == nil || !saneEntry(e, d.Time) {
+			continue
+		}
+		i := item{
+			Object:  e.Value,
+			Created: e.Created.UnixNano(),
+			Cost:    int64(e.FetchCost),
+			Source:  SourceSnapshot,
+		}
+		if e.Expires != nil {
+			i.Expiration = e.Expires.UnixNano()
+		}
+		if i.expired() {
+			continue
+		}
+		fc.storeitem(e.Key, i)
+		n++
+	}
+	return n, nil
+}
+
+// migrate verifies the checksum of d and returns its entries migrated to
+// dumpVersion
+func (fc *FetchCache) migrate(d rawDump) ([]json.RawMessage, error) {
+	if d.Version < 1 || d.Version > dumpVersion {
+		return nil, fmt.Errorf("%w: %d", ErrSnapshotVersion, d.Version)
+	}
+	if d.Version >= 2 {
+		sum, err := dumpChecksum(d.Entries)
+		if err != nil || d.Checksum == nil || *d.Checksum != sum {
+			return nil, fmt.Errorf("%w: checksum mismatch", ErrSnapshotCorrupted)
+		}
+	}
+	var entries []json.RawMessage
+	if err := json.Unmarshal(d.Entries, &entries); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSnapshotCorrupted, err)
+	}
+	for v := d.Version; v < dumpVersion; v++ {
+		var err error
+		if entries, err = migrations[v](entries); err != nil {
+			return nil, fmt.Errorf("migrate snapshot from version %d: %w", v, err)
+		}
+	}
+	return entries, nil
+}
+
+// saneEntry reports whether the times of e, from a document written at
+// written, are consistent
+func saneEntry(e DumpEntry, written time.Time) bool {
+	switch {
+	case e.Key == "" || e.Created.IsZero() || e.Created.After(written):
+		return false
+	case e.Expires != nil && e.Expires.Before(e.Created):
+		return false
+	}
+	return true
+}
+
+// seal returns the JSON of m, the value of key, encrypted with enc
+func seal(key string, m *Model, enc Encrypter) ([]byte, error) {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	return enc.Encrypt(b, []byte(key))
+}
+
+// unseal returns the value of key encrypted by seal
+func unseal(key string, sealed []byte, enc Encrypter) (*Model, error) {
+	b, err := enc.Decrypt(sealed, []byte(key))
+	if err != nil {
+		return nil, err
+	}
+	var m Model
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
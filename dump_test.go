@@ -0,0 +1,139 @@
+package resource
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFetchCache_Dump_Hydrate(t *testing.T) {
+	mockedFetcher := &FetcherMock{
+		FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+			return &Model{Name: id}, nil
+		},
+	}
+
+	tests := []struct {
+		name          string
+		withValues    bool
+		wantHydrated  int
+		wantItemCount int
+	}{
+		{
+			name:          "success restore dump with values",
+			withValues:    true,
+			wantHydrated:  2,
+			wantItemCount: 2,
+		},
+		{
+			name: "success skip dump without values",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fc := NewCache(mockedFetcher)
+			_, _ = fc.Fetch(context.Background(), "a")
+			_, _ = fc.FetchWithOptions(context.Background(), "b", OverrideTTL(time.Minute))
+			_, _ = fc.FetchWithOptions(context.Background(), "expired", OverrideTTL(time.Nanosecond))
+			time.Sleep(time.Millisecond)
+
+			var buf bytes.Buffer
+			if err := fc.Dump(&buf, tt.withValues); err != nil {
+				t.Fatalf("FetchCache.Dump() error = %v", err)
+			}
+
+			restored := NewCache(mockedFetcher)
+			n, err := restored.Hydrate(&buf)
+			if err != nil {
+				t.Fatalf("FetchCache.Hydrate() error = %v", err)
+			}
+			if n != tt.wantHydrated || restored.items.Len() != tt.wantItemCount {
+				t.Errorf("FetchCache.Hydrate() expect %v items, have %v (%v cached)", tt.wantHydrated, n, restored.items.Len())
+			}
+			have, _ := restored.items.Get("b")
+			want, _ := fc.items.Get("b")
+			want.Source = SourceSnapshot
+			if tt.wantItemCount > 0 && !reflect.DeepEqual(have, want) {
+				t.Errorf("FetchCache.Hydrate() = %+v, want %+v", have, want)
+			}
+		})
+	}
+}
+
+func TestFetchCache_Hydrate_Integrity(t *testing.T) {
+	fakeFetchID := "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	notExistModelID := "5634aeed-2106-43de-ab7d-c0ad4b1e195e"
+	mockedFetcher := &FetcherMock{
+		FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+			return &Model{Name: id}, nil
+		},
+	}
+	fc := NewCache(mockedFetcher)
+	defer fc.Close(context.Background())
+	_, _ = fc.Fetch(context.Background(), fakeFetchID)
+	var buf bytes.Buffer
+	if err := fc.Dump(&buf, true); err != nil {
+		t.Fatalf("FetchCache.Dump() error = %v", err)
+	}
+	current := buf.String()
+	written := time.Now().Add(-time.Minute).Format(time.RFC3339Nano)
+	created := time.Now().Add(-time.Hour).Format(time.RFC3339Nano)
+
+	tests := []struct {
+		name         string
+		doc          string
+		wantVersion  int
+		wantHydrated int
+		wantErr      error
+	}{
+		{
+			name:         "success current version",
+			doc:          current,
+			wantVersion:  dumpVersion,
+			wantHydrated: 1,
+		},
+		{
+			name:         "success migrate version 1",
+			doc:          `{"version": 1, "time": "` + written + `", "entries": [{"key": "a", "created": "` + created + `", "value": {"Name": "a"}}]}`,
+			wantVersion:  1,
+			wantHydrated: 1,
+		},
+		{
+			name:        "success skip entry created after snapshot",
+			doc:         `{"version": 1, "time": "` + created + `", "entries": [{"key": "a", "created": "` + written + `", "value": {"Name": "a"}}]}`,
+			wantVersion: 1,
+		},
+		{
+			name:    "fail checksum mismatch",
+			doc:     strings.Replace(current, fakeFetchID, notExistModelID, 1),
+			wantErr: ErrSnapshotCorrupted,
+		},
+		{
+			name:    "fail newer version",
+			doc:     `{"version": 3, "time": "` + written + `", "entries": []}`,
+			wantErr: ErrSnapshotVersion,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			version := 0
+			restored := NewCache(mockedFetcher, WithSnapshotMigration(func(v int, e *DumpEntry) error {
+				version = v
+				return nil
+			}))
+			defer restored.Close(context.Background())
+
+			n, err := restored.Hydrate(strings.NewReader(tt.doc))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("FetchCache.Hydrate() expect error = %v, have %v", tt.wantErr, err)
+			}
+			if n != tt.wantHydrated || version != tt.wantVersion {
+				t.Errorf("FetchCache.Hydrate() expect %v items of version %v, have %v of version %v", tt.wantHydrated, tt.wantVersion, n, version)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package resource
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// LoadMode is how a key is loaded, see WithLoadPolicy.
+type LoadMode int
+
+// Load mode list
+const (
+	// Lazy keys are fetched on demand, on a miss.
+	Lazy LoadMode = iota
+	// Eager keys are refreshed in the background once first loaded, so
+	// they stay warm regardless of accesses.
+	Eager
+)
+
+// LoadPolicy classifies a key as Lazy or Eager.
+type LoadPolicy func(id string) LoadMode
+
+// WithLoadPolicy refreshes the keys classified Eager by policy every
+// interval once they have been fetched, one key at a time or with
+// WithRefreshPool in the refresh pool. A failed refresh keeps the cached
+// model until the next interval.
+func WithLoadPolicy(policy LoadPolicy, interval time.Duration) Option {
+	return func(o *options) {
+		o.loadPolicy = policy
+		o.eagerInterval = interval
+	}
+}
+
+// eagerKeys is the set of keys refreshed in the background
+type eagerKeys struct {
+	mu   sync.Mutex
+	keys map[string]struct{}
+}
+
+func (e *eagerKeys) add(id string) {
+	e.mu.Lock()
+	if e.keys == nil {
+		e.keys = make(map[string]struct{})
+	}
+	e.keys[id] = struct{}{}
+	e.mu.Unlock()
+}
+
+func (e *eagerKeys) list() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	ids := make([]string, 0, len(e.keys))
+	for id := range e.keys {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// loaded registers id for background refresh if the policy says so
+func (fc *FetchCache) loaded(id string) {
+	if fc.opts.loadPolicy != nil && fc.opts.loadPolicy(id) == Eager {
+		fc.eager.add(id)
+	}
+}
+
+// refreshEager refreshes the eager keys every interval until stop
+func (fc *FetchCache) refreshEager(stop <-chan struct{}) {
+	ticker := time.NewTicker(fc.opts.eagerInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+		if fc.Degraded() {
+			continue
+		}
+		for _, id := range fc.eager.list() {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			if fc.refreshes != nil {
+				fc.queueRefresh(id)
+				continue
+			}
+			_ = fc.reload(context.Background(), id)
+		}
+	}
+}
+
+// reload fetches id from the Fetcher and caches it
+func (fc *FetchCache) reload(ctx context.Context, id string) error {
+	key := fc.key(id)
+	fc.lock(key)
+	defer fc.unlock(key)
+	_, _, err := fc.fetchFromFetcher(fc.refreshContext(ctx, id), id, fetchOptions{})
+	return err
+}
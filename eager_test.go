@@ -0,0 +1,47 @@
+package resource
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFetchCache_WithLoadPolicy(t *testing.T) {
+	var (
+		mu        sync.Mutex
+		callCount = make(map[string]int)
+	)
+	mockedFetcher := &FetcherMock{
+		FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+			mu.Lock()
+			callCount[id]++
+			mu.Unlock()
+			return &Model{Name: id}, nil
+		},
+	}
+	policy := func(id string) LoadMode {
+		if strings.HasPrefix(id, "config/") {
+			return Eager
+		}
+		return Lazy
+	}
+	fc := NewCache(mockedFetcher, WithLoadPolicy(policy, 10*time.Millisecond))
+
+	_, _ = fc.Fetch(context.Background(), "config/flags")
+	_, _ = fc.Fetch(context.Background(), "artifact/weights")
+	time.Sleep(55 * time.Millisecond)
+	if err := fc.Close(context.Background()); err != nil {
+		t.Fatalf("FetchCache.Close() error = %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if callCount["config/flags"] < 3 {
+		t.Errorf("FetchCache expect eager key refreshed in background, have %v fetches", callCount["config/flags"])
+	}
+	if callCount["artifact/weights"] != 1 {
+		t.Errorf("FetchCache expect lazy key fetched once, have %v fetches", callCount["artifact/weights"])
+	}
+}
@@ -0,0 +1,39 @@
+package resource
+
+import (
+	"context"
+	"time"
+)
+
+// WithEmptyTTL caches the empty results, the ids the Fetcher resolves to a
+// nil model without error, for d instead of the default expiration, so
+// optional resources are checked again sooner or later than the others.
+// A d of 0 means they never expire and a negative d doesn't cache them.
+// Empty results aren't errors: they aren't subject to WithErrorPolicy and
+// the negative caching. See FetchOptional.
+func WithEmptyTTL(d time.Duration) Option {
+	return func(o *options) {
+		o.emptyTTL = d
+		o.hasEmptyTTL = true
+	}
+}
+
+// FetchOptional is FetchWithOptions for the optional resources: it reports
+// whether id has a model, false when the Fetcher returned a nil model
+// without error, which is cached, see WithEmptyTTL.
+func (fc *FetchCache) FetchOptional(ctx context.Context, id string, opts ...FetchOption) (*Model, bool, error) {
+	model, err := fc.FetchWithOptions(ctx, id, opts...)
+	if err != nil {
+		return nil, false, err
+	}
+	return model, model != nil, nil
+}
+
+// resultTTL returns the TTL of a fetched model, unless overridden by the
+// Result or the call, and whether the model is to be cached
+func (fc *FetchCache) resultTTL(model *Model) (time.Duration, bool) {
+	if model == nil && fc.opts.hasEmptyTTL {
+		return fc.opts.emptyTTL, fc.opts.emptyTTL >= 0
+	}
+	return fc.defaultTTL(), true
+}
@@ -0,0 +1,83 @@
+package resource
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFetchCache_FetchOptional(t *testing.T) {
+	var (
+		fakeFetchID     = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+		notExistModelID = "5634aeed-2106-43de-ab7d-c0ad4b1e195e"
+	)
+	tests := []struct {
+		name      string
+		opts      []Option
+		id        string
+		wantFound bool
+		wantCalls int64
+		wantTTL   time.Duration
+	}{
+		{
+			name:      "success model",
+			opts:      []Option{WithTTL(time.Hour), WithEmptyTTL(time.Minute)},
+			id:        fakeFetchID,
+			wantFound: true,
+			wantCalls: 1,
+			wantTTL:   time.Hour,
+		},
+		{
+			name:      "success empty result with its ttl",
+			opts:      []Option{WithTTL(time.Hour), WithEmptyTTL(time.Minute)},
+			id:        notExistModelID,
+			wantCalls: 1,
+			wantTTL:   time.Minute,
+		},
+		{
+			name:      "success empty result with the default ttl",
+			opts:      []Option{WithTTL(time.Hour)},
+			id:        notExistModelID,
+			wantCalls: 1,
+			wantTTL:   time.Hour,
+		},
+		{
+			name:      "success empty result not cached",
+			opts:      []Option{WithTTL(time.Hour), WithEmptyTTL(-1)},
+			id:        notExistModelID,
+			wantCalls: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int64
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					atomic.AddInt64(&calls, 1)
+					if id == notExistModelID {
+						return nil, nil
+					}
+					return &Model{Name: id}, nil
+				},
+			}
+			fc := NewCache(mockedFetcher, tt.opts...)
+			for i := 0; i < 2; i++ {
+				model, found, err := fc.FetchOptional(context.Background(), tt.id)
+				if err != nil || found != tt.wantFound || (model != nil) != tt.wantFound {
+					t.Fatalf("FetchCache.FetchOptional() expect found = %v, have %v, %v, %v", tt.wantFound, model, found, err)
+				}
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("FetchCache.FetchOptional() expect %v Fetcher calls, have %v", tt.wantCalls, calls)
+			}
+			info, ok := fc.Entry(tt.id)
+			if ok != (tt.wantTTL > 0) {
+				t.Fatalf("FetchCache.Entry() expect cached = %v, have %v", tt.wantTTL > 0, ok)
+			}
+			if ok && info.Expires.Sub(info.Created) != tt.wantTTL {
+				t.Errorf("FetchCache.FetchOptional() expect ttl = %v, have %v", tt.wantTTL, info.Expires.Sub(info.Created))
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package resource
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// FlagProvider is an interface that defines the CacheEnabled method.
+//
+// It lets an external feature-flag system switch caching off, see
+// WithFlagProvider.
+type FlagProvider interface {
+	// CacheEnabled reports whether Fetch may use the cache.
+	CacheEnabled(ctx context.Context) bool
+}
+
+// WithFlagProvider consults p on every Fetch, caching is used only while
+// both p and SetEnabled allow it.
+func WithFlagProvider(p FlagProvider) Option {
+	return func(o *options) {
+		o.flags = p
+	}
+}
+
+// SetEnabled switches caching on or off at runtime. While disabled every
+// Fetch goes straight to the Fetcher and nothing is cached.
+func (fc *FetchCache) SetEnabled(enabled bool) {
+	var v int32
+	if !enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&fc.disabled, v)
+}
+
+// Enabled reports whether caching is switched on by SetEnabled.
+func (fc *FetchCache) Enabled() bool {
+	return atomic.LoadInt32(&fc.disabled) == 0
+}
+
+func (fc *FetchCache) cacheEnabled(ctx context.Context) bool {
+	if !fc.Enabled() {
+		return false
+	}
+	return fc.opts.flags == nil || fc.opts.flags.CacheEnabled(ctx)
+}
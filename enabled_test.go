@@ -0,0 +1,68 @@
+package resource
+
+import (
+	"context"
+	"testing"
+)
+
+type flagProviderFunc func(ctx context.Context) bool
+
+func (f flagProviderFunc) CacheEnabled(ctx context.Context) bool {
+	return f(ctx)
+}
+
+func TestFetchCache_SetEnabled(t *testing.T) {
+	var (
+		fakeFetchID = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	)
+
+	tests := []struct {
+		name             string
+		enabled          bool
+		flag             bool
+		serviceCallCount int
+		wantCached       bool
+	}{
+		{
+			name:             "success use cache when enabled",
+			enabled:          true,
+			flag:             true,
+			serviceCallCount: 1,
+			wantCached:       true,
+		},
+		{
+			name:             "success bypass cache when disabled",
+			flag:             true,
+			serviceCallCount: 3,
+		},
+		{
+			name:             "success bypass cache when flag is off",
+			enabled:          true,
+			serviceCallCount: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serviceCallCount := 0
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					serviceCallCount++
+					return &Model{Name: "lorem"}, nil
+				},
+			}
+			flag := flagProviderFunc(func(ctx context.Context) bool { return tt.flag })
+			fc := NewCache(mockedFetcher, WithFlagProvider(flag))
+			fc.SetEnabled(tt.enabled)
+
+			for i := 0; i < 3; i++ {
+				_, _ = fc.Fetch(context.Background(), fakeFetchID)
+			}
+			if tt.serviceCallCount != serviceCallCount {
+				t.Errorf("FetchCache.Fetch() expect service call count = %v, have %v", tt.serviceCallCount, serviceCallCount)
+			}
+			if _, found := fc.items.Get(fakeFetchID); found != tt.wantCached {
+				t.Errorf("FetchCache.Fetch() expect cached = %v, have %v", tt.wantCached, found)
+			}
+		})
+	}
+}
@@ -0,0 +1,291 @@
+package resource
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// Error list
+var (
+	ErrUnknownKey  = errors.New("unknown encryption key")
+	ErrNoEncrypter = errors.New("encrypted data without encrypter")
+	ErrCorrupted   = errors.New("corrupted encrypted data")
+)
+
+// Encrypter is an interface that encrypts the data the cache persists:
+// the values written by Dump and Snapshot.WriteTo and the files of a
+// StreamCache, see WithEncrypter.
+//
+// The additional data aad is authenticated along the plaintext but not
+// encrypted, it tells what the plaintext is, e.g. the key of a value, so
+// a ciphertext can't be passed for another one.
+type Encrypter interface {
+	// Encrypt returns the authenticated encryption of plaintext and aad,
+	// whose length only depends on the length of plaintext.
+	Encrypt(plaintext, aad []byte) ([]byte, error)
+	// Decrypt returns the plaintext of a ciphertext returned by Encrypt
+	// with the same aad.
+	Decrypt(ciphertext, aad []byte) ([]byte, error)
+}
+
+// WithEncrypter encrypts the data persisted by the cache with e: Dump and
+// Snapshot.WriteTo write the values encrypted, Hydrate decrypts them, and
+// a StreamCache stores its files encrypted.
+func WithEncrypter(e Encrypter) Option {
+	return func(o *options) {
+		o.encrypter = e
+	}
+}
+
+// aesKeyIDSize is the size of the key id starting the ciphertexts of AESGCM
+const aesKeyIDSize = 4
+
+// AESGCM is an Encrypter using AES-GCM. It encrypts with its current key
+// and decrypts with any of its keys, so keys can be rotated while data
+// encrypted with the previous ones is still around.
+type AESGCM struct {
+	current uint32
+	aeads   map[uint32]cipher.AEAD
+}
+
+// NewAESGCM creates an AESGCM encrypting with key and also decrypting with
+// the previous keys. Keys are 16, 24 or 32 bytes for AES-128, AES-192 or
+// AES-256.
+func NewAESGCM(key []byte, previous ...[]byte) (*AESGCM, error) {
+	a := &AESGCM{aeads: make(map[uint32]cipher.AEAD, 1+len(previous))}
+	for i, k := range append([][]byte{key}, previous...) {
+		block, err := aes.NewCipher(k)
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		id := aesKeyID(k)
+		if i == 0 {
+			a.current = id
+		}
+		if _, ok := a.aeads[id]; !ok {
+			a.aeads[id] = aead
+		}
+	}
+	return a, nil
+}
+
+// aesKeyID identifies a key in ciphertexts without revealing it
+func aesKeyID(key []byte) uint32 {
+	sum := sha256.Sum256(key)
+	return binary.BigEndian.Uint32(sum[:aesKeyIDSize])
+}
+
+// Encrypt implements Encrypter. The ciphertext is the key id, the random
+// nonce and the plaintext sealed with the key id and aad.
+func (a *AESGCM) Encrypt(plaintext, aad []byte) ([]byte, error) {
+	aead := a.aeads[a.current]
+	out := make([]byte, aesKeyIDSize+aead.NonceSize(), aesKeyIDSize+aead.NonceSize()+len(plaintext)+aead.Overhead())
+	binary.BigEndian.PutUint32(out, a.current)
+	nonce := out[aesKeyIDSize:]
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(out, nonce, plaintext, aesAdditionalData(out[:aesKeyIDSize], aad)), nil
+}
+
+// Decrypt implements Encrypter.
+func (a *AESGCM) Decrypt(ciphertext, aad []byte) ([]byte, error) {
+	if len(ciphertext) < aesKeyIDSize {
+		return nil, ErrUnknownKey
+	}
+	aead, ok := a.aeads[binary.BigEndian.Uint32(ciphertext)]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	rest := ciphertext[aesKeyIDSize:]
+	if len(rest) < aead.NonceSize() {
+		return nil, ErrCorrupted
+	}
+	return aead.Open(nil, rest[:aead.NonceSize()], rest[aead.NonceSize():], aesAdditionalData(ciphertext[:aesKeyIDSize], aad))
+}
+
+// aesAdditionalData returns the key id followed by aad
+func aesAdditionalData(keyID, aad []byte) []byte {
+	return append(append(make([]byte, 0, len(keyID)+len(aad)), keyID...), aad...)
+}
+
+// Const list
+const (
+	// encryptedChunkSize is the plaintext size of the frames of an
+	// encrypted stream but the last one
+	encryptedChunkSize = 64 << 10
+	// streamIDSize is the size of the random id of an encrypted stream
+	streamIDSize = 16
+	// streamHeaderSize is the plaintext size of the header frame of an
+	// encrypted stream, its size and id
+	streamHeaderSize = 8 + streamIDSize
+)
+
+// encryptStream copies r to w encrypted with e and returns the number of
+// plaintext bytes copied, name identifies the stream. The stream is a
+// header frame holding the plaintext size and a random stream id,
+// authenticated with name, followed by a frame per chunk holding its data,
+// authenticated with the stream id, its index and whether it is the last
+// one, each frame being the length of the ciphertext and the ciphertext.
+// So chunks can't be reordered, dropped, truncated or spliced from another
+// stream, nor a stream passed for another name, unnoticed.
+//
+// w must be positioned after room for the header frame, written last at
+// offset 0 once the size is known.
+func encryptStream(w io.WriterAt, r io.Reader, e Encrypter, name []byte) (int64, error) {
+	offset := headerFrameLen(e)
+	if offset < 0 {
+		return 0, ErrCorrupted
+	}
+	var header [streamHeaderSize]byte
+	id := header[8:]
+	if _, err := io.ReadFull(rand.Reader, id); err != nil {
+		return 0, err
+	}
+	var (
+		br    = bufio.NewReader(r)
+		buf   = make([]byte, encryptedChunkSize)
+		total int64
+	)
+	for index := uint64(0); ; index++ {
+		n, err := io.ReadFull(br, buf)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return total, err
+		}
+		last := err != nil
+		if !last {
+			// a full chunk is the last one at the end of r
+			if _, err := br.Peek(1); err == io.EOF {
+				last = true
+			} else if err != nil {
+				return total, err
+			}
+		}
+		written, err := writeFrame(w, offset, buf[:n], e, chunkAdditionalData(id, index, last))
+		if err != nil {
+			return total, err
+		}
+		offset += written
+		total += int64(n)
+		if last {
+			break
+		}
+	}
+
+	binary.BigEndian.PutUint64(header[:8], uint64(total))
+	_, err := writeFrame(w, 0, header[:], e, name)
+	return total, err
+}
+
+// chunkAdditionalData returns the additional data of the chunk index of
+// the stream id
+func chunkAdditionalData(id []byte, index uint64, last bool) []byte {
+	aad := make([]byte, len(id)+9)
+	copy(aad, id)
+	binary.BigEndian.PutUint64(aad[len(id):], index)
+	if last {
+		aad[len(aad)-1] = 1
+	}
+	return aad
+}
+
+// headerFrameLen returns the length of the header frame of e, which is
+// fixed
+func headerFrameLen(e Encrypter) int64 {
+	sealed, err := e.Encrypt(make([]byte, streamHeaderSize), nil)
+	if err != nil {
+		return -1
+	}
+	return int64(4 + len(sealed))
+}
+
+func writeFrame(w io.WriterAt, offset int64, plaintext []byte, e Encrypter, aad []byte) (int64, error) {
+	sealed, err := e.Encrypt(plaintext, aad)
+	if err != nil {
+		return 0, err
+	}
+	frame := make([]byte, 4+len(sealed))
+	binary.BigEndian.PutUint32(frame, uint32(len(sealed)))
+	copy(frame[4:], sealed)
+	_, err = w.WriteAt(frame, offset)
+	return int64(len(frame)), err
+}
+
+func readFrame(r io.Reader, e Encrypter, aad []byte) ([]byte, error) {
+	var n [4]byte
+	if _, err := io.ReadFull(r, n[:]); err != nil {
+		return nil, err
+	}
+	size := binary.BigEndian.Uint32(n[:])
+	if size > 2*encryptedChunkSize {
+		return nil, ErrCorrupted
+	}
+	sealed := make([]byte, size)
+	if _, err := io.ReadFull(r, sealed); err != nil {
+		return nil, ErrCorrupted
+	}
+	return e.Decrypt(sealed, aad)
+}
+
+// decryptReader reads a stream written by encryptStream
+type decryptReader struct {
+	r     io.ReadCloser
+	e     Encrypter
+	id    []byte
+	size  int64
+	read  int64
+	index uint64
+	buf   []byte
+	err   error
+}
+
+// newDecryptReader reads the header frame of the stream name of r
+func newDecryptReader(r io.ReadCloser, e Encrypter, name []byte) (*decryptReader, error) {
+	header, err := readFrame(r, e, name)
+	if err != nil || len(header) != streamHeaderSize {
+		return nil, ErrCorrupted
+	}
+	return &decryptReader{r: r, e: e, id: header[8:], size: int64(binary.BigEndian.Uint64(header))}, nil
+}
+
+// Read implements io.Reader. Every chunk but the last one is full, so the
+// size tells which chunk is the last one.
+func (d *decryptReader) Read(p []byte) (int, error) {
+	for len(d.buf) == 0 {
+		if d.err != nil {
+			return 0, d.err
+		}
+		if d.read == d.size && d.index > 0 {
+			d.err = io.EOF
+			continue
+		}
+		remaining := d.size - d.read
+		last := remaining <= encryptedChunkSize
+		chunk, err := readFrame(d.r, d.e, chunkAdditionalData(d.id, d.index, last))
+		if err != nil || last && int64(len(chunk)) != remaining || !last && len(chunk) != encryptedChunkSize {
+			d.err = ErrCorrupted
+			continue
+		}
+		d.index++
+		d.buf = chunk
+		d.read += int64(len(chunk))
+	}
+	n := copy(p, d.buf)
+	d.buf = d.buf[n:]
+	return n, nil
+}
+
+// Close implements io.Closer.
+func (d *decryptReader) Close() error {
+	return d.r.Close()
+}
@@ -0,0 +1,386 @@
+package resource
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var (
+	testKey1 = []byte("0123456789abcdef0123456789abcdef")
+	testKey2 = []byte("fedcba9876543210fedcba9876543210")
+)
+
+func TestAESGCM(t *testing.T) {
+	oldKey, err := NewAESGCM(testKey1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rotated, err := NewAESGCM(testKey2, testKey1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newKey, err := NewAESGCM(testKey2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		enc     *AESGCM
+		dec     *AESGCM
+		tamper  bool
+		aad     string
+		wantErr error
+	}{
+		{
+			name: "success same key",
+			enc:  oldKey,
+			dec:  oldKey,
+		},
+		{
+			name: "success rotated key",
+			enc:  oldKey,
+			dec:  rotated,
+		},
+		{
+			name:    "failed unknown key",
+			enc:     oldKey,
+			dec:     newKey,
+			wantErr: ErrUnknownKey,
+		},
+		{
+			name:   "failed tampered",
+			enc:    rotated,
+			dec:    rotated,
+			tamper: true,
+		},
+		{
+			name:   "failed other additional data",
+			enc:    rotated,
+			dec:    rotated,
+			tamper: true,
+			aad:    "ipsum",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plaintext := []byte("lorem ipsum")
+			ciphertext, err := tt.enc.Encrypt(plaintext, []byte("lorem"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if bytes.Contains(ciphertext, plaintext) {
+				t.Errorf("AESGCM.Encrypt() expect no plaintext, have %q", ciphertext)
+			}
+			aad := []byte("lorem")
+			if tt.aad != "" {
+				aad = []byte(tt.aad)
+			} else if tt.tamper {
+				ciphertext[len(ciphertext)-1] ^= 1
+			}
+			have, err := tt.dec.Decrypt(ciphertext, aad)
+			if tt.tamper {
+				if err == nil {
+					t.Errorf("AESGCM.Decrypt() expect error, have %q", have)
+				}
+				return
+			}
+			if err != tt.wantErr {
+				t.Fatalf("AESGCM.Decrypt() expect error = %v, have %v", tt.wantErr, err)
+			}
+			if err == nil && !bytes.Equal(have, plaintext) {
+				t.Errorf("AESGCM.Decrypt() expect = %q, have %q", plaintext, have)
+			}
+		})
+	}
+}
+
+func TestFetchCache_DumpEncrypted(t *testing.T) {
+	fakeFetchID := "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	enc, err := NewAESGCM(testKey1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mockedFetcher := &FetcherMock{
+		FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+			return &Model{Name: "lorem", Data: []byte("ipsum")}, nil
+		},
+	}
+	fc := NewCache(mockedFetcher, WithTTL(time.Minute), WithEncrypter(enc))
+	defer fc.Close(context.Background())
+	_, _ = fc.Fetch(context.Background(), fakeFetchID)
+
+	var buf bytes.Buffer
+	if err := fc.Dump(&buf, true); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(buf.Bytes(), []byte("lorem")) {
+		t.Errorf("FetchCache.Dump() expect encrypted values, have %s", buf.Bytes())
+	}
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantN   int
+		wantErr error
+	}{
+		{
+			name:  "success hydrate",
+			opts:  []Option{WithEncrypter(enc)},
+			wantN: 1,
+		},
+		{
+			name:    "failed without encrypter",
+			wantErr: ErrNoEncrypter,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			restored := NewCache(&FetcherMock{}, tt.opts...)
+			defer restored.Close(context.Background())
+
+			n, err := restored.Hydrate(bytes.NewReader(buf.Bytes()))
+			if err != tt.wantErr || n != tt.wantN {
+				t.Fatalf("FetchCache.Hydrate() expect = %v, %v, have %v, %v", tt.wantN, tt.wantErr, n, err)
+			}
+			if n == 0 {
+				return
+			}
+			model, err := restored.Fetch(context.Background(), fakeFetchID)
+			if err != nil || model.Name != "lorem" || string(model.Data) != "ipsum" {
+				t.Errorf("FetchCache.Hydrate() expect model lorem, have %v, %v", model, err)
+			}
+		})
+	}
+}
+
+func TestFetchCache_HydrateSwappedValues(t *testing.T) {
+	enc, err := NewAESGCM(testKey1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mockedFetcher := &FetcherMock{
+		FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+			return &Model{Name: id}, nil
+		},
+	}
+	fc := NewCache(mockedFetcher, WithEncrypter(enc))
+	defer fc.Close(context.Background())
+	_, _ = fc.Fetch(context.Background(), "lorem")
+	_, _ = fc.Fetch(context.Background(), "ipsum")
+
+	var buf bytes.Buffer
+	if err := fc.Dump(&buf, true); err != nil {
+		t.Fatal(err)
+	}
+	var d rawDump
+	if err := json.Unmarshal(buf.Bytes(), &d); err != nil {
+		t.Fatal(err)
+	}
+	var entries []DumpEntry
+	if err := json.Unmarshal(d.Entries, &entries); err != nil || len(entries) != 2 {
+		t.Fatalf("FetchCache.Dump() expect 2 entries, have %v, %v", len(entries), err)
+	}
+	// the values of the two keys are swapped, with a valid checksum
+	entries[0].Sealed, entries[1].Sealed = entries[1].Sealed, entries[0].Sealed
+	d.Entries, _ = json.Marshal(entries)
+	sum, err := dumpChecksum(d.Entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.Checksum = &sum
+	swapped, _ := json.Marshal(d)
+
+	restored := NewCache(&FetcherMock{}, WithEncrypter(enc))
+	defer restored.Close(context.Background())
+	n, err := restored.Hydrate(bytes.NewReader(swapped))
+	if err == nil || errors.Is(err, ErrSnapshotCorrupted) {
+		t.Errorf("FetchCache.Hydrate() expect the swapped values rejected, have %v cached, %v", n, err)
+	}
+}
+
+func TestEncryptStream(t *testing.T) {
+	enc, err := NewAESGCM(testKey1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// frame returns the offsets of the frame i of b, the header being -1
+	headerLen := int(headerFrameLen(enc))
+	chunkLen := headerLen - streamHeaderSize + encryptedChunkSize
+	frame := func(i int) (int, int) {
+		if i < 0 {
+			return 0, headerLen
+		}
+		return headerLen + i*chunkLen, headerLen + (i+1)*chunkLen
+	}
+	other := func(name string, size int) []byte {
+		path := filepath.Join(t.TempDir(), "other")
+		file, err := os.Create(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer file.Close()
+		if _, err := encryptStream(file, bytes.NewReader(bytes.Repeat([]byte{'x'}, size)), enc, []byte(name)); err != nil {
+			t.Fatal(err)
+		}
+		b, _ := os.ReadFile(path)
+		return b
+	}
+
+	tests := []struct {
+		name      string
+		size      int
+		tamper    func(b []byte) []byte
+		wantErr   error
+		wantBadID bool
+	}{
+		{
+			name: "success empty",
+			size: 0,
+		},
+		{
+			name: "success one chunk",
+			size: encryptedChunkSize,
+		},
+		{
+			name: "success several chunks",
+			size: 3*encryptedChunkSize + 1,
+		},
+		{
+			name:    "failed truncated",
+			size:    3 * encryptedChunkSize,
+			tamper:  func(b []byte) []byte { return b[:len(b)-100] },
+			wantErr: ErrCorrupted,
+		},
+		{
+			name:    "failed altered",
+			size:    3 * encryptedChunkSize,
+			tamper:  func(b []byte) []byte { b[len(b)/2] ^= 1; return b },
+			wantErr: ErrCorrupted,
+		},
+		{
+			name: "failed chunks reordered",
+			size: 3 * encryptedChunkSize,
+			tamper: func(b []byte) []byte {
+				start, end := frame(0)
+				next, _ := frame(1)
+				swapped := append([]byte(nil), b[start:end]...)
+				copy(b[start:end], b[next:next+chunkLen])
+				copy(b[next:next+chunkLen], swapped)
+				return b
+			},
+			wantErr: ErrCorrupted,
+		},
+		{
+			name: "failed truncated after a full chunk",
+			size: 2 * encryptedChunkSize,
+			tamper: func(b []byte) []byte {
+				_, end := frame(0)
+				return b[:end]
+			},
+			wantErr: ErrCorrupted,
+		},
+		{
+			name: "failed chunk spliced from another stream",
+			size: 3 * encryptedChunkSize,
+			tamper: func(b []byte) []byte {
+				start, end := frame(1)
+				copy(b[start:end], other("lorem", 3*encryptedChunkSize)[start:end])
+				return b
+			},
+			wantErr: ErrCorrupted,
+		},
+		{
+			name:      "failed stream of another name",
+			size:      encryptedChunkSize,
+			tamper:    func(b []byte) []byte { return other("ipsum", encryptedChunkSize) },
+			wantBadID: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload := bytes.Repeat([]byte{'x'}, tt.size)
+			path := filepath.Join(t.TempDir(), "stream")
+			file, err := os.Create(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			n, err := encryptStream(file, bytes.NewReader(payload), enc, []byte("lorem"))
+			file.Close()
+			if err != nil || n != int64(tt.size) {
+				t.Fatalf("encryptStream() expect = %v, have %v, %v", tt.size, n, err)
+			}
+			if tt.tamper != nil {
+				b, _ := os.ReadFile(path)
+				if err := os.WriteFile(path, tt.tamper(b), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			file, err = os.Open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r, err := newDecryptReader(file, enc, []byte("lorem"))
+			if tt.wantBadID {
+				if !errors.Is(err, ErrCorrupted) {
+					t.Errorf("newDecryptReader() expect error = %v, have %v", ErrCorrupted, err)
+				}
+				file.Close()
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer r.Close()
+			have, err := io.ReadAll(r)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("decryptReader.Read() expect error = %v, have %v", tt.wantErr, err)
+			}
+			if err == nil && (!bytes.Equal(have, payload) || r.size != int64(tt.size)) {
+				t.Errorf("decryptReader.Read() expect %d bytes, have %d of %d", tt.size, len(have), r.size)
+			}
+		})
+	}
+}
+
+func TestStreamCache_OpenEncrypted(t *testing.T) {
+	fakeFetchID := "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	payload := bytes.Repeat([]byte("lorem ipsum "), 10000)
+	enc, err := NewAESGCM(testKey1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := streamingFetcherFunc(func(ctx context.Context, id string) (io.ReadCloser, int64, error) {
+		return io.NopCloser(bytes.NewReader(payload)), int64(len(payload)), nil
+	})
+	sc, err := NewStreamCache(f, t.TempDir(), WithEncrypter(enc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sc.Close(context.Background())
+
+	r, size, err := sc.Open(context.Background(), fakeFetchID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	have, err := io.ReadAll(r)
+	if err != nil || size != int64(len(payload)) || !bytes.Equal(have, payload) {
+		t.Errorf("StreamCache.Open() expect %d bytes, have %d of %d, %v", len(payload), len(have), size, err)
+	}
+
+	model, _ := sc.Cache().Fetch(context.Background(), fakeFetchID)
+	stored, _ := os.ReadFile(model.Name)
+	if bytes.Contains(stored, []byte("lorem")) {
+		t.Errorf("StreamCache.Open() expect encrypted file")
+	}
+}
@@ -0,0 +1,122 @@
+package resource
+
+import (
+	"context"
+	"time"
+)
+
+// Entry is the locked access to the entry of an id given by
+// FetchCache.WithEntry. Its changes are applied once the callback returns
+// nil, an Entry must not be used after that.
+type Entry interface {
+	// ID returns the id of the entry.
+	ID() string
+	// Info returns the metadata of the cached entry and false if the id
+	// isn't cached or has expired.
+	Info() (EntryInfo, bool)
+	// Model returns a copy of the cached model with its own Data, nil if
+	// the id isn't cached.
+	Model() *Model
+	// Set caches model for the id.
+	Set(model *Model)
+	// SetTTL caches the entry for ttl from now, a ttl of 0 meaning it never
+	// expires. It applies to the model of Set or else to the cached one.
+	SetTTL(ttl time.Duration)
+	// Delete clears the entry, undoing Set and SetTTL.
+	Delete()
+}
+
+// WithEntry calls fn with the entry of id while holding the lock of id, so
+// no fetch or other update of id interleaves, for read-check-update
+// changes. The changes made through the Entry are applied when fn returns
+// nil and discarded otherwise, the error of fn is returned. Like Update, a
+// change drops id from the L2 store and other caches and the actor of ctx
+// is recorded.
+func (fc *FetchCache) WithEntry(ctx context.Context, id string, fn func(e Entry) error) error {
+	id = fc.canonical(id)
+	key := fc.key(id)
+	if _, err := fc.lockContext(ctx, key); err != nil {
+		return err
+	}
+	defer fc.unlock(key)
+
+	e := &entry{id: id, ttl: -1}
+	if i, found := fc.fetchFromCache(key); found && fc.owns(i, id) {
+		e.info, e.found = fc.info(key, i), true
+	}
+	if err := fn(e); err != nil {
+		return err
+	}
+
+	switch {
+	case e.deleted:
+		if fc.opts.l2 != nil {
+			_ = fc.opts.l2.Delete(context.Background(), id)
+		}
+		removed := 0
+		if fc.removeitem(key) {
+			removed = 1
+		}
+		fc.negative.remove(key)
+		fc.broadcast(Invalidation{Key: id})
+		fc.audit(AuditClear, ActorFromContext(ctx), fc.traceID(ctx), []string{id}, removed)
+		return nil
+	case e.model == nil && e.ttl >= 0 && e.found:
+		e.model = e.info.Model
+	case e.model == nil:
+		return nil
+	}
+	ttl := e.ttl
+	if ttl < 0 {
+		ttl = fc.defaultTTL()
+	}
+	fc.writeLocked(ctx, key, id, e.model, ttl)
+	return nil
+}
+
+// entry implements Entry, a ttl below 0 means it wasn't set
+type entry struct {
+	id      string
+	info    EntryInfo
+	found   bool
+	model   *Model
+	ttl     time.Duration
+	deleted bool
+}
+
+// ID implements Entry.
+func (e *entry) ID() string {
+	return e.id
+}
+
+// Info implements Entry.
+func (e *entry) Info() (EntryInfo, bool) {
+	return e.info, e.found
+}
+
+// Model implements Entry.
+func (e *entry) Model() *Model {
+	m := e.info.Model
+	if m == nil {
+		return nil
+	}
+	return &Model{Name: m.Name, Data: append([]byte(nil), m.Data...), Value: m.Value}
+}
+
+// Set implements Entry.
+func (e *entry) Set(model *Model) {
+	e.model, e.deleted = model, false
+}
+
+// SetTTL implements Entry.
+func (e *entry) SetTTL(ttl time.Duration) {
+	if ttl < 0 {
+		ttl = 0
+	}
+	e.ttl, e.deleted = ttl, false
+}
+
+// Delete implements Entry.
+func (e *entry) Delete() {
+	e.model, e.ttl, e.deleted = nil, -1, true
+}
@@ -0,0 +1,105 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFetchCache_WithEntry(t *testing.T) {
+	fakeFetchID := "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	errCheck := errors.New("check failed")
+
+	tests := []struct {
+		name        string
+		cached      bool
+		fn          func(e Entry) error
+		wantErr     error
+		wantFound   bool
+		wantName    string
+		wantExpires bool
+	}{
+		{
+			name:   "success set a mutated copy",
+			cached: true,
+			fn: func(e Entry) error {
+				m := e.Model()
+				m.Name += "-updated"
+				e.Set(m)
+				return nil
+			},
+			wantFound:   true,
+			wantName:    "lorem-updated",
+			wantExpires: true,
+		},
+		{
+			name:   "success set ttl",
+			cached: true,
+			fn: func(e Entry) error {
+				e.SetTTL(0)
+				return nil
+			},
+			wantFound: true,
+			wantName:  "lorem",
+		},
+		{
+			name:   "success delete",
+			cached: true,
+			fn: func(e Entry) error {
+				e.Delete()
+				return nil
+			},
+		},
+		{
+			name: "success set ttl of missing entry",
+			fn: func(e Entry) error {
+				if _, found := e.Info(); found || e.Model() != nil {
+					return errCheck
+				}
+				e.SetTTL(time.Minute)
+				return nil
+			},
+		},
+		{
+			name:   "fail error discards the changes",
+			cached: true,
+			fn: func(e Entry) error {
+				e.Set(&Model{Name: "ipsum"})
+				return errCheck
+			},
+			wantErr:     errCheck,
+			wantFound:   true,
+			wantName:    "lorem",
+			wantExpires: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					return &Model{Name: "lorem"}, nil
+				},
+			}
+			fc := NewCache(mockedFetcher, WithTTL(time.Hour))
+			defer fc.Close(context.Background())
+			if tt.cached {
+				_, _ = fc.Fetch(context.Background(), fakeFetchID)
+			}
+
+			if err := fc.WithEntry(context.Background(), fakeFetchID, tt.fn); err != tt.wantErr {
+				t.Errorf("FetchCache.WithEntry() expect error = %v, have %v", tt.wantErr, err)
+			}
+			info, found := fc.Entry(fakeFetchID)
+			if found != tt.wantFound {
+				t.Fatalf("FetchCache.Entry() expect found = %v, have %v", tt.wantFound, found)
+			}
+			if !found {
+				return
+			}
+			if info.Model.Name != tt.wantName || info.Expires.IsZero() == tt.wantExpires {
+				t.Errorf("FetchCache.Entry() expect = %v expires %v, have %+v", tt.wantName, tt.wantExpires, info)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package resource
+
+import (
+	"context"
+	"time"
+)
+
+// ErrorCache configures the caching of the errors of the Fetcher apart
+// from that of the models, so a burst of backend failures can be cached for
+// a second while good models keep their TTL, see WithErrorCache.
+type ErrorCache struct {
+	// TTL is how long an error is returned to the fetches of its id
+	// instead of calling the Fetcher, 0 disables the error cache.
+	TTL time.Duration
+	// MaxEntries bounds the number of cached errors, when full the expired
+	// ones, or else the one expiring first, make room. 0 means no limit.
+	MaxEntries int
+	// Cacheable reports whether err of the Fetcher for id is cached, every
+	// error is if nil.
+	Cacheable func(id string, err error) bool
+}
+
+// WithErrorCache caches the errors of the Fetcher according to c. It
+// applies to the errors an ErrorPolicy doesn't classify ErrorCacheNegative,
+// those keep the negative TTL of WithErrorPolicy and share MaxEntries.
+// Errors of fetches aborted by their ctx are never cached.
+func WithErrorCache(c ErrorCache) Option {
+	return func(o *options) {
+		o.errorCache = c
+	}
+}
+
+// errorCacheable reports whether err of a Fetcher call for id is cached by
+// WithErrorCache
+func (fc *FetchCache) errorCacheable(ctx context.Context, id string, err error) bool {
+	c := fc.opts.errorCache
+	if c.TTL <= 0 || ctx.Err() != nil {
+		return false
+	}
+	return c.Cacheable == nil || c.Cacheable(id, err)
+}
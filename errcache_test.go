@@ -0,0 +1,108 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWithErrorCache(t *testing.T) {
+	fakeFetchID := "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	notExistModelID := "5634aeed-2106-43de-ab7d-c0ad4b1e195e"
+	errBackend := errors.New("backend unavailable")
+
+	tests := []struct {
+		name             string
+		errorCache       ErrorCache
+		ids              []string
+		serviceCallCount int
+	}{
+		{
+			name:             "success error cached",
+			errorCache:       ErrorCache{TTL: time.Minute},
+			ids:              []string{notExistModelID, notExistModelID, fakeFetchID, fakeFetchID},
+			serviceCallCount: 2,
+		},
+		{
+			name: "success error not cacheable",
+			errorCache: ErrorCache{
+				TTL:       time.Minute,
+				Cacheable: func(id string, err error) bool { return err != errBackend },
+			},
+			ids:              []string{notExistModelID, notExistModelID},
+			serviceCallCount: 2,
+		},
+		{
+			name:             "success capacity bounds errors",
+			errorCache:       ErrorCache{TTL: time.Minute, MaxEntries: 1},
+			ids:              []string{notExistModelID, "lorem", "lorem", notExistModelID},
+			serviceCallCount: 3,
+		},
+		{
+			name:             "success error cache disabled",
+			ids:              []string{notExistModelID, notExistModelID},
+			serviceCallCount: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serviceCallCount := 0
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					serviceCallCount++
+					if id != fakeFetchID {
+						return nil, errBackend
+					}
+					return &Model{Name: id}, nil
+				},
+			}
+			fc := NewCache(mockedFetcher, WithTTL(10*time.Minute), WithErrorCache(tt.errorCache))
+			defer fc.Close(context.Background())
+
+			for _, id := range tt.ids {
+				_, err := fc.Fetch(context.Background(), id)
+				if (id != fakeFetchID) != (err == errBackend) {
+					t.Errorf("FetchCache.Fetch() unexpected error = %v for %v", err, id)
+				}
+			}
+			if serviceCallCount != tt.serviceCallCount {
+				t.Errorf("FetchCache.Fetch() expect service call count = %v, have %v", tt.serviceCallCount, serviceCallCount)
+			}
+		})
+	}
+}
+
+func TestWithErrorCache_Expiration(t *testing.T) {
+	notExistModelID := "5634aeed-2106-43de-ab7d-c0ad4b1e195e"
+	errBackend := errors.New("backend unavailable")
+
+	serviceCallCount := 0
+	mockedFetcher := &FetcherMock{
+		FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+			serviceCallCount++
+			if serviceCallCount == 1 {
+				return nil, errBackend
+			}
+			return &Model{Name: id}, nil
+		},
+	}
+	fc := NewCache(mockedFetcher, WithTTL(10*time.Minute), WithErrorCache(ErrorCache{TTL: 20 * time.Millisecond}))
+	defer fc.Close(context.Background())
+
+	if _, err := fc.Fetch(context.Background(), notExistModelID); err != errBackend {
+		t.Fatalf("FetchCache.Fetch() expect error = %v, have %v", errBackend, err)
+	}
+	if _, err := fc.Fetch(context.Background(), notExistModelID); err != errBackend {
+		t.Fatalf("FetchCache.Fetch() expect cached error = %v, have %v", errBackend, err)
+	}
+	time.Sleep(30 * time.Millisecond)
+	for i := 0; i < 2; i++ {
+		if _, err := fc.Fetch(context.Background(), notExistModelID); err != nil {
+			t.Fatalf("FetchCache.Fetch() expect error = nil, have %v", err)
+		}
+	}
+	if serviceCallCount != 2 {
+		t.Errorf("FetchCache.Fetch() expect service call count = 2, have %v", serviceCallCount)
+	}
+}
@@ -0,0 +1,176 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrBreakerOpen is returned by misses while the breaker tripped by an
+// ErrorTripBreaker error is open, see WithErrorPolicy.
+var ErrBreakerOpen = errors.New("fetch breaker open")
+
+// ErrorAction is a set of actions taken on an error of the Fetcher, see
+// ErrorPolicy. The zero ErrorAction passes the error through untouched.
+type ErrorAction uint
+
+// Error action list
+const (
+	// ErrorCacheNegative returns the error to the fetches of the id for
+	// the negative TTL instead of calling the Fetcher.
+	ErrorCacheNegative ErrorAction = 1 << iota
+	// ErrorServeStale serves the expired item of the id if there is one,
+	// however old, instead of the error.
+	ErrorServeStale
+	// ErrorTripBreaker opens the breaker for the breaker cooldown: misses
+	// fail with ErrBreakerOpen unless they can be served stale, and the
+	// cache is Degraded.
+	ErrorTripBreaker
+	// ErrorBackoff counts the error towards WithFailureBackoff.
+	ErrorBackoff
+)
+
+// ErrorPassThrough passes an error through untouched.
+const ErrorPassThrough ErrorAction = 0
+
+// ErrorPolicy is an interface that defines the Classify method.
+type ErrorPolicy interface {
+	// Classify returns the actions to take on err returned by the Fetcher
+	// for id.
+	Classify(id string, err error) ErrorAction
+}
+
+// ErrorPolicyFunc is an adapter to use ordinary functions as ErrorPolicy.
+type ErrorPolicyFunc func(id string, err error) ErrorAction
+
+// Classify implements ErrorPolicy.
+func (f ErrorPolicyFunc) Classify(id string, err error) ErrorAction {
+	return f(id, err)
+}
+
+// WithErrorPolicy handles the errors of the Fetcher according to p,
+// negativeTTL is how long ErrorCacheNegative errors are cached and
+// breakerCooldown how long ErrorTripBreaker errors open the breaker.
+// Without an ErrorPolicy every error is ErrorBackoff. Errors of fetches
+// aborted by their ctx are always passed through.
+func WithErrorPolicy(p ErrorPolicy, negativeTTL, breakerCooldown time.Duration) Option {
+	return func(o *options) {
+		o.errorPolicy = p
+		o.negativeTTL = negativeTTL
+		o.breakerCooldown = breakerCooldown
+	}
+}
+
+// classify returns the actions on err of a Fetcher call for id
+func (fc *FetchCache) classify(ctx context.Context, id string, err error) ErrorAction {
+	if err == nil || ctx.Err() != nil {
+		return ErrorPassThrough
+	}
+	if fc.opts.errorPolicy == nil {
+		return ErrorBackoff
+	}
+	return fc.opts.errorPolicy.Classify(id, err)
+}
+
+// failed takes the negative caching and breaker actions of act on err of
+// a Fetcher call for id, and caches err per WithErrorCache
+func (fc *FetchCache) failed(ctx context.Context, key, id string, err error, act ErrorAction) {
+	now := time.Now()
+	switch {
+	case act&ErrorCacheNegative != 0 && fc.opts.negativeTTL > 0:
+		fc.negative.set(key, err, now.Add(fc.opts.negativeTTL))
+	case fc.errorCacheable(ctx, id, err):
+		fc.negative.set(key, err, now.Add(fc.opts.errorCache.TTL))
+	}
+	if act&ErrorTripBreaker != 0 && fc.opts.breakerCooldown > 0 {
+		atomic.StoreInt64(&fc.breaker, now.Add(fc.opts.breakerCooldown).UnixNano())
+	}
+}
+
+// breakerOpen reports whether a tripped breaker is open at now
+func (fc *FetchCache) breakerOpen(now time.Time) bool {
+	until := atomic.LoadInt64(&fc.breaker)
+	return until != 0 && now.UnixNano() < until
+}
+
+// negativeCache holds the errors cached by ErrorCacheNegative and
+// WithErrorCache by key, at most max when above 0
+type negativeCache struct {
+	// n is the number of entries, so misses take no lock without any
+	n   int32 // accessed atomically
+	mu  sync.Mutex
+	m   map[string]negativeEntry
+	max int
+}
+
+type negativeEntry struct {
+	err   error
+	until int64
+}
+
+// get returns the error cached for key if it is still cached at now
+func (n *negativeCache) get(key string, now time.Time) error {
+	if atomic.LoadInt32(&n.n) == 0 {
+		return nil
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	e, ok := n.m[key]
+	if !ok {
+		return nil
+	}
+	if now.UnixNano() >= e.until {
+		n.deleteLocked(key)
+		return nil
+	}
+	return e.err
+}
+
+// set caches err for key until then, making room if full by dropping the
+// expired entries or else the one expiring first
+func (n *negativeCache) set(key string, err error, until time.Time) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.m == nil {
+		n.m = make(map[string]negativeEntry)
+	}
+	if _, found := n.m[key]; !found && n.max > 0 && len(n.m) >= n.max {
+		now, first, soonest := time.Now().UnixNano(), "", int64(0)
+		for k, e := range n.m {
+			if e.until <= now {
+				delete(n.m, k)
+			} else if first == "" || e.until < soonest {
+				first, soonest = k, e.until
+			}
+		}
+		if len(n.m) >= n.max {
+			delete(n.m, first)
+		}
+	}
+	n.m[key] = negativeEntry{err: err, until: until.UnixNano()}
+	atomic.StoreInt32(&n.n, int32(len(n.m)))
+}
+
+func (n *negativeCache) remove(key string) {
+	if atomic.LoadInt32(&n.n) == 0 {
+		return
+	}
+	n.mu.Lock()
+	n.deleteLocked(key)
+	n.mu.Unlock()
+}
+
+func (n *negativeCache) reset() {
+	n.mu.Lock()
+	n.m = nil
+	atomic.StoreInt32(&n.n, 0)
+	n.mu.Unlock()
+}
+
+// deleteLocked removes key, n.mu must be held
+func (n *negativeCache) deleteLocked(key string) {
+	delete(n.m, key)
+	atomic.StoreInt32(&n.n, int32(len(n.m)))
+}
@@ -0,0 +1,163 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFetchCache_WithErrorPolicy(t *testing.T) {
+	var (
+		fakeFetchID     = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+		notExistModelID = "5634aeed-2106-43de-ab7d-c0ad4b1e195e"
+		errBackend      = errors.New("backend down")
+	)
+
+	tests := []struct {
+		name             string
+		action           ErrorAction
+		cached           bool
+		fetchID          string
+		wantErr          error
+		wantModel        bool
+		serviceCallCount int
+		wantDegraded     bool
+	}{
+		{
+			name:             "success pass through",
+			action:           ErrorPassThrough,
+			fetchID:          fakeFetchID,
+			wantErr:          errBackend,
+			serviceCallCount: 2,
+		},
+		{
+			name:             "success cache negative",
+			action:           ErrorCacheNegative,
+			fetchID:          fakeFetchID,
+			wantErr:          errBackend,
+			serviceCallCount: 1,
+		},
+		{
+			name:             "success cache negative per id",
+			action:           ErrorCacheNegative,
+			fetchID:          notExistModelID,
+			wantErr:          errBackend,
+			serviceCallCount: 2,
+		},
+		{
+			name:             "success serve stale",
+			action:           ErrorServeStale,
+			cached:           true,
+			fetchID:          fakeFetchID,
+			wantModel:        true,
+			serviceCallCount: 3,
+		},
+		{
+			name:             "success serve stale without item",
+			action:           ErrorServeStale,
+			fetchID:          fakeFetchID,
+			wantErr:          errBackend,
+			serviceCallCount: 2,
+		},
+		{
+			name:             "success trip breaker",
+			action:           ErrorTripBreaker,
+			fetchID:          notExistModelID,
+			wantErr:          ErrBreakerOpen,
+			serviceCallCount: 1,
+			wantDegraded:     true,
+		},
+		{
+			name:             "success trip breaker serve stale",
+			action:           ErrorTripBreaker,
+			cached:           true,
+			fetchID:          fakeFetchID,
+			wantModel:        true,
+			serviceCallCount: 2,
+			wantDegraded:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serviceCallCount := 0
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					serviceCallCount++
+					if tt.cached && serviceCallCount == 1 {
+						return &Model{Name: "lorem"}, nil
+					}
+					return nil, errBackend
+				},
+			}
+			policy := ErrorPolicyFunc(func(id string, err error) ErrorAction {
+				if err != errBackend {
+					t.Errorf("ErrorPolicy.Classify() expect err = %v, have %v", errBackend, err)
+				}
+				return tt.action
+			})
+			fc := NewCache(mockedFetcher, WithErrorPolicy(policy, time.Minute, time.Minute))
+			defer fc.Close(context.Background())
+
+			if tt.cached {
+				_, _ = fc.FetchWithOptions(context.Background(), fakeFetchID, OverrideTTL(time.Nanosecond))
+				time.Sleep(time.Millisecond)
+			}
+			_, _ = fc.Fetch(context.Background(), fakeFetchID)
+			model, err := fc.Fetch(context.Background(), tt.fetchID)
+			if err != tt.wantErr {
+				t.Errorf("FetchCache.Fetch() expect error = %v, have %v", tt.wantErr, err)
+			}
+			if (model != nil) != tt.wantModel {
+				t.Errorf("FetchCache.Fetch() expect model = %v, have %v", tt.wantModel, model)
+			}
+			if serviceCallCount != tt.serviceCallCount {
+				t.Errorf("FetchCache.Fetch() expect service call count = %v, have %v", tt.serviceCallCount, serviceCallCount)
+			}
+			if fc.Degraded() != tt.wantDegraded {
+				t.Errorf("FetchCache.Degraded() expect = %v, have %v", tt.wantDegraded, fc.Degraded())
+			}
+		})
+	}
+}
+
+func TestFetchCache_WithErrorPolicy_Clear(t *testing.T) {
+	fakeFetchID := "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	serviceCallCount := 0
+	mockedFetcher := &FetcherMock{
+		FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+			serviceCallCount++
+			return nil, ErrNotFound
+		},
+	}
+	policy := ErrorPolicyFunc(func(id string, err error) ErrorAction { return ErrorCacheNegative })
+	fc := NewCache(mockedFetcher, WithErrorPolicy(policy, time.Minute, 0))
+	defer fc.Close(context.Background())
+
+	_, _ = fc.Fetch(context.Background(), fakeFetchID)
+	fc.Clear(fakeFetchID)
+	if _, err := fc.Fetch(context.Background(), fakeFetchID); err != ErrNotFound {
+		t.Errorf("FetchCache.Fetch() expect error = %v, have %v", ErrNotFound, err)
+	}
+	if serviceCallCount != 2 {
+		t.Errorf("FetchCache.Clear() expect service call count = %v, have %v", 2, serviceCallCount)
+	}
+}
+
+func TestFetchCache_WithErrorPolicy_Backoff(t *testing.T) {
+	fakeFetchID := "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	errBackend := errors.New("backend down")
+	mockedFetcher := &FetcherMock{
+		FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+			return nil, errBackend
+		},
+	}
+	policy := ErrorPolicyFunc(func(id string, err error) ErrorAction { return ErrorPassThrough })
+	fc := NewCache(mockedFetcher, WithErrorPolicy(policy, 0, 0), WithFailureBackoff(time.Minute, time.Minute))
+	defer fc.Close(context.Background())
+
+	_, _ = fc.Fetch(context.Background(), fakeFetchID)
+	if _, err := fc.Fetch(context.Background(), fakeFetchID); err != errBackend {
+		t.Errorf("FetchCache.Fetch() expect error = %v, have %v", errBackend, err)
+	}
+}
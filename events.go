@@ -0,0 +1,149 @@
+package resource
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// EventType is the kind of a cache event
+type EventType int
+
+// Event type list
+const (
+	EventHit EventType = iota + 1
+	EventMiss
+	EventSet
+	EventEvict
+	EventExpire
+	EventFlush
+)
+
+var eventTypeNames = map[EventType]string{
+	EventHit:    "hit",
+	EventMiss:   "miss",
+	EventSet:    "set",
+	EventEvict:  "evict",
+	EventExpire: "expire",
+	EventFlush:  "flush",
+}
+
+// String returns the lower case name of the event type.
+func (t EventType) String() string {
+	if name, ok := eventTypeNames[t]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// Event is something that happened to the cache.
+// Key is empty for EventFlush.
+type Event struct {
+	Type EventType
+	Key  string
+	Time time.Time
+	// TraceID is the id of the request which caused the event, see
+	// WithTraceID.
+	TraceID string
+}
+
+// Subscription is a bounded stream of all cache events.
+//
+// Events are delivered without blocking the cache: when the buffer is full
+// the event is dropped and counted, see Dropped.
+type Subscription struct {
+	dropped uint64 // accessed atomically, keep first for alignment
+	ch      chan Event
+	hub     *eventHub
+	once    sync.Once
+}
+
+// Events returns the channel events are delivered on.
+// The channel is closed by Close.
+func (s *Subscription) Events() <-chan Event {
+	return s.ch
+}
+
+// Dropped returns the number of events lost because the buffer was full.
+func (s *Subscription) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+// Close stops the delivery of events and closes the events channel.
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		s.hub.remove(s)
+		close(s.ch)
+	})
+}
+
+// Subscribe returns a subscription to all cache events with a buffer
+// of size buffer. A buffer smaller than 1 is treated as 1.
+func (fc *FetchCache) Subscribe(buffer int) *Subscription {
+	if buffer < 1 {
+		buffer = 1
+	}
+	s := &Subscription{
+		ch:  make(chan Event, buffer),
+		hub: fc.events,
+	}
+	fc.events.add(s)
+	return s
+}
+
+// eventHub fans out events to subscriptions
+type eventHub struct {
+	mu   sync.RWMutex
+	subs []*Subscription
+}
+
+func (h *eventHub) add(s *Subscription) {
+	h.mu.Lock()
+	h.subs = append(h.subs, s)
+	h.mu.Unlock()
+}
+
+func (h *eventHub) remove(s *Subscription) {
+	h.mu.Lock()
+	for i, sub := range h.subs {
+		if sub == s {
+			h.subs = append(h.subs[:i], h.subs[i+1:]...)
+			break
+		}
+	}
+	h.mu.Unlock()
+}
+
+// closeAll closes every subscription
+func (h *eventHub) closeAll() {
+	h.mu.RLock()
+	subs := make([]*Subscription, len(h.subs))
+	copy(subs, h.subs)
+	h.mu.RUnlock()
+	for _, s := range subs {
+		s.Close()
+	}
+}
+
+// publish delivers the event to every subscription without blocking
+func (h *eventHub) publish(t EventType, key string) {
+	h.publishTrace(t, key, "")
+}
+
+// publishTrace is publish for an event caused by the request of trace
+func (h *eventHub) publishTrace(t EventType, key, trace string) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if len(h.subs) == 0 {
+		return
+	}
+
+	e := Event{Type: t, Key: key, Time: time.Now(), TraceID: trace}
+	for _, s := range h.subs {
+		select {
+		case s.ch <- e:
+		default:
+			atomic.AddUint64(&s.dropped, 1)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestFetchCache_Subscribe(t *testing.T) {
+	var (
+		fakeFetchID = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	)
+
+	mockedFetcher := &FetcherMock{
+		FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+			if id == fakeFetchID {
+				return &Model{Name: "lorem"}, nil
+			}
+
+			return nil, errors.New("not found model")
+		},
+	}
+
+	tests := []struct {
+		name        string
+		buffer      int
+		do          func(fc *FetchCache)
+		wantTypes   []EventType
+		wantDropped uint64
+	}{
+		{
+			name:   "success receive miss set hit evict flush",
+			buffer: 10,
+			do: func(fc *FetchCache) {
+				_, _ = fc.Fetch(context.Background(), fakeFetchID)
+				_, _ = fc.Fetch(context.Background(), fakeFetchID)
+				fc.Clear(fakeFetchID)
+				fc.Flush()
+			},
+			wantTypes: []EventType{EventMiss, EventSet, EventHit, EventEvict, EventFlush},
+		},
+		{
+			name:   "success count dropped events when buffer is full",
+			buffer: 1,
+			do: func(fc *FetchCache) {
+				_, _ = fc.Fetch(context.Background(), fakeFetchID)
+				_, _ = fc.Fetch(context.Background(), fakeFetchID)
+			},
+			wantTypes:   []EventType{EventMiss},
+			wantDropped: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fc := NewCache(mockedFetcher)
+			sub := fc.Subscribe(tt.buffer)
+			tt.do(fc)
+			sub.Close()
+
+			var types []EventType
+			for e := range sub.Events() {
+				types = append(types, e.Type)
+			}
+			if !reflect.DeepEqual(types, tt.wantTypes) {
+				t.Errorf("FetchCache.Subscribe() expect events = %v, have %v", tt.wantTypes, types)
+			}
+			if sub.Dropped() != tt.wantDropped {
+				t.Errorf("Subscription.Dropped() expect = %v, have %v", tt.wantDropped, sub.Dropped())
+			}
+		})
+	}
+}
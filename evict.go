@@ -0,0 +1,112 @@
+package resource
+
+// EvictionPolicy chooses which item to evict when the cache is full, see
+// WithMaxEntries.
+type EvictionPolicy int
+
+// Eviction policy list
+const (
+	// EvictOldest evicts the item cached the longest ago.
+	EvictOldest EvictionPolicy = iota
+	// EvictCostAware evicts by Greedy-Dual-Size-Frequency: items which
+	// took long to fetch, are hit often and are small are kept the longest,
+	// and an aging clock lets idle expensive items go eventually.
+	EvictCostAware
+	// EvictSampled evicts the least recently accessed of a few items
+	// sampled at random, an approximate LRU in the way of Redis which only
+	// looks at WithEvictionSample items per eviction, not all of them, and
+	// keeps no list to update on every hit.
+	EvictSampled
+)
+
+// defaultEvictionSample is the number of items sampled by EvictSampled
+const defaultEvictionSample = 5
+
+// WithEvictionPolicy sets how items are chosen for eviction, EvictOldest
+// by default.
+func WithEvictionPolicy(p EvictionPolicy) Option {
+	return func(o *options) {
+		o.evictionPolicy = p
+	}
+}
+
+// WithEvictionSample sets the number of items EvictSampled samples per
+// eviction, 5 if n is lower than 1. More samples evict closer to LRU.
+func WithEvictionSample(n int) Option {
+	return func(o *options) {
+		o.evictionSample = n
+	}
+}
+
+// victimLocked returns the key of the item to evict and false if every
+// item is protected, see pinSet, fc.itemsLock must be held.
+func (fc *FetchCache) victimLocked() (string, bool) {
+	var (
+		victim string
+		min    float64
+		first  = true
+	)
+	sample := fc.opts.evictionSample
+	if sample < 1 {
+		sample = defaultEvictionSample
+	}
+	fc.items.Range(func(key string, i item) bool {
+		if fc.pins.protected(key) {
+			return true
+		}
+		var p float64
+		switch fc.opts.evictionPolicy {
+		case EvictCostAware:
+			p = fc.gdsfPriority(key, i)
+		case EvictSampled:
+			// Range starts at a random item
+			p = float64(i.Created)
+			if accessed := fc.stats.lastAccess(key); accessed > i.Created {
+				p = float64(accessed)
+			}
+			sample--
+		default:
+			p = float64(i.Created)
+		}
+		if first || p < min {
+			victim, min, first = key, p, false
+		}
+		return fc.opts.evictionPolicy != EvictSampled || sample > 0
+	})
+	if first {
+		return "", false
+	}
+	if fc.opts.evictionPolicy == EvictCostAware {
+		fc.evictClock = min
+	}
+	return victim, true
+}
+
+// gdsfPriority returns the Greedy-Dual-Size-Frequency priority of i,
+// the clock when it was stored plus frequency times cost over size
+func (fc *FetchCache) gdsfPriority(key string, i item) float64 {
+	size := 1
+	if i.Object != nil {
+		size += len(i.Object.Name) + len(i.Object.Data)
+	}
+	freq := float64(fc.stats.hits(key) + 1)
+	return i.Clock + freq*float64(i.Cost)/float64(size)
+}
+
+// evictOne evicts the item chosen by the eviction policy and returns
+// false if the cache is empty or every item is protected
+func (fc *FetchCache) evictOne() bool {
+	fc.lockItems()
+	if fc.items.Len() == 0 {
+		fc.itemsLock.Unlock()
+		return false
+	}
+	evicted := fc.evictLocked(fc.items.Len()-1, EvictedBudget)
+	fc.itemsChanged(false)
+	fc.itemsLock.Unlock()
+	for _, key := range evicted {
+		fc.stats.remove(key)
+		fc.events.publish(EventEvict, key)
+	}
+	return len(evicted) > 0
+}
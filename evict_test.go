@@ -0,0 +1,96 @@
+package resource
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestFetchCache_WithEvictionPolicy(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      EvictionPolicy
+		wantEvicted string
+	}{
+		{
+			name:        "success evict oldest",
+			policy:      EvictOldest,
+			wantEvicted: "slow",
+		},
+		{
+			name:        "success evict cheapest",
+			policy:      EvictCostAware,
+			wantEvicted: "fast",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					if id == "slow" {
+						time.Sleep(5 * time.Millisecond)
+					}
+					return &Model{Name: id}, nil
+				},
+			}
+			fc := NewCache(mockedFetcher, WithMaxEntries(2), WithEvictionPolicy(tt.policy))
+			for _, id := range []string{"slow", "fast", "new"} {
+				if _, err := fc.Fetch(context.Background(), id); err != nil {
+					t.Fatalf("FetchCache.Fetch() error = %v", err)
+				}
+			}
+
+			for _, id := range []string{"slow", "fast", "new"} {
+				_, found := fc.Entry(id)
+				if found == (id == tt.wantEvicted) {
+					t.Errorf("FetchCache.Entry(%v) expect cached = %v, have %v", id, id != tt.wantEvicted, found)
+				}
+			}
+			if entry, found := fc.Entry("new"); !found || entry.FetchCost <= 0 {
+				t.Errorf("FetchCache.Entry() expect a fetch cost, have %v", entry.FetchCost)
+			}
+		})
+	}
+}
+
+func TestFetchCache_WithEvictionPolicy_Sampled(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      EvictionPolicy
+		wantEvicted string
+	}{
+		{
+			name:        "success evict oldest despite a hit",
+			policy:      EvictOldest,
+			wantEvicted: "a",
+		},
+		{
+			name:        "success evict least recently accessed",
+			policy:      EvictSampled,
+			wantEvicted: "b",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					return &Model{Name: id}, nil
+				},
+			}
+			// sampling every item is exact LRU
+			fc := NewCache(mockedFetcher, WithMaxEntries(3), WithEvictionPolicy(tt.policy), WithEvictionSample(10))
+			for _, id := range []string{"a", "b", "c", "a", "d"} {
+				if _, err := fc.Fetch(context.Background(), id); err != nil {
+					t.Fatalf("FetchCache.Fetch() error = %v", err)
+				}
+			}
+
+			for _, id := range []string{"a", "b", "c", "d"} {
+				_, found := fc.Entry(id)
+				if found == (id == tt.wantEvicted) {
+					t.Errorf("FetchCache.Entry(%v) expect cached = %v, have %v", id, id != tt.wantEvicted, found)
+				}
+			}
+		})
+	}
+}
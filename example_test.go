@@ -0,0 +1,114 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// exampleFetcher fetches models named after their id, counting the calls
+func exampleFetcher(calls *int) Fetcher {
+	return &FetcherMock{
+		FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+			*calls++
+			return &Model{Name: "model " + id}, nil
+		},
+	}
+}
+
+func ExampleNew() {
+	var calls int
+	fc, err := New(exampleFetcher(&calls), WithTTL(time.Minute), WithMaxEntries(1000))
+	if err != nil {
+		panic(err)
+	}
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		m, _ := fc.Fetch(ctx, "a")
+		fmt.Println(m.Name)
+	}
+	fmt.Println("fetcher calls:", calls)
+	// Output:
+	// model a
+	// model a
+	// model a
+	// fetcher calls: 1
+}
+
+func ExampleNew_conflictingOptions() {
+	var calls int
+	_, err := New(exampleFetcher(&calls), WithL2Lock(time.Second, 0))
+	fmt.Println(errors.Is(err, ErrInvalidOptions))
+	fmt.Println(err)
+	// Output:
+	// true
+	// invalid options: WithL2Lock requires WithL2
+}
+
+func ExampleWithL2() {
+	var calls int
+	store := newMapL2Store()
+	first := NewCache(exampleFetcher(&calls), WithL2(store, nil))
+	second := NewCache(exampleFetcher(&calls), WithL2(store, nil))
+	ctx := context.Background()
+	first.Fetch(ctx, "a")
+	m, _ := second.Fetch(ctx, "a")
+	fmt.Println(m.Name)
+	fmt.Println("fetcher calls:", calls, "L2 hits:", second.Stats().L2Hits)
+	// Output:
+	// model a
+	// fetcher calls: 1 L2 hits: 1
+}
+
+func ExampleFetchCache_Namespace() {
+	var calls int
+	fc := NewCache(exampleFetcher(&calls))
+	sessions := fc.Namespace("tenantA/sessions", Expire(Expiration{AfterAccess: time.Minute}))
+	m, _ := sessions.Fetch(context.Background(), "a")
+	fmt.Println(sessions.Key("a"))
+	fmt.Println(m.Name)
+	// Output:
+	// tenantA/sessions/a
+	// model tenantA/sessions/a
+}
+
+func ExampleFetchCache_FetchOptional() {
+	fc := NewCache(&FetcherMock{
+		FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+			return nil, nil
+		},
+	}, WithEmptyTTL(time.Minute))
+	_, found, err := fc.FetchOptional(context.Background(), "a")
+	fmt.Println(found, err)
+	// Output:
+	// false <nil>
+}
+
+func ExampleFetchCache_Warm() {
+	var calls int
+	fc := NewCache(exampleFetcher(&calls), WithReadiness(1))
+	ch := make(chan WarmEntry, 2)
+	ch <- WarmEntry{ID: "a", Model: &Model{Name: "model a"}}
+	ch <- WarmEntry{ID: "b", Model: &Model{Name: "model b"}}
+	close(ch)
+	fmt.Println("ready:", fc.Ready())
+	p, _ := fc.Warm(context.Background(), ChanWarmer(ch), nil)
+	fmt.Println("loaded:", p.Loaded, "ready:", fc.Ready())
+	m, _ := fc.Fetch(context.Background(), "b")
+	fmt.Println(m.Name, "fetcher calls:", calls)
+	// Output:
+	// ready: false
+	// loaded: 2 ready: true
+	// model b fetcher calls: 0
+}
+
+func ExampleKeyedGroup() {
+	g := NewKeyedGroup(0, 0)
+	v, shared, err := g.Do(context.Background(), "report", func(ctx context.Context) (interface{}, error) {
+		return "built", nil
+	})
+	fmt.Println(v, shared, err)
+	// Output:
+	// built false <nil>
+}
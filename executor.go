@@ -0,0 +1,126 @@
+package resource
+
+import (
+	"context"
+	"sync"
+)
+
+// WithFetchManyConcurrency makes FetchMany run at most n fetches at a
+// time, all of them at once if n is lower than 1, the default.
+func WithFetchManyConcurrency(n int) Option {
+	return func(o *options) {
+		o.fetchManyConcurrency = n
+	}
+}
+
+// WithWarmConcurrency makes Warm cache up to n entries at a time, 1 if n is
+// lower, the default. Entries are still pulled one at a time.
+func WithWarmConcurrency(n int) Option {
+	return func(o *options) {
+		o.warmConcurrency = n
+	}
+}
+
+// group runs the tasks of an operation of the cache, Warm, FetchMany or a
+// refresh of many keys, with at most limit tasks at a time under a context
+// canceled by the parent context, Close and, when failFast, the first
+// failed task. It runs the tasks in the calling goroutine WithSynchronous.
+type group struct {
+	ctx         context.Context
+	cancel      context.CancelFunc
+	stop        <-chan struct{}
+	sem         chan struct{}
+	failFast    bool
+	synchronous bool
+	wg          sync.WaitGroup
+	once        sync.Once
+	err         error
+}
+
+// newGroup returns a group of at most limit tasks at a time, no limit if
+// lower than 1, which must be waited for
+func (fc *FetchCache) newGroup(ctx context.Context, limit int, failFast bool) *group {
+	ctx, cancel := context.WithCancel(ctx)
+	g := &group{
+		ctx:         ctx,
+		cancel:      cancel,
+		stop:        fc.life.stop,
+		failFast:    failFast,
+		synchronous: fc.opts.synchronous,
+	}
+	if limit > 0 {
+		g.sem = make(chan struct{}, limit)
+	}
+	if !g.synchronous {
+		go func() {
+			select {
+			case <-g.stop:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+	}
+	return g
+}
+
+// Go runs fn with the context of the group once a slot is free, it
+// returns false without running fn if the group is canceled first
+func (g *group) Go(fn func(ctx context.Context) error) bool {
+	if g.synchronous {
+		select {
+		case <-g.stop:
+			g.cancel()
+		default:
+		}
+		if g.ctx.Err() != nil {
+			return false
+		}
+		g.done(fn(g.ctx))
+		return true
+	}
+	if g.sem != nil {
+		select {
+		case g.sem <- struct{}{}:
+		case <-g.ctx.Done():
+			return false
+		}
+	}
+	if g.ctx.Err() != nil {
+		g.release()
+		return false
+	}
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		defer g.release()
+		g.done(fn(g.ctx))
+	}()
+	return true
+}
+
+// Wait waits for the tasks, cancels the context of the group and returns
+// the first error of a task
+func (g *group) Wait() error {
+	g.wg.Wait()
+	g.cancel()
+	return g.err
+}
+
+func (g *group) release() {
+	if g.sem != nil {
+		<-g.sem
+	}
+}
+
+// done records the result of a task
+func (g *group) done(err error) {
+	if err == nil {
+		return
+	}
+	g.once.Do(func() {
+		g.err = err
+		if g.failFast {
+			g.cancel()
+		}
+	})
+}
@@ -0,0 +1,144 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFetchCache_newGroup(t *testing.T) {
+	errTask := errors.New("task failed")
+
+	tests := []struct {
+		name        string
+		limit       int
+		failFast    bool
+		tasks       int
+		fail        int
+		close       bool
+		wantMax     int32
+		wantStarted int
+		wantErr     error
+	}{
+		{
+			name:        "success bounded concurrency",
+			limit:       2,
+			tasks:       8,
+			wantMax:     2,
+			wantStarted: 8,
+		},
+		{
+			name:        "failed first error without fail fast",
+			limit:       1,
+			tasks:       4,
+			fail:        2,
+			wantMax:     1,
+			wantStarted: 4,
+			wantErr:     errTask,
+		},
+		{
+			name:        "failed fail fast cancels the other tasks",
+			limit:       1,
+			failFast:    true,
+			tasks:       4,
+			fail:        2,
+			wantMax:     1,
+			wantStarted: 2,
+			wantErr:     errTask,
+		},
+		{
+			name:    "failed canceled by close",
+			limit:   1,
+			tasks:   4,
+			close:   true,
+			wantErr: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fc := NewCache(&FetcherMock{})
+			if tt.close {
+				_ = fc.Close(context.Background())
+			}
+			g := fc.newGroup(context.Background(), tt.limit, tt.failFast)
+			if tt.close {
+				<-g.ctx.Done()
+			}
+			var running, max int32
+			started := 0
+			for i := 1; i <= tt.tasks; i++ {
+				i := i
+				if !g.Go(func(ctx context.Context) error {
+					n := atomic.AddInt32(&running, 1)
+					defer atomic.AddInt32(&running, -1)
+					for {
+						m := atomic.LoadInt32(&max)
+						if n <= m || atomic.CompareAndSwapInt32(&max, m, n) {
+							break
+						}
+					}
+					time.Sleep(time.Millisecond)
+					if i == tt.fail {
+						return errTask
+					}
+					return nil
+				}) {
+					break
+				}
+				started++
+			}
+			err := g.Wait()
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("group.Wait() expect error = %v, have %v", tt.wantErr, err)
+			}
+			if started != tt.wantStarted {
+				t.Errorf("group.Go() expect started = %v, have %v", tt.wantStarted, started)
+			}
+			if max != tt.wantMax {
+				t.Errorf("group.Go() expect max concurrency = %v, have %v", tt.wantMax, max)
+			}
+		})
+	}
+}
+
+// blockingWarmer is a Warmer returning one entry then blocking until its
+// context is done
+type blockingWarmer struct {
+	sent bool
+}
+
+func (w *blockingWarmer) Next(ctx context.Context) (WarmEntry, error) {
+	if !w.sent {
+		w.sent = true
+		return WarmEntry{ID: "dca76878-a8f6-4ff5-b263-1e8c7e61bc20", Model: &Model{Name: "warm"}}, nil
+	}
+	<-ctx.Done()
+	return WarmEntry{}, ctx.Err()
+}
+
+func TestFetchCache_Warm_Close(t *testing.T) {
+	fc := NewCache(&FetcherMock{}, WithWarmConcurrency(4))
+	done := make(chan error, 1)
+	var p WarmProgress
+	go func() {
+		var err error
+		p, err = fc.Warm(context.Background(), &blockingWarmer{}, nil)
+		done <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	_ = fc.Close(context.Background())
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("FetchCache.Warm() expect error = %v, have %v", context.Canceled, err)
+		}
+		if p.Loaded != 1 {
+			t.Errorf("FetchCache.Warm() expect loaded = 1, have %v", p.Loaded)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("FetchCache.Warm() expect to be canceled by Close")
+	}
+}
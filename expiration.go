@@ -0,0 +1,52 @@
+package resource
+
+import "time"
+
+// Expiration combines the ways an item expires, for the model classes
+// needing more than a TTL. An item expires at the earliest of:
+//
+//   - AfterWrite after it was stored, the TTL;
+//   - AfterAccess after its last hit, or after it was stored if never hit;
+//   - MaxLifetime after it was first loaded, however often it was
+//     refreshed since, see WithMaxLifetime.
+//
+// A field of 0 keeps the setting of the cache, or of the parent namespace
+// when given to a Namespace: the TTL of the Result or of WithTTL, no idle
+// expiration and the max lifetime of WithMaxLifetime.
+type Expiration struct {
+	AfterWrite  time.Duration
+	AfterAccess time.Duration
+	MaxLifetime time.Duration
+}
+
+// Expire sets the expiration of the items stored by the call, a Namespace
+// giving it sets it for its subtree. Its AfterWrite is OverrideTTL, the
+// last of them given wins. Hits are recorded at a resolution of 100ms, so
+// is AfterAccess.
+func Expire(e Expiration) FetchOption {
+	return func(o *fetchOptions) {
+		if e.AfterWrite > 0 {
+			o.ttl, o.hasTTL = e.AfterWrite, true
+		}
+		if e.AfterAccess > 0 {
+			o.idle = e.AfterAccess
+		}
+		if e.MaxLifetime > 0 {
+			o.maxLifetime = e.MaxLifetime
+		}
+	}
+}
+
+// idled reports whether the item i of key went without a hit for its
+// time-to-idle
+func (fc *FetchCache) idled(key string, i item) bool {
+	if i.Idle <= 0 {
+		return false
+	}
+	now := time.Now().UnixNano()
+	accessed := fc.stats.lastAccess(key)
+	if accessed < i.Created {
+		accessed = i.Created
+	}
+	return now-accessed >= i.Idle
+}
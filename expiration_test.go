@@ -0,0 +1,77 @@
+package resource
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestNamespace_Expire(t *testing.T) {
+	var fakeFetchID = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	type step struct {
+		sleep     time.Duration
+		wantFetch bool
+	}
+	tests := []struct {
+		name  string
+		e     Expiration
+		steps []step
+	}{
+		{
+			name: "success after write",
+			e:    Expiration{AfterWrite: 50 * time.Millisecond},
+			steps: []step{
+				{sleep: 10 * time.Millisecond},
+				{sleep: 80 * time.Millisecond, wantFetch: true},
+			},
+		},
+		{
+			name: "success after access kept by hits",
+			e:    Expiration{AfterAccess: 300 * time.Millisecond},
+			steps: []step{
+				{sleep: 150 * time.Millisecond},
+				{sleep: 200 * time.Millisecond},
+				{sleep: 350 * time.Millisecond, wantFetch: true},
+			},
+		},
+		{
+			name: "success max lifetime before after write",
+			e:    Expiration{AfterWrite: time.Hour, MaxLifetime: 50 * time.Millisecond},
+			steps: []step{
+				{sleep: 80 * time.Millisecond, wantFetch: true},
+			},
+		},
+		{
+			name: "success after access before after write",
+			e:    Expiration{AfterWrite: time.Hour, AfterAccess: 50 * time.Millisecond},
+			steps: []step{
+				{sleep: 80 * time.Millisecond, wantFetch: true},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					return &Model{Name: id}, nil
+				},
+			}
+			fc := NewCache(mockedFetcher, WithTTL(time.Hour))
+			ns := fc.Namespace("models", Expire(tt.e))
+			ctx := context.Background()
+			if _, err := ns.Fetch(ctx, fakeFetchID); err != nil {
+				t.Fatalf("Namespace.Fetch() expect no error, have %v", err)
+			}
+			for n, s := range tt.steps {
+				time.Sleep(s.sleep)
+				calls := len(mockedFetcher.FetchCalls())
+				if _, err := ns.Fetch(ctx, fakeFetchID); err != nil {
+					t.Fatalf("Namespace.Fetch() expect no error, have %v", err)
+				}
+				if fetched := len(mockedFetcher.FetchCalls()) > calls; fetched != s.wantFetch {
+					t.Errorf("Namespace.Fetch() step %v expect fetched = %v, have %v", n, s.wantFetch, fetched)
+				}
+			}
+		})
+	}
+}
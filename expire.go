@@ -0,0 +1,107 @@
+package resource
+
+import "time"
+
+// sweepBatch is the number of items a sweep looks at per itemsLock section
+const sweepBatch = 64
+
+// WithExpirySweep removes expired items every interval, which are
+// otherwise only replaced when their key is fetched again. A sweep looks
+// at batches of random items and stops after budget items, or as soon as
+// less than a quarter of a batch had expired, so its cost stays bounded and
+// smooth however large the cache is. Items still in the stale window of
+// WithStaleWhileRevalidate are kept.
+func WithExpirySweep(interval time.Duration, budget int) Option {
+	return func(o *options) {
+		if budget < 1 {
+			budget = sweepBatch
+		}
+		o.sweepInterval = interval
+		o.sweepBudget = budget
+	}
+}
+
+// WithExpiredBatches sends to ch the keys of the expired items removed by
+// every sweep of WithExpirySweep and every tick of WithExpiryWheel, so a
+// consumer can recompute them in bulk. A batch is dropped when ch is full,
+// the removals never wait for the consumer.
+func WithExpiredBatches(ch chan<- []string) Option {
+	return func(o *options) {
+		o.expiredBatches = ch
+	}
+}
+
+// notifyExpired sends the keys of items to the channel of
+// WithExpiredBatches
+func (fc *FetchCache) notifyExpired(items []expiredItem) {
+	if fc.opts.expiredBatches == nil || len(items) == 0 {
+		return
+	}
+	keys := make([]string, len(items))
+	for n, i := range items {
+		keys[n] = i.key
+	}
+	select {
+	case fc.opts.expiredBatches <- keys:
+	default:
+	}
+}
+
+// runExpirySweep sweeps every interval until stop
+func (fc *FetchCache) runExpirySweep(stop <-chan struct{}) {
+	ticker := time.NewTicker(fc.opts.sweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+		fc.sweepExpired()
+	}
+}
+
+// sweepExpired removes expired items from random batches and returns how
+// many it removed
+func (fc *FetchCache) sweepExpired() int {
+	var all []expiredItem
+	for seen := 0; seen < fc.opts.sweepBudget; {
+		batch := sweepBatch
+		if left := fc.opts.sweepBudget - seen; left < batch {
+			batch = left
+		}
+		// expired items past the stale window and idle items, the
+		// iteration of the map store starts at a random item so batches
+		// sample the whole map
+		deadline := time.Now().UnixNano() - int64(fc.staleWindow())
+		var expired []expiredItem
+		n := 0
+		fc.lockItems()
+		fc.items.Range(func(key string, i item) bool {
+			if n == batch {
+				return false
+			}
+			n++
+			if i.Expiration != 0 && i.Expiration < deadline || fc.idled(key, i) {
+				expired = append(expired, expiredItem{key, i.Object})
+			}
+			return true
+		})
+		for _, e := range expired {
+			fc.items.Delete(e.key)
+		}
+		if len(expired) > 0 {
+			fc.itemsChanged(false)
+		}
+		fc.itemsLock.Unlock()
+
+		fc.expired(expired)
+		all = append(all, expired...)
+		seen += n
+		if n < batch || len(expired)*4 < n {
+			break
+		}
+	}
+	fc.notifyExpired(all)
+	return len(all)
+}
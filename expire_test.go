@@ -0,0 +1,107 @@
+package resource
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestFetchCache_WithExpirySweep(t *testing.T) {
+	tests := []struct {
+		name        string
+		items       int
+		budget      int
+		staleWindow time.Duration
+		wantRemoved int
+	}{
+		{
+			name:        "success remove all expired",
+			items:       100,
+			budget:      1000,
+			wantRemoved: 100,
+		},
+		{
+			name:        "success bounded by budget",
+			items:       100,
+			budget:      10,
+			wantRemoved: 10,
+		},
+		{
+			name:        "success keep stale items",
+			items:       100,
+			budget:      1000,
+			staleWindow: time.Minute,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					return &Model{Name: "lorem"}, nil
+				},
+			}
+			fc := NewCache(mockedFetcher,
+				WithSynchronous(),
+				WithTTL(time.Millisecond),
+				WithStaleWhileRevalidate(tt.staleWindow),
+				WithExpirySweep(time.Millisecond, tt.budget),
+			)
+			defer fc.Close(context.Background())
+			for i := 0; i < tt.items; i++ {
+				if _, err := fc.Fetch(context.Background(), strconv.Itoa(i)); err != nil {
+					t.Fatalf("FetchCache.Fetch() error = %v", err)
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			fc.Tick(context.Background())
+
+			s := fc.Stats()
+			if int(s.Expired) != tt.wantRemoved {
+				t.Errorf("FetchCache.Tick() expect expired = %v, have %v", tt.wantRemoved, s.Expired)
+			}
+			if s.Items != tt.items-tt.wantRemoved {
+				t.Errorf("FetchCache.Tick() expect items = %v, have %v", tt.items-tt.wantRemoved, s.Items)
+			}
+		})
+	}
+}
+
+func TestFetchCache_WithExpiredBatches(t *testing.T) {
+	mockedFetcher := &FetcherMock{
+		FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+			return &Model{Name: id}, nil
+		},
+	}
+	batches := make(chan []string, 1)
+	fc := NewCache(mockedFetcher,
+		WithSynchronous(),
+		WithTTL(time.Millisecond),
+		WithExpirySweep(time.Millisecond, 1000),
+		WithExpiredBatches(batches),
+	)
+	defer fc.Close(context.Background())
+	for i := 0; i < 100; i++ {
+		if _, err := fc.Fetch(context.Background(), strconv.Itoa(i)); err != nil {
+			t.Fatalf("FetchCache.Fetch() error = %v", err)
+		}
+	}
+	time.Sleep(5 * time.Millisecond)
+	fc.Tick(context.Background())
+
+	select {
+	case keys := <-batches:
+		if len(keys) != 100 {
+			t.Errorf("WithExpiredBatches() expect a batch of 100 keys, have %v", len(keys))
+		}
+	default:
+		t.Fatalf("WithExpiredBatches() expect a batch")
+	}
+	// nothing expired, nothing sent
+	fc.Tick(context.Background())
+	select {
+	case keys := <-batches:
+		t.Errorf("WithExpiredBatches() expect no batch, have %v", keys)
+	default:
+	}
+}
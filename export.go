@@ -0,0 +1,76 @@
+package resource
+
+import "context"
+
+// exportBatch is the most items ExportTo reads under one acquisition of the
+// items lock
+const exportBatch = 256
+
+// ExportTo calls fn with the key and model of every cached item which
+// hasn't expired, e.g. to sync the cache to an analytics store, until fn
+// returns an error or ctx is done, whose error is returned. It is designed
+// for live traffic: the keys are listed first, shard by shard for a
+// ShardedStore, then read exportBatch at a time, each list and batch under
+// its own acquisition of the items lock, and fn is called without any lock
+// held. The items written during the export may or may not be seen, the
+// ones removed by an eviction, a Clear or a Flush meanwhile are skipped.
+// With WithHashedKeys the keys are the hashes of the ids.
+func (fc *FetchCache) ExportTo(ctx context.Context, fn func(id string, m *Model) error) error {
+	keys := fc.exportKeys()
+	type entry struct {
+		key   string
+		model *Model
+	}
+	batch := make([]entry, 0, exportBatch)
+	for len(keys) > 0 {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		n := exportBatch
+		if len(keys) < n {
+			n = len(keys)
+		}
+		batch = batch[:0]
+		fc.rlockItems()
+		for _, key := range keys[:n] {
+			if i, found := fc.items.Get(key); found && !i.expired() && i.Object != nil {
+				batch = append(batch, entry{key, i.Object})
+			}
+		}
+		fc.itemsLock.RUnlock()
+		keys = keys[n:]
+		for _, e := range batch {
+			if err := fn(e.key, e.model); err != nil {
+				return err
+			}
+		}
+	}
+	return ctx.Err()
+}
+
+// exportKeys lists the keys of the items
+func (fc *FetchCache) exportKeys() []string {
+	fc.rlockItems()
+	s, sharded := fc.items.(*ShardedStore)
+	keys := make([]string, 0, fc.items.Len())
+	if !sharded {
+		fc.items.Range(func(key string, i Item) bool {
+			keys = append(keys, key)
+			return true
+		})
+		fc.itemsLock.RUnlock()
+		return keys
+	}
+	fc.itemsLock.RUnlock()
+	for n := 0; ; n++ {
+		fc.rlockItems()
+		if n >= len(s.shards) {
+			fc.itemsLock.RUnlock()
+			return keys
+		}
+		for key := range s.shards[n] {
+			keys = append(keys, key)
+		}
+		fc.itemsLock.RUnlock()
+	}
+}
@@ -0,0 +1,129 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFetchCache_ExportTo(t *testing.T) {
+	errSink := errors.New("sink down")
+
+	tests := []struct {
+		name     string
+		opts     []Option
+		n        int
+		expired  string
+		sinkErr  error
+		wantKeys int
+		wantErr  error
+	}{
+		{
+			name:     "success export the items in batches",
+			n:        2*exportBatch + 10,
+			wantKeys: 2*exportBatch + 10,
+		},
+		{
+			name:     "success export the shards of a sharded store",
+			opts:     []Option{WithStore(NewShardedStore(4, nil, nil))},
+			n:        100,
+			wantKeys: 100,
+		},
+		{
+			name:     "success skip expired items",
+			n:        10,
+			expired:  "expired",
+			wantKeys: 10,
+		},
+		{
+			name:     "failed stop at the first sink error",
+			n:        10,
+			sinkErr:  errSink,
+			wantKeys: 1,
+			wantErr:  errSink,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					return &Model{Name: id}, nil
+				},
+			}
+			fc := NewCache(mockedFetcher, tt.opts...)
+			for i := 0; i < tt.n; i++ {
+				fc.Fetch(context.Background(), fmt.Sprintf("id-%d", i))
+			}
+			if tt.expired != "" {
+				fc.FetchWithOptions(context.Background(), tt.expired, OverrideTTL(time.Nanosecond))
+				time.Sleep(time.Millisecond)
+			}
+
+			var keys []string
+			err := fc.ExportTo(context.Background(), func(id string, m *Model) error {
+				if m.Name != id {
+					t.Errorf("FetchCache.ExportTo() expect model of %s, have %s", id, m.Name)
+				}
+				keys = append(keys, id)
+				return tt.sinkErr
+			})
+			if err != tt.wantErr {
+				t.Fatalf("FetchCache.ExportTo() expect error = %v, have %v", tt.wantErr, err)
+			}
+			if len(keys) != tt.wantKeys {
+				t.Errorf("FetchCache.ExportTo() expect keys = %d, have %d", tt.wantKeys, len(keys))
+			}
+			sort.Strings(keys)
+			for i := 1; i < len(keys); i++ {
+				if keys[i] == keys[i-1] {
+					t.Errorf("FetchCache.ExportTo() expect unique keys, have %s twice", keys[i])
+				}
+			}
+		})
+	}
+}
+
+func TestFetchCache_ExportTo_LiveTraffic(t *testing.T) {
+	mockedFetcher := &FetcherMock{
+		FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+			return &Model{Name: id}, nil
+		},
+	}
+	fc := NewCache(mockedFetcher, WithMaxEntries(500))
+	for i := 0; i < 500; i++ {
+		fc.Fetch(context.Background(), fmt.Sprintf("id-%d", i))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 500; ctx.Err() == nil; i++ {
+			fc.Fetch(context.Background(), fmt.Sprintf("id-%d", i))
+			if i%100 == 0 {
+				fc.Flush()
+			}
+		}
+	}()
+	exported := 0
+	err := fc.ExportTo(context.Background(), func(id string, m *Model) error {
+		exported++
+		return nil
+	})
+	cancel()
+	wg.Wait()
+	if err != nil || exported > 500 {
+		t.Errorf("FetchCache.ExportTo() expect at most 500 items, have %d, %v", exported, err)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	if err := fc.ExportTo(ctx, func(id string, m *Model) error { return nil }); err != context.Canceled {
+		t.Errorf("FetchCache.ExportTo() expect error = %v, have %v", context.Canceled, err)
+	}
+}
@@ -0,0 +1,182 @@
+package resource
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// TenantFunc returns the tenant of the fetch of id, such as the first
+// segment of the path of a Namespace, see WithTenantFairness.
+type TenantFunc func(ctx context.Context, id string) string
+
+// WithTenantFairness shares the Fetcher call slots of WithMaxConcurrency
+// among the tenants tenant returns rather than in arrival order: the misses
+// waiting for a slot are queued per tenant, and a freed slot goes to the
+// oldest miss of the next tenant in a weighted round robin, where a tenant
+// is served up to its weight in weights in a row, 1 if missing. A miss
+// storm of one tenant then only delays its own misses. It has no effect
+// without WithMaxConcurrency.
+func WithTenantFairness(tenant TenantFunc, weights map[string]int) Option {
+	return func(o *options) {
+		o.tenant = tenant
+		o.tenantWeights = weights
+	}
+}
+
+// tenantOf returns the tenant of the fetch of id, empty without
+// WithTenantFairness
+func (fc *FetchCache) tenantOf(ctx context.Context, id string) string {
+	if fc.opts.tenant == nil {
+		return ""
+	}
+	return fc.opts.tenant(ctx, id)
+}
+
+// fairWaiter is an acquire call queued by a fairQueue, ready is closed once
+// it is granted a slot
+type fairWaiter struct {
+	ready   chan struct{}
+	granted bool
+}
+
+// fairQueue is the weighted round robin over the tenants of the waiters of
+// a limiter, guarded by the limiter mutex
+type fairQueue struct {
+	weights map[string]int
+	queues  map[string][]*fairWaiter
+	// order is the tenants with waiters, turn the one being served and
+	// served the waiters it was granted in a row
+	order  []string
+	turn   int
+	served int
+}
+
+func newFairQueue(weights map[string]int) *fairQueue {
+	return &fairQueue{weights: weights, queues: make(map[string][]*fairWaiter)}
+}
+
+// push queues w for tenant
+func (q *fairQueue) push(tenant string, w *fairWaiter) {
+	if len(q.queues[tenant]) == 0 {
+		q.order = append(q.order, tenant)
+	}
+	q.queues[tenant] = append(q.queues[tenant], w)
+}
+
+// remove drops w of tenant, which gave up waiting
+func (q *fairQueue) remove(tenant string, w *fairWaiter) {
+	waiters := q.queues[tenant]
+	for i, v := range waiters {
+		if v == w {
+			q.set(tenant, append(waiters[:i:i], waiters[i+1:]...))
+			return
+		}
+	}
+}
+
+// pop returns the next waiter to grant a slot, nil if none
+func (q *fairQueue) pop() *fairWaiter {
+	if len(q.order) == 0 {
+		return nil
+	}
+	if q.turn >= len(q.order) {
+		q.turn, q.served = 0, 0
+	}
+	tenant := q.order[q.turn]
+	waiters := q.queues[tenant]
+	w := waiters[0]
+	q.served++
+	if weight := q.weights[tenant]; q.served >= weight {
+		q.turn, q.served = q.turn+1, 0
+	}
+	q.set(tenant, waiters[1:])
+	return w
+}
+
+// set replaces the waiters of tenant, dropping it from the round robin once
+// it has none
+func (q *fairQueue) set(tenant string, waiters []*fairWaiter) {
+	if len(waiters) > 0 {
+		q.queues[tenant] = waiters
+		return
+	}
+	delete(q.queues, tenant)
+	for i, t := range q.order {
+		if t != tenant {
+			continue
+		}
+		q.order = append(q.order[:i], q.order[i+1:]...)
+		switch {
+		case i < q.turn:
+			q.turn--
+		case i == q.turn:
+			q.served = 0
+		}
+		return
+	}
+}
+
+// acquireFair is acquire with WithTenantFairness: the call waits in the
+// queue of tenant until dispatchLocked grants it a slot
+func (l *limiter) acquireFair(ctx context.Context, tenant string) error {
+	l.mu.Lock()
+	if l.limit <= 0 || l.active < l.limit && len(l.fair.order) == 0 {
+		l.active++
+		l.mu.Unlock()
+		return nil
+	}
+	if l.maxWaiting > 0 && l.waiting >= l.maxWaiting {
+		l.mu.Unlock()
+		atomic.AddUint64(&l.rejected, 1)
+		return ErrQueueFull
+	}
+	w := &fairWaiter{ready: make(chan struct{})}
+	l.fair.push(tenant, w)
+	l.waiting++
+	l.mu.Unlock()
+
+	var timeout <-chan time.Time
+	if l.waitTimeout > 0 {
+		timer := time.NewTimer(l.waitTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	var err error
+	select {
+	case <-w.ready:
+		return nil
+	case <-timeout:
+		atomic.AddUint64(&l.rejected, 1)
+		err = ErrQueueTimeout
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if w.granted {
+		// granted meanwhile, pass the slot on
+		l.active--
+		l.dispatchLocked()
+		return err
+	}
+	l.fair.remove(tenant, w)
+	l.waiting--
+	return err
+}
+
+// dispatchLocked grants the free slots to the queued waiters, l.mu must
+// be held
+func (l *limiter) dispatchLocked() {
+	for l.limit <= 0 || l.active < l.limit {
+		w := l.fair.pop()
+		if w == nil {
+			return
+		}
+		l.waiting--
+		l.active++
+		w.granted = true
+		close(w.ready)
+	}
+}
@@ -0,0 +1,115 @@
+package resource
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFetchCache_WithTenantFairness(t *testing.T) {
+	tenant := func(ctx context.Context, id string) string {
+		return strings.SplitN(id, "/", 2)[0]
+	}
+
+	tests := []struct {
+		name    string
+		weights map[string]int
+		queued  []string
+		want    string
+	}{
+		{
+			name:   "success alternate tenants",
+			queued: []string{"a/1", "a/2", "a/3", "a/4", "a/5", "b/1", "b/2"},
+			want:   "ababaaa",
+		},
+		{
+			name:    "success serve tenants by weight",
+			weights: map[string]int{"a": 2},
+			queued:  []string{"a/1", "a/2", "a/3", "a/4", "a/5", "b/1", "b/2"},
+			want:    "aabaaba",
+		},
+		{
+			name:   "success keep arrival order of a single tenant",
+			queued: []string{"b/1", "b/2", "b/3"},
+			want:   "bbb",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			started := make(chan string)
+			release := make(chan struct{})
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					started <- id
+					<-release
+					return &Model{Name: id}, nil
+				},
+			}
+			fc := NewCache(mockedFetcher, WithMaxConcurrency(1), WithTenantFairness(tenant, tt.weights))
+			go fc.Fetch(context.Background(), "a/0")
+			<-started
+			for i, id := range tt.queued {
+				go fc.Fetch(context.Background(), id)
+				for fc.Stats().QueueDepth != i+1 {
+					time.Sleep(time.Millisecond)
+				}
+			}
+
+			var have []byte
+			for range tt.queued {
+				release <- struct{}{}
+				have = append(have, (<-started)[0])
+			}
+			release <- struct{}{}
+			if string(have) != tt.want {
+				t.Errorf("WithTenantFairness() expect order = %s, have %s", tt.want, have)
+			}
+		})
+	}
+}
+
+func TestFetchCache_WithTenantFairness_Canceled(t *testing.T) {
+	tenant := func(ctx context.Context, id string) string {
+		return strings.SplitN(id, "/", 2)[0]
+	}
+	started := make(chan string)
+	release := make(chan struct{})
+	mockedFetcher := &FetcherMock{
+		FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+			started <- id
+			<-release
+			return &Model{Name: id}, nil
+		},
+	}
+	fc := NewCache(mockedFetcher, WithMaxConcurrency(1), WithTenantFairness(tenant, nil))
+	go fc.Fetch(context.Background(), "a/0")
+	<-started
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error)
+	go func() {
+		_, err := fc.Fetch(ctx, "b/1")
+		errs <- err
+	}()
+	for fc.Stats().QueueDepth != 1 {
+		time.Sleep(time.Millisecond)
+	}
+	go fc.Fetch(context.Background(), "a/1")
+	for fc.Stats().QueueDepth != 2 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	if err := <-errs; err != context.Canceled {
+		t.Fatalf("FetchCache.Fetch() expect error = %v, have %v", context.Canceled, err)
+	}
+	if depth := fc.Stats().QueueDepth; depth != 1 {
+		t.Errorf("FetchCache.Stats() expect QueueDepth = 1, have %d", depth)
+	}
+
+	release <- struct{}{}
+	if id := <-started; id != "a/1" {
+		t.Errorf("WithTenantFairness() expect next = a/1, have %s", id)
+	}
+	release <- struct{}{}
+}
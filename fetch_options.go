@@ -0,0 +1,114 @@
+package resource
+
+import (
+	"sync"
+	"time"
+)
+
+// FetchOption configures a single FetchWithOptions call.
+type FetchOption func(*fetchOptions)
+
+type fetchOptions struct {
+	bypass       bool
+	noStore      bool
+	minFreshness time.Duration
+	ttl          time.Duration
+	hasTTL       bool
+	idle         time.Duration
+	maxLifetime  time.Duration
+	allOrNothing bool
+	// trace is the trace id of the request, see WithTraceID
+	trace string
+	// class counts the fetch, see WithKeyClassifier
+	class *classCounters
+}
+
+// fetchOptionsPool holds the fetchOptions the options are applied to, which
+// escape to the heap through the FetchOption calls
+var fetchOptionsPool = sync.Pool{
+	New: func() interface{} { return new(fetchOptions) },
+}
+
+// newFetchOptions applies every list of opts in order
+func newFetchOptions(opts ...[]FetchOption) fetchOptions {
+	n := 0
+	for _, list := range opts {
+		n += len(list)
+	}
+	if n == 0 {
+		return fetchOptions{}
+	}
+	o := fetchOptionsPool.Get().(*fetchOptions)
+	*o = fetchOptions{}
+	for _, list := range opts {
+		for _, opt := range list {
+			opt(o)
+		}
+	}
+	v := *o
+	fetchOptionsPool.Put(o)
+	return v
+}
+
+// fresh returns true if i satisfies the freshness required by the call.
+func (o fetchOptions) fresh(i item) bool {
+	if o.minFreshness <= 0 {
+		return true
+	}
+	return i.age() <= o.minFreshness
+}
+
+// ttlOr returns the overridden TTL or d if there is no override.
+func (o fetchOptions) ttlOr(d time.Duration) time.Duration {
+	if o.hasTTL {
+		return o.ttl
+	}
+	return d
+}
+
+// expiration returns the Expiration of the items stored by the call for
+// the TTL ttl
+func (o fetchOptions) expiration(ttl time.Duration) Expiration {
+	return Expiration{AfterWrite: ttl, AfterAccess: o.idle, MaxLifetime: o.maxLifetime}
+}
+
+// BypassCache always fetches from the Fetcher, ignoring any cached item.
+// The result is still cached unless NoStore is also given.
+func BypassCache() FetchOption {
+	return func(o *fetchOptions) {
+		o.bypass = true
+	}
+}
+
+// NoStore does not cache the result fetched from the Fetcher.
+func NoStore() FetchOption {
+	return func(o *fetchOptions) {
+		o.noStore = true
+	}
+}
+
+// MinFreshness treats cached items older than d as misses, whatever their
+// TTL. Expired items older than d aren't served stale either, see
+// WithStaleWhileRevalidate and RateLimitStale.
+func MinFreshness(d time.Duration) FetchOption {
+	return func(o *fetchOptions) {
+		o.minFreshness = d
+	}
+}
+
+// OverrideTTL caches the fetched result for d instead of the default
+// expiration. A d of 0 means the item never expires.
+func OverrideTTL(d time.Duration) FetchOption {
+	return func(o *fetchOptions) {
+		o.ttl = d
+		o.hasTTL = true
+	}
+}
+
+// AllOrNothing makes FetchMany fail as a whole when any id fails.
+// It has no effect on single id fetches.
+func AllOrNothing() FetchOption {
+	return func(o *fetchOptions) {
+		o.allOrNothing = true
+	}
+}
@@ -0,0 +1,148 @@
+package resource
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestFetchCache_FetchWithOptions(t *testing.T) {
+	var (
+		fakeFetchID = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	)
+
+	tests := []struct {
+		name             string
+		prefetch         bool
+		wait             time.Duration
+		opts             []FetchOption
+		serviceCallCount int
+		wantCached       bool
+		wantExpiration   bool
+	}{
+		{
+			name:             "success get from cache without options",
+			prefetch:         true,
+			serviceCallCount: 1,
+			wantCached:       true,
+		},
+		{
+			name:             "success bypass cache",
+			prefetch:         true,
+			opts:             []FetchOption{BypassCache()},
+			serviceCallCount: 2,
+			wantCached:       true,
+		},
+		{
+			name:             "success not store result",
+			opts:             []FetchOption{NoStore()},
+			serviceCallCount: 1,
+		},
+		{
+			name:             "success treat old item as miss",
+			prefetch:         true,
+			wait:             5 * time.Millisecond,
+			opts:             []FetchOption{MinFreshness(time.Millisecond)},
+			serviceCallCount: 2,
+			wantCached:       true,
+		},
+		{
+			name:             "success keep fresh enough item",
+			prefetch:         true,
+			opts:             []FetchOption{MinFreshness(time.Minute)},
+			serviceCallCount: 1,
+			wantCached:       true,
+		},
+		{
+			name:             "success override ttl",
+			opts:             []FetchOption{OverrideTTL(time.Minute)},
+			serviceCallCount: 1,
+			wantCached:       true,
+			wantExpiration:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serviceCallCount := 0
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					serviceCallCount++
+					return &Model{Name: "lorem"}, nil
+				},
+			}
+			fc := NewCache(mockedFetcher)
+			if tt.prefetch {
+				_, _ = fc.Fetch(context.Background(), fakeFetchID)
+			}
+			time.Sleep(tt.wait)
+
+			if _, err := fc.FetchWithOptions(context.Background(), fakeFetchID, tt.opts...); err != nil {
+				t.Errorf("FetchCache.FetchWithOptions() error = %v", err)
+				return
+			}
+			if tt.serviceCallCount != serviceCallCount {
+				t.Errorf("FetchCache.FetchWithOptions() expect service call count = %v, have %v", tt.serviceCallCount, serviceCallCount)
+			}
+			i, found := fc.items.Get(fakeFetchID)
+			if found != tt.wantCached {
+				t.Errorf("FetchCache.FetchWithOptions() expect cached = %v, have %v", tt.wantCached, found)
+			}
+			if (i.Expiration != 0) != tt.wantExpiration {
+				t.Errorf("FetchCache.FetchWithOptions() expect expiration = %v, have %v", tt.wantExpiration, i.Expiration)
+			}
+		})
+	}
+}
+
+func TestFetchCache_FetchWithOptions_MinFreshnessStale(t *testing.T) {
+	var (
+		fakeFetchID = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	)
+
+	tests := []struct {
+		name      string
+		opts      []FetchOption
+		wantErr   error
+		wantStale bool
+	}{
+		{
+			name:      "success serve stale",
+			wantStale: true,
+		},
+		{
+			name:    "failed stale older than min freshness",
+			opts:    []FetchOption{MinFreshness(time.Millisecond)},
+			wantErr: context.DeadlineExceeded,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					return &Model{Name: "lorem"}, nil
+				},
+			}
+			fc := NewCache(mockedFetcher, WithTTL(time.Millisecond), WithStaleWhileRevalidate(time.Minute))
+			defer fc.Close(context.Background())
+			if _, err := fc.Fetch(context.Background(), fakeFetchID); err != nil {
+				t.Fatalf("FetchCache.Fetch() error = %v", err)
+			}
+			time.Sleep(5 * time.Millisecond)
+			// a refresh in progress holds the key
+			if err := fc.LockContext(context.Background(), fakeFetchID); err != nil {
+				t.Fatalf("FetchCache.LockContext() error = %v", err)
+			}
+			defer fc.Unlock(fakeFetchID)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+			model, err := fc.FetchWithOptions(ctx, fakeFetchID, tt.opts...)
+			if err != tt.wantErr {
+				t.Fatalf("FetchCache.FetchWithOptions() expect error = %v, have %v", tt.wantErr, err)
+			}
+			if (model != nil) != tt.wantStale {
+				t.Errorf("FetchCache.FetchWithOptions() expect stale = %v, have %v", tt.wantStale, model)
+			}
+		})
+	}
+}
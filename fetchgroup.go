@@ -0,0 +1,52 @@
+package resource
+
+import "context"
+
+// FetchGroup is a Fetcher shared by several caches over the same origin,
+// such as a strict freshness cache and a latency optimized one with
+// different TTLs and policies: the concurrent fetches of an id by any of
+// them share a single call of the Fetcher, whose result, directives
+// included, every cache then caches as it is configured to. Give the
+// group to NewCache in place of the Fetcher.
+//
+// The call is made with the values of the context of the first fetch and
+// is canceled once every fetch waiting for it gave up, see KeyedGroup.
+type FetchGroup struct {
+	group *KeyedGroup
+	fetch func(ctx context.Context, id string) (Result, error)
+}
+
+// NewFetchGroup creates a FetchGroup over f, which may be a ResultFetcher.
+func NewFetchGroup(f Fetcher) *FetchGroup {
+	g := &FetchGroup{group: NewKeyedGroup(0, 0)}
+	if rf, ok := f.(ResultFetcher); ok {
+		g.fetch = rf.FetchResult
+	} else {
+		g.fetch = func(ctx context.Context, id string) (Result, error) {
+			model, err := f.Fetch(ctx, id)
+			return Result{Model: model}, err
+		}
+	}
+	return g
+}
+
+// Fetch implements Fetcher.
+func (g *FetchGroup) Fetch(ctx context.Context, id string) (*Model, error) {
+	r, err := g.FetchResult(ctx, id)
+	return r.Model, err
+}
+
+// FetchResult implements ResultFetcher.
+func (g *FetchGroup) FetchResult(ctx context.Context, id string) (Result, error) {
+	v, _, err := g.group.Do(ctx, id, func(ctx context.Context) (interface{}, error) {
+		return g.fetch(ctx, id)
+	})
+	r, _ := v.(Result)
+	return r, err
+}
+
+// Shared returns the number of fetches served by the call of another
+// fetch.
+func (g *FetchGroup) Shared() uint64 {
+	return g.group.Stats().Shared
+}
@@ -0,0 +1,96 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFetchGroup(t *testing.T) {
+	var fakeFetchID = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	var calls int64
+	release := make(chan struct{})
+	mockedFetcher := &FetcherMock{
+		FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+			atomic.AddInt64(&calls, 1)
+			select {
+			case <-release:
+				return &Model{Name: id}, nil
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		},
+	}
+	g := NewFetchGroup(mockedFetcher)
+	strict := NewCache(g, WithTTL(time.Second))
+	fast := NewCache(g, WithTTL(time.Hour))
+
+	// a fetch giving up doesn't cancel the call of the others
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	errs := make([]error, 3)
+	for n, fc := range []*FetchCache{strict, fast} {
+		wg.Add(1)
+		go func(n int, fc *FetchCache) {
+			defer wg.Done()
+			_, errs[n] = fc.Fetch(context.Background(), fakeFetchID)
+		}(n, fc)
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_, errs[2] = g.Fetch(ctx, fakeFetchID)
+	}()
+	for g.Shared() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if errs[0] != nil || errs[1] != nil {
+		t.Errorf("FetchGroup.Fetch() expect no error, have %v", errs[:2])
+	}
+	if !errors.Is(errs[2], context.Canceled) {
+		t.Errorf("FetchGroup.Fetch() expect error = %v, have %v", context.Canceled, errs[2])
+	}
+	if calls != 1 {
+		t.Errorf("FetchGroup.Fetch() expect a single Fetcher call, have %v", calls)
+	}
+	for _, tt := range []struct {
+		fc      *FetchCache
+		wantTTL time.Duration
+	}{{strict, time.Second}, {fast, time.Hour}} {
+		info, ok := tt.fc.Entry(fakeFetchID)
+		if !ok || info.Expires.Sub(info.Created) != tt.wantTTL {
+			t.Errorf("FetchCache.Entry() expect cached for %v, have %v %v", tt.wantTTL, ok, info.Expires.Sub(info.Created))
+		}
+	}
+}
+
+func TestFetchGroup_cancel(t *testing.T) {
+	var fakeFetchID = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	canceled := make(chan error, 1)
+	mockedFetcher := &FetcherMock{
+		FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+			<-ctx.Done()
+			canceled <- ctx.Err()
+			return nil, ctx.Err()
+		},
+	}
+	g := NewFetchGroup(mockedFetcher)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := g.Fetch(ctx, fakeFetchID); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("FetchGroup.Fetch() expect error = %v, have %v", context.DeadlineExceeded, err)
+	}
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Errorf("FetchGroup.Fetch() expect the call canceled once every fetch gave up")
+	}
+}
@@ -0,0 +1,115 @@
+package resource
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// handoverRequest is the line a receiver opens a handover with
+const handoverRequest = "HANDOVER 1\n"
+
+// Error list
+var (
+	ErrHandover = errors.New("invalid handover")
+)
+
+// ServeHandover hands the cached items over to the replacement of the
+// process during a deploy, so it starts warm instead of sending all its
+// misses to the origin: the replacement connects to l, e.g. a unix socket
+// at a path both processes know, with ReceiveHandover and is sent a Dump
+// with the values. ServeHandover returns nil once a replacement
+// acknowledged having cached the items, or ctx.Err() when ctx is done
+// first, and closes l. The cache keeps serving meanwhile.
+func (fc *FetchCache) ServeHandover(ctx context.Context, l net.Listener) error {
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+		}
+		_ = l.Close()
+	}()
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+		if fc.handOver(ctx, conn) == nil {
+			return nil
+		}
+	}
+}
+
+// handOver sends the items to the receiver connected by conn and waits for
+// its acknowledgement
+func (fc *FetchCache) handOver(ctx context.Context, conn net.Conn) error {
+	defer conn.Close()
+	defer interruptOnDone(ctx, conn)()
+	r := bufio.NewReader(conn)
+	if line, err := r.ReadString('\n'); err != nil || line != handoverRequest {
+		return ErrHandover
+	}
+	w := bufio.NewWriter(conn)
+	if err := fc.Dump(w, true); err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	ack, err := r.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(ack, "OK ") {
+		return fmt.Errorf("%w: %s", ErrHandover, strings.TrimSpace(ack))
+	}
+	return nil
+}
+
+// ReceiveHandover connects to the ServeHandover of the process being
+// replaced at address on network, e.g. "unix" and a socket path, and
+// caches the items it hands over, see Hydrate. It returns the number of
+// items cached, and the error of the connection when there is no process
+// to take over from, in which case the cache just starts cold.
+func (fc *FetchCache) ReceiveHandover(ctx context.Context, network, address string) (int, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, network, address)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+	defer interruptOnDone(ctx, conn)()
+	if _, err := conn.Write([]byte(handoverRequest)); err != nil {
+		return 0, err
+	}
+	n, err := fc.Hydrate(bufio.NewReader(conn))
+	if err != nil {
+		_, _ = fmt.Fprintf(conn, "ERR %v\n", err)
+		return n, err
+	}
+	_, err = fmt.Fprintf(conn, "OK %d\n", n)
+	return n, err
+}
+
+// interruptOnDone interrupts the reads and writes of conn once ctx is done,
+// until the returned func is called
+func interruptOnDone(ctx context.Context, conn net.Conn) func() {
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = conn.SetDeadline(time.Now())
+		case <-done:
+		}
+	}()
+	return func() { close(done) }
+}
@@ -0,0 +1,77 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFetchCache_ServeHandover(t *testing.T) {
+	var (
+		fakeFetchID     = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+		notExistModelID = "5634aeed-2106-43de-ab7d-c0ad4b1e195e"
+	)
+	mockedFetcher := &FetcherMock{
+		FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+			return &Model{Name: id, Data: []byte("lorem")}, nil
+		},
+	}
+	old := NewCache(mockedFetcher, WithTTL(time.Hour))
+	for _, id := range []string{fakeFetchID, notExistModelID} {
+		_, _ = old.Fetch(context.Background(), id)
+	}
+	path := filepath.Join(t.TempDir(), "handover.sock")
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("net.Listen() expect no error, have %v", err)
+	}
+	served := make(chan error, 1)
+	go func() { served <- old.ServeHandover(context.Background(), l) }()
+
+	// a connection which isn't a handover is dropped
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		t.Fatalf("net.Dial() expect no error, have %v", err)
+	}
+	_, _ = conn.Write([]byte("GET / HTTP/1.1\n"))
+	_ = conn.Close()
+
+	replacement := NewCache(mockedFetcher, WithTTL(time.Hour))
+	n, err := replacement.ReceiveHandover(context.Background(), "unix", path)
+	if err != nil || n != 2 {
+		t.Errorf("FetchCache.ReceiveHandover() expect 2 items, have %v, %v", n, err)
+	}
+	select {
+	case err := <-served:
+		if err != nil {
+			t.Errorf("FetchCache.ServeHandover() expect no error, have %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("FetchCache.ServeHandover() expect to return once acknowledged")
+	}
+	model, err := replacement.Fetch(context.Background(), fakeFetchID)
+	if err != nil || string(model.Data) != "lorem" || len(mockedFetcher.FetchCalls()) != 2 {
+		t.Errorf("FetchCache.Fetch() expect the item handed over, have %v, %v with %v calls", model, err, len(mockedFetcher.FetchCalls()))
+	}
+
+	// nothing listens anymore, the replacement starts cold
+	if _, err := replacement.ReceiveHandover(context.Background(), "unix", path); err == nil {
+		t.Errorf("FetchCache.ReceiveHandover() expect an error without a process to take over from")
+	}
+}
+
+func TestFetchCache_ServeHandover_canceled(t *testing.T) {
+	fc := NewCache(&FetcherMock{})
+	l, err := net.Listen("unix", filepath.Join(t.TempDir(), "handover.sock"))
+	if err != nil {
+		t.Fatalf("net.Listen() expect no error, have %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := fc.ServeHandover(ctx, l); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("FetchCache.ServeHandover() expect error = %v, have %v", context.DeadlineExceeded, err)
+	}
+}
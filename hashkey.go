@@ -0,0 +1,77 @@
+package resource
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"hash/crc32"
+	"hash/fnv"
+)
+
+// KeyHash is the hash function of WithHashedKeys.
+type KeyHash int
+
+// Key hash list
+const (
+	// HashFNV64 stores 11 bytes per key, collisions become likely past
+	// hundreds of millions of keys.
+	HashFNV64 KeyHash = iota + 1
+	// HashSHA256 stores 43 bytes per key, collisions are not a concern.
+	HashSHA256
+)
+
+// CollisionPolicy is how WithHashedKeys handles two ids with the same hash.
+type CollisionPolicy int
+
+// Collision policy list
+const (
+	// CollisionIgnore trusts the hash, an id colliding with another is
+	// served the model of the other.
+	CollisionIgnore CollisionPolicy = iota
+	// CollisionVerify keeps a 4 byte checksum of the id with each item, a
+	// hit whose checksum doesn't match is handled as a miss and the
+	// colliding item is replaced.
+	CollisionVerify
+)
+
+// WithHashedKeys stores the items under a hash of their id instead of the
+// id, bounding the memory of very long ids. The Fetcher, Clear, KeyStats and
+// invalidations still use the original ids, but events, Dump, the admin
+// keys and evictions name the items by their hash, base64 encoded so it
+// survives JSON, and WithScheduledRefresh, which has no id to
+// fetch cached items with, refreshes only the keys of WithLoadPolicy.
+func WithHashedKeys(h KeyHash, p CollisionPolicy) Option {
+	return func(o *options) {
+		o.keyHash = h
+		o.collisionPolicy = p
+	}
+}
+
+// key returns the internal key of id
+func (fc *FetchCache) key(id string) string {
+	switch fc.opts.keyHash {
+	case HashFNV64:
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(id))
+		var b [8]byte
+		binary.BigEndian.PutUint64(b[:], h.Sum64())
+		return base64.RawURLEncoding.EncodeToString(b[:])
+	case HashSHA256:
+		sum := sha256.Sum256([]byte(id))
+		return base64.RawURLEncoding.EncodeToString(sum[:])
+	}
+	return id
+}
+
+// idSum returns the checksum of id kept with its item, 0 when not verified
+func (fc *FetchCache) idSum(id string) uint32 {
+	if fc.opts.keyHash == 0 || fc.opts.collisionPolicy != CollisionVerify {
+		return 0
+	}
+	return crc32.ChecksumIEEE([]byte(id)) | 1
+}
+
+// owns returns false if i was cached for another id than id
+func (fc *FetchCache) owns(i item, id string) bool {
+	return i.IDSum == 0 || i.IDSum == fc.idSum(id)
+}
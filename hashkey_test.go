@@ -0,0 +1,133 @@
+package resource
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFetchCache_WithHashedKeys(t *testing.T) {
+	var (
+		longID  = "https://example.com/" + strings.Repeat("a", 500)
+		otherID = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	)
+
+	tests := []struct {
+		name      string
+		hash      KeyHash
+		policy    CollisionPolicy
+		collision bool
+		wantModel string
+		wantKey   int
+	}{
+		{
+			name:      "success fnv64",
+			hash:      HashFNV64,
+			wantModel: longID,
+			wantKey:   11,
+		},
+		{
+			name:      "success sha256",
+			hash:      HashSHA256,
+			wantModel: longID,
+			wantKey:   43,
+		},
+		{
+			name:      "success collision ignored",
+			hash:      HashFNV64,
+			collision: true,
+			wantModel: otherID,
+			wantKey:   11,
+		},
+		{
+			name:      "success collision verified",
+			hash:      HashFNV64,
+			policy:    CollisionVerify,
+			collision: true,
+			wantModel: longID,
+			wantKey:   11,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ids []string
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					ids = append(ids, id)
+					return &Model{Name: id}, nil
+				},
+			}
+			fc := NewCache(mockedFetcher, WithHashedKeys(tt.hash, tt.policy))
+			if tt.collision {
+				// otherID was cached under the hash of longID
+				fc.cacheitem(fc.key(longID), otherID, &Model{Name: otherID}, SourceSet, time.Minute, 0)
+			}
+
+			for i := 0; i < 2; i++ {
+				model, err := fc.Fetch(context.Background(), longID)
+				if err != nil {
+					t.Fatalf("FetchCache.Fetch() error = %v", err)
+				}
+				if model.Name != tt.wantModel {
+					t.Errorf("FetchCache.Fetch() expect model = %.40v, have %.40v", tt.wantModel, model.Name)
+				}
+			}
+			fc.items.Range(func(id string, _ Item) bool {
+				if len(id) != tt.wantKey {
+					t.Errorf("FetchCache.Fetch() expect stored key of %v bytes, have %v", tt.wantKey, len(id))
+				}
+				return true
+			})
+			if _, ok := fc.KeyStats(longID); !ok {
+				t.Errorf("FetchCache.KeyStats() expect stats by original id")
+			}
+
+			fc.Clear(longID)
+			if _, err := fc.Fetch(context.Background(), longID); err != nil {
+				t.Fatalf("FetchCache.Fetch() error = %v", err)
+			}
+			for _, id := range ids {
+				if id != longID {
+					t.Errorf("FetchCache.Fetch() expect fetcher called with original id, have %.40v", id)
+				}
+			}
+		})
+	}
+}
+
+func TestFetchCache_WithHashedKeys_LockAndDump(t *testing.T) {
+	longID := "https://example.com/" + strings.Repeat("a", 500)
+	mockedFetcher := &FetcherMock{
+		FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+			return &Model{Name: id}, nil
+		},
+	}
+	fc := NewCache(mockedFetcher, WithHashedKeys(HashSHA256, CollisionIgnore))
+	defer fc.Close(context.Background())
+
+	fc.Lock(longID)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	if _, err := fc.Fetch(ctx, longID); err != context.DeadlineExceeded {
+		t.Errorf("FetchCache.Fetch() expect error = %v while locked, have %v", context.DeadlineExceeded, err)
+	}
+	cancel()
+	fc.Unlock(longID)
+	if _, err := fc.Fetch(context.Background(), longID); err != nil {
+		t.Fatalf("FetchCache.Fetch() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := fc.Dump(&buf, true); err != nil {
+		t.Fatalf("FetchCache.Dump() error = %v", err)
+	}
+	restored := NewCache(mockedFetcher, WithHashedKeys(HashSHA256, CollisionIgnore))
+	defer restored.Close(context.Background())
+	if n, err := restored.Hydrate(&buf); err != nil || n != 1 {
+		t.Fatalf("FetchCache.Hydrate() = %v, %v", n, err)
+	}
+	if _, found := restored.Entry(longID); !found {
+		t.Errorf("FetchCache.Hydrate() expect the hashed entry reachable by id")
+	}
+}
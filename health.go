@@ -0,0 +1,69 @@
+package resource
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// HealthChecker is an interface that defines the Check method.
+type HealthChecker interface {
+	// Check returns an error when the backend of the Fetcher is unhealthy.
+	Check(ctx context.Context) error
+}
+
+// WithHealthChecker checks h every interval, and while the backend is
+// unhealthy degrades the cache to shed load from it: expired items are
+// served for up to staleWindow past their expiration while refreshed, see
+// WithStaleWhileRevalidate, and the eager, scheduled and drift check
+// refreshes are suppressed. Normal behavior resumes once a check succeeds.
+func WithHealthChecker(h HealthChecker, interval, staleWindow time.Duration) Option {
+	return func(o *options) {
+		o.health = h
+		o.healthInterval = interval
+		o.degradedStaleWindow = staleWindow
+	}
+}
+
+// Degraded reports whether the last health check failed, see
+// WithHealthChecker, the breaker of WithErrorPolicy is open or the cache is
+// in incident mode, see SetIncidentMode.
+func (fc *FetchCache) Degraded() bool {
+	now := time.Now()
+	return atomic.LoadInt32(&fc.degraded) == 1 || fc.breakerOpen(now) || now.UnixNano() < atomic.LoadInt64(&fc.incidentUntil)
+}
+
+// checkHealth runs the health check and updates the degraded state
+func (fc *FetchCache) checkHealth(ctx context.Context) {
+	var degraded int32
+	if err := fc.opts.health.Check(ctx); err != nil {
+		degraded = 1
+	}
+	atomic.StoreInt32(&fc.degraded, degraded)
+}
+
+// runHealthCheck checks the health every interval until stop
+func (fc *FetchCache) runHealthCheck(stop <-chan struct{}) {
+	ctx, cancel := fc.life.context()
+	defer cancel()
+
+	ticker := time.NewTicker(fc.opts.healthInterval)
+	defer ticker.Stop()
+	for {
+		fc.checkHealth(ctx)
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// staleWindow returns how long past their expiration items are served
+// while refreshed
+func (fc *FetchCache) staleWindow() time.Duration {
+	if fc.Degraded() && fc.opts.degradedStaleWindow > fc.opts.staleWindow {
+		return fc.opts.degradedStaleWindow
+	}
+	return fc.opts.staleWindow
+}
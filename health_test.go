@@ -0,0 +1,79 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// healthCheckerFunc adapts a func to HealthChecker
+type healthCheckerFunc func(ctx context.Context) error
+
+func (f healthCheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+func TestFetchCache_WithHealthChecker(t *testing.T) {
+	var (
+		fakeFetchID = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	)
+
+	tests := []struct {
+		name         string
+		healthErr    error
+		wantDegraded bool
+		wantStale    bool
+		wantCalls    int32
+	}{
+		{
+			name:      "success healthy",
+			wantCalls: 2,
+		},
+		{
+			name:         "success degraded",
+			healthErr:    errors.New("backend down"),
+			wantDegraded: true,
+			wantStale:    true,
+			wantCalls:    1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var callCount int32
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					atomic.AddInt32(&callCount, 1)
+					return &Model{Name: "lorem"}, nil
+				},
+			}
+			health := healthCheckerFunc(func(ctx context.Context) error { return tt.healthErr })
+			fc := NewCache(mockedFetcher,
+				WithSynchronous(),
+				WithTTL(time.Millisecond),
+				WithLoadPolicy(func(id string) LoadMode { return Eager }, time.Millisecond),
+				WithHealthChecker(health, time.Millisecond, time.Minute),
+			)
+			if _, err := fc.Fetch(context.Background(), fakeFetchID); err != nil {
+				t.Fatalf("FetchCache.Fetch() error = %v", err)
+			}
+
+			time.Sleep(5 * time.Millisecond)
+			fc.Tick(context.Background())
+			if fc.Degraded() != tt.wantDegraded {
+				t.Errorf("FetchCache.Degraded() expect %v, have %v", tt.wantDegraded, fc.Degraded())
+			}
+			if callCount != tt.wantCalls {
+				t.Errorf("FetchCache.Tick() expect %v fetcher calls, have %v", tt.wantCalls, callCount)
+			}
+			if !tt.wantDegraded {
+				// the refresh made the item fresh again, let it expire
+				time.Sleep(5 * time.Millisecond)
+			}
+			if _, found := fc.staleItem(fc.key(fakeFetchID)); found != tt.wantStale {
+				t.Errorf("FetchCache.staleItem() expect stale item = %v, have %v", tt.wantStale, found)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package resource
+
+import "time"
+
+// hitRatioSlots is the number of samples of the counters a hit ratio
+// window slides by
+const hitRatioSlots = 10
+
+// HitRatioAlert reports a hit ratio below the threshold of
+// WithHitRatioWatch, over the Hits and Misses of the last Window.
+type HitRatioAlert struct {
+	Ratio  float64
+	Hits   uint64
+	Misses uint64
+	Window time.Duration
+}
+
+// WithHitRatioWatch computes the hit ratio over a window sliding by a tenth
+// of window and calls fn when it drops below threshold, once until it
+// recovers. Windows with less than minFetches fetches are ignored. A
+// sudden drop tells a cache busting bug, such as an explosion of the key
+// cardinality.
+func WithHitRatioWatch(threshold float64, window time.Duration, minFetches uint64, fn func(HitRatioAlert)) Option {
+	return func(o *options) {
+		o.hitRatioThreshold = threshold
+		o.hitRatioWindow = window
+		o.hitRatioMin = minFetches
+		o.hitRatioAlert = fn
+	}
+}
+
+// hitRatioWatch holds the samples of the counters over a window
+type hitRatioWatch struct {
+	threshold float64
+	min       uint64
+	window    time.Duration
+	samples   [hitRatioSlots + 1][2]uint64
+	n         int
+	alerting  bool
+}
+
+// observe records the current counters and returns the alert to fire, if
+// any
+func (w *hitRatioWatch) observe(hits, misses uint64) (HitRatioAlert, bool) {
+	w.samples[w.n%len(w.samples)] = [2]uint64{hits, misses}
+	w.n++
+	oldest := w.samples[0]
+	if w.n > len(w.samples) {
+		oldest = w.samples[w.n%len(w.samples)]
+	}
+	a := HitRatioAlert{Hits: hits - oldest[0], Misses: misses - oldest[1], Window: w.window}
+	total := a.Hits + a.Misses
+	if total < w.min || total == 0 {
+		return a, false
+	}
+	a.Ratio = float64(a.Hits) / float64(total)
+	if a.Ratio >= w.threshold {
+		w.alerting = false
+		return a, false
+	}
+	if w.alerting {
+		return a, false
+	}
+	w.alerting = true
+	return a, true
+}
+
+// runHitRatioWatch samples the counters every tenth of the window until
+// stop
+func (fc *FetchCache) runHitRatioWatch(stop <-chan struct{}) {
+	w := &hitRatioWatch{threshold: fc.opts.hitRatioThreshold, min: fc.opts.hitRatioMin, window: fc.opts.hitRatioWindow}
+	w.observe(0, 0)
+	ticker := time.NewTicker(fc.opts.hitRatioWindow / hitRatioSlots)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+		c := fc.metrics.counters()
+		if a, ok := w.observe(c.Hits, c.Misses); ok {
+			fc.opts.hitRatioAlert(a)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package resource
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func Test_hitRatioWatch_observe(t *testing.T) {
+	type sample struct {
+		hits, misses uint64
+		wantAlert    bool
+	}
+	tests := []struct {
+		name    string
+		samples []sample
+	}{
+		{
+			name: "success no alert above threshold",
+			samples: []sample{
+				{hits: 90, misses: 10},
+				{hits: 180, misses: 20},
+			},
+		},
+		{
+			name: "success alert once until recovered",
+			samples: []sample{
+				{hits: 10, misses: 90, wantAlert: true},
+				{hits: 20, misses: 180},
+				{hits: 2000, misses: 180},
+				{hits: 2000, misses: 5000, wantAlert: true},
+			},
+		},
+		{
+			name: "success too few fetches",
+			samples: []sample{
+				{hits: 1, misses: 5},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &hitRatioWatch{threshold: 0.5, min: 10, window: time.Second}
+			w.observe(0, 0)
+			for i, s := range tt.samples {
+				a, ok := w.observe(s.hits, s.misses)
+				if ok != s.wantAlert {
+					t.Errorf("hitRatioWatch.observe() expect alert %v at %v, have %v %+v", s.wantAlert, i, ok, a)
+				}
+			}
+		})
+	}
+}
+
+func TestWithHitRatioWatch(t *testing.T) {
+	mockedFetcher := &FetcherMock{
+		FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+			return &Model{Name: id}, nil
+		},
+	}
+	alerts := make(chan HitRatioAlert, 1)
+	fc := NewCache(mockedFetcher, WithHitRatioWatch(0.5, 100*time.Millisecond, 10, func(a HitRatioAlert) {
+		select {
+		case alerts <- a:
+		default:
+		}
+	}))
+	defer fc.Close(context.Background())
+
+	for i := 0; i < 20; i++ {
+		_, _ = fc.Fetch(context.Background(), strconv.Itoa(i))
+	}
+	select {
+	case a := <-alerts:
+		if a.Ratio != 0 || a.Misses != 20 {
+			t.Errorf("WithHitRatioWatch() expect a ratio of 0 over 20 misses, have %+v", a)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("WithHitRatioWatch() expect an alert")
+	}
+}
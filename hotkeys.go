@@ -0,0 +1,168 @@
+package resource
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// HotKeyStore is an interface that persists the list of the hottest ids
+// across restarts, see WithHotKeys.
+type HotKeyStore interface {
+	// Load returns the saved ids, none if nothing was saved yet.
+	Load(ctx context.Context) ([]string, error)
+	// Save replaces the saved ids.
+	Save(ctx context.Context, ids []string) error
+}
+
+// WithHotKeys saves the n most hit ids to store every interval and when
+// the cache is closed. When the cache is created the saved ids are fetched
+// in the background, at most concurrency at a time, so a restarted cache
+// is warm again without persisting any value which could be stale. It has
+// no effect with WithHashedKeys as the ids aren't known.
+func WithHotKeys(store HotKeyStore, n int, interval time.Duration, concurrency int) Option {
+	return func(o *options) {
+		if concurrency < 1 {
+			concurrency = 1
+		}
+		o.hotKeys = store
+		o.hotKeysN = n
+		o.hotKeysInterval = interval
+		o.hotKeysConcurrency = concurrency
+	}
+}
+
+// hotKeys returns the n ids with the most hits, most hit first
+func (fc *FetchCache) hotKeys(n int) []string {
+	type keyHits struct {
+		id   string
+		hits uint64
+	}
+	var all []keyHits
+	fc.stats.m.Range(func(k, v interface{}) bool {
+		if hits := atomic.LoadUint64(&v.(*keyStats).hits); hits > 0 {
+			all = append(all, keyHits{k.(string), hits})
+		}
+		return true
+	})
+	sort.Slice(all, func(a, b int) bool {
+		if all[a].hits != all[b].hits {
+			return all[a].hits > all[b].hits
+		}
+		return all[a].id < all[b].id
+	})
+	if len(all) > n {
+		all = all[:n]
+	}
+	ids := make([]string, len(all))
+	for i, k := range all {
+		ids[i] = k.id
+	}
+	return ids
+}
+
+// saveHotKeys saves the current hot keys
+func (fc *FetchCache) saveHotKeys(ctx context.Context) error {
+	if fc.opts.keyHash != 0 {
+		return nil
+	}
+	return fc.opts.hotKeys.Save(ctx, fc.hotKeys(fc.opts.hotKeysN))
+}
+
+// warmHotKeys fetches the saved hot keys
+func (fc *FetchCache) warmHotKeys(ctx context.Context) {
+	if fc.opts.keyHash != 0 {
+		return
+	}
+	ids, err := fc.opts.hotKeys.Load(ctx)
+	if err != nil {
+		fc.warmup.end(0, true)
+		return
+	}
+	fc.warmup.sized(len(ids))
+	fc.reloadAll(ctx, ids, fc.opts.hotKeysConcurrency, func(err error) {
+		fc.warmup.loaded(err == nil)
+	})
+	fc.warmup.end(0, false)
+}
+
+// runHotKeys warms the cache then saves the hot keys every interval and
+// once more on stop
+func (fc *FetchCache) runHotKeys(stop <-chan struct{}) {
+	ctx, cancel := fc.life.context()
+	defer cancel()
+	fc.warmHotKeys(ctx)
+
+	var tick <-chan time.Time
+	if fc.opts.hotKeysInterval > 0 {
+		ticker := time.NewTicker(fc.opts.hotKeysInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	for {
+		select {
+		case <-stop:
+			_ = fc.saveHotKeys(context.Background())
+			return
+		case <-tick:
+			_ = fc.saveHotKeys(ctx)
+		}
+	}
+}
+
+// FileHotKeys returns a HotKeyStore keeping the ids in the file at path, one
+// per line. The file is replaced atomically on Save.
+func FileHotKeys(path string) HotKeyStore {
+	return fileHotKeys(path)
+}
+
+type fileHotKeys string
+
+// Load implements HotKeyStore.
+func (f fileHotKeys) Load(ctx context.Context) ([]string, error) {
+	file, err := os.Open(string(f))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var ids []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if id := strings.TrimSpace(scanner.Text()); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids, scanner.Err()
+}
+
+// Save implements HotKeyStore.
+func (f fileHotKeys) Save(ctx context.Context, ids []string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(string(f)), filepath.Base(string(f))+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	w := bufio.NewWriter(tmp)
+	for _, id := range ids {
+		w.WriteString(id)
+		w.WriteByte('\n')
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), string(f))
+}
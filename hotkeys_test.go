@@ -0,0 +1,142 @@
+package resource
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memHotKeys is an in-memory HotKeyStore
+type memHotKeys struct {
+	mu  sync.Mutex
+	ids []string
+}
+
+func (m *memHotKeys) Load(ctx context.Context) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.ids, nil
+}
+
+func (m *memHotKeys) Save(ctx context.Context, ids []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.ids = ids
+	return nil
+}
+
+func TestFetchCache_WithHotKeys(t *testing.T) {
+	var (
+		fakeFetchID     = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+		notExistModelID = "5634aeed-2106-43de-ab7d-c0ad4b1e195e"
+	)
+
+	tests := []struct {
+		name       string
+		saved      []string
+		n          int
+		hits       map[string]int
+		wantWarmed []string
+		wantSaved  []string
+	}{
+		{
+			name:      "success save most hit first",
+			n:         2,
+			hits:      map[string]int{fakeFetchID: 1, notExistModelID: 3},
+			wantSaved: []string{notExistModelID, fakeFetchID},
+		},
+		{
+			name:      "success save top n",
+			n:         1,
+			hits:      map[string]int{fakeFetchID: 2, notExistModelID: 1},
+			wantSaved: []string{fakeFetchID},
+		},
+		{
+			name:       "success warm saved keys",
+			saved:      []string{fakeFetchID, notExistModelID},
+			n:          2,
+			wantWarmed: []string{notExistModelID, fakeFetchID},
+			wantSaved:  []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				mu      sync.Mutex
+				fetched []string
+			)
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					mu.Lock()
+					fetched = append(fetched, id)
+					mu.Unlock()
+					return &Model{Name: "lorem"}, nil
+				},
+			}
+			store := &memHotKeys{ids: tt.saved}
+			fc := NewCache(mockedFetcher, WithSynchronous(), WithHotKeys(store, tt.n, time.Hour, 2))
+			fc.Tick(context.Background())
+			sort.Strings(fetched)
+			if !reflect.DeepEqual(append([]string{}, fetched...), append([]string{}, tt.wantWarmed...)) {
+				t.Errorf("FetchCache.Tick() expect warmed %v, have %v", tt.wantWarmed, fetched)
+			}
+
+			for id, hits := range tt.hits {
+				for i := 0; i <= hits; i++ {
+					if _, err := fc.Fetch(context.Background(), id); err != nil {
+						t.Fatalf("FetchCache.Fetch() error = %v", err)
+					}
+				}
+			}
+			if err := fc.Close(context.Background()); err != nil {
+				t.Fatalf("FetchCache.Close() error = %v", err)
+			}
+			if !reflect.DeepEqual(store.ids, tt.wantSaved) {
+				t.Errorf("FetchCache.Close() expect saved %v, have %v", tt.wantSaved, store.ids)
+			}
+		})
+	}
+}
+
+func TestFileHotKeys(t *testing.T) {
+	var (
+		fakeFetchID     = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+		notExistModelID = "5634aeed-2106-43de-ab7d-c0ad4b1e195e"
+	)
+
+	tests := []struct {
+		name string
+		save []string
+		want []string
+	}{
+		{
+			name: "success not saved yet",
+		},
+		{
+			name: "success round trip",
+			save: []string{fakeFetchID, notExistModelID},
+			want: []string{fakeFetchID, notExistModelID},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := FileHotKeys(filepath.Join(t.TempDir(), "hot"))
+			if tt.save != nil {
+				if err := store.Save(context.Background(), tt.save); err != nil {
+					t.Fatalf("FileHotKeys.Save() error = %v", err)
+				}
+			}
+			ids, err := store.Load(context.Background())
+			if err != nil {
+				t.Fatalf("FileHotKeys.Load() error = %v", err)
+			}
+			if !reflect.DeepEqual(ids, tt.want) {
+				t.Errorf("FileHotKeys.Load() expect %v, have %v", tt.want, ids)
+			}
+		})
+	}
+}
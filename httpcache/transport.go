@@ -0,0 +1,300 @@
+// Package httpcache provides an http.RoundTripper caching GET responses in
+// a resource.FetchCache, so HTTP clients get the same coalescing of
+// concurrent requests, TTL and invalidation as Model fetchers.
+package httpcache
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httputil"
+	"strconv"
+	"strings"
+	"time"
+
+	resource "github.com/hieunmce/cache"
+)
+
+// Transport is an http.RoundTripper caching the responses of Base.
+//
+// Only successful GET responses are cached. Their freshness follows the
+// Cache-Control max-age and Expires headers, or DefaultTTL without them.
+// Stale responses with an ETag or Last-Modified header are revalidated with
+// a conditional request. Within a stale-while-revalidate window the stale
+// response is returned while it is refreshed in the background. Responses
+// with no-store, private or Vary are never cached.
+//
+// The cache is shared by all the callers of the Transport, so the responses
+// to requests with an Authorization or Cookie header are only cached when
+// marked public or with s-maxage. Requests with Cache-Control no-store skip
+// the cache and with no-cache always go to the origin, caching the
+// response.
+type Transport struct {
+	// Base makes the actual requests, http.DefaultTransport if nil.
+	Base http.RoundTripper
+	// DefaultTTL is the freshness of responses without freshness headers.
+	DefaultTTL time.Duration
+
+	cache *resource.FetchCache
+}
+
+// New creates a Transport over base, opts configure the underlying cache.
+func New(base http.RoundTripper, opts ...resource.Option) *Transport {
+	t := &Transport{Base: base}
+	t.cache = resource.NewCache(fetcher{t: t}, opts...)
+	return t
+}
+
+// Cache returns the cache of t, keyed by request URL.
+func (t *Transport) Cache() *resource.FetchCache {
+	return t.cache
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || req.Header.Get("Range") != "" {
+		return t.base().RoundTrip(req)
+	}
+	reqd := parseDirectives(req.Header)
+	if reqd.noStore {
+		return t.base().RoundTrip(req)
+	}
+	key := req.URL.String()
+	fetched := new(bool)
+	ctx := context.WithValue(req.Context(), requestKey{}, req)
+	ctx = context.WithValue(ctx, fetchedKey{}, fetched)
+
+	var opts []resource.FetchOption
+	if reqd.noCache {
+		opts = append(opts, resource.BypassCache())
+	}
+	model, err := t.cache.FetchWithOptions(ctx, key, opts...)
+	if u, ok := err.(*uncacheable); ok {
+		return t.uncacheable(u, req)
+	}
+	if err != nil {
+		return nil, err
+	}
+	resp, err := readResponse(model.Data, req)
+	if err != nil || *fetched {
+		return resp, err
+	}
+
+	d := parseDirectives(resp.Header)
+	lifetime := d.lifetime(resp.Header, t.DefaultTTL)
+	age := responseAge(resp.Header)
+	switch {
+	case age <= lifetime:
+		return resp, nil
+	case age <= lifetime+d.staleWhileRevalidate:
+		go func() {
+			ctx := context.WithValue(context.Background(), requestKey{}, req.WithContext(context.Background()))
+			_, _ = t.refresh(context.WithValue(ctx, staleKey{}, model), key, lifetime)
+		}()
+		return resp, nil
+	}
+
+	model, err = t.refresh(context.WithValue(ctx, staleKey{}, model), key, lifetime)
+	if u, ok := err.(*uncacheable); ok {
+		return t.uncacheable(u, req)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return readResponse(model.Data, req)
+}
+
+// refresh refetches key unless a concurrent caller refreshed it within
+// lifetime, in which case that response is reused
+func (t *Transport) refresh(ctx context.Context, key string, lifetime time.Duration) (*resource.Model, error) {
+	if lifetime <= 0 {
+		return t.cache.FetchWithOptions(ctx, key, resource.BypassCache())
+	}
+	return t.cache.FetchWithOptions(ctx, key, resource.MinFreshness(lifetime))
+}
+
+// uncacheable returns the response of u if it answers req, an error cached
+// by the cache is made of the response to another request so req is made
+// again without the cache
+func (t *Transport) uncacheable(u *uncacheable, req *http.Request) (*http.Response, error) {
+	if u.req != req {
+		return t.base().RoundTrip(req)
+	}
+	return u.resp, nil
+}
+
+func (t *Transport) base() http.RoundTripper {
+	if t.Base == nil {
+		return http.DefaultTransport
+	}
+	return t.Base
+}
+
+type requestKey struct{}
+
+// staleKey carries the stale cached model being refreshed
+type staleKey struct{}
+
+// fetchedKey carries a flag set when the response came from the origin
+type fetchedKey struct{}
+
+// uncacheable is returned by fetcher for responses which must not be
+// cached, RoundTrip hands the response to the caller
+type uncacheable struct {
+	req  *http.Request
+	resp *http.Response
+}
+
+func (u *uncacheable) Error() string {
+	return "httpcache: uncacheable response " + u.resp.Status
+}
+
+// fetcher makes the request carried by ctx through the base transport
+type fetcher struct {
+	t *Transport
+}
+
+// Fetch implements resource.Fetcher.
+func (f fetcher) Fetch(ctx context.Context, key string) (*resource.Model, error) {
+	req, _ := ctx.Value(requestKey{}).(*http.Request)
+	if req == nil {
+		return nil, resource.ErrNotFound
+	}
+
+	// revalidate the stale cached copy when possible
+	var prev *http.Response
+	if model, ok := ctx.Value(staleKey{}).(*resource.Model); ok {
+		prev, _ = readResponse(model.Data, req)
+	}
+	out := req.Clone(ctx)
+	if prev != nil {
+		if etag := prev.Header.Get("ETag"); etag != "" {
+			out.Header.Set("If-None-Match", etag)
+		}
+		if lm := prev.Header.Get("Last-Modified"); lm != "" {
+			out.Header.Set("If-Modified-Since", lm)
+		}
+	}
+
+	resp, err := f.t.base().RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+	if fetched, ok := ctx.Value(fetchedKey{}).(*bool); ok {
+		*fetched = true
+	}
+	if resp.StatusCode == http.StatusNotModified && prev != nil {
+		resp.Body.Close()
+		for _, h := range []string{"Date", "Cache-Control", "Expires", "ETag", "Last-Modified"} {
+			if v := resp.Header.Get(h); v != "" {
+				prev.Header.Set(h, v)
+			}
+		}
+		resp = prev
+	}
+	if resp.Header.Get("Date") == "" {
+		resp.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+	}
+
+	d := parseDirectives(resp.Header)
+	if resp.StatusCode != http.StatusOK || d.noStore || d.private || resp.Header.Get("Vary") != "" || !d.shareable(req) {
+		return nil, &uncacheable{req: req, resp: resp}
+	}
+
+	data, err := httputil.DumpResponse(resp, true)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	return &resource.Model{Name: key, Data: data}, nil
+}
+
+func readResponse(data []byte, req *http.Request) (*http.Response, error) {
+	return http.ReadResponse(bufio.NewReader(bytes.NewReader(data)), req)
+}
+
+// directives are the Cache-Control directives used by Transport
+type directives struct {
+	noStore              bool
+	noCache              bool
+	private              bool
+	public               bool
+	maxAge               time.Duration
+	hasMaxAge            bool
+	sMaxAge              time.Duration
+	hasSMaxAge           bool
+	staleWhileRevalidate time.Duration
+}
+
+func parseDirectives(h http.Header) directives {
+	var d directives
+	for _, part := range strings.Split(h.Get("Cache-Control"), ",") {
+		name, value := strings.TrimSpace(part), ""
+		if i := strings.IndexByte(name, '='); i >= 0 {
+			name, value = name[:i], strings.Trim(name[i+1:], `"`)
+		}
+		switch strings.ToLower(name) {
+		case "no-store":
+			d.noStore = true
+		case "no-cache":
+			d.noCache = true
+		case "private":
+			d.private = true
+		case "public":
+			d.public = true
+		case "s-maxage":
+			if secs, err := strconv.Atoi(value); err == nil {
+				d.sMaxAge, d.hasSMaxAge = time.Duration(secs)*time.Second, true
+			}
+		case "max-age":
+			if secs, err := strconv.Atoi(value); err == nil {
+				d.maxAge, d.hasMaxAge = time.Duration(secs)*time.Second, true
+			}
+		case "stale-while-revalidate":
+			if secs, err := strconv.Atoi(value); err == nil {
+				d.staleWhileRevalidate = time.Duration(secs) * time.Second
+			}
+		}
+	}
+	return d
+}
+
+// shareable reports whether the response to req, with directives d, may be
+// served to other callers: requests with credentials need the response to
+// allow it explicitly
+func (d directives) shareable(req *http.Request) bool {
+	if req.Header.Get("Authorization") == "" && req.Header.Get("Cookie") == "" {
+		return true
+	}
+	return d.public || d.hasSMaxAge
+}
+
+// lifetime returns how long a response stays fresh in the shared cache
+func (d directives) lifetime(h http.Header, defaultTTL time.Duration) time.Duration {
+	switch {
+	case d.noCache:
+		return 0
+	case d.hasSMaxAge:
+		return d.sMaxAge
+	case d.hasMaxAge:
+		return d.maxAge
+	}
+	if expires, err := http.ParseTime(h.Get("Expires")); err == nil {
+		date, err := http.ParseTime(h.Get("Date"))
+		if err != nil {
+			date = time.Now()
+		}
+		return expires.Sub(date)
+	}
+	return defaultTTL
+}
+
+// responseAge returns the age of a response from its Date header
+func responseAge(h http.Header) time.Duration {
+	date, err := http.ParseTime(h.Get("Date"))
+	if err != nil {
+		return 0
+	}
+	return time.Since(date)
+}
@@ -0,0 +1,140 @@
+package httpcache
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTransport_RoundTrip(t *testing.T) {
+	tests := []struct {
+		name          string
+		cacheControl  string
+		reqHeader     http.Header
+		etag          string
+		wait          time.Duration
+		callCount     int
+		wantFullCount int32
+		wantCondCount int32
+		defaultTTL    time.Duration
+	}{
+		{
+			name:          "success serve fresh response from cache",
+			cacheControl:  "max-age=60",
+			callCount:     20,
+			wantFullCount: 1,
+		},
+		{
+			name:          "success never cache no-store response",
+			cacheControl:  "no-store",
+			callCount:     3,
+			wantFullCount: 3,
+		},
+		{
+			name:          "success revalidate stale response with etag",
+			cacheControl:  "no-cache",
+			etag:          `"v1"`,
+			callCount:     3,
+			wantFullCount: 1,
+			wantCondCount: 2,
+		},
+		{
+			name:          "success never share response to authorized request",
+			cacheControl:  "max-age=60",
+			reqHeader:     http.Header{"Authorization": {"Bearer lorem"}},
+			callCount:     3,
+			wantFullCount: 3,
+		},
+		{
+			name:          "success share public response to authorized request",
+			cacheControl:  "public, max-age=60",
+			reqHeader:     http.Header{"Authorization": {"Bearer lorem"}},
+			callCount:     3,
+			wantFullCount: 1,
+		},
+		{
+			name:          "success skip cache for no-store request",
+			cacheControl:  "max-age=60",
+			reqHeader:     http.Header{"Cache-Control": {"no-store"}},
+			callCount:     3,
+			wantFullCount: 3,
+		},
+		{
+			name:          "success go to origin for no-cache request",
+			cacheControl:  "max-age=60",
+			reqHeader:     http.Header{"Cache-Control": {"no-cache"}},
+			callCount:     3,
+			wantFullCount: 3,
+		},
+		{
+			name:          "success use default ttl without headers",
+			callCount:     5,
+			defaultTTL:    time.Minute,
+			wantFullCount: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fullCount, condCount int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.cacheControl != "" {
+					w.Header().Set("Cache-Control", tt.cacheControl)
+				}
+				if tt.etag != "" {
+					w.Header().Set("ETag", tt.etag)
+					if r.Header.Get("If-None-Match") == tt.etag {
+						atomic.AddInt32(&condCount, 1)
+						w.WriteHeader(http.StatusNotModified)
+						return
+					}
+				}
+				atomic.AddInt32(&fullCount, 1)
+				_, _ = io.WriteString(w, "lorem")
+			}))
+			defer srv.Close()
+
+			tr := New(nil)
+			tr.DefaultTTL = tt.defaultTTL
+			client := &http.Client{Transport: tr}
+
+			var wg sync.WaitGroup
+			get := func() {
+				defer wg.Done()
+				req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+				for h, v := range tt.reqHeader {
+					req.Header[h] = v
+				}
+				resp, err := client.Do(req)
+				if err != nil {
+					t.Errorf("Transport.RoundTrip() error = %v", err)
+					return
+				}
+				defer resp.Body.Close()
+				body, _ := io.ReadAll(resp.Body)
+				if resp.StatusCode != http.StatusOK || string(body) != "lorem" {
+					t.Errorf("Transport.RoundTrip() = %v %q, want 200 %q", resp.StatusCode, body, "lorem")
+				}
+			}
+			// first request fills the cache, the others run concurrently
+			wg.Add(1)
+			get()
+			wg.Add(tt.callCount - 1)
+			for i := 1; i < tt.callCount; i++ {
+				if tt.wantCondCount > 0 {
+					get()
+					continue
+				}
+				go get()
+			}
+			wg.Wait()
+
+			if fullCount != tt.wantFullCount || condCount != tt.wantCondCount {
+				t.Errorf("Transport.RoundTrip() expect %v full and %v conditional origin requests, have %v and %v", tt.wantFullCount, tt.wantCondCount, fullCount, condCount)
+			}
+		})
+	}
+}
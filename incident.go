@@ -0,0 +1,68 @@
+package resource
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// defaultIncidentDuration is how long the incident mode lasts, see
+// WithIncidentDuration
+const defaultIncidentDuration = time.Hour
+
+// WithIncidentDuration sets how long the incident mode set by
+// SetIncidentMode lasts before reverting, 1h by default.
+func WithIncidentDuration(d time.Duration) Option {
+	return func(o *options) {
+		o.incidentDuration = d
+	}
+}
+
+// SetIncidentMode relaxes the freshness of the cache to shed the load of
+// the origin during an outage: the cached items are served until they are
+// maxStale old, expired, marked stale or not as fresh as MinFreshness
+// requires, without refreshing them, and the eager, scheduled and drift
+// check refreshes are suppressed, see Degraded. It reverts after
+// WithIncidentDuration, or when SetIncidentMode is called with 0. The mode
+// is broadcast to the other caches with WithInvalidator.
+func (fc *FetchCache) SetIncidentMode(maxStale time.Duration) {
+	fc.setIncidentMode(maxStale)
+	fc.broadcast(Invalidation{Incident: true, MaxStale: Duration(maxStale)})
+}
+
+// IncidentMode returns the max staleness of the incident mode and when it
+// reverts, 0 if it is off.
+func (fc *FetchCache) IncidentMode() (time.Duration, time.Time) {
+	until := atomic.LoadInt64(&fc.incidentUntil)
+	if until == 0 || time.Now().UnixNano() >= until {
+		return 0, time.Time{}
+	}
+	return time.Duration(atomic.LoadInt64(&fc.incidentStale)), time.Unix(0, until)
+}
+
+// setIncidentMode sets the incident mode of this cache
+func (fc *FetchCache) setIncidentMode(maxStale time.Duration) {
+	if maxStale <= 0 {
+		atomic.StoreInt64(&fc.incidentUntil, 0)
+		return
+	}
+	d := fc.opts.incidentDuration
+	if d <= 0 {
+		d = defaultIncidentDuration
+	}
+	atomic.StoreInt64(&fc.incidentStale, int64(maxStale))
+	atomic.StoreInt64(&fc.incidentUntil, time.Now().Add(d).UnixNano())
+}
+
+// incidentItem returns the item of key to serve in incident mode in place
+// of refreshing it, if any
+func (fc *FetchCache) incidentItem(key string, now time.Time) (item, bool) {
+	until := atomic.LoadInt64(&fc.incidentUntil)
+	if until == 0 || now.UnixNano() >= until {
+		return item{}, false
+	}
+	i, found := fc.peekitem(key)
+	if !found || now.UnixNano()-i.Created > atomic.LoadInt64(&fc.incidentStale) {
+		return item{}, false
+	}
+	return i, true
+}
@@ -0,0 +1,79 @@
+package resource
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFetchCache_SetIncidentMode(t *testing.T) {
+	var fakeFetchID = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	tests := []struct {
+		name      string
+		maxStale  time.Duration
+		duration  time.Duration
+		wait      time.Duration
+		wantCalls int64
+		wantSrc   Source
+	}{
+		{
+			name:      "success expired item served",
+			maxStale:  time.Hour,
+			duration:  time.Hour,
+			wantCalls: 1,
+			wantSrc:   SourceStale,
+		},
+		{
+			name:      "success item older than max stale refreshed",
+			maxStale:  time.Millisecond,
+			duration:  time.Hour,
+			wait:      5 * time.Millisecond,
+			wantCalls: 2,
+			wantSrc:   SourceOrigin,
+		},
+		{
+			name:      "success reverted after the duration",
+			maxStale:  time.Hour,
+			duration:  time.Millisecond,
+			wait:      5 * time.Millisecond,
+			wantCalls: 2,
+			wantSrc:   SourceOrigin,
+		},
+		{
+			name:      "success turned off",
+			wantCalls: 2,
+			wantSrc:   SourceOrigin,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int64
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					atomic.AddInt64(&calls, 1)
+					return &Model{Name: id}, nil
+				},
+			}
+			inv := &localInvalidator{}
+			fc := NewCache(mockedFetcher, WithIncidentDuration(tt.duration), WithInvalidator(inv))
+			other := NewCache(mockedFetcher, WithIncidentDuration(tt.duration), WithInvalidator(inv))
+			if _, err := fc.FetchWithOptions(context.Background(), fakeFetchID, OverrideTTL(time.Nanosecond)); err != nil {
+				t.Fatalf("FetchCache.FetchWithOptions() expect no error, have %v", err)
+			}
+			fc.SetIncidentMode(tt.maxStale)
+			if on := other.Degraded(); on != (tt.maxStale > 0) {
+				t.Errorf("FetchCache.SetIncidentMode() expect the other cache degraded = %v, have %v", tt.maxStale > 0, on)
+			}
+			time.Sleep(tt.wait)
+
+			_, src, err := fc.FetchWithSource(context.Background(), fakeFetchID)
+			if err != nil || src != tt.wantSrc {
+				t.Errorf("FetchCache.FetchWithSource() expect source %v, have %v, %v", tt.wantSrc, src, err)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("FetchCache.FetchWithSource() expect %v Fetcher calls, have %v", tt.wantCalls, calls)
+			}
+		})
+	}
+}
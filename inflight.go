@@ -0,0 +1,187 @@
+package resource
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// InFlightFetch is a Fetcher call in progress, see FetchCache.InFlight.
+type InFlightFetch struct {
+	// ID is the id being fetched.
+	ID string `json:"id"`
+	// Running is how long the call has been running.
+	Running time.Duration `json:"running"`
+	// Waiters is the number of fetches of ID waiting for the call.
+	Waiters int `json:"waiters"`
+}
+
+// InFlight returns the Fetcher calls in progress sorted by id, to tell
+// which keys a slow or stuck backend holds up.
+func (fc *FetchCache) InFlight() []InFlightFetch {
+	fetches, waiters := fc.inflight.snapshot()
+	now := time.Now()
+	out := make([]InFlightFetch, 0, len(fetches))
+	for key, f := range fetches {
+		out = append(out, InFlightFetch{ID: f.id, Running: now.Sub(f.start), Waiters: waiters[key]})
+	}
+	sort.Slice(out, func(a, b int) bool { return out[a].ID < out[b].ID })
+	return out
+}
+
+// WithDuplicateFetchCheck calls fail with the id of every Fetcher call
+// started while another call for the same key is in flight, which the key
+// locks rule out. It is meant for tests asserting that a key is fetched at
+// most once at a time, e.g. with fail reporting to testing.T; see also
+// Stats.Coalesced and FetcherCalls.
+func WithDuplicateFetchCheck(fail func(id string)) Option {
+	return func(o *options) {
+		o.duplicateFetch = fail
+	}
+}
+
+// inflightTracker records the Fetcher calls in flight and the callers
+// waiting on key locks.
+//
+// It also keeps the tombstones of the invalidations racing the calls: every
+// invalidation takes the next generation, the key of a call in flight
+// records the generation of its last removal and a flush the generation of
+// all keys, so a call started at an older generation knows its result
+// predates an invalidation and mustn't be cached.
+type inflightTracker struct {
+	mu      sync.Mutex
+	fetches map[string]inflightFetch
+	waiters map[string]int
+	// gen is the generation of the last invalidation and flushed that of
+	// the last flush, guarded by mu
+	gen, flushed uint64
+	tombstones   map[string]uint64
+	// duplicate is called with the id of a call started while another call
+	// for the same key is in flight, see WithDuplicateFetchCheck
+	duplicate func(id string)
+}
+
+// inflightFetch is a Fetcher call in flight, cancel is not nil with
+// WithCancelOnClear
+type inflightFetch struct {
+	id     string
+	start  time.Time
+	cancel context.CancelFunc
+}
+
+func newInflightTracker(duplicate func(id string)) *inflightTracker {
+	return &inflightTracker{
+		fetches:    make(map[string]inflightFetch),
+		waiters:    make(map[string]int),
+		tombstones: make(map[string]uint64),
+		duplicate:  duplicate,
+	}
+}
+
+// start records the call for key, canceled by cancel if not nil, and
+// returns the current generation
+func (t *inflightTracker) start(key, id string, cancel context.CancelFunc) uint64 {
+	t.mu.Lock()
+	_, running := t.fetches[key]
+	t.fetches[key] = inflightFetch{id: id, start: time.Now(), cancel: cancel}
+	gen := t.gen
+	t.mu.Unlock()
+	if running && t.duplicate != nil {
+		t.duplicate(id)
+	}
+	return gen
+}
+
+func (t *inflightTracker) done(key string) {
+	t.mu.Lock()
+	delete(t.fetches, key)
+	delete(t.tombstones, key)
+	t.mu.Unlock()
+}
+
+// running reports whether a Fetcher call for key is in flight
+func (t *inflightTracker) running(key string) bool {
+	t.mu.Lock()
+	_, found := t.fetches[key]
+	t.mu.Unlock()
+	return found
+}
+
+// invalidate records the removal of key, a tombstone if a call for key is
+// in flight
+func (t *inflightTracker) invalidate(key string) {
+	t.mu.Lock()
+	if _, found := t.fetches[key]; found {
+		t.gen++
+		t.tombstones[key] = t.gen
+	}
+	t.mu.Unlock()
+}
+
+// cancel cancels the call for key in flight, if any and cancelable
+func (t *inflightTracker) cancel(key string) {
+	t.mu.Lock()
+	if f, found := t.fetches[key]; found && f.cancel != nil {
+		f.cancel()
+	}
+	t.mu.Unlock()
+}
+
+// cancelAll cancels the cancelable calls in flight
+func (t *inflightTracker) cancelAll() {
+	t.mu.Lock()
+	for _, f := range t.fetches {
+		if f.cancel != nil {
+			f.cancel()
+		}
+	}
+	t.mu.Unlock()
+}
+
+// invalidateAll records the removal of all keys
+func (t *inflightTracker) invalidateAll() {
+	t.mu.Lock()
+	t.gen++
+	t.flushed = t.gen
+	t.tombstones = make(map[string]uint64)
+	t.mu.Unlock()
+}
+
+// invalidated reports whether key was removed after generation gen,
+// returned by start
+func (t *inflightTracker) invalidated(key string, gen uint64) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.flushed > gen || t.tombstones[key] > gen
+}
+
+// wait adds delta to the number of callers waiting on the lock of key
+func (t *inflightTracker) wait(key interface{}, delta int) {
+	k, ok := key.(string)
+	if !ok {
+		return
+	}
+	t.mu.Lock()
+	if n := t.waiters[k] + delta; n > 0 {
+		t.waiters[k] = n
+	} else {
+		delete(t.waiters, k)
+	}
+	t.mu.Unlock()
+}
+
+// snapshot returns copies of the fetches in flight and of the lock waiters
+func (t *inflightTracker) snapshot() (map[string]inflightFetch, map[string]int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	fetches := make(map[string]inflightFetch, len(t.fetches))
+	for key, f := range t.fetches {
+		fetches[key] = f
+	}
+	waiters := make(map[string]int, len(t.waiters))
+	for key, n := range t.waiters {
+		waiters[key] = n
+	}
+	return fetches, waiters
+}
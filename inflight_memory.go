@@ -0,0 +1,142 @@
+package resource
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// WithInFlightMemory bounds the bytes of the models being fetched and not
+// cached yet to budget, so a burst of misses of large models can't exhaust
+// the memory before eviction runs. Every Fetcher call reserves the size
+// expected of its model, the size of the model cached for the id if any,
+// otherwise the average size of the models fetched, and waits for its ctx
+// while the reservations exceed budget, one call always being let through.
+// The reservation is replaced by the actual size on return and released
+// once the model is cached, queued WithWriteBuffer or dropped. Sizes are
+// measured by the SizeFunc of WithMaxValueSize, DefaultSize without. The
+// reserved bytes are reported in Stats.
+func WithInFlightMemory(budget int64) Option {
+	return func(o *options) {
+		o.inflightMemory = budget
+	}
+}
+
+// memoryBudget is the budget of WithInFlightMemory
+type memoryBudget struct {
+	max int64
+	mu  sync.Mutex
+	// used is the bytes reserved and wake is closed and replaced when
+	// bytes are released, guarded by mu
+	used int64
+	wake chan struct{}
+	// fetched and bytes are the number and total size of the models
+	// fetched, for the average
+	fetched int64 // accessed atomically
+	bytes   int64 // accessed atomically
+}
+
+func newMemoryBudget(max int64) *memoryBudget {
+	if max <= 0 {
+		return nil
+	}
+	return &memoryBudget{max: max, wake: make(chan struct{})}
+}
+
+// reserve waits until n bytes fit the budget or ctx is done
+func (b *memoryBudget) reserve(ctx context.Context, n int64) error {
+	for {
+		b.mu.Lock()
+		if b.used == 0 || b.used+n <= b.max {
+			b.used += n
+			b.mu.Unlock()
+			return nil
+		}
+		wake := b.wake
+		b.mu.Unlock()
+		select {
+		case <-wake:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// adjust adds delta to the bytes reserved, waking the waiters if it is
+// negative
+func (b *memoryBudget) adjust(delta int64) {
+	b.mu.Lock()
+	b.used += delta
+	if delta < 0 {
+		close(b.wake)
+		b.wake = make(chan struct{})
+	}
+	b.mu.Unlock()
+}
+
+// reserved returns the bytes reserved
+func (b *memoryBudget) reserved() int64 {
+	if b == nil {
+		return 0
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.used
+}
+
+// average returns the average size of the models fetched, 0 if none
+func (b *memoryBudget) average() int64 {
+	n := atomic.LoadInt64(&b.fetched)
+	if n == 0 {
+		return 0
+	}
+	return atomic.LoadInt64(&b.bytes) / n
+}
+
+// modelSize returns the size of model for the budget
+func (fc *FetchCache) modelSize(model *Model) int64 {
+	if fc.opts.sizeFunc != nil {
+		return int64(fc.opts.sizeFunc(model))
+	}
+	return int64(DefaultSize(model))
+}
+
+// reserveMemory reserves the expected size of the model of key and
+// returns it, see WithInFlightMemory
+func (fc *FetchCache) reserveMemory(ctx context.Context, key string) (int64, error) {
+	if fc.memory == nil {
+		return 0, nil
+	}
+	n := fc.memory.average()
+	if i, found := fc.peekitem(key); found {
+		n = fc.modelSize(i.Object)
+	}
+	if err := fc.memory.reserve(ctx, n); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// fetchedMemory replaces the reservation of a Fetcher call by the size of
+// its model and returns it
+func (fc *FetchCache) fetchedMemory(reserved int64, model *Model) int64 {
+	if fc.memory == nil {
+		return 0
+	}
+	if model == nil {
+		fc.memory.adjust(-reserved)
+		return 0
+	}
+	n := fc.modelSize(model)
+	atomic.AddInt64(&fc.memory.fetched, 1)
+	atomic.AddInt64(&fc.memory.bytes, n)
+	fc.memory.adjust(n - reserved)
+	return n
+}
+
+// releaseMemory releases n bytes of the budget
+func (fc *FetchCache) releaseMemory(n int64) {
+	if fc.memory != nil && n != 0 {
+		fc.memory.adjust(-n)
+	}
+}
@@ -0,0 +1,92 @@
+package resource
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFetchCache_WithInFlightMemory(t *testing.T) {
+	tests := []struct {
+		name    string
+		budget  int64
+		wantMax int32
+	}{
+		{
+			name:    "success no budget",
+			wantMax: 4,
+		},
+		{
+			name:    "success budget of one model",
+			budget:  1000,
+			wantMax: 1,
+		},
+		{
+			name:    "success budget of two models",
+			budget:  2000,
+			wantMax: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var running, max int32
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					n := atomic.AddInt32(&running, 1)
+					defer atomic.AddInt32(&running, -1)
+					for {
+						m := atomic.LoadInt32(&max)
+						if n <= m || atomic.CompareAndSwapInt32(&max, m, n) {
+							break
+						}
+					}
+					time.Sleep(20 * time.Millisecond)
+					return &Model{Name: id, Data: make([]byte, 1000-len(id))}, nil
+				},
+			}
+			fc := NewCache(mockedFetcher, WithInFlightMemory(tt.budget))
+			defer fc.Close(context.Background())
+			// the first fetch teaches the average size
+			if _, err := fc.Fetch(context.Background(), "first"); err != nil {
+				t.Fatalf("FetchCache.Fetch() error = %v", err)
+			}
+			atomic.StoreInt32(&max, 0)
+
+			var wg sync.WaitGroup
+			for _, id := range []string{"a", "b", "c", "d"} {
+				wg.Add(1)
+				go func(id string) {
+					defer wg.Done()
+					if _, err := fc.Fetch(context.Background(), id); err != nil {
+						t.Errorf("FetchCache.Fetch() error = %v", err)
+					}
+				}(id)
+			}
+			wg.Wait()
+			if max != tt.wantMax {
+				t.Errorf("FetchCache.Fetch() expect max concurrent fetches = %v, have %v", tt.wantMax, max)
+			}
+			if got := fc.Stats().InFlightBytes; got != 0 {
+				t.Errorf("FetchCache.Stats() expect in flight bytes = 0, have %v", got)
+			}
+		})
+	}
+}
+
+func TestFetchCache_WithInFlightMemory_Context(t *testing.T) {
+	b := newMemoryBudget(10)
+	if err := b.reserve(context.Background(), 8); err != nil {
+		t.Fatalf("memoryBudget.reserve() error = %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	if err := b.reserve(ctx, 8); err != context.DeadlineExceeded {
+		t.Errorf("memoryBudget.reserve() expect error = %v, have %v", context.DeadlineExceeded, err)
+	}
+	b.adjust(-8)
+	if err := b.reserve(context.Background(), 8); err != nil {
+		t.Errorf("memoryBudget.reserve() expect room, have %v", err)
+	}
+}
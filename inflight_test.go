@@ -0,0 +1,233 @@
+package resource
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFetchCache_InFlight(t *testing.T) {
+	var (
+		fakeFetchID = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	)
+
+	tests := []struct {
+		name        string
+		waiters     int
+		wantWaiters int
+	}{
+		{
+			name: "success no waiter",
+		},
+		{
+			name:        "success coalesced waiters",
+			waiters:     2,
+			wantWaiters: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entered, release := make(chan struct{}), make(chan struct{})
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					close(entered)
+					<-release
+					return &Model{Name: "lorem"}, nil
+				},
+			}
+			fc := NewCache(mockedFetcher)
+			defer fc.Close(context.Background())
+
+			done := make(chan struct{}, 1+tt.waiters)
+			fetch := func() {
+				_, _ = fc.Fetch(context.Background(), fakeFetchID)
+				done <- struct{}{}
+			}
+			go fetch()
+			<-entered
+			for i := 0; i < tt.waiters; i++ {
+				go fetch()
+			}
+			// waiters block on the key lock once their goroutine runs
+			for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(time.Millisecond) {
+				if f := fc.InFlight(); len(f) == 1 && f[0].Waiters == tt.wantWaiters {
+					break
+				}
+				if time.Now().After(deadline) {
+					t.Fatalf("FetchCache.InFlight() expect %v waiters, have %+v", tt.wantWaiters, fc.InFlight())
+				}
+			}
+
+			f := fc.Stats().InFlight
+			if len(f) != 1 || f[0].ID != fakeFetchID || f[0].Running <= 0 {
+				t.Errorf("FetchCache.Stats() expect %v in flight, have %+v", fakeFetchID, f)
+			}
+			close(release)
+			for i := 0; i < 1+tt.waiters; i++ {
+				<-done
+			}
+			if f := fc.InFlight(); len(f) != 0 {
+				t.Errorf("FetchCache.InFlight() expect none after fetch, have %+v", f)
+			}
+		})
+	}
+}
+
+func TestFetchCache_Clear_InFlight(t *testing.T) {
+	fakeFetchID := "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	notExistModelID := "5634aeed-2106-43de-ab7d-c0ad4b1e195e"
+
+	tests := []struct {
+		name          string
+		invalidate    func(fc *FetchCache)
+		wantWait      bool
+		wantCached    bool
+		wantDiscarded uint64
+	}{
+		{
+			name:       "success clear waits for fetch",
+			invalidate: func(fc *FetchCache) { fc.Clear(fakeFetchID) },
+			wantWait:   true,
+		},
+		{
+			name:          "success flush discards fetch",
+			invalidate:    func(fc *FetchCache) { fc.Flush() },
+			wantDiscarded: 1,
+		},
+		{
+			name:       "success other clear keeps fetch",
+			invalidate: func(fc *FetchCache) { fc.Clear(notExistModelID) },
+			wantCached: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entered, release := make(chan struct{}), make(chan struct{})
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					if id == fakeFetchID {
+						close(entered)
+						<-release
+					}
+					return &Model{Name: "lorem"}, nil
+				},
+			}
+			fc := NewCache(mockedFetcher)
+			defer fc.Close(context.Background())
+
+			fetched := make(chan error)
+			go func() {
+				_, err := fc.Fetch(context.Background(), fakeFetchID)
+				fetched <- err
+			}()
+			<-entered
+			invalidated := make(chan struct{})
+			go func() {
+				tt.invalidate(fc)
+				close(invalidated)
+			}()
+			select {
+			case <-invalidated:
+				if tt.wantWait {
+					t.Errorf("FetchCache.Clear() expect to wait for the fetch in flight")
+				}
+			case <-time.After(20 * time.Millisecond):
+				if !tt.wantWait {
+					t.Errorf("FetchCache.Clear() expect not to wait for the fetch in flight")
+				}
+			}
+			close(release)
+			if err := <-fetched; err != nil {
+				t.Fatalf("FetchCache.Fetch() expect error = nil, have %v", err)
+			}
+			<-invalidated
+
+			if _, found := fc.Entry(fakeFetchID); found != tt.wantCached {
+				t.Errorf("FetchCache.Entry() expect found = %v, have %v", tt.wantCached, found)
+			}
+			if have := fc.Stats().Discarded; have != tt.wantDiscarded {
+				t.Errorf("FetchCache.Stats() expect discarded = %v, have %v", tt.wantDiscarded, have)
+			}
+		})
+	}
+}
+
+func TestWithDuplicateFetchCheck(t *testing.T) {
+	var (
+		fakeFetchID = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	)
+
+	tests := []struct {
+		name          string
+		fetches       int
+		wantCalls     uint64
+		wantCoalesced uint64
+	}{
+		{
+			name:      "success single fetch",
+			fetches:   1,
+			wantCalls: 1,
+		},
+		{
+			name:          "success concurrent fetches call the fetcher once",
+			fetches:       32,
+			wantCalls:     1,
+			wantCoalesced: 31,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var running int32
+			release := make(chan struct{})
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					atomic.AddInt32(&running, 1)
+					<-release
+					return &Model{Name: id}, nil
+				},
+			}
+			fc := NewCache(mockedFetcher, WithDuplicateFetchCheck(func(id string) {
+				t.Errorf("FetchCache.Fetch() expect at most one fetch of %v at a time", id)
+			}))
+			defer fc.Close(context.Background())
+
+			var wg sync.WaitGroup
+			for i := 0; i < tt.fetches; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					_, _ = fc.Fetch(context.Background(), fakeFetchID)
+				}()
+			}
+			for atomic.LoadInt32(&running) == 0 || len(fc.InFlight()) > 0 && fc.InFlight()[0].Waiters < tt.fetches-1 {
+				time.Sleep(time.Millisecond)
+			}
+			close(release)
+			wg.Wait()
+
+			got := fc.Stats()
+			if got.FetcherCalls != tt.wantCalls {
+				t.Errorf("FetchCache.Stats() expect fetcher calls = %v, have %v", tt.wantCalls, got.FetcherCalls)
+			}
+			if got.Coalesced != tt.wantCoalesced {
+				t.Errorf("FetchCache.Stats() expect coalesced = %v, have %v", tt.wantCoalesced, got.Coalesced)
+			}
+			if ks, _ := fc.KeyStats(fakeFetchID); ks.FetcherCalls != tt.wantCalls {
+				t.Errorf("FetchCache.KeyStats() expect fetcher calls = %v, have %v", tt.wantCalls, ks.FetcherCalls)
+			}
+		})
+	}
+}
+
+func Test_inflightTracker_duplicate(t *testing.T) {
+	var duplicates []string
+	tr := newInflightTracker(func(id string) { duplicates = append(duplicates, id) })
+	tr.start("a", "a", nil)
+	tr.start("b", "b", nil)
+	tr.start("a", "a", nil)
+	if len(duplicates) != 1 || duplicates[0] != "a" {
+		t.Errorf("inflightTracker.start() expect duplicate = [a], have %v", duplicates)
+	}
+}
@@ -0,0 +1,12 @@
+// Package integration holds the end-to-end tests of the cache against real
+// memcached and Redis servers, behind the integration build tag:
+//
+//	go test -tags integration ./integration
+//
+// Each server is started in a docker container for the run, unless its
+// "host:port" is given in CACHE_IT_MEMCACHED or CACHE_IT_REDIS. The tests
+// of a server neither given nor started are skipped.
+//
+// memcached backs the L2 store and its locks, see the memcache package,
+// and a Redis stream the invalidations, see invalidation.RedisStream.
+package integration
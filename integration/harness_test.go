@@ -0,0 +1,116 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Const list
+const (
+	// startTimeout bounds the wait for a started server to be ready
+	startTimeout = 30 * time.Second
+	// eventually bounds the wait for an invalidation to reach another cache
+	eventually = 5 * time.Second
+)
+
+// server is a backend of the tests, given by env or run by docker
+type server struct {
+	env   string
+	image string
+	port  string
+
+	addr      string
+	container string
+	// skip is why the tests of the server are skipped, when addr is empty
+	skip string
+}
+
+var (
+	memcached = &server{env: "CACHE_IT_MEMCACHED", image: "memcached:1.6-alpine", port: "11211"}
+	redis     = &server{env: "CACHE_IT_REDIS", image: "redis:7-alpine", port: "6379"}
+)
+
+func TestMain(m *testing.M) {
+	servers := []*server{memcached, redis}
+	for _, s := range servers {
+		s.start()
+	}
+	code := m.Run()
+	for _, s := range servers {
+		s.stop()
+	}
+	os.Exit(code)
+}
+
+// start sets the address of s, running it in a container if not given
+func (s *server) start() {
+	if s.addr = os.Getenv(s.env); s.addr != "" {
+		return
+	}
+	if _, err := exec.LookPath("docker"); err != nil {
+		s.skip = fmt.Sprintf("%s not set and docker not found", s.env)
+		return
+	}
+	args := []string{"run", "-d", "--rm", "-p", "127.0.0.1::" + s.port, s.image}
+	out, err := exec.Command("docker", args...).Output()
+	if err != nil {
+		s.skip = fmt.Sprintf("docker run %s: %v", s.image, err)
+		return
+	}
+	s.container = strings.TrimSpace(string(out))
+	out, err = exec.Command("docker", "port", s.container, s.port+"/tcp").Output()
+	if err != nil {
+		s.skip = fmt.Sprintf("docker port %s: %v", s.image, err)
+		return
+	}
+	addr := strings.TrimSpace(strings.SplitN(string(out), "\n", 2)[0])
+	if err := s.wait(addr); err != nil {
+		s.skip = fmt.Sprintf("%s not ready: %v", s.image, err)
+		return
+	}
+	s.addr = addr
+}
+
+// wait waits for the server at addr to be ready
+func (s *server) wait(addr string) error {
+	deadline := time.Now().Add(startTimeout)
+	for {
+		conn, err := net.DialTimeout("tcp", addr, time.Second)
+		if err == nil {
+			conn.Close()
+		}
+		if err == nil || time.Now().After(deadline) {
+			return err
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// stop removes the container of s, if any
+func (s *server) stop() {
+	if s.container != "" {
+		_ = exec.Command("docker", "rm", "-f", s.container).Run()
+	}
+}
+
+// require returns the address of s, skipping t if there is none
+func (s *server) require(t *testing.T) string {
+	t.Helper()
+	if s.addr == "" {
+		t.Skip(s.skip)
+	}
+	return s.addr
+}
+
+// uniqueID returns an id not used by earlier runs against the same servers
+func uniqueID(name string) string {
+	return fmt.Sprintf("it-%s-%d", name, time.Now().UnixNano())
+}
@@ -0,0 +1,66 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	resource "github.com/hieunmce/cache"
+	"github.com/hieunmce/cache/invalidation"
+)
+
+func TestStreamInvalidator(t *testing.T) {
+	addr := redis.require(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		invalidate func(fc *resource.FetchCache, id string)
+	}{
+		{
+			name:       "success broadcast clear",
+			invalidate: func(fc *resource.FetchCache, id string) { fc.Clear(id) },
+		},
+		{
+			name:       "success broadcast flush",
+			invalidate: func(fc *resource.FetchCache, id string) { fc.Flush() },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream := invalidation.NewRedisStream(addr, uniqueID("invalidations"))
+			defer stream.Close()
+			id := uniqueID("model")
+			origin := &originFetcher{}
+			caches := []*resource.FetchCache{
+				resource.NewCache(origin, resource.WithInvalidator(invalidation.New(stream))),
+				resource.NewCache(origin, resource.WithInvalidator(invalidation.New(stream))),
+			}
+			defer func() {
+				for _, fc := range caches {
+					_ = fc.Close(ctx)
+				}
+			}()
+			for _, fc := range caches {
+				if _, err := fc.Fetch(ctx, id); err != nil {
+					t.Fatalf("FetchCache.Fetch() error = %v", err)
+				}
+			}
+
+			tt.invalidate(caches[0], id)
+			deadline := time.Now().Add(eventually)
+			for {
+				if _, _, found := caches[1].GetWithExpiration(id); !found {
+					break
+				}
+				if time.Now().After(deadline) {
+					t.Fatalf("FetchCache.GetWithExpiration() expect %v invalidated by the other cache", id)
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	resource "github.com/hieunmce/cache"
+	"github.com/hieunmce/cache/memcache"
+)
+
+// originFetcher is the origin of the models, counting its calls
+type originFetcher struct {
+	calls int32
+	delay time.Duration
+}
+
+func (f *originFetcher) Fetch(ctx context.Context, id string) (*resource.Model, error) {
+	atomic.AddInt32(&f.calls, 1)
+	time.Sleep(f.delay)
+	return &resource.Model{Name: id, Data: []byte("data of " + id)}, nil
+}
+
+func TestL2(t *testing.T) {
+	store := memcache.New(memcached.require(t))
+	defer store.Close()
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		opts      []resource.Option
+		parallel  bool
+		wantCalls int32
+	}{
+		{
+			name:      "success share fetched models",
+			wantCalls: 1,
+		},
+		{
+			name:      "success share fetches with L2 lock",
+			opts:      []resource.Option{resource.WithL2Lock(5*time.Second, 10*time.Millisecond)},
+			parallel:  true,
+			wantCalls: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id := uniqueID("l2")
+			origin := &originFetcher{delay: 50 * time.Millisecond}
+			opts := append([]resource.Option{resource.WithL2(store, resource.JSONCodec{})}, tt.opts...)
+			caches := []*resource.FetchCache{resource.NewCache(origin, opts...), resource.NewCache(origin, opts...)}
+
+			var wg sync.WaitGroup
+			sources := make([]resource.Source, len(caches))
+			for n, fc := range caches {
+				fetch := func(n int, fc *resource.FetchCache) {
+					defer wg.Done()
+					model, src, err := fc.FetchWithSource(ctx, id)
+					if err != nil || model.Name != id {
+						t.Errorf("FetchCache.FetchWithSource() expect model = %v, have %v, %v", id, model, err)
+					}
+					sources[n] = src
+				}
+				wg.Add(1)
+				if tt.parallel {
+					go fetch(n, fc)
+				} else {
+					fetch(n, fc)
+				}
+			}
+			wg.Wait()
+
+			if calls := atomic.LoadInt32(&origin.calls); calls != tt.wantCalls {
+				t.Errorf("Fetcher.Fetch() expect calls = %v, have %v", tt.wantCalls, calls)
+			}
+			if !tt.parallel && sources[1] != resource.SourceL2 {
+				t.Errorf("FetchCache.FetchWithSource() expect source = %v, have %v", resource.SourceL2, sources[1])
+			}
+			for _, fc := range caches {
+				_ = fc.Close(ctx)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package resource
+
+import "context"
+
+// InvalidateSet clears ids, and the ids depending on them, atomically: a
+// concurrent Fetch observes either all of them cached or none, and fetches
+// of them in flight complete before the set is cleared so none of their
+// results outlives it. The actor of ctx is recorded, see WithAuditHook.
+func (fc *FetchCache) InvalidateSet(ctx context.Context, ids ...string) {
+	seen := make(map[string]struct{})
+	var all []string
+	for _, id := range ids {
+		for _, id := range fc.deps.closure(fc.canonical(id)) {
+			if _, ok := seen[id]; !ok {
+				seen[id] = struct{}{}
+				all = append(all, id)
+			}
+		}
+	}
+	keys := make([]string, len(all))
+	for i, id := range all {
+		keys[i] = fc.key(id)
+	}
+	if fc.opts.cancelOnClear {
+		for _, key := range keys {
+			fc.inflight.cancel(key)
+		}
+	}
+	for _, key := range keys {
+		fc.inflight.invalidate(key)
+	}
+	unlock := fc.lockKeys(keys)
+	defer unlock()
+
+	fc.lockItems()
+	var removed []string
+	for _, key := range keys {
+		fc.writes.cancel(key)
+		if _, found := fc.items.Get(key); found {
+			fc.items.Delete(key)
+			removed = append(removed, key)
+		}
+	}
+	fc.itemsChanged(true)
+	fc.itemsLock.Unlock()
+
+	for _, key := range removed {
+		fc.events.publish(EventEvict, key)
+	}
+	for _, id := range all {
+		if fc.opts.l2 != nil {
+			_ = fc.opts.l2.Delete(context.Background(), id)
+		}
+		fc.broadcast(Invalidation{Key: id})
+	}
+	fc.audit(AuditInvalidateSet, ActorFromContext(ctx), fc.traceID(ctx), ids, len(removed))
+}
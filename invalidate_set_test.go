@@ -0,0 +1,67 @@
+package resource
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFetchCache_InvalidateSet(t *testing.T) {
+	ids := []string{"order", "invoice", "shipment"}
+
+	mockedFetcher := &FetcherMock{
+		FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+			return &Model{Name: id}, nil
+		},
+	}
+	fc := NewCache(mockedFetcher)
+	fc.AddDependency("summary", "order")
+	for _, id := range append(ids, "summary", "other") {
+		if _, err := fc.Fetch(context.Background(), id); err != nil {
+			t.Fatalf("FetchCache.Fetch() error = %v", err)
+		}
+	}
+
+	// readers check that the set is never observed partially invalidated
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				fc.itemsLock.RLock()
+				cached := 0
+				for _, id := range ids {
+					if _, found := fc.items.Get(id); found {
+						cached++
+					}
+				}
+				fc.itemsLock.RUnlock()
+				if cached != 0 && cached != len(ids) {
+					t.Errorf("FetchCache.InvalidateSet() expect all or none cached, have %v", cached)
+					return
+				}
+			}
+		}()
+	}
+	time.Sleep(time.Millisecond)
+	fc.InvalidateSet(context.Background(), ids...)
+	close(stop)
+	wg.Wait()
+
+	for _, id := range append(ids, "summary") {
+		if _, found := fc.Entry(id); found {
+			t.Errorf("FetchCache.InvalidateSet() expect %v cleared", id)
+		}
+	}
+	if _, found := fc.Entry("other"); !found {
+		t.Errorf("FetchCache.InvalidateSet() expect other still cached")
+	}
+}
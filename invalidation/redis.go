@@ -0,0 +1,321 @@
+package invalidation
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Const list
+const (
+	defaultRedisTimeout = 500 * time.Millisecond
+	// redisBlock is how long a read waits on the server for new entries
+	// in a XREAD
+	redisBlock = time.Second
+	// redisReadCount is the most entries read at once
+	redisReadCount = 100
+)
+
+// Error list
+var (
+	ErrRedis = errors.New("invalidation: redis error")
+)
+
+// RedisStream is a Stream over a Redis stream, appended by XADD and read by
+// XREAD. The sequence of an entry is its id <ms>-<n> as ms<<16 | n, an n
+// of 65536 or more, only seen past 65536 appends in a millisecond, fails
+// the read.
+type RedisStream struct {
+	// Password authenticates the connections with AUTH if set.
+	Password string
+	// MaxLen trims the stream to about MaxLen entries on every append, no
+	// limit if 0. It must be larger than the appends a reader can miss
+	// while disconnected.
+	MaxLen int
+	// Timeout bounds each command but the blocking reads.
+	Timeout time.Duration
+
+	addr string
+	key  string
+	mu   sync.Mutex
+	idle *redisConn
+}
+
+// NewRedisStream creates a RedisStream of the stream key of the Redis
+// server at "host:port" addr.
+func NewRedisStream(addr, key string) *RedisStream {
+	return &RedisStream{Timeout: defaultRedisTimeout, addr: addr, key: key}
+}
+
+// Append implements Stream.
+func (s *RedisStream) Append(ctx context.Context, data []byte) error {
+	args := []string{"XADD", s.key}
+	if s.MaxLen > 0 {
+		args = append(args, "MAXLEN", "~", strconv.Itoa(s.MaxLen))
+	}
+	_, err := s.do(ctx, append(args, "*", "data", string(data))...)
+	return err
+}
+
+// Last implements Stream with XREVRANGE.
+func (s *RedisStream) Last(ctx context.Context) (uint64, error) {
+	reply, err := s.do(ctx, "XREVRANGE", s.key, "+", "-", "COUNT", "1")
+	if err != nil {
+		return 0, err
+	}
+	entries, err := redisEntries(reply)
+	if err != nil || len(entries) == 0 {
+		return 0, err
+	}
+	return entries[0].seq, nil
+}
+
+// Read implements Stream, on a connection of its own.
+func (s *RedisStream) Read(ctx context.Context, from uint64, fn func(seq uint64, data []byte)) error {
+	c, err := s.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.nc.Close()
+	// closing the connection interrupts the blocking read
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			c.nc.Close()
+		case <-stop:
+		}
+	}()
+	last := "$"
+	if from > 0 {
+		last = redisID(from - 1)
+	}
+	block := strconv.Itoa(int(redisBlock / time.Millisecond))
+	count := strconv.Itoa(redisReadCount)
+	for ctx.Err() == nil {
+		reply, err := c.do(time.Now().Add(redisBlock+s.timeout()), "XREAD", "COUNT", count, "BLOCK", block, "STREAMS", s.key, last)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+		// [[key, entries]], nil when none arrived
+		streams, _ := reply.([]interface{})
+		for _, stream := range streams {
+			kv, ok := stream.([]interface{})
+			if !ok || len(kv) != 2 {
+				return fmt.Errorf("%w: unexpected XREAD reply", ErrRedis)
+			}
+			entries, err := redisEntries(kv[1])
+			if err != nil {
+				return err
+			}
+			for _, e := range entries {
+				last = e.id
+				fn(e.seq, e.data)
+			}
+		}
+	}
+	return ctx.Err()
+}
+
+func (s *RedisStream) timeout() time.Duration {
+	if s.Timeout <= 0 {
+		return defaultRedisTimeout
+	}
+	return s.Timeout
+}
+
+// do runs a command on the idle connection, dialing one if there is none
+func (s *RedisStream) do(ctx context.Context, args ...string) (interface{}, error) {
+	s.mu.Lock()
+	c := s.idle
+	s.idle = nil
+	s.mu.Unlock()
+	if c == nil {
+		var err error
+		if c, err = s.dial(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	deadline := time.Now().Add(s.timeout())
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	reply, err := c.do(deadline, args...)
+	if err != nil && !errors.Is(err, ErrRedis) {
+		c.nc.Close()
+		return nil, err
+	}
+	s.mu.Lock()
+	if s.idle == nil {
+		s.idle, c = c, nil
+	}
+	s.mu.Unlock()
+	if c != nil {
+		c.nc.Close()
+	}
+	return reply, err
+}
+
+func (s *RedisStream) dial(ctx context.Context) (*redisConn, error) {
+	dialCtx, cancel := context.WithTimeout(ctx, s.timeout())
+	defer cancel()
+	var d net.Dialer
+	nc, err := d.DialContext(dialCtx, "tcp", s.addr)
+	if err != nil {
+		return nil, err
+	}
+	c := &redisConn{nc: nc, r: bufio.NewReader(nc)}
+	if s.Password != "" {
+		if _, err := c.do(time.Now().Add(s.timeout()), "AUTH", s.Password); err != nil {
+			nc.Close()
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// Close closes the idle connection.
+func (s *RedisStream) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.idle == nil {
+		return nil
+	}
+	err := s.idle.nc.Close()
+	s.idle = nil
+	return err
+}
+
+// redisEntry is an entry of a Redis stream
+type redisEntry struct {
+	id   string
+	seq  uint64
+	data []byte
+}
+
+// redisEntries returns the entries of a XRANGE or XREAD reply, [[id,
+// [field, value...]]...]
+func redisEntries(reply interface{}) ([]redisEntry, error) {
+	list, _ := reply.([]interface{})
+	entries := make([]redisEntry, 0, len(list))
+	for _, v := range list {
+		e, ok := v.([]interface{})
+		if !ok || len(e) != 2 {
+			return nil, fmt.Errorf("%w: unexpected stream entry", ErrRedis)
+		}
+		id, _ := e[0].(string)
+		seq, err := redisSeq(id)
+		if err != nil {
+			return nil, err
+		}
+		fields, _ := e[1].([]interface{})
+		entry := redisEntry{id: id, seq: seq}
+		for i := 0; i+1 < len(fields); i += 2 {
+			if fields[i] == "data" {
+				value, _ := fields[i+1].(string)
+				entry.data = []byte(value)
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// redisSeq returns the sequence of the entry id
+func redisSeq(id string) (uint64, error) {
+	i := strings.IndexByte(id, '-')
+	if i < 0 {
+		return 0, fmt.Errorf("%w: bad entry id %q", ErrRedis, id)
+	}
+	ms, err := strconv.ParseUint(id[:i], 10, 48)
+	if err != nil {
+		return 0, fmt.Errorf("%w: bad entry id %q", ErrRedis, id)
+	}
+	n, err := strconv.ParseUint(id[i+1:], 10, 16)
+	if err != nil {
+		return 0, fmt.Errorf("%w: entry id %q out of the sequences", ErrRedis, id)
+	}
+	return ms<<16 | n, nil
+}
+
+// redisID returns the entry id of seq
+func redisID(seq uint64) string {
+	return strconv.FormatUint(seq>>16, 10) + "-" + strconv.FormatUint(seq&0xffff, 10)
+}
+
+// redisConn is a connection to a Redis server speaking RESP2
+type redisConn struct {
+	nc net.Conn
+	r  *bufio.Reader
+}
+
+// do sends the command args and returns its reply: a string, an int64, nil
+// or a []interface{} of them, a reply error wraps ErrRedis
+func (c *redisConn) do(deadline time.Time, args ...string) (interface{}, error) {
+	if err := c.nc.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if _, err := io.WriteString(c.nc, b.String()); err != nil {
+		return nil, err
+	}
+	return c.reply()
+}
+
+func (c *redisConn) reply() (interface{}, error) {
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, fmt.Errorf("%w: empty reply", ErrRedis)
+	}
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, fmt.Errorf("%w: %s", ErrRedis, line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$', '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("%w: bad reply %q", ErrRedis, line)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		if line[0] == '$' {
+			buf := make([]byte, n+2)
+			if _, err := io.ReadFull(c.r, buf); err != nil {
+				return nil, err
+			}
+			return string(buf[:n]), nil
+		}
+		values := make([]interface{}, n)
+		for i := range values {
+			if values[i], err = c.reply(); err != nil && !errors.Is(err, ErrRedis) {
+				return nil, err
+			}
+		}
+		return values, nil
+	}
+	return nil, fmt.Errorf("%w: unexpected reply %q", ErrRedis, line)
+}
@@ -0,0 +1,236 @@
+package invalidation
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	resource "github.com/hieunmce/cache"
+)
+
+// fakeRedis is a Redis server supporting AUTH, XADD, XREVRANGE and XREAD
+// of a single stream
+type fakeRedis struct {
+	ln       net.Listener
+	password string
+	mu       sync.Mutex
+	ids      []string
+	data     []string
+	seq      int
+	notify   chan struct{}
+}
+
+func newFakeRedis(t *testing.T, password string) *fakeRedis {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() error = %v", err)
+	}
+	s := &fakeRedis{ln: ln, password: password, notify: make(chan struct{})}
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(c)
+		}
+	}()
+	return s
+}
+
+func (s *fakeRedis) serve(c net.Conn) {
+	defer c.Close()
+	r := bufio.NewReader(c)
+	authed := s.password == ""
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+		switch {
+		case strings.EqualFold(args[0], "AUTH"):
+			if args[1] != s.password {
+				io.WriteString(c, "-WRONGPASS invalid password\r\n")
+				continue
+			}
+			authed = true
+			io.WriteString(c, "+OK\r\n")
+		case !authed:
+			io.WriteString(c, "-NOAUTH Authentication required.\r\n")
+		case args[0] == "XADD":
+			// XADD key [MAXLEN ~ n] * data value
+			s.mu.Lock()
+			s.seq++
+			id := "1700000000000-" + strconv.Itoa(s.seq)
+			s.ids, s.data = append(s.ids, id), append(s.data, args[len(args)-1])
+			close(s.notify)
+			s.notify = make(chan struct{})
+			s.mu.Unlock()
+			fmt.Fprintf(c, "$%d\r\n%s\r\n", len(id), id)
+		case args[0] == "XREVRANGE":
+			s.mu.Lock()
+			if len(s.ids) == 0 {
+				io.WriteString(c, "*0\r\n")
+			} else {
+				io.WriteString(c, "*1\r\n"+s.entry(len(s.ids)-1))
+			}
+			s.mu.Unlock()
+		case args[0] == "XREAD":
+			// XREAD COUNT n BLOCK ms STREAMS key id
+			block, _ := strconv.Atoi(args[4])
+			s.mu.Lock()
+			from := len(s.ids)
+			if args[7] != "$" {
+				for from = 0; from < len(s.ids) && s.ids[from] <= args[7]; from++ {
+				}
+			}
+			if from == len(s.ids) {
+				notify := s.notify
+				s.mu.Unlock()
+				select {
+				case <-notify:
+				case <-time.After(time.Duration(block) * time.Millisecond):
+				}
+				s.mu.Lock()
+			}
+			if from == len(s.ids) {
+				io.WriteString(c, "*-1\r\n")
+			} else {
+				out := fmt.Sprintf("*1\r\n*2\r\n$%d\r\n%s\r\n*%d\r\n", len(args[6]), args[6], len(s.ids)-from)
+				for i := from; i < len(s.ids); i++ {
+					out += s.entry(i)
+				}
+				io.WriteString(c, out)
+			}
+			s.mu.Unlock()
+		}
+	}
+}
+
+// entry returns the RESP of the entry i
+func (s *fakeRedis) entry(i int) string {
+	return fmt.Sprintf("*2\r\n$%d\r\n%s\r\n*2\r\n$4\r\ndata\r\n$%d\r\n%s\r\n", len(s.ids[i]), s.ids[i], len(s.data[i]), s.data[i])
+}
+
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, n)
+	for i := range args {
+		if line, err = r.ReadString('\n'); err != nil {
+			return nil, err
+		}
+		size, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		args[i] = string(buf[:size])
+	}
+	return args, nil
+}
+
+func TestRedisStream(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		auth     string
+		wantErr  error
+	}{
+		{
+			name: "success stream without password",
+		},
+		{
+			name:     "success stream with password",
+			password: "lorem",
+			auth:     "lorem",
+		},
+		{
+			name:     "fail wrong password",
+			password: "lorem",
+			auth:     "ipsum",
+			wantErr:  ErrRedis,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFakeRedis(t, tt.password)
+			defer server.ln.Close()
+			stream := NewRedisStream(server.ln.Addr().String(), "invalidations")
+			stream.Password = tt.auth
+			defer stream.Close()
+			ctx := context.Background()
+
+			if err := stream.Append(ctx, []byte("old")); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("RedisStream.Append() expect error = %v, have %v", tt.wantErr, err)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			last, err := stream.Last(ctx)
+			if err != nil || last != 1700000000000<<16|1 {
+				t.Fatalf("RedisStream.Last() expect %v, have %v, %v", uint64(1700000000000<<16|1), last, err)
+			}
+			for _, data := range []string{"a", "b"} {
+				if err := stream.Append(ctx, []byte(data)); err != nil {
+					t.Fatalf("RedisStream.Append() error = %v", err)
+				}
+			}
+
+			readCtx, cancel := context.WithCancel(ctx)
+			defer cancel()
+			var received []string
+			err = stream.Read(readCtx, last+1, func(seq uint64, data []byte) {
+				received = append(received, string(data))
+				if len(received) == 2 {
+					cancel()
+				}
+			})
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("RedisStream.Read() expect error = %v, have %v", context.Canceled, err)
+			}
+			if len(received) != 2 || received[0] != "a" || received[1] != "b" {
+				t.Errorf("RedisStream.Read() expect [a b], have %v", received)
+			}
+		})
+	}
+}
+
+func TestStreamInvalidator_RedisStream(t *testing.T) {
+	server := newFakeRedis(t, "")
+	defer server.ln.Close()
+	stream := NewRedisStream(server.ln.Addr().String(), "invalidations")
+	defer stream.Close()
+	inv := New(stream)
+
+	received := make(chan string, 1)
+	unsubscribe := inv.Subscribe(func(i resource.Invalidation) {
+		received <- i.Key
+	})
+	defer unsubscribe()
+	if err := inv.Publish(context.Background(), resource.Invalidation{Key: "lorem"}); err != nil {
+		t.Fatalf("StreamInvalidator.Publish() error = %v", err)
+	}
+	select {
+	case key := <-received:
+		if key != "lorem" {
+			t.Errorf("StreamInvalidator.Subscribe() expect lorem, have %v", key)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("StreamInvalidator.Subscribe() expect the invalidation delivered")
+	}
+}
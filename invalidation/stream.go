@@ -0,0 +1,135 @@
+// Package invalidation implements resource.Invalidator over durable
+// message streams such as Redis streams, see RedisStream, NATS JetStream or
+// Kafka, so fleets can broadcast Clear and Flush with at-least-once
+// delivery.
+package invalidation
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	resource "github.com/hieunmce/cache"
+)
+
+// Const list
+const (
+	defaultMinBackoff = 100 * time.Millisecond
+	defaultMaxBackoff = 10 * time.Second
+)
+
+// Stream is an interface for an append-only log of messages numbered by an
+// increasing sequence, starting at 1.
+//
+// With NATS JetStream, Append is a JetStream publish, Read an ordered
+// consumer started at sequence from (deliver new when from is 0) and Last
+// the last sequence of the stream info. With Kafka, a single partition
+// topic is used and the sequence is offset+1.
+type Stream interface {
+	// Append stores data at the end of the stream, it returns once the
+	// message is durable.
+	Append(ctx context.Context, data []byte) error
+	// Read calls fn in order for every message with a sequence of at least
+	// from, or only for messages appended from now on when from is 0. It
+	// returns when ctx is done or the connection fails.
+	Read(ctx context.Context, from uint64, fn func(seq uint64, data []byte)) error
+	// Last returns the sequence of the last message, 0 if there is none.
+	Last(ctx context.Context) (uint64, error)
+}
+
+// StreamInvalidator is a resource.Invalidator over a Stream.
+//
+// A subscription reads from the position of the stream when it started.
+// When reading fails it reconnects with exponential backoff and resumes
+// after the last message delivered, so no invalidation published since
+// Subscribe is lost while the connection is down.
+type StreamInvalidator struct {
+	// MinBackoff is the delay before the first reconnect.
+	MinBackoff time.Duration
+	// MaxBackoff caps the delay between reconnects.
+	MaxBackoff time.Duration
+
+	stream Stream
+}
+
+// New creates a StreamInvalidator over stream.
+func New(stream Stream) *StreamInvalidator {
+	return &StreamInvalidator{
+		MinBackoff: defaultMinBackoff,
+		MaxBackoff: defaultMaxBackoff,
+		stream:     stream,
+	}
+}
+
+// Publish implements resource.Invalidator.
+func (s *StreamInvalidator) Publish(ctx context.Context, inv resource.Invalidation) error {
+	data, err := json.Marshal(inv)
+	if err != nil {
+		return err
+	}
+	return s.stream.Append(ctx, data)
+}
+
+// Subscribe implements resource.Invalidator, the position of the stream is
+// read before it returns, or by the subscription once it connects should
+// that fail.
+func (s *StreamInvalidator) Subscribe(fn func(resource.Invalidation)) func() {
+	ctx, cancel := context.WithCancel(context.Background())
+	next, _ := s.position(ctx)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		s.run(ctx, fn, next)
+	}()
+
+	return func() {
+		cancel()
+		wg.Wait()
+	}
+}
+
+// position returns the sequence following the last message of the stream
+func (s *StreamInvalidator) position(ctx context.Context) (uint64, error) {
+	last, err := s.stream.Last(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return last + 1, nil
+}
+
+// run reads the messages from next, from the position of the stream once
+// connected if 0
+func (s *StreamInvalidator) run(ctx context.Context, fn func(resource.Invalidation), next uint64) {
+	backoff := s.MinBackoff
+	for {
+		delivered := false
+		var err error
+		if next == 0 {
+			next, err = s.position(ctx)
+		}
+		if err == nil {
+			_ = s.stream.Read(ctx, next, func(seq uint64, data []byte) {
+				next, delivered = seq+1, true
+				var inv resource.Invalidation
+				if err := json.Unmarshal(data, &inv); err != nil {
+					return
+				}
+				fn(inv)
+			})
+		}
+		if delivered {
+			backoff = s.MinBackoff
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > s.MaxBackoff {
+			backoff = s.MaxBackoff
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package invalidation
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	resource "github.com/hieunmce/cache"
+)
+
+var errDisconnected = errors.New("disconnected")
+
+// memStream is an in-memory Stream whose readers can be disconnected
+type memStream struct {
+	mu       sync.Mutex
+	messages [][]byte
+	notify   chan struct{}
+	drop     chan struct{}
+}
+
+func newMemStream() *memStream {
+	return &memStream{notify: make(chan struct{}), drop: make(chan struct{})}
+}
+
+func (s *memStream) Append(ctx context.Context, data []byte) error {
+	s.mu.Lock()
+	s.messages = append(s.messages, data)
+	close(s.notify)
+	s.notify = make(chan struct{})
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *memStream) Last(ctx context.Context) (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return uint64(len(s.messages)), nil
+}
+
+func (s *memStream) disconnect() {
+	s.mu.Lock()
+	close(s.drop)
+	s.drop = make(chan struct{})
+	s.mu.Unlock()
+}
+
+func (s *memStream) Read(ctx context.Context, from uint64, fn func(seq uint64, data []byte)) error {
+	s.mu.Lock()
+	if from == 0 {
+		from = uint64(len(s.messages)) + 1
+	}
+	s.mu.Unlock()
+	for {
+		s.mu.Lock()
+		msgs, notify, drop := s.messages, s.notify, s.drop
+		s.mu.Unlock()
+		for ; from <= uint64(len(msgs)); from++ {
+			fn(from, msgs[from-1])
+		}
+		select {
+		case <-notify:
+		case <-drop:
+			return errDisconnected
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func TestStreamInvalidator(t *testing.T) {
+	tests := []struct {
+		name    string
+		publish func(inv *StreamInvalidator, stream *memStream)
+	}{
+		{
+			name: "success replay the messages published while reconnecting",
+			publish: func(inv *StreamInvalidator, stream *memStream) {
+				ctx := context.Background()
+				_ = inv.Publish(ctx, resource.Invalidation{Key: "a"})
+				time.Sleep(10 * time.Millisecond)
+				stream.disconnect()
+				_ = inv.Publish(ctx, resource.Invalidation{Key: "b"})
+				_ = inv.Publish(ctx, resource.Invalidation{Key: "c"})
+			},
+		},
+		{
+			name: "success replay the messages published before the first delivery",
+			publish: func(inv *StreamInvalidator, stream *memStream) {
+				ctx := context.Background()
+				stream.disconnect()
+				_ = inv.Publish(ctx, resource.Invalidation{Key: "a"})
+				_ = inv.Publish(ctx, resource.Invalidation{Key: "b"})
+				time.Sleep(10 * time.Millisecond)
+				_ = inv.Publish(ctx, resource.Invalidation{Key: "c"})
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream := newMemStream()
+			// published before the subscription, not delivered
+			_ = stream.Append(context.Background(), []byte(`{"Key":"old"}`))
+			inv := New(stream)
+			inv.MinBackoff = 20 * time.Millisecond
+
+			var (
+				mu       sync.Mutex
+				received []string
+			)
+			unsubscribe := inv.Subscribe(func(i resource.Invalidation) {
+				mu.Lock()
+				received = append(received, i.Key)
+				mu.Unlock()
+			})
+			defer unsubscribe()
+			time.Sleep(10 * time.Millisecond)
+			tt.publish(inv, stream)
+
+			deadline := time.Now().Add(time.Second)
+			for time.Now().Before(deadline) {
+				mu.Lock()
+				n := len(received)
+				mu.Unlock()
+				if n >= 3 {
+					break
+				}
+				time.Sleep(5 * time.Millisecond)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if len(received) != 3 || received[0] != "a" || received[1] != "b" || received[2] != "c" {
+				t.Errorf("StreamInvalidator.Subscribe() received = %v, want [a b c]", received)
+			}
+		})
+	}
+}
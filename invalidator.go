@@ -0,0 +1,75 @@
+package resource
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+)
+
+// Invalidation is a Clear, Flush or SetIncidentMode broadcast between
+// cache instances.
+type Invalidation struct {
+	// Key is the cleared id, empty for a Flush.
+	Key string `json:"key,omitempty"`
+	// Flush is true when the whole cache was flushed.
+	Flush bool `json:"flush,omitempty"`
+	// Incident is true when the incident mode was set to MaxStale.
+	Incident bool     `json:"incident,omitempty"`
+	MaxStale Duration `json:"max_stale,omitempty"`
+	// Origin identifies the instance which broadcast the invalidation.
+	Origin string `json:"origin"`
+}
+
+// Invalidator is an interface for a transport broadcasting invalidations
+// between the cache instances of a fleet, see WithInvalidator.
+type Invalidator interface {
+	// Publish broadcasts inv to every subscribed instance.
+	Publish(ctx context.Context, inv Invalidation) error
+	// Subscribe calls fn for every invalidation broadcast until the
+	// returned function is called. Reconnecting and redelivering after
+	// failures is up to the Invalidator.
+	Subscribe(fn func(Invalidation)) (unsubscribe func())
+}
+
+// WithInvalidator broadcasts Clear and Flush to the other caches through
+// inv and applies theirs to this cache. Invalidations are delivered at
+// least once, applying one twice is harmless.
+func WithInvalidator(inv Invalidator) Option {
+	return func(o *options) {
+		o.invalidator = inv
+	}
+}
+
+// broadcast publishes inv to the other instances, if any
+func (fc *FetchCache) broadcast(inv Invalidation) {
+	if fc.opts.invalidator == nil {
+		return
+	}
+	inv.Origin = fc.instance
+	_ = fc.opts.invalidator.Publish(context.Background(), inv)
+}
+
+// invalidate applies an invalidation broadcast by another instance
+func (fc *FetchCache) invalidate(inv Invalidation) {
+	if inv.Origin == fc.instance {
+		return
+	}
+	if inv.Incident {
+		fc.setIncidentMode(time.Duration(inv.MaxStale))
+		return
+	}
+	if inv.Flush {
+		removed := fc.flushitems()
+		fc.audit(AuditInvalidation, inv.Origin, "", nil, removed)
+		return
+	}
+	removed := fc.clear(inv.Key, false)
+	fc.audit(AuditInvalidation, inv.Origin, "", []string{inv.Key}, removed)
+}
+
+func newInstanceID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
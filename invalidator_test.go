@@ -0,0 +1,94 @@
+package resource
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+// localInvalidator delivers invalidations synchronously to all subscribers
+type localInvalidator struct {
+	mu   sync.Mutex
+	subs map[int]func(Invalidation)
+	next int
+}
+
+func (l *localInvalidator) Publish(ctx context.Context, inv Invalidation) error {
+	l.mu.Lock()
+	subs := make([]func(Invalidation), 0, len(l.subs))
+	for _, fn := range l.subs {
+		subs = append(subs, fn)
+	}
+	l.mu.Unlock()
+	for _, fn := range subs {
+		fn(inv)
+	}
+	return nil
+}
+
+func (l *localInvalidator) Subscribe(fn func(Invalidation)) func() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.subs == nil {
+		l.subs = make(map[int]func(Invalidation))
+	}
+	id := l.next
+	l.next++
+	l.subs[id] = fn
+	return func() {
+		l.mu.Lock()
+		delete(l.subs, id)
+		l.mu.Unlock()
+	}
+}
+
+func TestFetchCache_Clear_Invalidator(t *testing.T) {
+	mockedFetcher := &FetcherMock{
+		FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+			return &Model{Name: id}, nil
+		},
+	}
+
+	tests := []struct {
+		name          string
+		do            func(fc *FetchCache)
+		wantItemCount int
+	}{
+		{
+			name:          "success clear key on every instance",
+			do:            func(fc *FetchCache) { fc.Clear("a") },
+			wantItemCount: 1,
+		},
+		{
+			name:          "success flush every instance",
+			do:            func(fc *FetchCache) { fc.Flush() },
+			wantItemCount: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inv := &localInvalidator{}
+			caches := []*FetchCache{
+				NewCache(mockedFetcher, WithInvalidator(inv)),
+				NewCache(mockedFetcher, WithInvalidator(inv)),
+				NewCache(mockedFetcher, WithInvalidator(inv)),
+			}
+			for _, fc := range caches {
+				_, _ = fc.Fetch(context.Background(), "a")
+				_, _ = fc.Fetch(context.Background(), "b")
+			}
+
+			tt.do(caches[0])
+			for i, fc := range caches {
+				if fc.items.Len() != tt.wantItemCount {
+					t.Errorf("instance %v expect item count = %v, have %v", i, tt.wantItemCount, fc.items.Len())
+				}
+			}
+
+			_ = caches[2].Close(context.Background())
+			if len(inv.subs) != 2 {
+				t.Errorf("FetchCache.Close() expect unsubscribe, have %v subscribers", len(inv.subs))
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package resource
+
+import (
+	"context"
+	"strconv"
+	"sync"
+)
+
+// Key is a cache key of any comparable type, such as an integer, a UUID or
+// a small struct, which encodes itself to the string id the cache and the
+// Fetcher use. Two keys must have the same encoding if and only if they
+// are equal.
+type Key interface {
+	// AppendKey appends the encoding of the key to b and returns it.
+	AppendKey(b []byte) []byte
+}
+
+// IntKey is a Key for integer ids, encoded in base 10.
+type IntKey int64
+
+// AppendKey implements Key.
+func (k IntKey) AppendKey(b []byte) []byte {
+	return strconv.AppendInt(b, int64(k), 10)
+}
+
+// StringKey is a Key for string ids, encoded as is.
+type StringKey string
+
+// AppendKey implements Key.
+func (k StringKey) AppendKey(b []byte) []byte {
+	return append(b, k...)
+}
+
+// keyBufPool holds the buffers keys are encoded in
+var keyBufPool = sync.Pool{
+	New: func() interface{} {
+		b := make([]byte, 0, 64)
+		return &b
+	},
+}
+
+// KeyID returns the string id of k.
+func KeyID(k Key) string {
+	if s, ok := k.(StringKey); ok {
+		return string(s)
+	}
+	buf := keyBufPool.Get().(*[]byte)
+	*buf = k.AppendKey((*buf)[:0])
+	id := string(*buf)
+	keyBufPool.Put(buf)
+	return id
+}
+
+// FetchKey is FetchWithOptions for the id of k, see KeyID.
+func (fc *FetchCache) FetchKey(ctx context.Context, k Key, opts ...FetchOption) (*Model, error) {
+	return fc.FetchWithOptions(ctx, KeyID(k), opts...)
+}
+
+// ClearKey is Clear for the id of k, see KeyID.
+func (fc *FetchCache) ClearKey(k Key) {
+	fc.Clear(KeyID(k))
+}
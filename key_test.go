@@ -0,0 +1,76 @@
+package resource
+
+import (
+	"context"
+	"testing"
+)
+
+// uuidKey is a struct Key as used for UUID types
+type uuidKey [16]byte
+
+func (k uuidKey) AppendKey(b []byte) []byte {
+	const hex = "0123456789abcdef"
+	for _, c := range k {
+		b = append(b, hex[c>>4], hex[c&0xf])
+	}
+	return b
+}
+
+func TestKeyID(t *testing.T) {
+	tests := []struct {
+		name string
+		key  Key
+		want string
+	}{
+		{
+			name: "success int key",
+			key:  IntKey(-42),
+			want: "-42",
+		},
+		{
+			name: "success string key",
+			key:  StringKey("dca76878-a8f6-4ff5-b263-1e8c7e61bc20"),
+			want: "dca76878-a8f6-4ff5-b263-1e8c7e61bc20",
+		},
+		{
+			name: "success struct key",
+			key:  uuidKey{0xdc, 0xa7, 0x68, 0x78},
+			want: "dca76878000000000000000000000000",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := KeyID(tt.key); got != tt.want {
+				t.Errorf("KeyID() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchCache_FetchKey(t *testing.T) {
+	var ids []string
+	mockedFetcher := &FetcherMock{
+		FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+			ids = append(ids, id)
+			return &Model{Name: id}, nil
+		},
+	}
+	fc := NewCache(mockedFetcher)
+
+	for i := 0; i < 3; i++ {
+		model, err := fc.FetchKey(context.Background(), IntKey(7))
+		if err != nil {
+			t.Fatalf("FetchCache.FetchKey() error = %v", err)
+		}
+		if model.Name != "7" {
+			t.Errorf("FetchCache.FetchKey() expect model = 7, have %v", model.Name)
+		}
+	}
+	fc.ClearKey(IntKey(7))
+	if _, err := fc.FetchKey(context.Background(), IntKey(7)); err != nil {
+		t.Fatalf("FetchCache.FetchKey() error = %v", err)
+	}
+	if len(ids) != 2 {
+		t.Errorf("FetchCache.FetchKey() expect 2 fetcher calls, have %v", ids)
+	}
+}
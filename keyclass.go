@@ -0,0 +1,127 @@
+package resource
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// KeyClassifier returns the class of an id, such as "configs" or
+// "artifacts", see WithKeyClassifier.
+type KeyClassifier func(id string) string
+
+// WithKeyClassifier breaks the hits, misses and latencies of Stats down by
+// the class c returns for the id of every fetch, see Stats.Classes, as the
+// hit ratio of the whole cache can hide a class performing badly. c is
+// called on every fetch and should return a handful of classes, the
+// counters of a class are kept for the life of the cache.
+func WithKeyClassifier(c KeyClassifier) Option {
+	return func(o *options) {
+		o.keyClassifier = c
+	}
+}
+
+// ClassStats is the part of Stats of the fetches of a class of ids, see
+// WithKeyClassifier.
+type ClassStats struct {
+	Hits         uint64       `json:"hits"`
+	Misses       uint64       `json:"misses"`
+	StaleHits    uint64       `json:"stale_hits"`
+	HitLatency   LatencyStats `json:"hit_latency"`
+	FetchLatency LatencyStats `json:"fetch_latency"`
+}
+
+// HitRatio returns the fraction of the fetches served from the cache, 0
+// without fetches.
+func (s ClassStats) HitRatio() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// classCounters are the counters of a class behind ClassStats, a nil
+// classCounters counts nothing
+type classCounters struct {
+	hits         uint64 // accessed atomically
+	misses       uint64 // accessed atomically
+	staleHits    uint64 // accessed atomically
+	hitLatency   histogram
+	fetchLatency histogram
+}
+
+func (c *classCounters) hit(latency time.Duration, stale bool) {
+	if c == nil {
+		return
+	}
+	atomic.AddUint64(&c.hits, 1)
+	if stale {
+		atomic.AddUint64(&c.staleHits, 1)
+	}
+	c.hitLatency.observe(latency)
+}
+
+func (c *classCounters) miss(latency time.Duration) {
+	if c == nil {
+		return
+	}
+	atomic.AddUint64(&c.misses, 1)
+	c.fetchLatency.observe(latency)
+}
+
+// keyClasses holds the counters of the classes of WithKeyClassifier
+type keyClasses struct {
+	classify KeyClassifier
+	m        sync.Map // class -> *classCounters
+}
+
+func newKeyClasses(c KeyClassifier) *keyClasses {
+	if c == nil {
+		return nil
+	}
+	return &keyClasses{classify: c}
+}
+
+// get returns the counters of the class of id, nil without
+// WithKeyClassifier
+func (k *keyClasses) get(id string) *classCounters {
+	if k == nil {
+		return nil
+	}
+	class := k.classify(id)
+	if v, ok := k.m.Load(class); ok {
+		return v.(*classCounters)
+	}
+	v, _ := k.m.LoadOrStore(class, &classCounters{})
+	return v.(*classCounters)
+}
+
+// each calls fn with every class and its counters
+func (k *keyClasses) each(fn func(class string, c *classCounters)) {
+	if k == nil {
+		return
+	}
+	k.m.Range(func(class, v interface{}) bool {
+		fn(class.(string), v.(*classCounters))
+		return true
+	})
+}
+
+// stats returns the ClassStats of every class, nil without
+// WithKeyClassifier
+func (k *keyClasses) stats() map[string]ClassStats {
+	if k == nil {
+		return nil
+	}
+	s := map[string]ClassStats{}
+	k.each(func(class string, c *classCounters) {
+		s[class] = ClassStats{
+			Hits:         atomic.LoadUint64(&c.hits),
+			Misses:       atomic.LoadUint64(&c.misses),
+			StaleHits:    atomic.LoadUint64(&c.staleHits),
+			HitLatency:   c.hitLatency.stats(),
+			FetchLatency: c.fetchLatency.stats(),
+		}
+	})
+	return s
+}
@@ -0,0 +1,79 @@
+package resource
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestFetchCache_WithKeyClassifier(t *testing.T) {
+	classify := func(id string) string {
+		return strings.SplitN(id, "/", 2)[0]
+	}
+
+	tests := []struct {
+		name      string
+		ids       []string
+		want      map[string][2]uint64
+		wantRatio map[string]float64
+	}{
+		{
+			name: "success break down hits and misses by class",
+			ids:  []string{"configs/a", "configs/a", "configs/a", "configs/b", "artifacts/x"},
+			want: map[string][2]uint64{
+				"configs":   {2, 2},
+				"artifacts": {0, 1},
+			},
+			wantRatio: map[string]float64{"configs": 0.5, "artifacts": 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					return &Model{Name: id}, nil
+				},
+			}
+			fc := NewCache(mockedFetcher, WithKeyClassifier(classify))
+			for _, id := range tt.ids {
+				fc.Fetch(context.Background(), id)
+			}
+
+			classes := fc.Stats().Classes
+			if len(classes) != len(tt.want) {
+				t.Fatalf("FetchCache.Stats() expect classes = %v, have %v", tt.want, classes)
+			}
+			for class, want := range tt.want {
+				s := classes[class]
+				if s.Hits != want[0] || s.Misses != want[1] || s.FetchLatency.Count != want[1] {
+					t.Errorf("FetchCache.Stats() expect %s hits, misses = %v, have %d, %d", class, want, s.Hits, s.Misses)
+				}
+				if s.HitRatio() != tt.wantRatio[class] {
+					t.Errorf("ClassStats.HitRatio() expect %s = %v, have %v", class, tt.wantRatio[class], s.HitRatio())
+				}
+			}
+
+			fc.StatsDelta()
+			fc.Fetch(context.Background(), "configs/a")
+			delta := fc.StatsDelta().Classes
+			if s := delta["configs"]; s.Hits != 1 || s.Misses != 0 || s.HitLatency.Count != 1 {
+				t.Errorf("FetchCache.StatsDelta() expect configs hits = 1, have %+v", s)
+			}
+			if s := delta["artifacts"]; s.Hits != 0 || s.Misses != 0 {
+				t.Errorf("FetchCache.StatsDelta() expect artifacts unchanged, have %+v", s)
+			}
+		})
+	}
+}
+
+func TestFetchCache_Stats_NoClasses(t *testing.T) {
+	fc := NewCache(&FetcherMock{
+		FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+			return &Model{Name: id}, nil
+		},
+	})
+	fc.Fetch(context.Background(), "configs/a")
+	if classes := fc.Stats().Classes; classes != nil {
+		t.Errorf("FetchCache.Stats() expect classes = nil, have %v", classes)
+	}
+}
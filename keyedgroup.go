@@ -0,0 +1,147 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Error list
+var (
+	ErrTooManyWaiters = errors.New("too many waiters")
+)
+
+// KeyedGroup coalesces the concurrent calls of a key into a single one,
+// the stampede protection of FetchCache without the caching: every Do of a
+// key while its call is in flight waits for that call and is served its
+// result.
+//
+// The call is made with the values of the context of the first Do and is
+// canceled once every Do waiting for it gave up.
+type KeyedGroup struct {
+	calls    uint64 // accessed atomically
+	shared   uint64 // accessed atomically
+	rejected uint64 // accessed atomically
+	timedOut uint64 // accessed atomically
+	// maxWaiters and timeout are those of NewKeyedGroup
+	maxWaiters int
+	timeout    time.Duration
+	mu         sync.Mutex
+	inflight   map[string]*keyedCall
+}
+
+// KeyedGroupStats are the counters of a KeyedGroup.
+type KeyedGroupStats struct {
+	// Calls is the number of calls made, Shared the number of Do served
+	// by the call of another one and Rejected the number of Do failed
+	// with ErrTooManyWaiters.
+	Calls    uint64 `json:"calls"`
+	Shared   uint64 `json:"shared"`
+	Rejected uint64 `json:"rejected"`
+	// TimedOut is the number of calls canceled by the timeout.
+	TimedOut uint64 `json:"timed_out"`
+	// InFlight is the number of calls in flight.
+	InFlight int `json:"in_flight"`
+}
+
+// keyedCall is a call of a KeyedGroup in flight
+type keyedCall struct {
+	key     string
+	done    chan struct{}
+	v       interface{}
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// NewKeyedGroup creates a KeyedGroup. A call of a key is shared by at most
+// maxWaiters Do besides the one making it, the others fail with
+// ErrTooManyWaiters, and is canceled after timeout, no limit for 0.
+func NewKeyedGroup(maxWaiters int, timeout time.Duration) *KeyedGroup {
+	return &KeyedGroup{
+		maxWaiters: maxWaiters,
+		timeout:    timeout,
+		inflight:   make(map[string]*keyedCall),
+	}
+}
+
+// Do calls fn for key unless a call of key is in flight, and returns the
+// result of the call and whether it was shared with another Do. It returns
+// ctx.Err() if ctx is done before the call.
+func (g *KeyedGroup) Do(ctx context.Context, key string, fn func(ctx context.Context) (interface{}, error)) (interface{}, bool, error) {
+	g.mu.Lock()
+	c, shared := g.inflight[key]
+	if shared {
+		if g.maxWaiters > 0 && c.waiters > g.maxWaiters {
+			g.mu.Unlock()
+			atomic.AddUint64(&g.rejected, 1)
+			return nil, false, ErrTooManyWaiters
+		}
+		atomic.AddUint64(&g.shared, 1)
+	} else {
+		callCtx, cancel := context.WithCancel(context.Background())
+		if g.timeout > 0 {
+			callCtx, cancel = context.WithTimeout(context.Background(), g.timeout)
+		}
+		c = &keyedCall{key: key, done: make(chan struct{}), cancel: cancel}
+		g.inflight[key] = c
+		atomic.AddUint64(&g.calls, 1)
+		go g.run(valuesContext{Context: callCtx, values: ctx}, c, fn)
+	}
+	c.waiters++
+	g.mu.Unlock()
+
+	defer g.leave(c)
+	select {
+	case <-c.done:
+		return c.v, shared, c.err
+	case <-ctx.Done():
+		return nil, shared, ctx.Err()
+	}
+}
+
+// Stats returns the counters of the group.
+func (g *KeyedGroup) Stats() KeyedGroupStats {
+	g.mu.Lock()
+	inflight := len(g.inflight)
+	g.mu.Unlock()
+	return KeyedGroupStats{
+		Calls:    atomic.LoadUint64(&g.calls),
+		Shared:   atomic.LoadUint64(&g.shared),
+		Rejected: atomic.LoadUint64(&g.rejected),
+		TimedOut: atomic.LoadUint64(&g.timedOut),
+		InFlight: inflight,
+	}
+}
+
+// run makes the call c
+func (g *KeyedGroup) run(ctx context.Context, c *keyedCall, fn func(ctx context.Context) (interface{}, error)) {
+	v, err := fn(ctx)
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		atomic.AddUint64(&g.timedOut, 1)
+	}
+	g.mu.Lock()
+	if g.inflight[c.key] == c {
+		delete(g.inflight, c.key)
+	}
+	g.mu.Unlock()
+	c.v, c.err = v, err
+	c.cancel()
+	close(c.done)
+}
+
+// leave removes a Do waiting for c, canceling c after the last one so the
+// next Do of its key makes a new call
+func (g *KeyedGroup) leave(c *keyedCall) {
+	g.mu.Lock()
+	c.waiters--
+	if c.waiters == 0 {
+		c.cancel()
+		if g.inflight[c.key] == c {
+			delete(g.inflight, c.key)
+		}
+	}
+	g.mu.Unlock()
+}
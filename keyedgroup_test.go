@@ -0,0 +1,126 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestKeyedGroup_Do(t *testing.T) {
+	var fakeFetchID = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	tests := []struct {
+		name         string
+		maxWaiters   int
+		timeout      time.Duration
+		callers      int
+		wantErr      error
+		wantStats    KeyedGroupStats
+		wantRejected int
+	}{
+		{
+			name:      "success shared",
+			callers:   5,
+			wantStats: KeyedGroupStats{Calls: 1, Shared: 4},
+		},
+		{
+			name:         "failed too many waiters",
+			maxWaiters:   2,
+			callers:      5,
+			wantErr:      ErrTooManyWaiters,
+			wantStats:    KeyedGroupStats{Calls: 1, Shared: 2, Rejected: 2},
+			wantRejected: 2,
+		},
+		{
+			name:      "failed timeout",
+			timeout:   10 * time.Millisecond,
+			callers:   1,
+			wantErr:   context.DeadlineExceeded,
+			wantStats: KeyedGroupStats{Calls: 1, TimedOut: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewKeyedGroup(tt.maxWaiters, tt.timeout)
+			release := make(chan struct{})
+			fn := func(ctx context.Context) (interface{}, error) {
+				select {
+				case <-release:
+					return fakeFetchID, nil
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				}
+			}
+			var (
+				wg       sync.WaitGroup
+				mu       sync.Mutex
+				rejected int
+				errs     []error
+			)
+			for n := 0; n < tt.callers; n++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					v, _, err := g.Do(context.Background(), fakeFetchID, fn)
+					mu.Lock()
+					defer mu.Unlock()
+					switch {
+					case errors.Is(err, ErrTooManyWaiters):
+						rejected++
+					case err != nil:
+						errs = append(errs, err)
+					case v != fakeFetchID:
+						t.Errorf("KeyedGroup.Do() expect %v, have %v", fakeFetchID, v)
+					}
+				}()
+				// the first Do makes the call, the others join it
+				for g.Stats().InFlight == 0 && tt.timeout == 0 {
+					time.Sleep(time.Millisecond)
+				}
+			}
+			if tt.timeout == 0 {
+				for {
+					s := g.Stats()
+					if s.Shared+s.Rejected == uint64(tt.callers-1) {
+						break
+					}
+					time.Sleep(time.Millisecond)
+				}
+				close(release)
+			}
+			wg.Wait()
+
+			if rejected != tt.wantRejected {
+				t.Errorf("KeyedGroup.Do() expect %v rejected, have %v", tt.wantRejected, rejected)
+			}
+			if tt.wantErr != nil && tt.wantErr != ErrTooManyWaiters && (len(errs) != 1 || !errors.Is(errs[0], tt.wantErr)) {
+				t.Errorf("KeyedGroup.Do() expect error = %v, have %v", tt.wantErr, errs)
+			}
+			if s := g.Stats(); s != tt.wantStats {
+				t.Errorf("KeyedGroup.Stats() expect %+v, have %+v", tt.wantStats, s)
+			}
+		})
+	}
+}
+
+func TestKeyedGroup_Do_cancel(t *testing.T) {
+	var fakeFetchID = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	g := NewKeyedGroup(0, 0)
+	canceled := make(chan error, 1)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, _, err := g.Do(ctx, fakeFetchID, func(ctx context.Context) (interface{}, error) {
+		<-ctx.Done()
+		canceled <- ctx.Err()
+		return nil, ctx.Err()
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("KeyedGroup.Do() expect error = %v, have %v", context.DeadlineExceeded, err)
+	}
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Errorf("KeyedGroup.Do() expect the call canceled once every Do gave up")
+	}
+}
@@ -0,0 +1,215 @@
+package resource
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// KeyStats are the statistics of a single key, see FetchCache.KeyStats.
+type KeyStats struct {
+	// Hits is the number of fetches served from the cache.
+	Hits uint64
+	// Misses is the number of fetches which went to the Fetcher.
+	Misses uint64
+	// Refreshes is the number of successful Fetcher calls after the first.
+	Refreshes uint64
+	// FetcherCalls is the number of Fetcher calls, failed ones included.
+	FetcherCalls uint64
+	// LastFetchLatency is how long the last Fetcher call took.
+	LastFetchLatency time.Duration
+	// Cached reports whether the key currently has a cached item.
+	Cached bool
+	// Age is how long ago the cached item was fetched, 0 if not cached.
+	Age time.Duration
+	// Failures is the number of consecutive failed fetches and RetryAt,
+	// when not zero, the end of their backoff, see WithFailureBackoff, or
+	// of the RetryAfter of a RateLimitedError.
+	Failures int
+	RetryAt  time.Time
+	// Churn is the fraction of the last ChurnSamples refreshes which
+	// changed the model, see WithChurnTracking.
+	Churn        float64
+	ChurnSamples int
+}
+
+// accessResolution is the precision of last access times, a hit only
+// writes the time of a key when it moved by more so hits of a hot key
+// mostly just read it
+const accessResolution = 100 * time.Millisecond
+
+// keyStats are the counters of a key, all fields are accessed atomically
+type keyStats struct {
+	hits        uint64
+	misses      uint64
+	loads       uint64
+	calls       uint64
+	lastLatency int64
+	// lastFetch is when the last Fetcher call started in ns
+	lastFetch int64
+	// lastAccess is the time of the last hit in ns
+	lastAccess int64
+	// failures is the number of consecutive failed fetches, retryAt the end
+	// of their backoff in ns and lastErr the errBox of the last one, see
+	// WithFailureBackoff
+	failures int64
+	retryAt  int64
+	lastErr  atomic.Value
+	// staleRetryAt is when the expired item may be refreshed again after a
+	// failed refresh in ns, see WithStaleRetry
+	staleRetryAt int64
+	// churn has a bit set for every refresh which changed the model among
+	// the last churnSamples, see WithChurnTracking
+	churn        uint64
+	churnSamples int64
+}
+
+// keyStatsSweep is the number of keys with stats past which the stats of
+// the keys not cached are dropped, so fetches of ids which are never cached,
+// such as a scan of missing ids, don't grow the stats without bound
+const keyStatsSweep = 4096
+
+// keyStatsSweepBatch is the number of keys a fetch adding a key past
+// keyStatsSweep sweeps, so no fetch pays for a sweep of every key
+const keyStatsSweepBatch = 64
+
+// keyStatsMap holds the keyStats of every key seen
+type keyStatsMap struct {
+	m sync.Map
+	// n is the number of keys and next the number past which they are
+	// swept again, twice the keys left by the last sweep
+	n, next  int64 // accessed atomically
+	sweeping int32 // accessed atomically
+	// swept and kept are the keys seen and left by the batches of the
+	// current sweep, a key seen by several batches is counted by each,
+	// owned by the batch holding sweeping
+	swept, kept int64
+	// cached reports whether key has a cached item, nil disables sweeps
+	cached func(key string) bool
+}
+
+func (s *keyStatsMap) get(id string) *keyStats {
+	if ks, ok := s.m.Load(id); ok {
+		return ks.(*keyStats)
+	}
+	ks, loaded := s.m.LoadOrStore(id, &keyStats{})
+	if !loaded {
+		n := atomic.AddInt64(&s.n, 1)
+		if n > keyStatsSweep && n > atomic.LoadInt64(&s.next) {
+			s.sweep()
+		}
+	}
+	return ks.(*keyStats)
+}
+
+// sweep drops the stats of up to keyStatsSweepBatch keys not cached and not
+// backing off, unless another batch is running. The sweep ends once its
+// batches saw as many keys as there are.
+func (s *keyStatsMap) sweep() {
+	if s.cached == nil || !atomic.CompareAndSwapInt32(&s.sweeping, 0, 1) {
+		return
+	}
+	defer atomic.StoreInt32(&s.sweeping, 0)
+	now, seen := time.Now().UnixNano(), 0
+	s.m.Range(func(key, v interface{}) bool {
+		if atomic.LoadInt64(&v.(*keyStats).retryAt) <= now && !s.cached(key.(string)) {
+			s.remove(key.(string))
+		} else {
+			s.kept++
+		}
+		seen++
+		return seen < keyStatsSweepBatch
+	})
+	s.swept += int64(seen)
+	if seen < keyStatsSweepBatch || s.swept >= atomic.LoadInt64(&s.n) {
+		atomic.StoreInt64(&s.next, 2*s.kept)
+		s.swept, s.kept = 0, 0
+	}
+}
+
+func (s *keyStatsMap) hit(id string, now time.Time) {
+	ks := s.get(id)
+	atomic.AddUint64(&ks.hits, 1)
+	if n := now.UnixNano(); n-atomic.LoadInt64(&ks.lastAccess) >= int64(accessResolution) {
+		atomic.StoreInt64(&ks.lastAccess, n)
+	}
+}
+
+func (s *keyStatsMap) miss(id string) {
+	atomic.AddUint64(&s.get(id).misses, 1)
+}
+
+func (s *keyStatsMap) fetched(id string, start time.Time, latency time.Duration, err error) {
+	ks := s.get(id)
+	atomic.StoreInt64(&ks.lastFetch, start.UnixNano())
+	atomic.StoreInt64(&ks.lastLatency, int64(latency))
+	atomic.AddUint64(&ks.calls, 1)
+	if err == nil {
+		atomic.AddUint64(&ks.loads, 1)
+	}
+}
+
+// hits returns the number of hits of id
+func (s *keyStatsMap) hits(id string) uint64 {
+	if ks, ok := s.m.Load(id); ok {
+		return atomic.LoadUint64(&ks.(*keyStats).hits)
+	}
+	return 0
+}
+
+// lastAccess returns the time of the last hit of id in ns, 0 if none
+func (s *keyStatsMap) lastAccess(id string) int64 {
+	if ks, ok := s.m.Load(id); ok {
+		return atomic.LoadInt64(&ks.(*keyStats).lastAccess)
+	}
+	return 0
+}
+
+func (s *keyStatsMap) remove(id string) {
+	if _, loaded := s.m.LoadAndDelete(id); loaded {
+		atomic.AddInt64(&s.n, -1)
+	}
+}
+
+func (s *keyStatsMap) reset() {
+	s.m.Range(func(key, _ interface{}) bool {
+		s.remove(key.(string))
+		return true
+	})
+}
+
+// KeyStats returns the statistics of key id and false if the key was never
+// fetched. Statistics are kept across Clear but dropped when the key is
+// evicted or the cache is flushed, and once thousands of keys have some,
+// those of the keys not cached nor backing off are dropped.
+func (fc *FetchCache) KeyStats(id string) (KeyStats, bool) {
+	id = fc.key(fc.canonical(id))
+	v, ok := fc.stats.m.Load(id)
+	if !ok {
+		return KeyStats{}, false
+	}
+	ks := v.(*keyStats)
+	s := KeyStats{
+		Hits:             atomic.LoadUint64(&ks.hits),
+		Misses:           atomic.LoadUint64(&ks.misses),
+		FetcherCalls:     atomic.LoadUint64(&ks.calls),
+		LastFetchLatency: time.Duration(atomic.LoadInt64(&ks.lastLatency)),
+	}
+	s.Failures = int(atomic.LoadInt64(&ks.failures))
+	if retryAt := atomic.LoadInt64(&ks.retryAt); retryAt != 0 && (s.Failures > 0 || retryAt > time.Now().UnixNano()) {
+		s.RetryAt = time.Unix(0, retryAt)
+	}
+	if loads := atomic.LoadUint64(&ks.loads); loads > 1 {
+		s.Refreshes = loads - 1
+	}
+	s.Churn, s.ChurnSamples = ks.churnRate()
+
+	fc.rlockItems()
+	i, found := fc.items.Get(id)
+	fc.itemsLock.RUnlock()
+	if found && !i.expired() {
+		s.Cached = true
+		s.Age = i.age()
+	}
+	return s, true
+}
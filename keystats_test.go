@@ -0,0 +1,178 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFetchCache_KeyStats(t *testing.T) {
+	var (
+		fakeFetchID     = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+		notExistModelID = "5634aeed-2106-43de-ab7d-c0ad4b1e195e"
+	)
+
+	mockedFetcher := &FetcherMock{
+		FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+			time.Sleep(time.Millisecond)
+			if id == fakeFetchID {
+				return &Model{Name: "lorem"}, nil
+			}
+
+			return nil, errors.New("not found model")
+		},
+	}
+
+	tests := []struct {
+		name       string
+		id         string
+		do         func(fc *FetchCache)
+		want       KeyStats
+		wantFound  bool
+		wantCached bool
+	}{
+		{
+			name: "success count hits misses and refreshes",
+			id:   fakeFetchID,
+			do: func(fc *FetchCache) {
+				_, _ = fc.Fetch(context.Background(), fakeFetchID)
+				_, _ = fc.Fetch(context.Background(), fakeFetchID)
+				fc.Clear(fakeFetchID)
+				_, _ = fc.Fetch(context.Background(), fakeFetchID)
+			},
+			want:       KeyStats{Hits: 1, Misses: 2, Refreshes: 1},
+			wantFound:  true,
+			wantCached: true,
+		},
+		{
+			name: "success count misses of failing key",
+			id:   notExistModelID,
+			do: func(fc *FetchCache) {
+				_, _ = fc.Fetch(context.Background(), notExistModelID)
+				_, _ = fc.Fetch(context.Background(), notExistModelID)
+			},
+			want:      KeyStats{Misses: 2},
+			wantFound: true,
+		},
+		{
+			name: "failed get stats after flush",
+			id:   fakeFetchID,
+			do: func(fc *FetchCache) {
+				_, _ = fc.Fetch(context.Background(), fakeFetchID)
+				fc.Flush()
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fc := NewCache(mockedFetcher)
+			tt.do(fc)
+
+			got, found := fc.KeyStats(tt.id)
+			if found != tt.wantFound {
+				t.Fatalf("FetchCache.KeyStats() found = %v, want %v", found, tt.wantFound)
+			}
+			if got.Hits != tt.want.Hits || got.Misses != tt.want.Misses || got.Refreshes != tt.want.Refreshes {
+				t.Errorf("FetchCache.KeyStats() = %+v, want %+v", got, tt.want)
+			}
+			if got.Cached != tt.wantCached || (got.Age > 0) != tt.wantCached {
+				t.Errorf("FetchCache.KeyStats() expect cached = %v, have %+v", tt.wantCached, got)
+			}
+			if tt.wantFound && got.LastFetchLatency < time.Millisecond {
+				t.Errorf("FetchCache.KeyStats() expect last fetch latency >= 1ms, have %v", got.LastFetchLatency)
+			}
+		})
+	}
+}
+
+func TestKeyStatsMap_LastAccess(t *testing.T) {
+	var (
+		fakeFetchID = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+		start       = time.Unix(1000, 0)
+	)
+
+	tests := []struct {
+		name string
+		hits []time.Duration
+		want time.Duration
+	}{
+		{
+			name: "success first hit",
+			hits: []time.Duration{0},
+		},
+		{
+			name: "success hit within resolution kept",
+			hits: []time.Duration{0, accessResolution / 2},
+		},
+		{
+			name: "success hit after resolution recorded",
+			hits: []time.Duration{0, accessResolution / 2, 2 * accessResolution},
+			want: 2 * accessResolution,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s keyStatsMap
+			for _, d := range tt.hits {
+				s.hit(fakeFetchID, start.Add(d))
+			}
+			if have := s.lastAccess(fakeFetchID); have != start.Add(tt.want).UnixNano() {
+				t.Errorf("keyStatsMap.lastAccess() expect %v, have %v", start.Add(tt.want).UnixNano(), have)
+			}
+		})
+	}
+}
+
+func TestKeyStatsMap_sweep(t *testing.T) {
+	s := keyStatsMap{cached: func(key string) bool { return key == "0" }}
+	for i := 0; i < 4*keyStatsSweepBatch; i++ {
+		s.m.Store(strconv.Itoa(i), &keyStats{})
+		atomic.AddInt64(&s.n, 1)
+	}
+
+	s.sweep()
+	if n := atomic.LoadInt64(&s.n); n > 4*keyStatsSweepBatch-keyStatsSweepBatch+1 {
+		t.Errorf("keyStatsMap.sweep() expect at most %v keys swept, have %v", keyStatsSweepBatch, 4*keyStatsSweepBatch-n)
+	}
+	if next := atomic.LoadInt64(&s.next); next != 0 {
+		t.Errorf("keyStatsMap.sweep() expect next = %v before the sweep ends, have %v", 0, next)
+	}
+	for i := 0; i < 4; i++ {
+		s.sweep()
+	}
+	if n := atomic.LoadInt64(&s.n); n != 1 {
+		t.Errorf("keyStatsMap.sweep() expect keys left = %v, have %v", 1, n)
+	}
+	// the kept key is counted by every batch which saw it
+	if next := atomic.LoadInt64(&s.next); next < 2 || next > 2*5 {
+		t.Errorf("keyStatsMap.sweep() expect next in [%v, %v], have %v", 2, 2*5, next)
+	}
+}
+
+func TestFetchCache_KeyStats_Bounded(t *testing.T) {
+	fakeFetchID := "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	mockedFetcher := &FetcherMock{
+		FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+			if id == fakeFetchID {
+				return &Model{Name: id}, nil
+			}
+			return nil, ErrNotFound
+		},
+	}
+	fc := NewCache(mockedFetcher)
+	defer fc.Close(context.Background())
+	_, _ = fc.Fetch(context.Background(), fakeFetchID)
+
+	for i := 0; i < 3*keyStatsSweep; i++ {
+		_, _ = fc.Fetch(context.Background(), strconv.Itoa(i))
+	}
+	if n := atomic.LoadInt64(&fc.stats.n); n > keyStatsSweep+1 {
+		t.Errorf("FetchCache.KeyStats() expect at most %v keys with stats, have %v", keyStatsSweep+1, n)
+	}
+	if _, ok := fc.KeyStats(fakeFetchID); !ok {
+		t.Errorf("FetchCache.KeyStats() expect stats of cached key kept")
+	}
+}
@@ -0,0 +1,199 @@
+package resource
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// L2Store is an interface for a second level store shared by several
+// caches, e.g. memcached or Redis. It is consulted on misses before the
+// Fetcher, see WithL2.
+type L2Store interface {
+	// Get returns the value stored for key and false if there is none.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set stores value for key for ttl, a ttl of 0 means no expiration.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Delete removes key, deleting a missing key is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// BatchL2 is an interface an L2Store can implement to look several keys up
+// in a single round trip, e.g. with MGET. FetchMany uses it for the ids
+// missing in memory.
+type BatchL2 interface {
+	// GetMany returns the values stored for the keys found.
+	GetMany(ctx context.Context, keys []string) (map[string][]byte, error)
+}
+
+// LockingL2 is an interface an L2Store can implement to lock keys across
+// the processes sharing it, e.g. with Redis SET NX PX or memcached add, see
+// WithL2Lock.
+type LockingL2 interface {
+	// Lock takes the lock of key for ttl and reports whether it got it,
+	// with the token of this holder, a lock held by another process is not
+	// an error.
+	Lock(ctx context.Context, key string, ttl time.Duration) (token string, locked bool, err error)
+	// Unlock releases the lock of key only if it is still held with token,
+	// it may have expired and been taken by another process meanwhile.
+	Unlock(ctx context.Context, key, token string) error
+}
+
+// Codec is an interface that serializes models for an L2Store.
+type Codec interface {
+	Encode(m *Model) ([]byte, error)
+	Decode(data []byte) (*Model, error)
+}
+
+// JSONCodec encodes models as JSON.
+type JSONCodec struct{}
+
+// Encode implements Codec.
+func (JSONCodec) Encode(m *Model) ([]byte, error) {
+	return json.Marshal(m)
+}
+
+// Decode implements Codec.
+func (JSONCodec) Decode(data []byte) (*Model, error) {
+	var m Model
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// WithL2 consults store on misses before the Fetcher and writes fetched
+// models to it with the cache TTL, serialized with codec (JSONCodec if nil).
+// Clear also deletes the key from store, Flush only flushes this cache.
+//
+// The store is best effort: its errors are treated as misses.
+func WithL2(store L2Store, codec Codec) Option {
+	return func(o *options) {
+		if codec == nil {
+			codec = JSONCodec{}
+		}
+		o.l2 = store
+		o.l2Codec = codec
+	}
+}
+
+// l2Fetcher looks ids up in the L2Store before calling next
+type l2Fetcher struct {
+	store L2Store
+	codec Codec
+	next  Fetcher
+	ttl   func() time.Duration
+	// corrupted is called for values failing their checksum, values have
+	// checksums when it is set, see WithChecksums
+	corrupted func()
+	// locker, lockTTL and lockPoll are those of WithL2Lock, locker is nil
+	// without
+	locker   LockingL2
+	lockTTL  time.Duration
+	lockPoll time.Duration
+}
+
+// l2PrefetchKey carries the values of an l2Prefetch
+type l2PrefetchKey struct{}
+
+// l2Prefetch holds the values looked up by a BatchL2 by id, nil for the
+// ids not found
+type l2Prefetch map[string][]byte
+
+// prefetchL2 looks up the ids of FetchMany not fresh in memory with a
+// single BatchL2 call and returns ctx carrying the values for l2Fetcher
+func (fc *FetchCache) prefetchL2(ctx context.Context, ids []string, o fetchOptions) context.Context {
+	batch, ok := fc.opts.l2.(BatchL2)
+	if !ok || o.bypass {
+		return ctx
+	}
+	var missing []string
+	for _, id := range ids {
+		id = fc.canonical(id)
+		key := fc.key(id)
+		if i, found := fc.peekitem(key); found && fc.current(key, i) && o.fresh(i) {
+			continue
+		}
+		if fc.inflight.running(key) {
+			// the fetch will be served the result of that call
+			continue
+		}
+		missing = append(missing, id)
+	}
+	if len(missing) == 0 {
+		return ctx
+	}
+	values, err := batch.GetMany(ctx, missing)
+	if err != nil {
+		return ctx
+	}
+	prefetch := make(l2Prefetch, len(missing))
+	for _, id := range missing {
+		prefetch[id] = values[id]
+	}
+	return context.WithValue(ctx, l2PrefetchKey{}, prefetch)
+}
+
+// get returns the value of id from the prefetch of ctx if it was looked
+// up, and else from the store
+func (lf *l2Fetcher) get(ctx context.Context, id string) ([]byte, bool, error) {
+	if prefetch, ok := ctx.Value(l2PrefetchKey{}).(l2Prefetch); ok {
+		if data, looked := prefetch[id]; looked {
+			return data, data != nil, nil
+		}
+	}
+	return lf.store.Get(ctx, id)
+}
+
+// Fetch implements Fetcher.
+func (lf *l2Fetcher) Fetch(ctx context.Context, id string) (*Model, error) {
+	if origin, _ := ctx.Value(originKey{}).(bool); origin {
+		return lf.fetchNext(ctx, id)
+	}
+	if model, ok := lf.lookup(ctx, id, lf.get); ok {
+		return model, nil
+	}
+	if lf.locker != nil {
+		return lf.fetchLocked(ctx, id)
+	}
+
+	return lf.fetchNext(ctx, id)
+}
+
+// lookup returns the model of id from the store with get
+func (lf *l2Fetcher) lookup(ctx context.Context, id string, get func(ctx context.Context, id string) ([]byte, bool, error)) (*Model, bool) {
+	data, found, err := get(ctx, id)
+	if err != nil || !found {
+		return nil, false
+	}
+	valid := true
+	if lf.corrupted != nil {
+		data, valid = verifyChecksum(data)
+	}
+	if !valid {
+		lf.corrupted()
+		_ = lf.store.Delete(ctx, id)
+		return nil, false
+	}
+	model, err := lf.codec.Decode(data)
+	if err != nil {
+		return nil, false
+	}
+	setSource(ctx, SourceL2)
+	return model, true
+}
+
+// fetchNext fetches id from next and writes it to the store
+func (lf *l2Fetcher) fetchNext(ctx context.Context, id string) (*Model, error) {
+	model, err := lf.next.Fetch(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if data, err := lf.codec.Encode(model); err == nil {
+		if lf.corrupted != nil {
+			data = appendChecksum(data)
+		}
+		_ = lf.store.Set(ctx, id, data, lf.ttl())
+	}
+	return model, nil
+}
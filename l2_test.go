@@ -0,0 +1,139 @@
+package resource
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// mapL2Store is an in-memory L2Store
+type mapL2Store struct {
+	mu   sync.Mutex
+	data map[string][]byte
+	ttls map[string]time.Duration
+}
+
+func newMapL2Store() *mapL2Store {
+	return &mapL2Store{data: make(map[string][]byte), ttls: make(map[string]time.Duration)}
+}
+
+func (s *mapL2Store) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.data[key]
+	return v, ok, nil
+}
+
+func (s *mapL2Store) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data[key] = value
+	s.ttls[key] = ttl
+	return nil
+}
+
+func (s *mapL2Store) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.data, key)
+	return nil
+}
+
+func TestFetchCache_Fetch_L2(t *testing.T) {
+	var (
+		fakeFetchID = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	)
+
+	originCallCount := 0
+	mockedFetcher := &FetcherMock{
+		FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+			originCallCount++
+			return &Model{Name: "lorem"}, nil
+		},
+	}
+	store := newMapL2Store()
+	first := NewCache(mockedFetcher, WithL2(store, nil), WithTTL(time.Minute))
+	second := NewCache(mockedFetcher, WithL2(store, nil))
+
+	if _, err := first.Fetch(context.Background(), fakeFetchID); err != nil {
+		t.Fatalf("FetchCache.Fetch() error = %v", err)
+	}
+	if store.ttls[fakeFetchID] != time.Minute {
+		t.Errorf("FetchCache.Fetch() expect l2 ttl = %v, have %v", time.Minute, store.ttls[fakeFetchID])
+	}
+	got, err := second.Fetch(context.Background(), fakeFetchID)
+	if err != nil || got.Name != "lorem" {
+		t.Errorf("FetchCache.Fetch() = %v, %v, want %v", got, err, "lorem")
+	}
+	if originCallCount != 1 {
+		t.Errorf("FetchCache.Fetch() expect origin call count = %v, have %v", 1, originCallCount)
+	}
+
+	second.Clear(fakeFetchID)
+	if _, found, _ := store.Get(context.Background(), fakeFetchID); found {
+		t.Errorf("FetchCache.Clear() expect key deleted from l2")
+	}
+}
+
+// batchMapL2Store is a mapL2Store implementing BatchL2, counting the calls
+type batchMapL2Store struct {
+	*mapL2Store
+	gets, getManys int32
+}
+
+func (s *batchMapL2Store) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	atomic.AddInt32(&s.gets, 1)
+	return s.mapL2Store.Get(ctx, key)
+}
+
+func (s *batchMapL2Store) GetMany(ctx context.Context, keys []string) (map[string][]byte, error) {
+	atomic.AddInt32(&s.getManys, 1)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	values := make(map[string][]byte)
+	for _, key := range keys {
+		if v, ok := s.data[key]; ok {
+			values[key] = v
+		}
+	}
+	return values, nil
+}
+
+func TestFetchCache_FetchMany_BatchL2(t *testing.T) {
+	var (
+		fakeFetchID     = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+		notExistModelID = "5634aeed-2106-43de-ab7d-c0ad4b1e195e"
+		cachedID        = "3f0c5a1e-0d3b-4c1a-9d8e-6b7a2c4e5f60"
+	)
+	store := &batchMapL2Store{mapL2Store: newMapL2Store()}
+	data, _ := JSONCodec{}.Encode(&Model{Name: "lorem"})
+	store.data[fakeFetchID] = data
+
+	var serviceCallCount int32
+	mockedFetcher := &FetcherMock{
+		FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+			atomic.AddInt32(&serviceCallCount, 1)
+			return &Model{Name: "ipsum"}, nil
+		},
+	}
+	fc := NewCache(mockedFetcher, WithL2(store, nil))
+	defer fc.Close(context.Background())
+	_, _ = fc.Fetch(context.Background(), cachedID)
+	atomic.StoreInt32(&store.gets, 0)
+
+	models, errs := fc.FetchMany(context.Background(), []string{fakeFetchID, notExistModelID, cachedID})
+	if len(errs) != 0 || len(models) != 3 {
+		t.Fatalf("FetchCache.FetchMany() expect 3 models, have %v, %v", models, errs)
+	}
+	if models[fakeFetchID].Name != "lorem" || models[notExistModelID].Name != "ipsum" {
+		t.Errorf("FetchCache.FetchMany() expect L2 and Fetcher models, have %v", models)
+	}
+	if store.getManys != 1 || store.gets != 0 {
+		t.Errorf("FetchCache.FetchMany() expect 1 GetMany and 0 Get, have %v and %v", store.getManys, store.gets)
+	}
+	if serviceCallCount != 2 {
+		t.Errorf("FetchCache.FetchMany() expect service call count = %v, have %v", 2, serviceCallCount)
+	}
+}
@@ -0,0 +1,70 @@
+package resource
+
+import (
+	"context"
+	"time"
+)
+
+// Const list
+const (
+	// l2LockPrefix is prepended to the ids to name their locks in the L2
+	// store, the NUL keeps them apart from the ids
+	l2LockPrefix = "\x00lock:"
+	// defaultL2LockPoll is the poll interval of WithL2Lock by default
+	defaultL2LockPoll = 50 * time.Millisecond
+)
+
+// WithL2Lock extends the stampede protection to the processes sharing the
+// L2 store of WithL2, when it implements LockingL2: on a miss of both
+// tiers, only the process taking the lock of the id calls the Fetcher,
+// the others poll the store every poll for the model it writes. The lock
+// is released after the call, and expires after ttl should its holder
+// die, which should be longer than a Fetcher call. A process whose poll
+// finds the lock free again, e.g. as the fetch of its holder failed,
+// takes it and calls the Fetcher itself, unless the model was written
+// meanwhile. A holder whose lock expired doesn't release the lock taken by
+// another process since. A poll of 0 is 50ms. It has no effect with a
+// store which doesn't implement LockingL2.
+func WithL2Lock(ttl, poll time.Duration) Option {
+	return func(o *options) {
+		if poll <= 0 {
+			poll = defaultL2LockPoll
+		}
+		o.l2LockTTL = ttl
+		o.l2LockPoll = poll
+	}
+}
+
+// fetchLocked fetches id from next under the lock of id in the store, or
+// waits for the process holding it to write the model to the store
+func (lf *l2Fetcher) fetchLocked(ctx context.Context, id string) (*Model, error) {
+	lock := l2LockPrefix + id
+	for {
+		token, locked, err := lf.locker.Lock(ctx, lock, lf.lockTTL)
+		if err != nil {
+			// the store is best effort
+			return lf.fetchNext(ctx, id)
+		}
+		if locked {
+			// the previous holder may have written the model since the
+			// last lookup
+			model, ok := lf.lookup(ctx, id, lf.store.Get)
+			if !ok {
+				model, err = lf.fetchNext(ctx, id)
+			}
+			_ = lf.locker.Unlock(context.Background(), lock, token)
+			return model, err
+		}
+
+		t := time.NewTimer(lf.lockPoll)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return nil, ctx.Err()
+		case <-t.C:
+		}
+		if model, ok := lf.lookup(ctx, id, lf.store.Get); ok {
+			return model, nil
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// lockingL2Store is a mapL2Store implementing LockingL2, onLock is called
+// when a lock is taken
+type lockingL2Store struct {
+	*mapL2Store
+	onLock func()
+}
+
+func (s lockingL2Store) Lock(ctx context.Context, key string, ttl time.Duration) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, held := s.data[key]; held {
+		return "", false, nil
+	}
+	token := strconv.Itoa(len(s.ttls))
+	s.data[key], s.ttls[key] = []byte(token), ttl
+	if s.onLock != nil {
+		s.onLock()
+	}
+	return token, true, nil
+}
+
+func (s lockingL2Store) Unlock(ctx context.Context, key, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if string(s.data[key]) == token {
+		delete(s.data, key)
+	}
+	return nil
+}
+
+func TestFetchCache_Fetch_L2Lock(t *testing.T) {
+	var fakeFetchID = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	tests := []struct {
+		name      string
+		failFirst bool
+		wantCalls int64
+	}{
+		{
+			name:      "success single fetch across processes",
+			wantCalls: 1,
+		},
+		{
+			name:      "success fetch after the holder failed",
+			failFirst: true,
+			wantCalls: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int64
+			release := make(chan struct{})
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					n := atomic.AddInt64(&calls, 1)
+					if n == 1 {
+						<-release
+						if tt.failFirst {
+							return nil, errors.New("unexpected error")
+						}
+					}
+					return &Model{Name: id}, nil
+				},
+			}
+			store := lockingL2Store{mapL2Store: newMapL2Store()}
+			// caches of several processes sharing the store
+			caches := make([]*FetchCache, 3)
+			for n := range caches {
+				caches[n] = NewCache(mockedFetcher, WithL2(store, nil), WithL2Lock(time.Minute, time.Millisecond))
+			}
+
+			var wg sync.WaitGroup
+			errs := make([]error, len(caches))
+			for n, fc := range caches {
+				wg.Add(1)
+				go func(n int, fc *FetchCache) {
+					defer wg.Done()
+					_, errs[n] = fc.Fetch(context.Background(), fakeFetchID)
+				}(n, fc)
+			}
+			for atomic.LoadInt64(&calls) == 0 {
+				time.Sleep(time.Millisecond)
+			}
+			time.Sleep(10 * time.Millisecond)
+			close(release)
+			wg.Wait()
+
+			if have := atomic.LoadInt64(&calls); have != tt.wantCalls {
+				t.Errorf("FetchCache.Fetch() expect %v Fetcher calls, have %v", tt.wantCalls, have)
+			}
+			failed := 0
+			for _, err := range errs {
+				if err != nil {
+					failed++
+				}
+			}
+			if want := int(tt.wantCalls) - 1; failed != want {
+				t.Errorf("FetchCache.Fetch() expect %v failed, have %v", want, errs)
+			}
+			if _, held, _ := store.Get(context.Background(), l2LockPrefix+fakeFetchID); held {
+				t.Errorf("FetchCache.Fetch() expect the lock released")
+			}
+		})
+	}
+}
+
+func TestFetchCache_Fetch_L2Lock_canceled(t *testing.T) {
+	var fakeFetchID = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	store := lockingL2Store{mapL2Store: newMapL2Store()}
+	_, _, _ = store.Lock(context.Background(), l2LockPrefix+fakeFetchID, time.Minute)
+	fc := NewCache(&FetcherMock{}, WithL2(store, nil), WithL2Lock(time.Minute, 0))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := fc.Fetch(ctx, fakeFetchID); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("FetchCache.Fetch() expect error = %v, have %v", context.DeadlineExceeded, err)
+	}
+}
+
+func TestFetchCache_Fetch_L2Lock_written(t *testing.T) {
+	var fakeFetchID = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	mockedFetcher := &FetcherMock{
+		FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+			return &Model{Name: id}, nil
+		},
+	}
+	store := lockingL2Store{mapL2Store: newMapL2Store()}
+	// the previous holder writes the model and releases its lock between
+	// the lookup and the lock
+	store.onLock = func() {
+		data, _ := JSONCodec{}.Encode(&Model{Name: fakeFetchID})
+		store.data[fakeFetchID] = data
+	}
+	fc := NewCache(mockedFetcher, WithL2(store, nil), WithL2Lock(time.Minute, time.Millisecond))
+
+	model, src, err := fc.FetchWithSource(context.Background(), fakeFetchID)
+	if err != nil || model.Name != fakeFetchID {
+		t.Fatalf("FetchCache.FetchWithSource() expect model = %v, have %v, %v", fakeFetchID, model, err)
+	}
+	if src != SourceL2 || len(mockedFetcher.FetchCalls()) != 0 {
+		t.Errorf("FetchCache.FetchWithSource() expect the model written read from %v, have %v and %v Fetcher calls", SourceL2, src, len(mockedFetcher.FetchCalls()))
+	}
+	if _, held, _ := store.Get(context.Background(), l2LockPrefix+fakeFetchID); held {
+		t.Errorf("FetchCache.FetchWithSource() expect the lock released")
+	}
+}
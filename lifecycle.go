@@ -0,0 +1,150 @@
+package resource
+
+import (
+	"context"
+	"sync"
+)
+
+// lifecycle tracks in-flight fetches and background goroutines so the
+// cache can be drained on Close
+type lifecycle struct {
+	mu       sync.Mutex
+	closed   bool
+	inflight int
+	drained  chan struct{}
+	stop     chan struct{}
+	workers  sync.WaitGroup
+}
+
+func newLifecycle() *lifecycle {
+	return &lifecycle{
+		drained: make(chan struct{}),
+		stop:    make(chan struct{}),
+	}
+}
+
+// goBackground runs fn in a goroutine labeled with the operation op, stop
+// is closed when the cache is closed and Close waits for fn to return.
+func (l *lifecycle) goBackground(op string, fn func(stop <-chan struct{})) {
+	l.workers.Add(1)
+	go func() {
+		defer l.workers.Done()
+		withLabels(context.Background(), op, "", func(context.Context) { fn(l.stop) })
+	}()
+}
+
+// context returns a context cancelled once the cache is closed or cancel is
+// called, for the calls of the background goroutines to the Fetcher.
+func (l *lifecycle) context() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		select {
+		case <-l.stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
+
+// enter registers an in-flight fetch, it returns false once closed.
+func (l *lifecycle) enter() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return false
+	}
+	l.inflight++
+	return true
+}
+
+func (l *lifecycle) leave() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inflight--
+	if l.closed && l.inflight == 0 {
+		close(l.drained)
+	}
+}
+
+// close stops accepting fetches, it returns false if already closed.
+func (l *lifecycle) close() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return false
+	}
+	l.closed = true
+	close(l.stop)
+	if l.inflight == 0 {
+		close(l.drained)
+	}
+	return true
+}
+
+// isClosed reports whether close was called
+func (l *lifecycle) isClosed() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.closed
+}
+
+// stopped is closed once background goroutines have returned
+func (l *lifecycle) stopped() <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		l.workers.Wait()
+		close(done)
+	}()
+	return done
+}
+
+// Close stops the cache from accepting new fetches, which fail with
+// ErrClosed from now on, issues the pending batches immediately and waits
+// for the in-flight fetches and background work to finish or ctx to be
+// done. Then the cache stops receiving invalidations, all subscriptions are
+// closed and the cached items are released.
+//
+// Close returns ctx.Err() if ctx is done before the cache is drained, and
+// ErrClosed if the cache was already closed.
+func (fc *FetchCache) Close(ctx context.Context) error {
+	if !fc.life.close() {
+		return ErrClosed
+	}
+	if fc.batch != nil {
+		fc.batch.flush()
+	}
+
+	waits := []<-chan struct{}{fc.life.drained}
+	if !fc.opts.synchronous {
+		waits = append(waits, fc.life.stopped())
+	}
+	var err error
+	for _, done := range waits {
+		select {
+		case <-done:
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+		if err != nil {
+			break
+		}
+	}
+
+	if fc.opts.synchronous && fc.opts.hotKeys != nil {
+		_ = fc.saveHotKeys(ctx)
+	}
+	if fc.unsubscribe != nil {
+		fc.unsubscribe()
+	}
+	fc.events.closeAll()
+	fc.trace.flush()
+	fc.inflight.invalidateAll()
+	fc.strict.closed()
+	fc.lockItems()
+	fc.writes.cancelAll()
+	fc.clearLocked()
+	fc.itemsChanged(true)
+	fc.itemsLock.Unlock()
+	return err
+}
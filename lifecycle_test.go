@@ -0,0 +1,114 @@
+package resource
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestFetchCache_Close(t *testing.T) {
+	var (
+		fakeFetchID = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	)
+
+	tests := []struct {
+		name          string
+		fetchDuration time.Duration
+		closeTimeout  time.Duration
+		wantErr       error
+	}{
+		{
+			name:          "success drain in-flight fetch",
+			fetchDuration: 10 * time.Millisecond,
+			closeTimeout:  time.Second,
+		},
+		{
+			name:          "failed drain before deadline",
+			fetchDuration: 100 * time.Millisecond,
+			closeTimeout:  10 * time.Millisecond,
+			wantErr:       context.DeadlineExceeded,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			started := make(chan struct{})
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					close(started)
+					time.Sleep(tt.fetchDuration)
+					return &Model{Name: "lorem"}, nil
+				},
+			}
+			fc := NewCache(mockedFetcher)
+			sub := fc.Subscribe(10)
+
+			done := make(chan error, 1)
+			go func() {
+				_, err := fc.Fetch(context.Background(), fakeFetchID)
+				done <- err
+			}()
+			<-started
+
+			ctx, cancel := context.WithTimeout(context.Background(), tt.closeTimeout)
+			defer cancel()
+			if err := fc.Close(ctx); err != tt.wantErr {
+				t.Errorf("FetchCache.Close() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err := <-done; err != nil {
+				t.Errorf("FetchCache.Fetch() in-flight error = %v", err)
+			}
+			if _, err := fc.Fetch(context.Background(), fakeFetchID); err != ErrClosed {
+				t.Errorf("FetchCache.Fetch() after close error = %v, want %v", err, ErrClosed)
+			}
+			if err := fc.Close(context.Background()); err != ErrClosed {
+				t.Errorf("FetchCache.Close() twice error = %v, want %v", err, ErrClosed)
+			}
+			for range sub.Events() {
+			}
+		})
+	}
+}
+
+func TestLifecycle_context(t *testing.T) {
+	tests := []struct {
+		name       string
+		close      bool
+		cancel     bool
+		wantCancel bool
+	}{
+		{
+			name:       "success cancelled on close",
+			close:      true,
+			wantCancel: true,
+		},
+		{
+			name:       "success cancelled by cancel",
+			cancel:     true,
+			wantCancel: true,
+		},
+		{
+			name: "success running",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newLifecycle()
+			ctx, cancel := l.context()
+			defer cancel()
+			if tt.close {
+				l.close()
+			}
+			if tt.cancel {
+				cancel()
+			}
+
+			select {
+			case <-ctx.Done():
+			case <-time.After(50 * time.Millisecond):
+			}
+			if cancelled := ctx.Err() != nil; cancelled != tt.wantCancel {
+				t.Errorf("lifecycle.context() expect cancelled = %v, have %v", tt.wantCancel, cancelled)
+			}
+		})
+	}
+}
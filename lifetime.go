@@ -0,0 +1,69 @@
+package resource
+
+import (
+	"context"
+	"time"
+)
+
+// WithMaxLifetime bounds how long an entry lives since it was first
+// loaded, however often it is refreshed: refreshes keep the load time, an
+// entry older than d expires whatever its TTL, it isn't served stale and is
+// fetched again skipping the L2 store, so changes of the origin are picked
+// up eventually. A d of 0 means no limit.
+func WithMaxLifetime(d time.Duration) Option {
+	return func(o *options) {
+		o.maxLifetime = d
+	}
+}
+
+// originKey marks the fetches which skip the L2 store
+type originKey struct{}
+
+// loadedAt returns when i was first loaded
+func (i *item) loadedAt() int64 {
+	if i.Loaded == 0 {
+		return i.Created
+	}
+	return i.Loaded
+}
+
+// outlived reports whether i is older than its max lifetime at now
+func (fc *FetchCache) outlived(i item, now int64) bool {
+	lifetime := i.Lifetime
+	if lifetime == 0 {
+		lifetime = int64(fc.opts.maxLifetime)
+	}
+	return lifetime > 0 && now-i.loadedAt() >= lifetime
+}
+
+// lifetime returns the load time of a model cached for key at now, 0 when
+// it is the same as Created, and its expiration capped by the max lifetime
+// d
+func (fc *FetchCache) lifetime(key, id string, now, expiration int64, d time.Duration) (int64, int64) {
+	if d <= 0 {
+		return 0, expiration
+	}
+	loaded := now
+	fc.rlockItems()
+	old, found := fc.items.Get(key)
+	fc.itemsLock.RUnlock()
+	if found && fc.owns(old, id) && !fc.outlived(old, now) {
+		loaded = old.loadedAt()
+	}
+	if end := loaded + int64(d); expiration == 0 || end < expiration {
+		expiration = end
+	}
+	return loaded, expiration
+}
+
+// originContext returns ctx skipping the L2 store when the item of key
+// outlived its max lifetime or was marked stale, see MarkAllStale
+func (fc *FetchCache) originContext(ctx context.Context, key string) context.Context {
+	if fc.opts.l2 == nil && fc.opts.seed == nil {
+		return ctx
+	}
+	if i, found := fc.peekitem(key); found && (fc.outlived(i, time.Now().UnixNano()) || fc.markedStale(i)) {
+		return context.WithValue(ctx, originKey{}, true)
+	}
+	return ctx
+}
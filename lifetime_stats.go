@@ -0,0 +1,60 @@
+package resource
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// LifetimeStats are the cumulative counters of a cache across restarts:
+// Dump and Snapshot.WriteTo write them along with the entries and Hydrate
+// restores them, so the hit ratio over a long horizon survives deploys.
+// Since is when the counting started.
+type LifetimeStats struct {
+	Since        time.Time `json:"since"`
+	Hits         uint64    `json:"hits"`
+	Misses       uint64    `json:"misses"`
+	FetcherCalls uint64    `json:"fetcher_calls"`
+}
+
+// lifetimeCounters holds the lifetime stats restored by Hydrate and the
+// counters of the cache at that time, which are counted from then on
+type lifetimeCounters struct {
+	mu     sync.Mutex
+	base   LifetimeStats
+	offset LifetimeStats
+}
+
+// counters returns the counters of the cache since it was created
+func (m *metrics) counters() LifetimeStats {
+	return LifetimeStats{
+		Hits:         atomic.LoadUint64(&m.hits),
+		Misses:       atomic.LoadUint64(&m.misses),
+		FetcherCalls: atomic.LoadUint64(&m.fetcherCalls),
+	}
+}
+
+// lifetimeStats returns the restored lifetime stats plus the counts made
+// since
+func (m *metrics) lifetimeStats() LifetimeStats {
+	m.lifetime.mu.Lock()
+	defer m.lifetime.mu.Unlock()
+	c, base, offset := m.counters(), m.lifetime.base, m.lifetime.offset
+	return LifetimeStats{
+		Since:        base.Since,
+		Hits:         base.Hits + c.Hits - offset.Hits,
+		Misses:       base.Misses + c.Misses - offset.Misses,
+		FetcherCalls: base.FetcherCalls + c.FetcherCalls - offset.FetcherCalls,
+	}
+}
+
+// restoreLifetime replaces the lifetime stats by s, the start of the
+// counting is kept if s has none
+func (m *metrics) restoreLifetime(s LifetimeStats) {
+	m.lifetime.mu.Lock()
+	defer m.lifetime.mu.Unlock()
+	if s.Since.IsZero() {
+		s.Since = m.lifetime.base.Since
+	}
+	m.lifetime.base, m.lifetime.offset = s, m.counters()
+}
@@ -0,0 +1,70 @@
+package resource
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestFetchCache_Stats_Lifetime(t *testing.T) {
+	var fakeFetchID = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	mockedFetcher := &FetcherMock{
+		FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+			return &Model{Name: id}, nil
+		},
+	}
+
+	old := NewCache(mockedFetcher)
+	for i := 0; i < 3; i++ {
+		_, _ = old.Fetch(context.Background(), fakeFetchID)
+	}
+	var buf bytes.Buffer
+	if err := old.Dump(&buf, true); err != nil {
+		t.Fatalf("FetchCache.Dump() expect no error, have %v", err)
+	}
+	since := old.Stats().Lifetime.Since
+
+	tests := []struct {
+		name      string
+		doc       string
+		fetches   int
+		wantHits  uint64
+		wantMiss  uint64
+		wantSince bool
+	}{
+		{
+			name:      "success restored from a dump",
+			doc:       buf.String(),
+			fetches:   2,
+			wantHits:  2 + 2,
+			wantMiss:  1,
+			wantSince: true,
+		},
+		{
+			name:     "success document without stats",
+			doc:      `{"version":1,"time":"2020-01-01T00:00:00Z","entries":[]}`,
+			fetches:  2,
+			wantHits: 1,
+			wantMiss: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fc := NewCache(mockedFetcher)
+			if _, err := fc.Hydrate(strings.NewReader(tt.doc)); err != nil {
+				t.Fatalf("FetchCache.Hydrate() expect no error, have %v", err)
+			}
+			for i := 0; i < tt.fetches; i++ {
+				_, _ = fc.Fetch(context.Background(), fakeFetchID)
+			}
+			have := fc.Stats().Lifetime
+			if have.Hits != tt.wantHits || have.Misses != tt.wantMiss {
+				t.Errorf("FetchCache.Stats() expect lifetime hits, misses = %v, %v, have %v, %v", tt.wantHits, tt.wantMiss, have.Hits, have.Misses)
+			}
+			if have.Since.Equal(since) != tt.wantSince {
+				t.Errorf("FetchCache.Stats() expect lifetime since %v = %v, have %v", since, tt.wantSince, have.Since)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package resource
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestWithMaxLifetime(t *testing.T) {
+	fakeFetchID := "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	sleepDuration := 60 * time.Millisecond
+
+	tests := []struct {
+		name             string
+		maxLifetime      time.Duration
+		refresh          bool
+		l2               bool
+		wantName         string
+		serviceCallCount int
+	}{
+		{
+			name:             "success refresh keeps the load time",
+			maxLifetime:      100 * time.Millisecond,
+			refresh:          true,
+			wantName:         "3",
+			serviceCallCount: 3,
+		},
+		{
+			name:             "success no max lifetime",
+			refresh:          true,
+			wantName:         "2",
+			serviceCallCount: 2,
+		},
+		{
+			name:             "success skip L2 after max lifetime",
+			maxLifetime:      100 * time.Millisecond,
+			l2:               true,
+			wantName:         "2",
+			serviceCallCount: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serviceCallCount := 0
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					serviceCallCount++
+					return &Model{Name: strconv.Itoa(serviceCallCount)}, nil
+				},
+			}
+			opts := []Option{WithTTL(0), WithMaxLifetime(tt.maxLifetime)}
+			if tt.l2 {
+				opts = append(opts, WithL2(newMapL2Store(), nil))
+			}
+			fc := NewCache(mockedFetcher, opts...)
+			defer fc.Close(context.Background())
+
+			_, _ = fc.Fetch(context.Background(), fakeFetchID)
+			time.Sleep(sleepDuration)
+			if tt.refresh {
+				_, _ = fc.FetchWithOptions(context.Background(), fakeFetchID, BypassCache())
+			}
+			time.Sleep(sleepDuration)
+			model, err := fc.Fetch(context.Background(), fakeFetchID)
+			if err != nil || model.Name != tt.wantName {
+				t.Errorf("FetchCache.Fetch() expect = %v, have %v, %v", tt.wantName, model, err)
+			}
+			if serviceCallCount != tt.serviceCallCount {
+				t.Errorf("FetchCache.Fetch() expect service call count = %v, have %v", tt.serviceCallCount, serviceCallCount)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package resource
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// LoaderEntry is a model held by the Map of a Loader and when it expires,
+// a zero Expires meaning never.
+type LoaderEntry struct {
+	Model   *Model
+	Expires time.Time
+}
+
+// Map is an interface for the map-like storage a Loader reads through,
+// e.g. an existing LRU. Its methods may be called concurrently, it may
+// drop entries at any time.
+type Map interface {
+	// Get returns the entry of key and false if there is none.
+	Get(key string) (LoaderEntry, bool)
+	// Set stores e for key, replacing the previous entry of key.
+	Set(key string, e LoaderEntry)
+	// Delete removes key, deleting a missing key is not an error.
+	Delete(key string)
+}
+
+// Loader reads through a Map the caller owns, fetching the missing and
+// expired models with a Fetcher, with the stampede protection and refresh
+// ahead of FetchCache but none of its storage: concurrent loads of a key
+// share one Fetcher call and errors aren't cached.
+//
+// A Loader is safe for use by multiple goroutines simultaneously.
+type Loader struct {
+	m            Map
+	f            Fetcher
+	ttl          time.Duration
+	refreshAhead time.Duration
+	// keyLock holds a channel per locked key, closed when unlocked
+	keyLock   sync.Map
+	refreshes sync.WaitGroup
+}
+
+// NewLoader creates a Loader of the models of f stored in m for ttl, 0
+// meaning forever. A refreshAhead above 0 refreshes in the background the
+// entries loaded within refreshAhead of their expiration, serving them
+// meanwhile.
+func NewLoader(m Map, f Fetcher, ttl, refreshAhead time.Duration) *Loader {
+	return &Loader{m: m, f: f, ttl: ttl, refreshAhead: refreshAhead}
+}
+
+// Load returns the model of key from the Map, fetching and storing it if
+// it is missing or has expired.
+func (l *Loader) Load(ctx context.Context, key string) (*Model, error) {
+	now := time.Now()
+	if e, found := l.m.Get(key); found && !loaderExpired(e, now) {
+		if l.refreshAhead > 0 && !e.Expires.IsZero() && e.Expires.Sub(now) < l.refreshAhead {
+			l.refresh(key)
+		}
+		return e.Model, nil
+	}
+
+	if err := l.lock(ctx, key); err != nil {
+		return nil, err
+	}
+	defer l.unlock(key)
+	// another caller may have loaded key meanwhile
+	if e, found := l.m.Get(key); found && !loaderExpired(e, time.Now()) {
+		return e.Model, nil
+	}
+	return l.fetch(ctx, key)
+}
+
+// Forget deletes key from the Map, the next Load fetches it again.
+func (l *Loader) Forget(key string) {
+	l.m.Delete(key)
+}
+
+// Wait blocks until the background refreshes are done or ctx is done.
+func (l *Loader) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		l.refreshes.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// fetch fetches key and stores it, the lock of key must be held
+func (l *Loader) fetch(ctx context.Context, key string) (*Model, error) {
+	model, err := l.f.Fetch(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	e := LoaderEntry{Model: model}
+	if l.ttl > 0 {
+		e.Expires = time.Now().Add(l.ttl)
+	}
+	l.m.Set(key, e)
+	return model, nil
+}
+
+// refresh fetches key in the background unless it is being fetched
+func (l *Loader) refresh(key string) {
+	if _, loaded := l.keyLock.LoadOrStore(key, make(chan struct{})); loaded {
+		return
+	}
+	l.refreshes.Add(1)
+	go func() {
+		defer l.refreshes.Done()
+		defer l.unlock(key)
+		_, _ = l.fetch(context.Background(), key)
+	}()
+}
+
+// lock locks key unless ctx is done first
+func (l *Loader) lock(ctx context.Context, key string) error {
+	for {
+		held, loaded := l.keyLock.LoadOrStore(key, make(chan struct{}))
+		if !loaded {
+			return nil
+		}
+		select {
+		case <-held.(chan struct{}):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// unlock releases key, waking the callers waiting in lock
+func (l *Loader) unlock(key string) {
+	if held, ok := l.keyLock.Load(key); ok {
+		l.keyLock.Delete(key)
+		close(held.(chan struct{}))
+	}
+}
+
+// loaderExpired reports whether e has expired at now
+func loaderExpired(e LoaderEntry, now time.Time) bool {
+	return !e.Expires.IsZero() && !now.Before(e.Expires)
+}
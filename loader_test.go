@@ -0,0 +1,95 @@
+package resource
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// syncMap is a Map over a sync.Map
+type syncMap struct {
+	m sync.Map
+}
+
+func (s *syncMap) Get(key string) (LoaderEntry, bool) {
+	v, ok := s.m.Load(key)
+	if !ok {
+		return LoaderEntry{}, false
+	}
+	return v.(LoaderEntry), true
+}
+
+func (s *syncMap) Set(key string, e LoaderEntry) {
+	s.m.Store(key, e)
+}
+
+func (s *syncMap) Delete(key string) {
+	s.m.Delete(key)
+}
+
+func TestLoader_Load(t *testing.T) {
+	fakeFetchID := "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+
+	tests := []struct {
+		name             string
+		ttl              time.Duration
+		refreshAhead     time.Duration
+		wait             time.Duration
+		serviceCallCount int32
+	}{
+		{
+			name:             "success concurrent loads share fetch",
+			ttl:              time.Minute,
+			serviceCallCount: 1,
+		},
+		{
+			name:             "success expired entry fetched again",
+			ttl:              20 * time.Millisecond,
+			wait:             30 * time.Millisecond,
+			serviceCallCount: 2,
+		},
+		{
+			name:             "success refresh ahead",
+			ttl:              time.Minute,
+			refreshAhead:     2 * time.Minute,
+			serviceCallCount: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var serviceCallCount int32
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					atomic.AddInt32(&serviceCallCount, 1)
+					time.Sleep(5 * time.Millisecond)
+					return &Model{Name: id}, nil
+				},
+			}
+			l := NewLoader(&syncMap{}, mockedFetcher, tt.ttl, tt.refreshAhead)
+
+			var wg sync.WaitGroup
+			for i := 0; i < 10; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					if model, err := l.Load(context.Background(), fakeFetchID); err != nil || model.Name != fakeFetchID {
+						t.Errorf("Loader.Load() = %v, %v", model, err)
+					}
+				}()
+			}
+			wg.Wait()
+			time.Sleep(tt.wait)
+			if _, err := l.Load(context.Background(), fakeFetchID); err != nil {
+				t.Errorf("Loader.Load() error = %v", err)
+			}
+			if err := l.Wait(context.Background()); err != nil {
+				t.Errorf("Loader.Wait() error = %v", err)
+			}
+			if have := atomic.LoadInt32(&serviceCallCount); have != tt.serviceCallCount {
+				t.Errorf("Loader.Load() expect service call count = %v, have %v", tt.serviceCallCount, have)
+			}
+		})
+	}
+}
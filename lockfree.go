@@ -0,0 +1,148 @@
+package resource
+
+import (
+	"math/rand"
+	"sync/atomic"
+)
+
+// lockFreeLoad is the mean number of items per bucket past which a
+// LockFreeStore doubles its buckets
+const lockFreeLoad = 1
+
+// LockFreeStore is an experimental Store whose Get takes no lock, for
+// extreme read concurrency where the read lock of the items becomes the
+// bottleneck: the cache doesn't take its items lock for the hits of a
+// LockFreeStore. Set and Delete copy the bucket of the key and publish the
+// copy atomically, growing rebuilds the table and publishes it the same
+// way, so a Get sees a bucket either before or after a write, never during
+// one; the garbage collector reclaims the old ones once no Get holds them.
+// Writes cost a copy of the bucket and are serialized by the cache as for
+// any Store.
+type LockFreeStore struct {
+	n     int64        // accessed atomically
+	table atomic.Value // *lockFreeTable
+	hash  HashFunc
+}
+
+// lockFreeTable is the immutable bucket array of a LockFreeStore, every
+// bucket holds a lockFreeBucket
+type lockFreeTable struct {
+	buckets []atomic.Value
+	mask    uint64
+}
+
+// lockFreeBucket is an immutable list of the items of a bucket
+type lockFreeBucket []lockFreeEntry
+
+type lockFreeEntry struct {
+	key  string
+	hash uint64
+	item Item
+}
+
+// NewLockFreeStore returns an empty LockFreeStore hashing the keys with
+// hash, FNVHash if nil.
+func NewLockFreeStore(hash HashFunc) *LockFreeStore {
+	if hash == nil {
+		hash = FNVHash
+	}
+	s := &LockFreeStore{hash: hash}
+	s.table.Store(newLockFreeTable(8))
+	return s
+}
+
+func newLockFreeTable(n int) *lockFreeTable {
+	return &lockFreeTable{buckets: make([]atomic.Value, n), mask: uint64(n - 1)}
+}
+
+// bucket returns the bucket of hash
+func (t *lockFreeTable) bucket(hash uint64) lockFreeBucket {
+	b, _ := t.buckets[hash&t.mask].Load().(lockFreeBucket)
+	return b
+}
+
+// Get implements Store, it may be called concurrently with Set and Delete.
+func (s *LockFreeStore) Get(key string) (Item, bool) {
+	h := s.hash(key)
+	for _, e := range s.table.Load().(*lockFreeTable).bucket(h) {
+		if e.hash == h && e.key == key {
+			return e.item, true
+		}
+	}
+	return Item{}, false
+}
+
+// Set implements Store.
+func (s *LockFreeStore) Set(key string, i Item) {
+	h := s.hash(key)
+	t := s.table.Load().(*lockFreeTable)
+	old := t.bucket(h)
+	b := make(lockFreeBucket, len(old), len(old)+1)
+	copy(b, old)
+	for n := range b {
+		if b[n].hash == h && b[n].key == key {
+			b[n].item = i
+			t.buckets[h&t.mask].Store(b)
+			return
+		}
+	}
+	t.buckets[h&t.mask].Store(append(b, lockFreeEntry{key: key, hash: h, item: i}))
+	if n := atomic.AddInt64(&s.n, 1); n > int64(len(t.buckets))*lockFreeLoad {
+		s.grow(t)
+	}
+}
+
+// Delete implements Store.
+func (s *LockFreeStore) Delete(key string) {
+	h := s.hash(key)
+	t := s.table.Load().(*lockFreeTable)
+	old := t.bucket(h)
+	for n := range old {
+		if old[n].hash == h && old[n].key == key {
+			b := make(lockFreeBucket, 0, len(old)-1)
+			b = append(append(b, old[:n]...), old[n+1:]...)
+			t.buckets[h&t.mask].Store(b)
+			atomic.AddInt64(&s.n, -1)
+			return
+		}
+	}
+}
+
+// Len implements Store.
+func (s *LockFreeStore) Len() int {
+	return int(atomic.LoadInt64(&s.n))
+}
+
+// Range implements Store, starting at a random bucket.
+func (s *LockFreeStore) Range(fn func(key string, i Item) bool) {
+	t := s.table.Load().(*lockFreeTable)
+	start := rand.Intn(len(t.buckets))
+	for n := range t.buckets {
+		b, _ := t.buckets[(start+n)%len(t.buckets)].Load().(lockFreeBucket)
+		for _, e := range b {
+			if !fn(e.key, e.item) {
+				return
+			}
+		}
+	}
+}
+
+// grow publishes a copy of t with twice the buckets
+func (s *LockFreeStore) grow(t *lockFreeTable) {
+	grown := newLockFreeTable(2 * len(t.buckets))
+	for n := range t.buckets {
+		b, _ := t.buckets[n].Load().(lockFreeBucket)
+		for _, e := range b {
+			nb, _ := grown.buckets[e.hash&grown.mask].Load().(lockFreeBucket)
+			grown.buckets[e.hash&grown.mask].Store(append(nb, e))
+		}
+	}
+	s.table.Store(grown)
+}
+
+// reset publishes an empty table, releasing the buckets of the old one
+func (s *LockFreeStore) reset() int {
+	n := atomic.SwapInt64(&s.n, 0)
+	s.table.Store(newLockFreeTable(8))
+	return int(n)
+}
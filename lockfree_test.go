@@ -0,0 +1,113 @@
+package resource
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestLockFreeStore(t *testing.T) {
+	s := NewLockFreeStore(nil)
+	for i := 0; i < 1000; i++ {
+		s.Set(strconv.Itoa(i), Item{Created: int64(i)})
+	}
+	s.Set("42", Item{Created: -1})
+	for i := 0; i < 1000; i += 2 {
+		s.Delete(strconv.Itoa(i))
+	}
+	s.Delete("missing")
+
+	if s.Len() != 500 {
+		t.Errorf("LockFreeStore.Len() expect 500, have %v", s.Len())
+	}
+	if i, found := s.Get("43"); !found || i.Created != 43 {
+		t.Errorf("LockFreeStore.Get() expect 43, have %v, %v", i.Created, found)
+	}
+	if _, found := s.Get("42"); found {
+		t.Errorf("LockFreeStore.Get() expect 42 deleted")
+	}
+	seen := 0
+	s.Range(func(key string, i Item) bool {
+		if key != strconv.FormatInt(i.Created, 10) {
+			t.Errorf("LockFreeStore.Range() expect the item of %v, have %v", key, i.Created)
+		}
+		seen++
+		return true
+	})
+	if seen != 500 {
+		t.Errorf("LockFreeStore.Range() expect 500 items, have %v", seen)
+	}
+}
+
+func TestLockFreeStore_Concurrent(t *testing.T) {
+	mockedFetcher := &FetcherMock{
+		FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+			return &Model{Name: id}, nil
+		},
+	}
+	fc := NewCache(mockedFetcher, WithStore(NewLockFreeStore(nil)), WithMaxEntries(500))
+	defer fc.Close(context.Background())
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 2000; i++ {
+				id := strconv.Itoa((i * (g + 1)) % 1000)
+				model, err := fc.Fetch(context.Background(), id)
+				if err != nil || model.Name != id {
+					t.Errorf("FetchCache.Fetch() expect %v, have %v, %v", id, model, err)
+					return
+				}
+				if i%500 == 0 && g == 0 {
+					fc.Flush()
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	if n := fc.Stats().Items; n > 500 {
+		t.Errorf("FetchCache.Stats() expect at most 500 items, have %v", n)
+	}
+}
+
+// Benchmark the hits of many keys from many goroutines by Store
+func BenchmarkStore_Hit(b *testing.B) {
+	mockedFetcher := &FetcherMock{
+		FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+			return &Model{Name: id}, nil
+		},
+	}
+	ids := make([]string, 1024)
+	for n := range ids {
+		ids[n] = strconv.Itoa(n)
+	}
+	for _, bb := range []struct {
+		name  string
+		store Store
+	}{
+		{name: "map", store: NewMapStore()},
+		{name: "sharded", store: NewShardedStore(16, nil, nil)},
+		{name: "lock-free", store: NewLockFreeStore(nil)},
+	} {
+		b.Run(bb.name, func(b *testing.B) {
+			fc := NewCache(mockedFetcher, WithStore(bb.store))
+			ctx := context.Background()
+			for _, id := range ids {
+				_, _ = fc.Fetch(ctx, id)
+			}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				n := 0
+				for pb.Next() {
+					_, _ = fc.Fetch(ctx, ids[n%len(ids)])
+					n++
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package resource
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// lockSampleRate is the rate at which lock acquisitions are timed for
+// Stats, one in lockSampleRate
+const lockSampleRate = 8
+
+// sampleLock reports whether to time the lock acquisition about to be made,
+// counting the acquisitions in n
+func sampleLock(n *uint64) bool {
+	return atomic.AddUint64(n, 1)%lockSampleRate == 0
+}
+
+// lockItems write locks fc.itemsLock, timing a sample of the acquisitions
+func (fc *FetchCache) lockItems() {
+	if !sampleLock(&fc.metrics.itemsLockSamples) {
+		fc.itemsLock.Lock()
+		return
+	}
+	start := time.Now()
+	fc.itemsLock.Lock()
+	fc.metrics.itemsLockWait.observe(time.Since(start))
+}
+
+// rlockItems read locks fc.itemsLock, timing a sample of the acquisitions
+func (fc *FetchCache) rlockItems() {
+	if !sampleLock(&fc.metrics.itemsLockSamples) {
+		fc.itemsLock.RLock()
+		return
+	}
+	start := time.Now()
+	fc.itemsLock.RLock()
+	fc.metrics.itemsLockWait.observe(time.Since(start))
+}
@@ -3,18 +3,26 @@ package resource
 import (
 	"context"
 	"errors"
+	"math/rand"
+	"os"
+	"os/signal"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 // Const list
 const (
 	DefaultExpiration time.Duration = 0
+	// DefaultAliasCacheSize is the number of resolved aliases kept, see
+	// WithAliasCacheSize.
+	DefaultAliasCacheSize = 10000
 )
 
 // Error list
 var (
 	ErrNotFound = errors.New("not found")
+	ErrClosed   = errors.New("cache closed")
 )
 
 // Coding Task: Concurrent in-memory cache.
@@ -30,6 +38,12 @@ var (
 // Model is a resource.
 type Model struct {
 	Name string
+	// Data is the raw content of the resource, if any.
+	Data []byte
+	// Value is the decoded resource of any type, if any, see ValueCache.
+	// It is kept in memory only: the L2 store, peers and dumps carry Name
+	// and Data.
+	Value interface{} `json:"-"`
 }
 
 // Fetcher is an interface that defines the Fetch method.
@@ -38,66 +52,421 @@ type Fetcher interface {
 	Fetch(ctx context.Context, id string) (*Model, error)
 }
 
+// New creates a new Fetcher which caches calls to f.Fetch, like NewCache,
+// but fails with ErrInvalidOptions when opts are out of range or conflict
+// with each other, e.g. WithL2Lock without WithL2.
+func New(f Fetcher, opts ...Option) (*FetchCache, error) {
+	if err := newOptions(opts).validate(); err != nil {
+		return nil, err
+	}
+	return NewCache(f, opts...), nil
+}
+
 // NewCache creates a new Fetcher which caches calls to f.Fetch.
-// See FetchCache for more details.
-func NewCache(f Fetcher) *FetchCache {
-	return &FetchCache{
-		cache:     newCache(),
-		f:         f,
-		keyLock:   &sync.Map{},
-		writeLock: &sync.Mutex{},
+// See FetchCache for more details. It doesn't check opts, see New.
+//
+// When WithBatchFetcher is given misses are loaded through the
+// BatchFetcher instead of f.
+func NewCache(f Fetcher, opts ...Option) *FetchCache {
+	o := newOptions(opts)
+	if rf, ok := f.(ResultFetcher); ok {
+		f = resultFetcher{f: rf}
+	}
+	var b *batcher
+	if o.batchFetcher != nil {
+		b = newBatcher(o.batchFetcher, o.batchWindow, o.batchMax)
+		b.synchronous = o.synchronous
+		b.recoverPanics, b.panicReport = o.recoverPanics, o.panicReport
+		f = b
+	}
+	life := newLifecycle()
+	if o.shadow != nil {
+		f = &shadowFetcher{
+			next:        f,
+			shadow:      o.shadow,
+			equal:       o.shadowEqual,
+			report:      o.shadowReport,
+			life:        life,
+			synchronous: o.synchronous,
+			traceID:     o.traceID,
+		}
+	}
+	if o.peers != nil {
+		f = &peerFetcher{pool: o.peers, local: f}
+	}
+
+	fc := &FetchCache{
+		cache:      newCache(o.store),
+		f:          f,
+		opts:       o,
+		batch:      b,
+		ttl:        int64(o.ttl),
+		maxEntries: int64(o.maxEntries),
+		fetchLimit: newLimiter(o.maxConcurrency, o.missQueueSize, o.missQueueTimeout),
+		fetchRate:  newTokenBucket(o.fetchRate, o.fetchBurst),
+		quota:      newOriginQuota(o.quota, o.quotaWindow),
+		classes:    newKeyClasses(o.keyClassifier),
+		strict:     newStrictChecker(o),
+		keyLock:    &sync.Map{},
+		itemsLock:  &sync.RWMutex{},
+		events:     &eventHub{},
+		stats:      &keyStatsMap{},
+		metrics:    &metrics{lifetime: lifetimeCounters{base: LifetimeStats{Since: time.Now()}}},
+		life:       life,
+		eager:      &eagerKeys{},
+		ticks:      newTicks(time.Now(), o),
+		snap:       &snapshot{},
+		deps:       newDepGraph(),
+		inflight:   newInflightTracker(o.duplicateFetch),
+		pins:       newPinSet(o.headroom),
+		memory:     newMemoryBudget(o.inflightMemory),
+		negative:   &negativeCache{max: o.errorCache.MaxEntries},
+		aliases:    newAliasTable(o.aliasCacheSize),
+	}
+	fc.snap.items.Store(map[string]item{})
+	fc.stats.cached = func(key string) bool {
+		_, found := fc.peekitem(key)
+		return found
+	}
+	if o.wheelResolution > 0 {
+		fc.wheel = newTimingWheel(o.wheelResolution, time.Now())
+	}
+	if o.refreshWorkers > 0 {
+		fc.refreshes = newRefreshPool(o.refreshQueueSize)
+	}
+	fc.lockFree, _ = o.store.(*LockFreeStore)
+	if o.tenant != nil {
+		fc.fetchLimit.fair = newFairQueue(o.tenantWeights)
+	}
+	fc.warmup = newWarmupTracker(o.readiness, o.hasReadiness)
+	if o.traceWriter != nil && o.traceRate > 0 {
+		fc.trace = newAccessTrace(o.traceWriter, o.traceRate)
+	}
+	if o.l2 != nil {
+		fc.f = &l2Fetcher{
+			store: o.l2,
+			codec: o.l2Codec,
+			next:  f,
+			ttl:   func() time.Duration { return time.Duration(atomic.LoadInt64(&fc.ttl)) },
+		}
+		if o.checksums {
+			fc.f.(*l2Fetcher).corrupted = fc.metrics.corrupted
+		}
+		if locker, ok := o.l2.(LockingL2); ok && o.l2LockTTL > 0 {
+			lf := fc.f.(*l2Fetcher)
+			lf.locker, lf.lockTTL, lf.lockPoll = locker, o.l2LockTTL, o.l2LockPoll
+		}
+	}
+	if o.seed != nil {
+		fc.f = &seedFetcher{seed: o.seed, key: fc.key, next: fc.f}
+	}
+	if o.peers != nil {
+		o.peers.mu.Lock()
+		o.peers.fc = fc
+		o.peers.mu.Unlock()
+	}
+	if o.invalidator != nil {
+		fc.instance = newInstanceID()
+		fc.unsubscribe = o.invalidator.Subscribe(fc.invalidate)
+	}
+	if o.synchronous {
+		return fc
+	}
+	if o.health != nil && o.healthInterval > 0 {
+		fc.life.goBackground("health-check", fc.runHealthCheck)
+	}
+	if o.diagnosticsWriter != nil && len(o.diagnosticsSignals) > 0 {
+		// registered before returning so no signal hits the default handler
+		ch := make(chan os.Signal, 1)
+		signal.Notify(ch, o.diagnosticsSignals...)
+		fc.life.goBackground("diagnostics", func(stop <-chan struct{}) { fc.runDiagnosticsSignal(ch, stop) })
 	}
+	if o.loadPolicy != nil && o.eagerInterval > 0 {
+		fc.life.goBackground("eager-refresh", fc.refreshEager)
+	}
+	if o.refreshSchedule != nil {
+		fc.life.goBackground("scheduled-refresh", fc.runScheduledRefresh)
+	}
+	if o.driftEqual != nil && o.driftInterval > 0 {
+		fc.life.goBackground("drift-check", fc.runDriftCheck)
+	}
+	if o.snapshotReads && o.snapshotInterval > 0 {
+		fc.life.goBackground("republish", fc.runRepublish)
+	}
+	if o.sweepInterval > 0 {
+		fc.life.goBackground("expiry-sweep", fc.runExpirySweep)
+	}
+	if fc.wheel != nil {
+		fc.life.goBackground("expiry-wheel", fc.runExpiryWheel)
+	}
+	if o.hitRatioAlert != nil && o.hitRatioWindow >= hitRatioSlots {
+		fc.life.goBackground("hit-ratio-watch", fc.runHitRatioWatch)
+	}
+	if o.compactInterval > 0 {
+		fc.life.goBackground("compaction", fc.runCompaction)
+	}
+	if o.hotKeys != nil {
+		if o.keyHash == 0 {
+			fc.warmup.begin()
+		}
+		fc.life.goBackground("hot-keys", fc.runHotKeys)
+	}
+	for i := 0; i < o.refreshWorkers; i++ {
+		fc.life.goBackground("refresh-worker", fc.runRefreshWorker)
+	}
+	if o.writeBuffer > 0 {
+		fc.writes = newWriteBuffer(o.writeBuffer)
+		fc.life.goBackground("writer", fc.runWriter)
+	}
+	return fc
 }
 
-func newCache() *cache {
+func newCache(s Store) *cache {
+	if s == nil {
+		s = NewMapStore()
+	}
 	return &cache{
-		items: make(map[string]item),
+		items: s,
 	}
 }
 
 // FetchCache implements an in-memory cache for a Fetcher.
 //
 // A FetchCache is safe for use by multiple goroutines simultaneously.
+//
+// Hits of fresh items take no per-key lock and make no heap allocation.
+// At most one Fetcher call per key is in flight at any time, on a first
+// load as well as when an item expires under load, whether the key is
+// fetched by Fetch, FetchMany, through a BatchFetcher, by a drift check or
+// by CacheAside.Write: they all hold the key lock during the call and are
+// listed by InFlight. Concurrent fetches of the key wait for that call and
+// are served its result, or with WithStaleWhileRevalidate are served the
+// expired item meanwhile. A fetch waiting for the call of another is
+// released with its ctx.Err() once its own context is done, however long
+// the context of the call, which goes on for the others.
 type FetchCache struct {
-	f         Fetcher
-	keyLock   *sync.Map
-	writeLock *sync.Mutex
+	version    uint64 // accessed atomically
+	ttl        int64  // accessed atomically
+	maxEntries int64  // accessed atomically
+	disabled   int32  // accessed atomically
+	degraded   int32  // accessed atomically
+	retryHints int32  // accessed atomically, set once the Fetcher returned a RateLimitedError
+	// breaker is when the breaker of ErrorTripBreaker closes in ns
+	breaker int64 // accessed atomically
+	// staleBefore is the time of the last MarkAllStale in ns
+	staleBefore int64 // accessed atomically
+	// incidentStale is the max staleness of the incident mode and
+	// incidentUntil when it reverts in ns, see SetIncidentMode
+	incidentStale int64 // accessed atomically
+	incidentUntil int64 // accessed atomically
+	f             Fetcher
+	opts          options
+	batch         *batcher
+	fetchLimit    *limiter
+	fetchRate     *tokenBucket
+	quota         *originQuota
+	classes       *keyClasses
+	strict        *strictChecker
+	keyLock       *sync.Map
+	itemsLock     *sync.RWMutex
+	// evictClock is the GDSF clock of EvictCostAware and pins the keys
+	// kept out of eviction, guarded by itemsLock
+	evictClock float64
+	pins       *pinSet
+	events     *eventHub
+	stats      *keyStatsMap
+	metrics    *metrics
+	life       *lifecycle
+	eager      *eagerKeys
+	ticks      *ticks
+	snap       *snapshot
+	deps       *depGraph
+	inflight   *inflightTracker
+	negative   *negativeCache
+	aliases    *aliasTable
+	// refreshes is the queue of WithRefreshPool, nil without
+	refreshes *refreshPool
+	// trace records the accesses of WithAccessTrace, nil without
+	trace *accessTrace
+	// wheel schedules the expirations with WithExpiryWheel, nil otherwise
+	wheel *timingWheel
+	// writes is the queue of WithWriteBuffer, nil without
+	writes *writeBuffer
+	// memory is the budget of WithInFlightMemory, nil without
+	memory *memoryBudget
+	// evictions is the log of WithEvictionLog, guarded by itemsLock
+	evictions evictionLog
+	// peak is the most items the map store held since it was last
+	// rebuilt, guarded by itemsLock
+	peak int
+	// lockFree is the store read without the items lock, nil unless it is
+	// a LockFreeStore
+	lockFree *LockFreeStore
+	// warmup follows the warm-ups for WithReadiness
+	warmup *warmupTracker
+	// cardinality counts the new keys of WithCardinalityGuard
+	cardinality cardinalityGuard
+	// instance identifies the cache in invalidations it broadcasts
+	instance    string
+	unsubscribe func()
 	*cache
 }
 
-// Lock lock cache by key
+// Lock lock cache by key, use WithKeys to lock several keys as taking
+// them one by one can deadlock. A string key is an id, normalized and
+// hashed like the fetches of it, so holding its lock excludes them.
+//
+// Deprecated: Lock can't be interrupted, use LockContext, WithKeys or Update.
 func (fc *FetchCache) Lock(key interface{}) {
-	m := sync.Mutex{}
-	tmp, _ := fc.keyLock.LoadOrStore(key, &m)
-	mm := tmp.(*sync.Mutex)
-	mm.Lock()
-	if mm != &m { // if item get from map is different from original && retry to lock that key
-		mm.Unlock()
-		fc.Lock(key)
-		return
+	fc.lock(fc.lockKeyOf(key))
+	fc.strict.lockedKey(fc.lockKeyOf(key))
+}
+
+// LockContext locks key like Lock, unless ctx is done first in which case
+// it returns the error of ctx and key isn't locked.
+func (fc *FetchCache) LockContext(ctx context.Context, key interface{}) error {
+	_, err := fc.lockContext(ctx, fc.lockKeyOf(key))
+	if err == nil {
+		fc.strict.lockedKey(fc.lockKeyOf(key))
 	}
-	return
+	return err
 }
 
-// Unlock cache by key
+// lockKeyOf returns the internal key of the lock of key, given to Lock
+func (fc *FetchCache) lockKeyOf(key interface{}) interface{} {
+	if id, ok := key.(string); ok {
+		return fc.key(fc.canonical(id))
+	}
+	return key
+}
+
+// lock locks key and reports whether it had to wait for another holder
+func (fc *FetchCache) lock(key interface{}) bool {
+	waited, _ := fc.lockContext(context.Background(), key)
+	return waited
+}
+
+// lockContext locks key unless ctx is done first, and reports whether it
+// had to wait for another holder
+func (fc *FetchCache) lockContext(ctx context.Context, key interface{}) (bool, error) {
+	var start time.Time
+	sampled := sampleLock(&fc.metrics.lockSamples)
+	if sampled {
+		start = time.Now()
+	}
+	waited, err := fc.lockKey(ctx, key)
+	if waited {
+		atomic.AddUint64(&fc.metrics.keyLockContended, 1)
+	}
+	if err != nil {
+		atomic.AddUint64(&fc.metrics.joinsAbandoned, 1)
+	}
+	if sampled && err == nil {
+		fc.metrics.keyLockWait.observe(time.Since(start))
+	}
+	return waited, err
+}
+
+// lockKey implements lockContext
+func (fc *FetchCache) lockKey(ctx context.Context, key interface{}) (bool, error) {
+	m := make(keyMutex, 1)
+	tmp, loaded := fc.keyLock.LoadOrStore(key, m)
+	mm := tmp.(keyMutex)
+	if loaded {
+		fc.inflight.wait(key, 1)
+	}
+	var err error
+	select {
+	case mm <- struct{}{}:
+	default:
+		select {
+		case mm <- struct{}{}:
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+	}
+	if loaded {
+		fc.inflight.wait(key, -1)
+	}
+	if err != nil {
+		return loaded, err
+	}
+	if mm != m { // if item get from map is different from original && retry to lock that key
+		<-mm
+		_, err := fc.lockKey(ctx, key)
+		return true, err
+	}
+	return false, nil
+}
+
+// tryLock locks key only if nobody holds it
+func (fc *FetchCache) tryLock(key interface{}) bool {
+	m := make(keyMutex, 1)
+	m <- struct{}{}
+	_, loaded := fc.keyLock.LoadOrStore(key, m)
+	return !loaded
+}
+
+// Unlock cache by key, locked by Lock or LockContext
 func (fc *FetchCache) Unlock(key interface{}) {
+	if fc.strict != nil {
+		_, held := fc.keyLock.Load(fc.lockKeyOf(key))
+		fc.strict.unlockedKey(fc.lockKeyOf(key), held)
+	}
+	fc.unlock(fc.lockKeyOf(key))
+}
+
+// unlock releases the lock of the internal key key
+func (fc *FetchCache) unlock(key interface{}) {
 	l, exist := fc.keyLock.Load(key)
 	if !exist {
 		return
 	}
-	tmp := l.(*sync.Mutex)
+	tmp := l.(keyMutex)
 	fc.keyLock.Delete(key)
-	tmp.Unlock()
+	<-tmp
 }
 
+// keyMutex is a mutex held while it holds a value, waiting for it can be
+// abandoned unlike sync.Mutex
+type keyMutex chan struct{}
+
 type cache struct {
-	items map[string]item
+	items Store
 }
 
-// item is a struct contains a resource model and its expiration
-type item struct {
-	Object     *Model
+// item is the name of Item in the cache internals
+type item = Item
+
+// Item is a cached resource model and its expiration, as held by a Store.
+type Item struct {
+	Object *Model
+	// Expiration and Created are unix times in ns, an Expiration of 0
+	// means the item doesn't expire
 	Expiration int64
+	Created    int64
+	// Version is set when the item is stored, see EntryInfo
+	Version uint64
+	// Cost is how long the item took to fetch in ns
+	Cost int64
+	// Clock is the eviction clock when the item was stored, see
+	// EvictCostAware
+	Clock float64
+	// IDSum is the checksum of the id with CollisionVerify
+	IDSum uint32
+	// Loaded is when the model was first loaded in ns, kept by refreshes
+	// with WithMaxLifetime, Created if 0
+	Loaded int64
+	// Idle is the time-to-idle in ns and Lifetime the max lifetime in ns,
+	// that of WithMaxLifetime if 0, see Expire
+	Idle     int64
+	Lifetime int64
+	// OriginVersion is the Result.Version of the model, if any
+	OriginVersion string
+	// Meta is the metadata of the item, see WithMetadata
+	Meta map[string]string
+	// Source is where the model came from, see EntryInfo
+	Source Source
 }
 
 // expired Returns true if the item has expired.
@@ -108,54 +477,484 @@ func (i *item) expired() bool {
 	return time.Now().UnixNano() > i.Expiration
 }
 
+// age returns how long ago the item was cached.
+func (i *item) age() time.Duration {
+	return time.Duration(time.Now().UnixNano() - i.Created)
+}
+
 // Fetch implements Fetcher.
 func (fc *FetchCache) Fetch(ctx context.Context, id string) (*Model, error) {
-	fc.Lock(id)
-	defer fc.Unlock(id)
-	item, found := fc.fetchFromCache(id)
-	if !found {
-		return fc.fetchFromFetcher(ctx, id)
+	return fc.FetchWithOptions(ctx, id)
+}
+
+// FetchWithOptions is Fetch with per-call options, see FetchOption.
+func (fc *FetchCache) FetchWithOptions(ctx context.Context, id string, opts ...FetchOption) (*Model, error) {
+	model, _, err := fc.fetch(ctx, id, opts)
+	return model, err
+}
+
+// fetchShared implements fetch through the cache shared by all requests
+func (fc *FetchCache) fetchShared(ctx context.Context, id string, opts []FetchOption) (*Model, Source, error) {
+	if !fc.life.enter() {
+		return nil, 0, ErrClosed
+	}
+	defer fc.life.leave()
+	start := time.Now()
+	o := newFetchOptions(fetchOptionsFromContext(ctx), opts)
+	if !fc.cacheEnabled(ctx) {
+		o.bypass, o.noStore = true, true
+	}
+	o.trace = fc.traceID(ctx)
+	o.class = fc.classes.get(id)
+	key := fc.key(id)
+	locked, waited := false, false
+	if !o.bypass {
+		if i, found := fc.peekitem(key); found && fc.current(key, i) && o.fresh(i) && fc.owns(i, id) {
+			fc.recordHit(key, o.trace, start)
+			fc.metrics.hit(o.class, time.Since(start), false, i.age())
+			return i.Object, SourceMemory, nil
+		}
+		if i, found := fc.incidentItem(key, start); found && fc.owns(i, id) {
+			fc.recordHit(key, o.trace, start)
+			fc.metrics.staleHit(o.class, time.Since(start), i.age())
+			return i.Object, SourceStale, nil
+		}
+		if stale, found := fc.staleItem(key); found && o.fresh(stale) && fc.owns(stale, id) {
+			if fc.refreshes != nil {
+				fc.recordHit(key, o.trace, start)
+				fc.metrics.staleHit(o.class, time.Since(start), stale.age())
+				fc.queueRefresh(id)
+				return stale.Object, SourceStale, nil
+			}
+			if fc.staleRetrying(key, start) || !fc.tryLock(key) {
+				// another caller is refreshing the item or its refresh
+				// failed
+				fc.recordHit(key, o.trace, start)
+				fc.metrics.staleHit(o.class, time.Since(start), stale.age())
+				return stale.Object, SourceStale, nil
+			}
+			locked = true
+		}
+	}
+	if !locked {
+		var err error
+		if waited, err = fc.lockContext(ctx, key); err != nil {
+			return nil, 0, err
+		}
+	}
+	defer fc.unlock(key)
+	if !o.bypass {
+		item, found := fc.fetchFromCache(key)
+		if found && o.fresh(item) && fc.owns(item, id) {
+			fc.recordHit(key, o.trace, start)
+			fc.metrics.hit(o.class, time.Since(start), waited, item.age())
+			return item.Object, SourceMemory, nil
+		}
 	}
 
-	return item.Object, nil
+	if err := fc.backingOff(key, id, start); err != nil {
+		if retryAfter(err) > 0 {
+			if model, ok := fc.serveStale(key, id, o, start); ok {
+				return model, SourceStale, nil
+			}
+		}
+		return nil, 0, err
+	}
+	if err := fc.negative.get(key, start); err != nil {
+		return nil, 0, err
+	}
+	if fc.breakerOpen(start) {
+		if model, ok := fc.serveStale(key, id, o, start); ok {
+			return model, SourceStale, nil
+		}
+		return nil, 0, ErrBreakerOpen
+	}
+	if err := fc.authorizeFetch(ctx, id); err != nil {
+		return nil, 0, err
+	}
+	if err := fc.debounce(ctx, key); err != nil {
+		return nil, 0, err
+	}
+	fc.stats.miss(key)
+	fc.events.publishTrace(EventMiss, key, o.trace)
+	fc.trace.record(key, false, start)
+	src := SourceOrigin
+	if fc.opts.l2 != nil || fc.opts.peers != nil || fc.opts.seed != nil {
+		ctx = context.WithValue(ctx, sourceKey{}, &src)
+	}
+	model, act, err := fc.fetchFromFetcher(ctx, id, o)
+	if err != nil && locked && ctx.Err() == nil {
+		fc.staleRefreshFailed(key, time.Now())
+	}
+	if act&ErrorServeStale != 0 || err == ErrRateLimited && fc.opts.rateLimitPolicy == RateLimitStale ||
+		err == ErrQuotaExhausted && fc.opts.quotaPolicy == RateLimitStale || retryAfter(err) > 0 {
+		if model, ok := fc.serveStale(key, id, o, start); ok {
+			return model, SourceStale, nil
+		}
+	}
+	fc.metrics.miss(o.class, time.Since(start), src)
+	if err != nil {
+		return nil, 0, err
+	}
+	return model, src, nil
 }
 
-// Clear item by id
+// Clear item by id, along with the items depending on it, see AddDependency.
+// Clear waits for a fetch of id in flight and removes its result, it
+// cancels the fetch first with WithCancelOnClear.
 func (fc *FetchCache) Clear(id string) {
-	fc.Lock(id)
-	defer fc.Unlock(id)
-	if _, found := fc.items[id]; !found {
-		return
+	fc.ClearContext(context.Background(), id)
+}
+
+// ClearContext is Clear recording the actor of ctx, see WithAuditHook.
+func (fc *FetchCache) ClearContext(ctx context.Context, id string) {
+	id = fc.canonical(id)
+	removed := fc.clear(id, true)
+	fc.audit(AuditClear, ActorFromContext(ctx), fc.traceID(ctx), []string{id}, removed)
+}
+
+// Flush removes all items from the cache without waiting for the fetches in
+// flight, which return their models without caching them
+func (fc *FetchCache) Flush() {
+	fc.FlushContext(context.Background())
+}
+
+// FlushContext is Flush recording the actor of ctx, see WithAuditHook.
+func (fc *FetchCache) FlushContext(ctx context.Context) {
+	removed := fc.flushitems()
+	fc.broadcast(Invalidation{Flush: true})
+	fc.audit(AuditFlush, ActorFromContext(ctx), fc.traceID(ctx), nil, removed)
+}
+
+// removeitem removes id and reports whether it was cached
+func (fc *FetchCache) removeitem(id string) bool {
+	fc.inflight.invalidate(id)
+	fc.lockItems()
+	_, pending := fc.writes.get(id)
+	fc.writes.cancel(id)
+	if _, found := fc.items.Get(id); !found {
+		fc.itemsLock.Unlock()
+		return pending
 	}
 
-	delete(fc.items, id)
+	fc.items.Delete(id)
+	fc.itemsChanged(true)
+	fc.itemsLock.Unlock()
+	fc.events.publish(EventEvict, id)
+	return true
+}
+
+// flushitems removes all items and returns how many there were
+func (fc *FetchCache) flushitems() int {
+	if fc.opts.cancelOnClear {
+		fc.inflight.cancelAll()
+	}
+	fc.inflight.invalidateAll()
+	fc.lockItems()
+	fc.writes.cancelAll()
+	n := fc.clearLocked()
+	fc.itemsChanged(true)
+	fc.itemsLock.Unlock()
+	fc.stats.reset()
+	fc.negative.reset()
+	fc.deps.reset()
+	fc.events.publish(EventFlush, "")
+	return n
+}
+
+func (fc *FetchCache) recordHit(id, trace string, now time.Time) {
+	fc.stats.hit(id, now)
+	fc.events.publishTrace(EventHit, id, trace)
+	fc.trace.record(id, true, now)
+}
+
+// peekitem returns the item of id, expired or not
+func (fc *FetchCache) peekitem(id string) (item, bool) {
+	if i, found := fc.writes.get(id); found {
+		return i, true
+	}
+	if fc.opts.snapshotReads {
+		return fc.loadSnapshot(id)
+	}
+	return fc.getitem(id)
+}
+
+// getitem returns the item of id from the store, expired or not
+func (fc *FetchCache) getitem(id string) (item, bool) {
+	if fc.lockFree != nil {
+		return fc.lockFree.Get(id)
+	}
+	fc.rlockItems()
+	i, found := fc.items.Get(id)
+	fc.itemsLock.RUnlock()
+	return i, found
 }
 
 func (fc *FetchCache) fetchFromCache(id string) (item, bool) {
-	i, found := fc.items[id]
-	if !found || i.expired() {
+	i, found := fc.writes.get(id)
+	if !found {
+		i, found = fc.getitem(id)
+	}
+	if !found {
+		return item{}, false
+	}
+	if i.expired() || fc.idled(id, i) {
+		fc.events.publish(EventExpire, id)
+		return item{}, false
+	}
+	if fc.markedStale(i) {
 		return item{}, false
 	}
 
 	return i, found
 }
 
-func (fc *FetchCache) fetchFromFetcher(ctx context.Context, id string) (*Model, error) {
-	model, err := fc.f.Fetch(ctx, id)
+// serveStale returns the expired item of id however old, unless o bypasses
+// the cache
+func (fc *FetchCache) serveStale(key, id string, o fetchOptions, start time.Time) (*Model, bool) {
+	if o.bypass {
+		return nil, false
+	}
+	stale, found := fc.peekitem(key)
+	if !found || !o.fresh(stale) || !fc.owns(stale, id) || fc.outlived(stale, start.UnixNano()) {
+		return nil, false
+	}
+	fc.recordHit(key, o.trace, start)
+	fc.metrics.staleHit(o.class, time.Since(start), stale.age())
+	return stale.Object, true
+}
+
+// fetchFromFetcher calls the Fetcher for id and caches the result, the
+// ErrorAction is the one taken on the error, see ErrorPolicy
+func (fc *FetchCache) fetchFromFetcher(ctx context.Context, id string, o fetchOptions) (*Model, ErrorAction, error) {
+	caller := ctx
+	ctx, stop := fc.budgetContext(ctx)
+	defer stop()
+	if err := fc.acquireFetch(ctx, id); err != nil {
+		return nil, ErrorPassThrough, budgetErr(caller, ctx, err)
+	}
+	key := fc.key(id)
+	reserved, err := fc.reserveMemory(ctx, key)
 	if err != nil {
-		return nil, err
+		fc.fetchLimit.release()
+		return nil, ErrorPassThrough, budgetErr(caller, ctx, err)
+	}
+	start := time.Now()
+	ctx = fc.originContext(ctx, key)
+	parent, cancel := ctx, context.CancelFunc(nil)
+	if fc.opts.cancelOnClear {
+		ctx, cancel = context.WithCancel(ctx)
+		defer cancel()
+	}
+	gen := fc.inflight.start(key, id, cancel)
+	defer fc.inflight.done(key)
+	var (
+		model  *Model
+		result Result
+	)
+	ctx = withResult(ctx, &result)
+	withLabels(ctx, "fetch", id, func(ctx context.Context) { model, err = fc.fetchValid(ctx, id) })
+	if err != nil && ctx.Err() != nil && parent.Err() == nil {
+		err = ErrFetchCanceled
+	}
+	err = budgetErr(caller, ctx, err)
+	latency := time.Since(start)
+	// the size of model stays reserved until it is cached on return
+	defer fc.releaseMemory(fc.fetchedMemory(reserved, model))
+	atomic.AddUint64(&fc.metrics.fetcherCalls, 1)
+	fc.stats.fetched(key, start, latency, err)
+	act := fc.classify(ctx, id, err)
+	if err == nil || act&ErrorBackoff != 0 {
+		fc.backoff(ctx, key, err)
+	}
+	fc.holdOff(key, err, time.Now())
+	fc.fetchLimit.release()
+	if err != nil {
+		fc.failed(ctx, key, id, err, act)
+		return nil, act, err
 	}
 
-	fc.cacheitem(id, model)
+	src := SourceOrigin
+	if p, ok := ctx.Value(sourceKey{}).(*Source); ok && *p != 0 {
+		src = *p
+	}
+	if src != SourceOrigin {
+		// served by the L2 store or a peer
+		fc.quota.refund()
+	}
+	if ttl, store := fc.resultTTL(model); store && !o.noStore && !result.NoStore {
+		result.Model = model
+		fc.keep(key, id, o.trace, result, src, o.expiration(o.ttlOr(result.ttl(ttl))), latency, func() bool {
+			return fc.inflight.invalidated(key, gen)
+		})
+	}
 
-	return model, nil
+	return model, ErrorPassThrough, nil
 }
 
-func (fc *FetchCache) cacheitem(id string, model *Model) {
-	fc.writeLock.Lock()
-	fc.items[id] = item{
+// keep caches the model of r for id like a fetch result, registering its
+// eager refresh and dependencies, unless it is oversized, a new key over
+// the cardinality guard or discard, if not nil, returns true, see
+// storeitemUnless. The events of the store carry trace, see WithTraceID.
+func (fc *FetchCache) keep(key, id, trace string, r Result, src Source, e Expiration, cost time.Duration, discard func() bool) bool {
+	model := r.Model
+	if fc.oversized(model, r.Size) || fc.overCardinality(key) {
+		return false
+	}
+	fc.trackChurn(key, model)
+	i := fc.newitem(key, id, model, e, cost)
+	i.OriginVersion, i.Source = r.Version, src
+	i.Meta = mergeMeta(i.Meta, r.Meta)
+	if !fc.bufferWrite(key, i, trace, discard) && fc.storeitemUnless(key, i, trace, discard) == 0 {
+		atomic.AddUint64(&fc.metrics.discarded, 1)
+		return false
+	}
+	fc.loaded(id)
+	if fc.opts.dependencies != nil {
+		fc.deps.set(id, fc.opts.dependencies(id, model))
+	}
+	return true
+}
+
+// acquireFetch waits until the rate and concurrency limits allow a call to
+// the Fetcher, fc.fetchLimit must be released after the call
+func (fc *FetchCache) acquireFetch(ctx context.Context, id string) error {
+	if err := fc.fetchRate.take(ctx, fc.opts.rateLimitPolicy); err != nil {
+		return err
+	}
+	if err := fc.quota.take(ctx, fc.opts.quotaPolicy); err != nil {
+		return err
+	}
+	if err := fc.fetchLimit.acquire(ctx, fc.tenantOf(ctx, id)); err != nil {
+		fc.quota.refund()
+		return err
+	}
+	return nil
+}
+
+// defaultTTL returns the configured TTL with jitter applied
+func (fc *FetchCache) defaultTTL() time.Duration {
+	ttl := time.Duration(atomic.LoadInt64(&fc.ttl))
+	if ttl <= 0 || fc.opts.ttlJitter <= 0 {
+		return ttl
+	}
+	return ttl - time.Duration(rand.Float64()*fc.opts.ttlJitter*float64(ttl))
+}
+
+// cacheitem caches model from src under key for ttl, cost is how long it
+// took to fetch
+func (fc *FetchCache) cacheitem(key, id string, model *Model, src Source, ttl, cost time.Duration) uint64 {
+	if src == SourceSet {
+		fc.strict.setAfterClose(key, fc.life.isClosed())
+	}
+	i := fc.newitem(key, id, model, Expiration{AfterWrite: ttl}, cost)
+	i.Source = src
+	return fc.storeitem(key, i)
+}
+
+// newitem returns the item caching model under key expiring by e, whose
+// AfterWrite is the TTL, 0 for none
+func (fc *FetchCache) newitem(key, id string, model *Model, e Expiration, cost time.Duration) item {
+	now := time.Now().UnixNano()
+	var expiration int64
+	if e.AfterWrite > 0 {
+		expiration = now + int64(e.AfterWrite)
+	}
+	lifetime := e.MaxLifetime
+	if lifetime <= 0 {
+		lifetime = fc.opts.maxLifetime
+	}
+	loaded, expiration := fc.lifetime(key, id, now, expiration, lifetime)
+
+	return item{
 		Object:     model,
-		Expiration: int64(DefaultExpiration),
+		Expiration: expiration,
+		Created:    now,
+		Cost:       int64(cost),
+		IDSum:      fc.idSum(id),
+		Loaded:     loaded,
+		Idle:       int64(e.AfterAccess),
+		Lifetime:   int64(e.MaxLifetime),
+		Meta:       fc.metadata(id, model),
+	}
+}
+
+// storeitem puts i in the cache, evicting old items if it is full, and
+// returns the version of i
+func (fc *FetchCache) storeitem(id string, i item) uint64 {
+	return fc.storeitemUnless(id, i, "", nil)
+}
+
+// storeitemUnless is storeitem unless discard, called under fc.itemsLock so
+// no removal of id interleaves, returns true, in which case it returns 0
+func (fc *FetchCache) storeitemUnless(id string, i item, trace string, discard func() bool) uint64 {
+	fc.lockItems()
+	if discard != nil && discard() {
+		fc.itemsLock.Unlock()
+		return 0
+	}
+	i, evicted := fc.storeLocked(id, i)
+	fc.itemsChanged(false)
+	fc.itemsLock.Unlock()
+	fc.afterStore(id, i, evicted, trace)
+	return i.Version
+}
+
+// storeLocked puts i in the cache evicting old items if it is full, it
+// returns i with its version and the keys evicted. fc.itemsLock must be
+// held and afterStore called once released.
+func (fc *FetchCache) storeLocked(id string, i item) (item, []string) {
+	fc.pins.stored(id)
+	var evicted []string
+	if _, found := fc.items.Get(id); !found {
+		evicted = fc.evictLocked(int(atomic.LoadInt64(&fc.maxEntries))-1, EvictedCapacity)
+	}
+	i.Version = atomic.AddUint64(&fc.version, 1)
+	i.Clock = fc.evictClock
+	fc.items.Set(id, i)
+	if n := fc.items.Len(); n > fc.peak {
+		fc.peak = n
+	}
+	return i, evicted
+}
+
+// afterStore does the work following storeLocked, the events carry trace
+func (fc *FetchCache) afterStore(id string, i item, evicted []string, trace string) {
+	fc.negative.remove(id)
+	fc.strict.stored(id, i.Object)
+	if fc.wheel != nil && i.Expiration != 0 {
+		fc.wheel.add(id, i.Expiration+int64(fc.staleWindow()), i.Version)
+	}
+	for _, key := range evicted {
+		fc.stats.remove(key)
+		fc.events.publishTrace(EventEvict, key, trace)
+	}
+	fc.events.publishTrace(EventSet, id, trace)
+	if fc.opts.stored != nil {
+		fc.opts.stored()
+	}
+}
+
+// evictLocked removes items chosen by the eviction policy until at most max
+// remain or only protected ones, see pinSet, logs them with reason and
+// returns their keys, a max below 0 means no limit. fc.itemsLock must be
+// held.
+func (fc *FetchCache) evictLocked(max int, reason EvictionReason) []string {
+	if max < 0 {
+		return nil
+	}
+
+	var evicted []string
+	for fc.items.Len() > max {
+		key, ok := fc.victimLocked()
+		if !ok {
+			break
+		}
+		if i, found := fc.items.Get(key); found {
+			fc.logEvictionLocked(key, i, reason)
+		}
+		fc.items.Delete(key)
+		evicted = append(evicted, key)
 	}
-	fc.writeLock.Unlock()
+	return evicted
 }
@@ -183,9 +183,204 @@ func TestFetchCache_Clear(t *testing.T) {
 			_, _ = fc.Fetch(context.Background(), fakeFetchID)
 			fc.Clear(tt.args.id)
 
-			if len(fc.items) != tt.remainCount {
-				t.Errorf("FetchCache.Clear() expect remain items count = %v, actual item count = %v", tt.remainCount, len(fc.items))
+			if fc.items.Len() != tt.remainCount {
+				t.Errorf("FetchCache.Clear() expect remain items count = %v, actual item count = %v", tt.remainCount, fc.items.Len())
 			}
 		})
 	}
 }
+
+// Test that hits of cached items don't allocate
+func TestFetchCache_Fetch_HitAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("the race detector allocates")
+	}
+	var (
+		fakeFetchID = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	)
+
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		cacheOpts []Option
+		opts      []FetchOption
+		subscribe bool
+	}{
+		{
+			name: "success without options",
+			ctx:  context.Background(),
+		},
+		{
+			name: "success with fetch options",
+			ctx:  context.Background(),
+			opts: []FetchOption{MinFreshness(time.Minute), OverrideTTL(time.Minute)},
+		},
+		{
+			name: "success with context options",
+			ctx:  WithMaxAge(context.Background(), time.Minute),
+		},
+		{
+			name:      "success with subscription",
+			ctx:       context.Background(),
+			subscribe: true,
+		},
+		{
+			name:      "success with snapshot reads",
+			ctx:       context.Background(),
+			cacheOpts: []Option{WithSnapshotReads(0)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					return &Model{Name: "lorem"}, nil
+				},
+			}
+			fc := NewCache(mockedFetcher, append(tt.cacheOpts, WithTTL(time.Minute))...)
+			if tt.subscribe {
+				defer fc.Subscribe(1).Close()
+			}
+			if _, err := fc.FetchWithOptions(tt.ctx, fakeFetchID, tt.opts...); err != nil {
+				t.Fatalf("FetchCache.FetchWithOptions() error = %v", err)
+			}
+
+			allocs := testing.AllocsPerRun(100, func() {
+				_, _ = fc.FetchWithOptions(tt.ctx, fakeFetchID, tt.opts...)
+			})
+			if allocs != 0 {
+				t.Errorf("FetchCache.FetchWithOptions() expect 0 allocations per hit, have %v", allocs)
+			}
+		})
+	}
+}
+
+func BenchmarkFetchCache_Fetch_Hit(b *testing.B) {
+	var (
+		fakeFetchID = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	)
+
+	mockedFetcher := &FetcherMock{
+		FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+			return &Model{Name: "lorem"}, nil
+		},
+	}
+	fc := NewCache(mockedFetcher)
+	ctx := context.Background()
+	_, _ = fc.Fetch(ctx, fakeFetchID)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_, _ = fc.Fetch(ctx, fakeFetchID)
+		}
+	})
+}
+
+func TestFetchCache_LockContext(t *testing.T) {
+	var (
+		fakeFetchID = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	)
+
+	tests := []struct {
+		name    string
+		held    bool
+		wantErr error
+	}{
+		{
+			name: "success free key",
+		},
+		{
+			name:    "failed held key canceled",
+			held:    true,
+			wantErr: context.DeadlineExceeded,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fc := NewCache(&FetcherMock{})
+			defer fc.Close(context.Background())
+			if tt.held {
+				if err := fc.LockContext(context.Background(), fakeFetchID); err != nil {
+					t.Fatalf("FetchCache.LockContext() error = %v", err)
+				}
+				defer fc.Unlock(fakeFetchID)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+			if err := fc.LockContext(ctx, fakeFetchID); err != tt.wantErr {
+				t.Fatalf("FetchCache.LockContext() expect error = %v, have %v", tt.wantErr, err)
+			}
+			if tt.wantErr == nil {
+				fc.Unlock(fakeFetchID)
+			}
+			// a fetch waiting for the key gives up with its context too
+			if !tt.held {
+				return
+			}
+			if _, err := fc.Fetch(ctx, fakeFetchID); err != tt.wantErr {
+				t.Errorf("FetchCache.Fetch() expect error = %v, have %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+// Test a fetch joining the call of another is released at its own deadline
+// while the call and the other fetches go on
+func TestFetchCache_Fetch_JoinDeadline(t *testing.T) {
+	var fakeFetchID = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	started := make(chan struct{})
+	release := make(chan struct{})
+	mockedFetcher := &FetcherMock{
+		FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+			close(started)
+			<-release
+			return &Model{Name: id}, ctx.Err()
+		},
+	}
+	fc := NewCache(mockedFetcher)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for n := range errs {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			if n == 1 {
+				<-started
+			}
+			_, errs[n] = fc.Fetch(ctx, fakeFetchID)
+		}(n)
+	}
+	<-started
+	for f := fc.InFlight(); len(f) == 0 || f[0].Waiters == 0; f = fc.InFlight() {
+		time.Sleep(time.Millisecond)
+	}
+
+	joinCtx, joinCancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer joinCancel()
+	begin := time.Now()
+	_, err := fc.Fetch(joinCtx, fakeFetchID)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("FetchCache.Fetch() expect error = %v, have %v", context.DeadlineExceeded, err)
+	}
+	if elapsed := time.Since(begin); elapsed > time.Second {
+		t.Errorf("FetchCache.Fetch() expect released at its deadline, have %v", elapsed)
+	}
+	if have := fc.Stats().JoinsAbandoned; have != 1 {
+		t.Errorf("FetchCache.Stats() expect JoinsAbandoned = 1, have %v", have)
+	}
+
+	close(release)
+	wg.Wait()
+	if errs[0] != nil || errs[1] != nil {
+		t.Errorf("FetchCache.Fetch() expect no error for the others, have %v", errs)
+	}
+	if calls := len(mockedFetcher.FetchCalls()); calls != 1 {
+		t.Errorf("FetchCache.Fetch() expect a single Fetcher call, have %v", calls)
+	}
+}
@@ -0,0 +1,67 @@
+package resource
+
+import (
+	"context"
+	"sync"
+)
+
+// FetchMany fetches all ids concurrently and returns the models found and
+// the error of every id which failed, so one failing id doesn't fail the
+// others. Duplicate ids are fetched once, and like every fetch an id with a
+// Fetcher call in flight, for a Fetch, another FetchMany or a batch of
+// WithBatchFetcher, is served the result of that call, see InFlight. When
+// the L2Store of WithL2 is a BatchL2 the ids missing in memory and not in
+// flight are looked up in it with one call.
+//
+// With the AllOrNothing option the first failure cancels the outstanding
+// fetches and FetchMany returns nil models. The fetches run at most
+// WithFetchManyConcurrency at a time and are canceled by Close.
+func (fc *FetchCache) FetchMany(ctx context.Context, ids []string, opts ...FetchOption) (map[string]*Model, map[string]error) {
+	o := newFetchOptions(fetchOptionsFromContext(ctx), opts)
+	if fc.opts.l2 != nil {
+		ctx = fc.prefetchL2(ctx, ids, o)
+	}
+
+	var (
+		mu     sync.Mutex
+		g      = fc.newGroup(ctx, fc.opts.fetchManyConcurrency, o.allOrNothing)
+		models = make(map[string]*Model, len(ids))
+		errs   = make(map[string]error)
+		seen   = make(map[string]struct{}, len(ids))
+	)
+	for _, id := range ids {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+
+		id := id
+		fetch := func(ctx context.Context) error {
+			model, err := fc.FetchWithOptions(ctx, id, opts...)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs[id] = err
+				return err
+			}
+			models[id] = model
+			return nil
+		}
+		if !g.Go(fetch) {
+			if o.allOrNothing {
+				break
+			}
+			// canceled: the cached ids are still served
+			_ = fetch(g.ctx)
+		}
+	}
+	_ = g.Wait()
+
+	if len(errs) == 0 {
+		return models, nil
+	}
+	if o.allOrNothing {
+		return nil, errs
+	}
+	return models, errs
+}
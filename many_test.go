@@ -0,0 +1,161 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFetchCache_FetchMany(t *testing.T) {
+	var (
+		notExistModelID = "5634aeed-2106-43de-ab7d-c0ad4b1e195e"
+	)
+
+	mockedFetcher := &FetcherMock{
+		FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+			if id == notExistModelID {
+				return nil, errors.New("not found model")
+			}
+
+			return &Model{Name: id}, nil
+		},
+	}
+
+	tests := []struct {
+		name           string
+		ids            []string
+		opts           []FetchOption
+		ctxOpts        []FetchOption
+		wantModelCount int
+		wantErrCount   int
+	}{
+		{
+			name:           "success fetch all ids",
+			ids:            []string{"a", "b", "c", "a"},
+			wantModelCount: 3,
+		},
+		{
+			name:           "success return partial results",
+			ids:            []string{"a", "b", notExistModelID},
+			wantModelCount: 2,
+			wantErrCount:   1,
+		},
+		{
+			name:         "failed all or nothing",
+			ids:          []string{"a", "b", notExistModelID},
+			opts:         []FetchOption{AllOrNothing()},
+			wantErrCount: 1,
+		},
+		{
+			name:         "failed all or nothing from context",
+			ids:          []string{"a", "b", notExistModelID},
+			ctxOpts:      []FetchOption{AllOrNothing()},
+			wantErrCount: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fc := NewCache(mockedFetcher)
+			ctx := WithFetchOptions(context.Background(), tt.ctxOpts...)
+			models, errs := fc.FetchMany(ctx, tt.ids, tt.opts...)
+			if len(models) != tt.wantModelCount {
+				t.Errorf("FetchCache.FetchMany() expect model count = %v, have %v", tt.wantModelCount, len(models))
+			}
+			if len(errs) != tt.wantErrCount {
+				t.Errorf("FetchCache.FetchMany() expect error count = %v, have %v", tt.wantErrCount, len(errs))
+			}
+			if _, ok := errs[notExistModelID]; tt.wantErrCount > 0 && !ok {
+				t.Errorf("FetchCache.FetchMany() expect error for %v, have %v", notExistModelID, errs)
+			}
+		})
+	}
+}
+
+func TestFetchCache_FetchMany_InFlight(t *testing.T) {
+	var (
+		fakeFetchID     = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+		notExistModelID = "5634aeed-2106-43de-ab7d-c0ad4b1e195e"
+	)
+
+	tests := []struct {
+		name  string
+		batch bool
+	}{
+		{
+			name: "success shared with Fetch",
+		},
+		{
+			name:  "success shared with a batch",
+			batch: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				mu    sync.Mutex
+				calls = map[string]int{}
+			)
+			entered, release := make(chan struct{}), make(chan struct{})
+			fetch := func(id string) *Model {
+				mu.Lock()
+				calls[id]++
+				first := calls[id] == 1 && id == fakeFetchID
+				mu.Unlock()
+				if first {
+					close(entered)
+					<-release
+				}
+				return &Model{Name: id}
+			}
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					return fetch(id), nil
+				},
+			}
+			var opts []Option
+			if tt.batch {
+				opts = append(opts, WithBatchFetcher(batchFetcherFunc(func(ctx context.Context, ids []string) (map[string]*Model, error) {
+					models := make(map[string]*Model, len(ids))
+					for _, id := range ids {
+						models[id] = fetch(id)
+					}
+					return models, nil
+				}), time.Millisecond, 1))
+			}
+			fc := NewCache(mockedFetcher, opts...)
+			defer fc.Close(context.Background())
+
+			done := make(chan struct{})
+			go func() {
+				_, _ = fc.Fetch(context.Background(), fakeFetchID)
+				close(done)
+			}()
+			<-entered
+			many := make(chan map[string]*Model)
+			go func() {
+				models, _ := fc.FetchMany(context.Background(), []string{fakeFetchID, notExistModelID})
+				many <- models
+			}()
+			for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(time.Millisecond) {
+				if f := fc.InFlight(); len(f) == 1 && f[0].Waiters == 1 {
+					break
+				}
+				if time.Now().After(deadline) {
+					t.Fatalf("FetchCache.InFlight() expect 1 waiter, have %+v", fc.InFlight())
+				}
+			}
+			close(release)
+			<-done
+			models := <-many
+
+			if len(models) != 2 || models[fakeFetchID] == nil {
+				t.Errorf("FetchCache.FetchMany() expect 2 models, have %v", models)
+			}
+			if calls[fakeFetchID] != 1 || calls[notExistModelID] != 1 {
+				t.Errorf("FetchCache.FetchMany() expect one call per id, have %v", calls)
+			}
+		})
+	}
+}
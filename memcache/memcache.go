@@ -0,0 +1,391 @@
+// Package memcache implements resource.L2Store, resource.BatchL2 and
+// resource.LockingL2 over a memcached cluster using the memcached text
+// protocol.
+package memcache
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Const list
+const (
+	// maxKeyLength is the longest key accepted by memcached
+	maxKeyLength = 250
+	// maxRelativeExpiration is the longest expiration memcached reads as
+	// seconds from now, longer ones must be unix timestamps
+	maxRelativeExpiration = 30 * 24 * time.Hour
+	defaultReplicas       = 100
+	defaultTimeout        = 500 * time.Millisecond
+	defaultMaxIdleConns   = 2
+)
+
+// Error list
+var (
+	ErrNoServers = errors.New("memcache: no servers configured")
+	ErrServer    = errors.New("memcache: server error")
+)
+
+// Store is a resource.L2Store backed by memcached servers, keys are
+// consistently hashed across the servers.
+type Store struct {
+	// Timeout bounds each network operation when ctx has no earlier deadline.
+	Timeout time.Duration
+	// MaxIdleConns is the number of idle connections kept per server.
+	MaxIdleConns int
+
+	mu     sync.Mutex
+	ring   []uint32
+	owners map[uint32]string
+	idle   map[string][]*conn
+	dialer net.Dialer
+}
+
+// New creates a Store for the given "host:port" servers.
+func New(servers ...string) *Store {
+	s := &Store{
+		Timeout:      defaultTimeout,
+		MaxIdleConns: defaultMaxIdleConns,
+		owners:       make(map[uint32]string),
+		idle:         make(map[string][]*conn),
+	}
+	for _, server := range servers {
+		for i := 0; i < defaultReplicas; i++ {
+			h := crc32.ChecksumIEEE([]byte(server + "-" + strconv.Itoa(i)))
+			s.ring = append(s.ring, h)
+			s.owners[h] = server
+		}
+	}
+	sort.Slice(s.ring, func(a, b int) bool { return s.ring[a] < s.ring[b] })
+	return s
+}
+
+// Get implements resource.L2Store.
+func (s *Store) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	key = legalKey(key)
+	var (
+		value []byte
+		found bool
+	)
+	err := s.do(ctx, key, func(c *conn) error {
+		return c.get("get", []string{key}, func(_ string, v []byte, _ string) {
+			value, found = v, true
+		})
+	})
+	return value, found, err
+}
+
+// GetMany implements resource.BatchL2, the keys of each server are read
+// with a single get command.
+func (s *Store) GetMany(ctx context.Context, keys []string) (map[string][]byte, error) {
+	byServer := make(map[string][]string)
+	original := make(map[string]string, len(keys))
+	for _, key := range keys {
+		legal := legalKey(key)
+		server, err := s.pick(legal)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := original[legal]; !ok {
+			byServer[server] = append(byServer[server], legal)
+		}
+		original[legal] = key
+	}
+
+	values := make(map[string][]byte, len(keys))
+	for _, legal := range byServer {
+		err := s.do(ctx, legal[0], func(c *conn) error {
+			return c.get("get", legal, func(key string, v []byte, _ string) {
+				if key, ok := original[key]; ok {
+					values[key] = v
+				}
+			})
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// Set implements resource.L2Store.
+func (s *Store) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	key = legalKey(key)
+	return s.do(ctx, key, func(c *conn) error {
+		if _, err := fmt.Fprintf(c.rw, "set %s 0 %d %d\r\n", key, expiration(ttl), len(value)); err != nil {
+			return err
+		}
+		if _, err := c.rw.Write(value); err != nil {
+			return err
+		}
+		if _, err := c.rw.WriteString("\r\n"); err != nil {
+			return err
+		}
+		if err := c.rw.Flush(); err != nil {
+			return err
+		}
+		return c.expect("STORED")
+	})
+}
+
+// Delete implements resource.L2Store.
+func (s *Store) Delete(ctx context.Context, key string) error {
+	key = legalKey(key)
+	return s.do(ctx, key, func(c *conn) error {
+		if _, err := fmt.Fprintf(c.rw, "delete %s\r\n", key); err != nil {
+			return err
+		}
+		if err := c.rw.Flush(); err != nil {
+			return err
+		}
+		return c.expect("DELETED", "NOT_FOUND")
+	})
+}
+
+// Lock implements resource.LockingL2 with add, which only stores a key
+// which doesn't exist, of a random token.
+func (s *Store) Lock(ctx context.Context, key string, ttl time.Duration) (string, bool, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", false, err
+	}
+	token := hex.EncodeToString(b[:])
+	key = legalKey(key)
+	locked := false
+	err := s.do(ctx, key, func(c *conn) error {
+		if _, err := fmt.Fprintf(c.rw, "add %s 0 %d %d\r\n%s\r\n", key, expiration(ttl), len(token), token); err != nil {
+			return err
+		}
+		if err := c.rw.Flush(); err != nil {
+			return err
+		}
+		line, err := c.readLine()
+		if err != nil {
+			return err
+		}
+		switch line {
+		case "STORED":
+			locked = true
+		case "NOT_STORED":
+		default:
+			return fmt.Errorf("%w: %s", ErrServer, line)
+		}
+		return nil
+	})
+	if !locked {
+		token = ""
+	}
+	return token, locked, err
+}
+
+// Unlock implements resource.LockingL2, the lock read with gets is only
+// expired by a cas of it, which fails if the lock changed since.
+func (s *Store) Unlock(ctx context.Context, key, token string) error {
+	key = legalKey(key)
+	return s.do(ctx, key, func(c *conn) error {
+		var unique string
+		err := c.get("gets", []string{key}, func(_ string, v []byte, cas string) {
+			if string(v) == token {
+				unique = cas
+			}
+		})
+		if err != nil || unique == "" {
+			return err
+		}
+		if _, err := fmt.Fprintf(c.rw, "cas %s 0 -1 0 %s\r\n\r\n", key, unique); err != nil {
+			return err
+		}
+		if err := c.rw.Flush(); err != nil {
+			return err
+		}
+		return c.expect("STORED", "EXISTS", "NOT_FOUND")
+	})
+}
+
+// Close closes the idle connections.
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for server, conns := range s.idle {
+		for _, c := range conns {
+			c.nc.Close()
+		}
+		delete(s.idle, server)
+	}
+	return nil
+}
+
+// do runs fn on a connection to the server owning key
+func (s *Store) do(ctx context.Context, key string, fn func(c *conn) error) error {
+	server, err := s.pick(key)
+	if err != nil {
+		return err
+	}
+	c, err := s.getConn(ctx, server)
+	if err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(s.Timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	if err := c.nc.SetDeadline(deadline); err != nil {
+		c.nc.Close()
+		return err
+	}
+	if err := fn(c); err != nil {
+		// the connection state is unknown after a failure
+		c.nc.Close()
+		return err
+	}
+	s.putConn(server, c)
+	return nil
+}
+
+func (s *Store) pick(key string) (string, error) {
+	if len(s.ring) == 0 {
+		return "", ErrNoServers
+	}
+	h := crc32.ChecksumIEEE([]byte(key))
+	i := sort.Search(len(s.ring), func(i int) bool { return s.ring[i] >= h })
+	if i == len(s.ring) {
+		i = 0
+	}
+	return s.owners[s.ring[i]], nil
+}
+
+func (s *Store) getConn(ctx context.Context, server string) (*conn, error) {
+	s.mu.Lock()
+	if conns := s.idle[server]; len(conns) > 0 {
+		c := conns[len(conns)-1]
+		s.idle[server] = conns[:len(conns)-1]
+		s.mu.Unlock()
+		return c, nil
+	}
+	s.mu.Unlock()
+
+	dialCtx, cancel := context.WithTimeout(ctx, s.Timeout)
+	defer cancel()
+	nc, err := s.dialer.DialContext(dialCtx, "tcp", server)
+	if err != nil {
+		return nil, err
+	}
+	return &conn{nc: nc, rw: bufio.NewReadWriter(bufio.NewReader(nc), bufio.NewWriter(nc))}, nil
+}
+
+func (s *Store) putConn(server string, c *conn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.idle[server]) >= s.MaxIdleConns {
+		c.nc.Close()
+		return
+	}
+	s.idle[server] = append(s.idle[server], c)
+}
+
+// conn is a connection to a memcached server
+type conn struct {
+	nc net.Conn
+	rw *bufio.ReadWriter
+}
+
+// get sends the get or gets command cmd for keys and calls fn for every
+// value returned, with its cas unique for gets
+func (c *conn) get(cmd string, keys []string, fn func(key string, value []byte, cas string)) error {
+	if _, err := fmt.Fprintf(c.rw, "%s %s\r\n", cmd, strings.Join(keys, " ")); err != nil {
+		return err
+	}
+	if err := c.rw.Flush(); err != nil {
+		return err
+	}
+	for {
+		line, err := c.readLine()
+		if err != nil {
+			return err
+		}
+		if line == "END" {
+			return nil
+		}
+		// VALUE <key> <flags> <bytes> [<cas unique>]
+		fields := strings.Fields(line)
+		if len(fields) < 4 || len(fields) > 5 || fields[0] != "VALUE" {
+			return fmt.Errorf("%w: %s", ErrServer, line)
+		}
+		size, err := strconv.Atoi(fields[3])
+		if err != nil {
+			return fmt.Errorf("%w: %s", ErrServer, line)
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(c.rw, buf); err != nil {
+			return err
+		}
+		cas := ""
+		if len(fields) == 5 {
+			cas = fields[4]
+		}
+		fn(fields[1], buf[:size], cas)
+	}
+}
+
+func (c *conn) readLine() (string, error) {
+	line, err := c.rw.ReadSlice('\n')
+	if err != nil {
+		return "", err
+	}
+	return string(bytes.TrimRight(line, "\r\n")), nil
+}
+
+// expect reads a reply line and fails unless it is one of want
+func (c *conn) expect(want ...string) error {
+	line, err := c.readLine()
+	if err != nil {
+		return err
+	}
+	for _, w := range want {
+		if line == w {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrServer, line)
+}
+
+// expiration maps ttl to a memcached expiration time: 0 for none, seconds
+// up to 30 days, a unix timestamp beyond. Sub-second TTLs round up to 1s.
+func expiration(ttl time.Duration) int64 {
+	if ttl <= 0 {
+		return 0
+	}
+	if ttl > maxRelativeExpiration {
+		return time.Now().Add(ttl).Unix()
+	}
+	return int64((ttl + time.Second - 1) / time.Second)
+}
+
+// legalKey returns key, or a hash of it when memcached would reject it
+// for its length or characters
+func legalKey(key string) string {
+	legal := len(key) > 0 && len(key) <= maxKeyLength
+	for i := 0; legal && i < len(key); i++ {
+		legal = key[i] > ' ' && key[i] != 0x7f
+	}
+	if legal {
+		return key
+	}
+	sum := sha1.Sum([]byte(key))
+	return "sha1:" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,231 @@
+package memcache
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeServer is a memcached server supporting get and gets of several
+// keys, set, add, cas and delete
+type fakeServer struct {
+	ln    net.Listener
+	mu    sync.Mutex
+	data  map[string][]byte
+	exps  map[string]int64
+	casID map[string]int
+}
+
+func newFakeServer(t *testing.T) *fakeServer {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() error = %v", err)
+	}
+	s := &fakeServer{ln: ln, data: make(map[string][]byte), exps: make(map[string]int64), casID: make(map[string]int)}
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(c)
+		}
+	}()
+	return s
+}
+
+func (s *fakeServer) serve(c net.Conn) {
+	defer c.Close()
+	rw := bufio.NewReadWriter(bufio.NewReader(c), bufio.NewWriter(c))
+	for {
+		line, err := rw.ReadString('\n')
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		s.mu.Lock()
+		switch fields[0] {
+		case "get":
+			for _, key := range fields[1:] {
+				if v, ok := s.data[key]; ok {
+					fmt.Fprintf(rw, "VALUE %s 0 %d\r\n%s\r\n", key, len(v), v)
+				}
+			}
+			rw.WriteString("END\r\n")
+		case "gets":
+			for _, key := range fields[1:] {
+				if v, ok := s.data[key]; ok {
+					fmt.Fprintf(rw, "VALUE %s 0 %d %d\r\n%s\r\n", key, len(v), s.casID[key], v)
+				}
+			}
+			rw.WriteString("END\r\n")
+		case "cas":
+			size, _ := strconv.Atoi(fields[4])
+			exp, _ := strconv.ParseInt(fields[3], 10, 64)
+			buf := make([]byte, size+2)
+			io.ReadFull(rw, buf)
+			switch _, ok := s.data[fields[1]]; {
+			case !ok:
+				rw.WriteString("NOT_FOUND\r\n")
+			case strconv.Itoa(s.casID[fields[1]]) != fields[5]:
+				rw.WriteString("EXISTS\r\n")
+			case exp < 0:
+				delete(s.data, fields[1])
+				rw.WriteString("STORED\r\n")
+			default:
+				s.data[fields[1]] = buf[:size]
+				s.casID[fields[1]]++
+				rw.WriteString("STORED\r\n")
+			}
+		case "set":
+			size, _ := strconv.Atoi(fields[4])
+			exp, _ := strconv.ParseInt(fields[3], 10, 64)
+			buf := make([]byte, size+2)
+			io.ReadFull(rw, buf)
+			s.data[fields[1]] = buf[:size]
+			s.exps[fields[1]] = exp
+			s.casID[fields[1]]++
+			rw.WriteString("STORED\r\n")
+		case "add":
+			size, _ := strconv.Atoi(fields[4])
+			buf := make([]byte, size+2)
+			io.ReadFull(rw, buf)
+			if _, ok := s.data[fields[1]]; ok {
+				rw.WriteString("NOT_STORED\r\n")
+			} else {
+				s.data[fields[1]] = buf[:size]
+				s.casID[fields[1]]++
+				rw.WriteString("STORED\r\n")
+			}
+		case "delete":
+			if _, ok := s.data[fields[1]]; ok {
+				delete(s.data, fields[1])
+				rw.WriteString("DELETED\r\n")
+			} else {
+				rw.WriteString("NOT_FOUND\r\n")
+			}
+		}
+		s.mu.Unlock()
+		rw.Flush()
+	}
+}
+
+func TestStore(t *testing.T) {
+	servers := []*fakeServer{newFakeServer(t), newFakeServer(t)}
+	var addrs []string
+	for _, s := range servers {
+		defer s.ln.Close()
+		addrs = append(addrs, s.ln.Addr().String())
+	}
+	store := New(addrs...)
+	defer store.Close()
+	ctx := context.Background()
+
+	keys := []string{"a", "b", "c", "d", "e", "f", "g", "h", strings.Repeat("long", 100), "with space"}
+	for _, key := range keys {
+		if err := store.Set(ctx, key, []byte("value-"+key), time.Minute); err != nil {
+			t.Fatalf("Store.Set() error = %v", err)
+		}
+	}
+	for _, key := range keys {
+		got, found, err := store.Get(ctx, key)
+		if err != nil || !found || string(got) != "value-"+key {
+			t.Errorf("Store.Get(%q) = %q, %v, %v", key, got, found, err)
+		}
+	}
+	values, err := store.GetMany(ctx, append([]string{"missing"}, keys...))
+	if err != nil || len(values) != len(keys) {
+		t.Errorf("Store.GetMany() = %v keys, %v", len(values), err)
+	}
+	for _, key := range keys {
+		if got := values[key]; string(got) != "value-"+key {
+			t.Errorf("Store.GetMany()[%q] = %q", key, got)
+		}
+	}
+	for _, s := range servers {
+		s.mu.Lock()
+		n := len(s.data)
+		s.mu.Unlock()
+		if n == 0 {
+			t.Errorf("Store expect keys spread over all servers, have no keys on %v", s.ln.Addr())
+		}
+	}
+
+	if err := store.Delete(ctx, "a"); err != nil {
+		t.Errorf("Store.Delete() error = %v", err)
+	}
+	if err := store.Delete(ctx, "a"); err != nil {
+		t.Errorf("Store.Delete() missing key error = %v", err)
+	}
+	if _, found, err := store.Get(ctx, "a"); found || err != nil {
+		t.Errorf("Store.Get() after delete found = %v, err = %v", found, err)
+	}
+}
+
+func TestStore_Lock(t *testing.T) {
+	server := newFakeServer(t)
+	defer server.ln.Close()
+	store := New(server.ln.Addr().String())
+	defer store.Close()
+	ctx := context.Background()
+
+	token, locked, err := store.Lock(ctx, "a#lock", time.Minute)
+	if !locked || token == "" || err != nil {
+		t.Errorf("Store.Lock() = %q, %v, %v", token, locked, err)
+	}
+	if _, locked, err := store.Lock(ctx, "a#lock", time.Minute); locked || err != nil {
+		t.Errorf("Store.Lock() held = %v, %v", locked, err)
+	}
+	if err := store.Unlock(ctx, "a#lock", token); err != nil {
+		t.Errorf("Store.Unlock() error = %v", err)
+	}
+	other, locked, err := store.Lock(ctx, "a#lock", time.Minute)
+	if !locked || other == token || err != nil {
+		t.Errorf("Store.Lock() unlocked = %q, %v, %v", other, locked, err)
+	}
+
+	// the first holder, whose lock expired, doesn't release the lock of
+	// the next one
+	if err := store.Unlock(ctx, "a#lock", token); err != nil {
+		t.Errorf("Store.Unlock() of an expired lock error = %v", err)
+	}
+	if _, locked, _ := store.Lock(ctx, "a#lock", time.Minute); locked {
+		t.Errorf("Store.Unlock() of an expired lock expect the lock of the next holder kept")
+	}
+	if err := store.Unlock(ctx, "a#lock", other); err != nil {
+		t.Errorf("Store.Unlock() error = %v", err)
+	}
+	if _, locked, _ := store.Lock(ctx, "a#lock", time.Minute); !locked {
+		t.Errorf("Store.Unlock() expect the lock released by its holder")
+	}
+}
+
+func Test_expiration(t *testing.T) {
+	tests := []struct {
+		name string
+		ttl  time.Duration
+		want int64
+	}{
+		{name: "success no expiration", ttl: 0, want: 0},
+		{name: "success round up sub second", ttl: time.Millisecond, want: 1},
+		{name: "success relative seconds", ttl: time.Hour, want: 3600},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := expiration(tt.ttl); got != tt.want {
+				t.Errorf("expiration() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if got := expiration(60 * 24 * time.Hour); got < time.Now().Unix() {
+		t.Errorf("expiration() expect unix timestamp for long ttl, have %v", got)
+	}
+}
@@ -0,0 +1,92 @@
+package resource
+
+import "unsafe"
+
+// mapGroupSlots and mapMaxLoad are the slots of a group of a Go map and
+// the fraction of the slots a map fills before growing
+const (
+	mapGroupSlots = 8
+	mapMaxLoad    = 7.0 / 8
+)
+
+// MemoryEstimate is an estimate of the memory held by the cached items, see
+// FetchCache.EstimateMemory.
+type MemoryEstimate struct {
+	Items int
+	// Keys, Values and Meta are the bytes of the keys, of the models as
+	// measured by the SizeFunc of WithMaxValueSize, DefaultSize by default,
+	// and of the metadata and origin versions.
+	Keys   int64
+	Values int64
+	Meta   int64
+	// Overhead is the bytes of the item and model structs and of the slots
+	// of the Go maps holding them, empty slots included.
+	Overhead int64
+	// Total is the sum of the above.
+	Total int64
+	// LoadFactor is the fraction of the slots of the maps in use. Maps
+	// grow as needed but never shrink, the slots are those of the most
+	// items they held since Compact last rebuilt them.
+	LoadFactor float64
+	// Shards is the number of items of every shard of a ShardedStore, nil
+	// with another Store.
+	Shards []int
+}
+
+// EstimateMemory returns an estimate of the memory held by the cached items
+// for capacity planning, without a heap profile. It looks at every item
+// under the read lock of the items, so it costs as much as a Snapshot.
+func (fc *FetchCache) EstimateMemory() MemoryEstimate {
+	size := fc.opts.sizeFunc
+	if size == nil {
+		size = DefaultSize
+	}
+	var e MemoryEstimate
+	fc.rlockItems()
+	e.Items = fc.items.Len()
+	fc.items.Range(func(key string, i item) bool {
+		e.Keys += int64(len(key))
+		e.Meta += int64(len(i.OriginVersion))
+		for k, v := range i.Meta {
+			e.Meta += int64(len(k) + len(v))
+		}
+		if i.Object != nil {
+			e.Values += int64(size(i.Object))
+			e.Overhead += int64(unsafe.Sizeof(Model{}))
+		}
+		return true
+	})
+	slots := 0
+	switch s := fc.items.(type) {
+	case *ShardedStore:
+		e.Shards = s.Occupancy()
+		for _, peak := range s.peaks {
+			slots += mapSlots(peak)
+		}
+	case mapStore:
+		slots = mapSlots(fc.peak)
+	default:
+		slots = mapSlots(e.Items)
+	}
+	fc.itemsLock.RUnlock()
+	if slots > 0 {
+		e.LoadFactor = float64(e.Items) / float64(slots)
+	}
+	// a slot holds the key header and the item, plus a control byte
+	e.Overhead += int64(slots) * int64(unsafe.Sizeof("")+unsafe.Sizeof(Item{})+1)
+	e.Total = e.Keys + e.Values + e.Meta + e.Overhead
+	return e
+}
+
+// mapSlots returns the slots of a Go map grown to hold n items, a power of
+// two number of groups filled to at most mapMaxLoad
+func mapSlots(n int) int {
+	if n == 0 {
+		return 0
+	}
+	slots := mapGroupSlots
+	for float64(n) > float64(slots)*mapMaxLoad {
+		slots *= 2
+	}
+	return slots
+}
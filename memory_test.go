@@ -0,0 +1,83 @@
+package resource
+
+import (
+	"context"
+	"strconv"
+	"testing"
+)
+
+func TestFetchCache_EstimateMemory(t *testing.T) {
+	mockedFetcher := &FetcherMock{
+		FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+			return &Model{Name: id, Data: make([]byte, 100)}, nil
+		},
+	}
+	tests := []struct {
+		name       string
+		opts       []Option
+		items      int
+		wantValues int64
+		wantShards int
+	}{
+		{
+			name:       "success map store",
+			items:      10,
+			wantValues: 10 * (1 + 100),
+		},
+		{
+			name:       "success sharded store",
+			opts:       []Option{WithStore(NewShardedStore(4, nil, nil))},
+			items:      10,
+			wantValues: 10 * (1 + 100),
+			wantShards: 4,
+		},
+		{
+			name:       "success size func",
+			opts:       []Option{WithMaxValueSize(1000, func(m *Model) int { return 1 })},
+			items:      10,
+			wantValues: 10,
+		},
+		{
+			name: "success empty",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fc := NewCache(mockedFetcher, tt.opts...)
+			for i := 0; i < tt.items; i++ {
+				_, _ = fc.Fetch(context.Background(), strconv.Itoa(i))
+			}
+			e := fc.EstimateMemory()
+			if e.Items != tt.items || e.Keys != int64(tt.items) || e.Values != tt.wantValues {
+				t.Errorf("FetchCache.EstimateMemory() expect items, keys, values = %v, %v, %v, have %v, %v, %v", tt.items, tt.items, tt.wantValues, e.Items, e.Keys, e.Values)
+			}
+			if len(e.Shards) != tt.wantShards {
+				t.Errorf("FetchCache.EstimateMemory() expect %v shards, have %v", tt.wantShards, e.Shards)
+			}
+			if tt.items > 0 && (e.LoadFactor <= 0 || e.LoadFactor > mapMaxLoad || e.Overhead <= 0) {
+				t.Errorf("FetchCache.EstimateMemory() expect a load factor and an overhead, have %+v", e)
+			}
+			if e.Total != e.Keys+e.Values+e.Meta+e.Overhead {
+				t.Errorf("FetchCache.EstimateMemory() expect the total of the parts, have %+v", e)
+			}
+		})
+	}
+}
+
+func Test_mapSlots(t *testing.T) {
+	tests := []struct {
+		n    int
+		want int
+	}{
+		{n: 0, want: 0},
+		{n: 1, want: 8},
+		{n: 7, want: 8},
+		{n: 8, want: 16},
+		{n: 100, want: 128},
+	}
+	for _, tt := range tests {
+		if have := mapSlots(tt.n); have != tt.want {
+			t.Errorf("mapSlots(%v) expect %v, have %v", tt.n, tt.want, have)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package resource
+
+// MetadataFunc returns the metadata of the model of id about to be cached,
+// such as its source region or build id, see WithMetadata.
+type MetadataFunc func(id string, m *Model) map[string]string
+
+// WithMetadata attaches the metadata returned by fn to every item cached,
+// by a fetch or any other way, readable in EntryInfo.Meta. The metadata of
+// a Result is added to it. Like Model.Value it is kept in memory only.
+func WithMetadata(fn MetadataFunc) Option {
+	return func(o *options) {
+		o.metadata = fn
+	}
+}
+
+// metadata returns the metadata of WithMetadata for model, nil without
+func (fc *FetchCache) metadata(id string, model *Model) map[string]string {
+	if fc.opts.metadata == nil {
+		return nil
+	}
+	return fc.opts.metadata(id, model)
+}
+
+// mergeMeta returns the metadata of a with those of b added, b winning
+func mergeMeta(a, b map[string]string) map[string]string {
+	if len(a) == 0 {
+		return b
+	}
+	if len(b) == 0 {
+		return a
+	}
+	m := make(map[string]string, len(a)+len(b))
+	for k, v := range a {
+		m[k] = v
+	}
+	for k, v := range b {
+		m[k] = v
+	}
+	return m
+}
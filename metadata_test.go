@@ -0,0 +1,52 @@
+package resource
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestWithMetadata(t *testing.T) {
+	var (
+		fakeFetchID = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	)
+	region := func(id string, m *Model) map[string]string {
+		return map[string]string{"region": "eu-west-1", "source": "hook"}
+	}
+
+	tests := []struct {
+		name     string
+		fetcher  Fetcher
+		metadata MetadataFunc
+		want     map[string]string
+	}{
+		{
+			name:    "success no metadata",
+			fetcher: &resultFetcherMock{},
+		},
+		{
+			name:     "success metadata of the hook",
+			fetcher:  &resultFetcherMock{},
+			metadata: region,
+			want:     map[string]string{"region": "eu-west-1", "source": "hook"},
+		},
+		{
+			name:     "success metadata of the result added",
+			fetcher:  &resultFetcherMock{result: Result{Meta: map[string]string{"source": "origin", "build": "42"}}},
+			metadata: region,
+			want:     map[string]string{"region": "eu-west-1", "source": "origin", "build": "42"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fc := NewCache(tt.fetcher, WithMetadata(tt.metadata))
+			if _, err := fc.Fetch(context.Background(), fakeFetchID); err != nil {
+				t.Fatalf("FetchCache.Fetch() error = %v", err)
+			}
+			info, _ := fc.Entry(fakeFetchID)
+			if !reflect.DeepEqual(info.Meta, tt.want) {
+				t.Errorf("FetchCache.Entry() expect meta = %v, have %v", tt.want, info.Meta)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package resource
+
+import "sort"
+
+// WithKeys runs fn holding the locks of ids, so no fetch, refresh or clear
+// of them runs concurrently. The locks are taken in a canonical order and
+// concurrent WithKeys calls over overlapping ids can't deadlock, unlike
+// taking them one by one with Lock.
+//
+// fn must not call methods locking ids itself, such as a Fetch missing one
+// of them or Clear, as the locks are not reentrant.
+func (fc *FetchCache) WithKeys(ids []string, fn func() error) error {
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = fc.key(fc.canonical(id))
+	}
+	unlock := fc.lockKeys(keys)
+	defer unlock()
+	return fn()
+}
+
+// lockKeys locks keys in sorted order, skipping duplicates, and returns
+// the func unlocking them
+func (fc *FetchCache) lockKeys(keys []string) func() {
+	locks := append([]string(nil), keys...)
+	sort.Strings(locks)
+	n := 0
+	for i, key := range locks {
+		if i == 0 || key != locks[i-1] {
+			locks[n] = key
+			n++
+		}
+	}
+	locks = locks[:n]
+	for _, key := range locks {
+		fc.lock(key)
+	}
+	return func() {
+		for _, key := range locks {
+			fc.unlock(key)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFetchCache_WithKeys(t *testing.T) {
+	var (
+		fakeFetchID     = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+		notExistModelID = "5634aeed-2106-43de-ab7d-c0ad4b1e195e"
+		errFn           = errors.New("lorem")
+	)
+
+	tests := []struct {
+		name    string
+		ids     []string
+		fnErr   error
+		wantErr error
+	}{
+		{
+			name: "success",
+			ids:  []string{fakeFetchID, notExistModelID},
+		},
+		{
+			name: "success duplicated ids",
+			ids:  []string{fakeFetchID, fakeFetchID},
+		},
+		{
+			name:    "failed fn error",
+			ids:     []string{fakeFetchID},
+			fnErr:   errFn,
+			wantErr: errFn,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fetching int32
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					atomic.AddInt32(&fetching, 1)
+					return &Model{Name: "lorem"}, nil
+				},
+			}
+			fc := NewCache(mockedFetcher)
+			defer fc.Close(context.Background())
+
+			done := make(chan struct{})
+			err := fc.WithKeys(tt.ids, func() error {
+				go func() {
+					defer close(done)
+					_, _ = fc.Fetch(context.Background(), fakeFetchID)
+				}()
+				// the fetch waits on the lock while fn runs
+				for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+					if _, waiters := fc.inflight.snapshot(); len(waiters) > 0 {
+						break
+					}
+				}
+				if n := atomic.LoadInt32(&fetching); n != 0 {
+					t.Errorf("FetchCache.WithKeys() expect no fetch while locked, have %v", n)
+				}
+				return tt.fnErr
+			})
+			<-done
+			if err != tt.wantErr {
+				t.Errorf("FetchCache.WithKeys() expect error = %v, have %v", tt.wantErr, err)
+			}
+			if n := atomic.LoadInt32(&fetching); n != 1 {
+				t.Errorf("FetchCache.WithKeys() expect fetch after unlock, have %v calls", n)
+			}
+		})
+	}
+}
+
+func TestFetchCache_WithKeys_NoDeadlock(t *testing.T) {
+	var (
+		fakeFetchID     = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+		notExistModelID = "5634aeed-2106-43de-ab7d-c0ad4b1e195e"
+	)
+	fc := NewCache(&FetcherMock{})
+	defer fc.Close(context.Background())
+
+	var wg sync.WaitGroup
+	for _, ids := range [][]string{{fakeFetchID, notExistModelID}, {notExistModelID, fakeFetchID}} {
+		wg.Add(1)
+		go func(ids []string) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				_ = fc.WithKeys(ids, func() error { return nil })
+			}
+		}(ids)
+	}
+	wg.Wait()
+}
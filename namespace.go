@@ -0,0 +1,87 @@
+package resource
+
+import (
+	"context"
+	"strings"
+)
+
+// Namespace is a view of a FetchCache over the ids under a path, such as
+// "tenantA/datasets": it shares the storage, the Fetcher and the workers
+// of the cache, which sees the ids joined with the path, and applies its
+// FetchOptions to every fetch. Children inherit the options of their
+// parent and the options they add override them, so a namespace may set
+// the TTL, the Expiration or the freshness of its subtree.
+type Namespace struct {
+	fc   *FetchCache
+	path string
+	opts []FetchOption
+}
+
+// Namespace returns the namespace of path, whose fetches apply opts.
+func (fc *FetchCache) Namespace(path string, opts ...FetchOption) *Namespace {
+	return &Namespace{fc: fc, path: strings.Trim(path, "/"), opts: opts}
+}
+
+// Namespace returns the child namespace of path, whose fetches apply the
+// options of ns then opts.
+func (ns *Namespace) Namespace(path string, opts ...FetchOption) *Namespace {
+	all := make([]FetchOption, 0, len(ns.opts)+len(opts))
+	all = append(append(all, ns.opts...), opts...)
+	return &Namespace{fc: ns.fc, path: ns.Key(strings.Trim(path, "/")), opts: all}
+}
+
+// Path returns the path of the namespace.
+func (ns *Namespace) Path() string {
+	return ns.path
+}
+
+// Key returns the id of the cache for id in the namespace.
+func (ns *Namespace) Key(id string) string {
+	if ns.path == "" {
+		return id
+	}
+	return ns.path + "/" + id
+}
+
+// Cache returns the underlying cache.
+func (ns *Namespace) Cache() *FetchCache {
+	return ns.fc
+}
+
+// Fetch returns the model of id in the namespace.
+func (ns *Namespace) Fetch(ctx context.Context, id string) (*Model, error) {
+	return ns.FetchWithOptions(ctx, id)
+}
+
+// FetchWithOptions is Fetch with per-call options, applied after those of
+// the namespace.
+func (ns *Namespace) FetchWithOptions(ctx context.Context, id string, opts ...FetchOption) (*Model, error) {
+	if len(opts) > 0 {
+		opts = append(append(make([]FetchOption, 0, len(ns.opts)+len(opts)), ns.opts...), opts...)
+	} else {
+		opts = ns.opts
+	}
+	return ns.fc.FetchWithOptions(ctx, ns.Key(id), opts...)
+}
+
+// Clear removes id of the namespace, see FetchCache.Clear.
+func (ns *Namespace) Clear(id string) {
+	ns.fc.Clear(ns.Key(id))
+}
+
+// Invalidate clears every cached id of the namespace and of its children
+// at once, see InvalidateSet, and returns how many there were. With
+// WithHashedKeys only the eager keys are known, see WithLoadPolicy.
+func (ns *Namespace) Invalidate(ctx context.Context) int {
+	prefix := ns.Key("")
+	var ids []string
+	for _, id := range ns.fc.cachedIDs() {
+		if strings.HasPrefix(id, prefix) {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) > 0 {
+		ns.fc.InvalidateSet(ctx, ids...)
+	}
+	return len(ids)
+}
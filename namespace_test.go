@@ -0,0 +1,78 @@
+package resource
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFetchCache_Namespace(t *testing.T) {
+	var fakeFetchID = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	var calls int64
+	mockedFetcher := &FetcherMock{
+		FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+			atomic.AddInt64(&calls, 1)
+			return &Model{Name: id}, nil
+		},
+	}
+	fc := NewCache(mockedFetcher, WithTTL(time.Hour))
+	tenant := fc.Namespace("/tenantA/", OverrideTTL(time.Minute))
+	datasets := tenant.Namespace("datasets", OverrideTTL(time.Second))
+	other := fc.Namespace("tenantB")
+
+	tests := []struct {
+		name    string
+		ns      *Namespace
+		wantKey string
+		wantTTL time.Duration
+	}{
+		{
+			name:    "success inherited ttl",
+			ns:      tenant,
+			wantKey: "tenantA/" + fakeFetchID,
+			wantTTL: time.Minute,
+		},
+		{
+			name:    "success overridden ttl",
+			ns:      datasets,
+			wantKey: "tenantA/datasets/" + fakeFetchID,
+			wantTTL: time.Second,
+		},
+		{
+			name:    "success cache ttl",
+			ns:      other,
+			wantKey: "tenantB/" + fakeFetchID,
+			wantTTL: time.Hour,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model, err := tt.ns.Fetch(context.Background(), fakeFetchID)
+			if err != nil || model.Name != tt.wantKey {
+				t.Fatalf("Namespace.Fetch() expect %v, have %v, %v", tt.wantKey, model, err)
+			}
+			info, ok := fc.Entry(tt.wantKey)
+			if !ok {
+				t.Fatalf("FetchCache.Entry() expect %v cached", tt.wantKey)
+			}
+			if ttl := info.Expires.Sub(info.Created); ttl != tt.wantTTL {
+				t.Errorf("Namespace.Fetch() expect ttl = %v, have %v", tt.wantTTL, ttl)
+			}
+		})
+	}
+
+	t.Run("success invalidate subtree", func(t *testing.T) {
+		if n := tenant.Invalidate(context.Background()); n != 2 {
+			t.Errorf("Namespace.Invalidate() expect 2, have %v", n)
+		}
+		for _, key := range []string{"tenantA/" + fakeFetchID, "tenantA/datasets/" + fakeFetchID} {
+			if _, ok := fc.Entry(key); ok {
+				t.Errorf("Namespace.Invalidate() expect %v cleared", key)
+			}
+		}
+		if _, ok := fc.Entry("tenantB/" + fakeFetchID); !ok {
+			t.Errorf("Namespace.Invalidate() expect other namespaces kept")
+		}
+	})
+}
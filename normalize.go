@@ -0,0 +1,19 @@
+package resource
+
+// WithKeyNormalizer applies fn to every id given to the cache before
+// anything else, so spellings of an id which fn maps to the same string,
+// e.g. "ABC" and "abc" when it case-folds, share one entry and one Fetcher
+// call. The Fetcher and aliases get normalized ids, see RegisterAlias.
+func WithKeyNormalizer(fn func(id string) string) Option {
+	return func(o *options) {
+		o.keyNormalizer = fn
+	}
+}
+
+// normalize returns id normalized by the normalizer of WithKeyNormalizer
+func (fc *FetchCache) normalize(id string) string {
+	if fc.opts.keyNormalizer == nil {
+		return id
+	}
+	return fc.opts.keyNormalizer(id)
+}
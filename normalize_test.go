@@ -0,0 +1,96 @@
+package resource
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestWithKeyNormalizer(t *testing.T) {
+	normalize := func(id string) string {
+		return strings.ToLower(strings.TrimSpace(id))
+	}
+
+	tests := []struct {
+		name             string
+		normalizer       func(id string) string
+		ids              []string
+		wantFetchedID    string
+		wantFound        bool
+		serviceCallCount int
+	}{
+		{
+			name:             "success one entry for every spelling",
+			normalizer:       normalize,
+			ids:              []string{"ABC", "abc", " Abc "},
+			wantFetchedID:    "abc",
+			wantFound:        true,
+			serviceCallCount: 1,
+		},
+		{
+			name:             "success without normalizer",
+			ids:              []string{"ABC", "abc", " Abc "},
+			wantFetchedID:    "ABC",
+			serviceCallCount: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fetchedIDs []string
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					fetchedIDs = append(fetchedIDs, id)
+					return &Model{Name: id}, nil
+				},
+			}
+			fc := NewCache(mockedFetcher, WithKeyNormalizer(tt.normalizer))
+			defer fc.Close(context.Background())
+
+			for _, id := range tt.ids {
+				_, _ = fc.Fetch(context.Background(), id)
+			}
+			if len(fetchedIDs) != tt.serviceCallCount || fetchedIDs[0] != tt.wantFetchedID {
+				t.Errorf("FetchCache.Fetch() expect %v calls for %v, have %v", tt.serviceCallCount, tt.wantFetchedID, fetchedIDs)
+			}
+			if _, found := fc.Entry("aBc"); found != tt.wantFound {
+				t.Errorf("FetchCache.Entry() expect found = %v, have %v", tt.wantFound, found)
+			}
+			fc.Clear("ABC ")
+			if _, found := fc.Entry("abc"); found != (tt.normalizer == nil) {
+				t.Errorf("FetchCache.Clear() expect cleared = %v, have %v", tt.normalizer != nil, !found)
+			}
+		})
+	}
+}
+
+func TestWithKeyNormalizer_WriteAndWithKeys(t *testing.T) {
+	mockedFetcher := &FetcherMock{
+		FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+			return &Model{Name: id}, nil
+		},
+	}
+	fc := NewCache(mockedFetcher, WithKeyNormalizer(strings.ToLower))
+	defer fc.Close(context.Background())
+	_, _ = fc.Fetch(context.Background(), "abc")
+
+	aside := NewCacheAside(fc, func(ctx context.Context, id string, model *Model) error {
+		return nil
+	})
+	_, err := aside.Write(context.Background(), "ABC", func(current *Model) (*Model, error) {
+		return &Model{Name: "lorem"}, nil
+	})
+	if err != nil {
+		t.Fatalf("CacheAside.Write() expect error = nil, have %v", err)
+	}
+	if _, found := fc.Entry("abc"); found {
+		t.Errorf("CacheAside.Write() expect the normalized entry cleared")
+	}
+
+	_ = fc.WithKeys([]string{"ABC"}, func() error {
+		if fc.tryLock(fc.key("abc")) {
+			fc.unlock(fc.key("abc"))
+			t.Errorf("FetchCache.WithKeys() expect the normalized key locked")
+		}
+		return nil
+	})
+}
@@ -0,0 +1,41 @@
+package resource
+
+import "errors"
+
+// WithNotFound makes the Fetcher errors for which isNotFound returns true
+// match ErrNotFound with errors.Is, keeping their message and wrapped
+// errors, so the not found errors of any backend get the same treatment
+// from ErrorPolicy, WithErrorCache and the callers.
+func WithNotFound(isNotFound func(err error) bool) Option {
+	return func(o *options) {
+		o.isNotFound = isNotFound
+	}
+}
+
+// notFoundError is a Fetcher error classified as not found
+type notFoundError struct {
+	err error
+}
+
+func (e notFoundError) Error() string {
+	return e.err.Error()
+}
+
+// Is makes the error match ErrNotFound.
+func (e notFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// Unwrap returns the error of the Fetcher.
+func (e notFoundError) Unwrap() error {
+	return e.err
+}
+
+// normalizeNotFound returns err matching ErrNotFound if it is a not found
+// error of the Fetcher, see WithNotFound
+func (fc *FetchCache) normalizeNotFound(err error) error {
+	if err == nil || fc.opts.isNotFound == nil || errors.Is(err, ErrNotFound) || !fc.opts.isNotFound(err) {
+		return err
+	}
+	return notFoundError{err: err}
+}
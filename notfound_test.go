@@ -0,0 +1,86 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// errNoSuchKey is the not found error of a backend
+type errNoSuchKey struct {
+	key string
+}
+
+func (e *errNoSuchKey) Error() string {
+	return "no such key " + e.key
+}
+
+func TestWithNotFound(t *testing.T) {
+	var (
+		fakeFetchID     = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+		notExistModelID = "5634aeed-2106-43de-ab7d-c0ad4b1e195e"
+	)
+	errOther := errors.New("connection reset")
+	isNotFound := func(err error) bool {
+		var nsk *errNoSuchKey
+		return errors.As(err, &nsk)
+	}
+
+	tests := []struct {
+		name         string
+		id           string
+		wantNotFound bool
+		wantErr      error
+		wantCalls    int64
+	}{
+		{
+			name:         "failed backend not found",
+			id:           notExistModelID,
+			wantNotFound: true,
+			wantCalls:    1,
+		},
+		{
+			name:      "failed other error",
+			id:        fakeFetchID,
+			wantErr:   errOther,
+			wantCalls: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int64
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					atomic.AddInt64(&calls, 1)
+					if id == notExistModelID {
+						return nil, &errNoSuchKey{key: id}
+					}
+					return nil, errOther
+				},
+			}
+			fc := NewCache(mockedFetcher, WithNotFound(isNotFound), WithErrorCache(ErrorCache{
+				TTL:       time.Minute,
+				Cacheable: func(id string, err error) bool { return errors.Is(err, ErrNotFound) },
+			}))
+			var err error
+			for i := 0; i < 2; i++ {
+				_, err = fc.Fetch(context.Background(), tt.id)
+			}
+			if errors.Is(err, ErrNotFound) != tt.wantNotFound {
+				t.Errorf("FetchCache.Fetch() expect not found = %v, have %v", tt.wantNotFound, err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("FetchCache.Fetch() expect error = %v, have %v", tt.wantErr, err)
+			}
+			var nsk *errNoSuchKey
+			if tt.wantNotFound && (!errors.As(err, &nsk) || err.Error() != "no such key "+tt.id) {
+				t.Errorf("FetchCache.Fetch() expect the backend error kept, have %v", err)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("FetchCache.Fetch() expect %v Fetcher calls, have %v", tt.wantCalls, calls)
+			}
+		})
+	}
+}
@@ -0,0 +1,239 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// ErrInvalidOptions is returned by New for options which are out of range
+// or conflict with each other.
+var ErrInvalidOptions = errors.New("invalid options")
+
+// Option configures a FetchCache, see New.
+type Option func(*options)
+
+type options struct {
+	ttl             time.Duration
+	ttlJitter       float64
+	maxEntries      int
+	maxConcurrency  int
+	batchFetcher    BatchFetcher
+	batchWindow     time.Duration
+	batchMax        int
+	flags           FlagProvider
+	peers           *HTTPPool
+	l2              L2Store
+	l2Codec         Codec
+	l2LockTTL       time.Duration
+	l2LockPoll      time.Duration
+	seed            *Seed
+	invalidator     Invalidator
+	loadPolicy      LoadPolicy
+	eagerInterval   time.Duration
+	staleWindow     time.Duration
+	staleRetry      time.Duration
+	synchronous     bool
+	keyHash         KeyHash
+	collisionPolicy CollisionPolicy
+	// drift check
+	driftEqual    func(a, b *Model) bool
+	driftInterval time.Duration
+	driftSample   int
+	driftRepair   bool
+	// shadow fetcher
+	shadow       Fetcher
+	shadowEqual  func(model, shadow *Model) bool
+	shadowReport func(Mismatch)
+	// health check
+	health              HealthChecker
+	healthInterval      time.Duration
+	degradedStaleWindow time.Duration
+	evictionPolicy      EvictionPolicy
+	evictionSample      int
+	evictionLog         int
+	dependencies        DependencyFunc
+	audit               func(AuditRecord)
+	traceID             func(ctx context.Context) string
+	maxValueSize        int
+	sizeFunc            SizeFunc
+	backoffMin          time.Duration
+	backoffMax          time.Duration
+	errorPolicy         ErrorPolicy
+	negativeTTL         time.Duration
+	breakerCooldown     time.Duration
+	validator           func(id string, m *Model) error
+	validatorRetries    int
+	recoverPanics       bool
+	panicReport         func(*PanicError)
+	strict              bool
+	strictReport        func(Misuse)
+	encrypter           Encrypter
+	checksums           bool
+	refreshWorkers      int
+	refreshQueueSize    int
+	store               Store
+	stored              func()
+	traceWriter         io.Writer
+	traceRate           float64
+	maxLifetime         time.Duration
+	aliasResolver       ResolverFunc
+	aliasCacheSize      int
+	keyNormalizer       func(id string) string
+	churnEqual          func(prev, next *Model) bool
+	churnWindow         int
+	errorCache          ErrorCache
+	snapshotMigration   SnapshotMigration
+	duplicateFetch      func(id string)
+	refreshContext      ContextFactory
+	metadata            MetadataFunc
+	keyClassifier       KeyClassifier
+	newKeysPerMinute    int
+	emptyTTL            time.Duration
+	cancelOnClear       bool
+	fetchBudget         time.Duration
+	isNotFound          func(err error) bool
+	authorize           func(ctx context.Context, id string) error
+	incidentDuration    time.Duration
+	refreshDebounce     time.Duration
+	compactInterval     time.Duration
+	compactLoad         float64
+	readiness           float64
+	hasReadiness        bool
+	hasEmptyTTL         bool
+	cardinalityAlert    func(key string)
+	// expiry
+	sweepInterval   time.Duration
+	sweepBudget     int
+	wheelResolution time.Duration
+	onExpire        func(key string, model *Model)
+	expiredBatches  chan<- []string
+	// hit ratio watch
+	hitRatioThreshold float64
+	hitRatioWindow    time.Duration
+	hitRatioMin       uint64
+	hitRatioAlert     func(HitRatioAlert)
+	// hot keys
+	hotKeys            HotKeyStore
+	hotKeysN           int
+	hotKeysInterval    time.Duration
+	hotKeysConcurrency int
+	// diagnostics
+	diagnosticsWriter  io.Writer
+	diagnosticsSignals []os.Signal
+	// miss queue
+	missQueueSize    int
+	missQueueTimeout time.Duration
+	// tenant fairness
+	tenant        TenantFunc
+	tenantWeights map[string]int
+	// fetch rate limit
+	fetchRate       float64
+	fetchBurst      int
+	rateLimitPolicy RateLimitPolicy
+	// origin quota
+	quota       int64
+	quotaWindow time.Duration
+	quotaPolicy RateLimitPolicy
+	// snapshot reads
+	snapshotReads    bool
+	snapshotInterval time.Duration
+	// scheduled refresh
+	refreshSchedule    Schedule
+	refreshConcurrency int
+	// parallel work
+	fetchManyConcurrency int
+	warmConcurrency      int
+	headroom             int
+	writeBuffer          int
+	inflightMemory       int64
+}
+
+func newOptions(opts []Option) options {
+	o := options{ttl: DefaultExpiration}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// validate checks the options given to New
+func (o options) validate() error {
+	switch {
+	case o.ttl < 0:
+		return fmt.Errorf("%w: WithTTL must not be negative", ErrInvalidOptions)
+	case o.ttlJitter < 0 || o.ttlJitter >= 1:
+		return fmt.Errorf("%w: WithTTLJitter must be in [0, 1)", ErrInvalidOptions)
+	case o.maxEntries < 0:
+		return fmt.Errorf("%w: WithMaxEntries must not be negative", ErrInvalidOptions)
+	case o.maxConcurrency < 0:
+		return fmt.Errorf("%w: WithMaxConcurrency must not be negative", ErrInvalidOptions)
+	case o.fetchBudget < 0:
+		return fmt.Errorf("%w: WithFetchBudget must not be negative", ErrInvalidOptions)
+	case o.hasReadiness && (o.readiness < 0 || o.readiness > 1):
+		return fmt.Errorf("%w: WithReadiness must be in [0, 1]", ErrInvalidOptions)
+	case o.quota < 0 || o.quota > 0 && o.quotaWindow <= 0:
+		return fmt.Errorf("%w: WithOriginQuota needs a n and a window above 0", ErrInvalidOptions)
+	case o.expiredBatches != nil && o.sweepInterval <= 0 && o.wheelResolution <= 0:
+		return fmt.Errorf("%w: WithExpiredBatches requires WithExpirySweep or WithExpiryWheel", ErrInvalidOptions)
+	case o.l2LockTTL > 0 && o.l2 == nil:
+		return fmt.Errorf("%w: WithL2Lock requires WithL2", ErrInvalidOptions)
+	case o.store != nil && o.store.Len() > 0:
+		return fmt.Errorf("%w: the Store of WithStore must be empty", ErrInvalidOptions)
+	case o.peers != nil && o.peers.Replicas > 1 && o.peers.AuthorizePut == nil:
+		return fmt.Errorf("%w: the HTTPPool of WithPeers needs AuthorizePut with Replicas above 1", ErrInvalidOptions)
+	}
+	if _, ok := o.l2.(LockingL2); o.l2LockTTL > 0 && !ok {
+		return fmt.Errorf("%w: WithL2Lock requires a L2Store implementing LockingL2", ErrInvalidOptions)
+	}
+	return nil
+}
+
+// WithTTL sets how long fetched models are cached, a d of 0 means they
+// never expire. It can be changed with Reconfigure.
+func WithTTL(d time.Duration) Option {
+	return func(o *options) {
+		o.ttl = d
+	}
+}
+
+// WithTTLJitter randomly shortens the TTL of each cached item by up to
+// fraction of it, so items cached together don't all expire together.
+func WithTTLJitter(fraction float64) Option {
+	return func(o *options) {
+		o.ttlJitter = fraction
+	}
+}
+
+// WithMaxEntries bounds the number of cached items, when full an item is
+// evicted to make room, the oldest one unless WithEvictionPolicy says
+// otherwise. A n of 0 means no limit. It can be changed with
+// Reconfigure.
+func WithMaxEntries(n int) Option {
+	return func(o *options) {
+		o.maxEntries = n
+	}
+}
+
+// WithMaxConcurrency bounds the number of concurrent calls to the Fetcher,
+// further misses wait for a free slot or their ctx. A n of 0 means no
+// limit. It can be changed with Reconfigure.
+func WithMaxConcurrency(n int) Option {
+	return func(o *options) {
+		o.maxConcurrency = n
+	}
+}
+
+// WithBatchFetcher collects misses arriving within window and loads them
+// with a single b.FetchBatch call instead of one Fetch per id. A batch is
+// issued early once it holds max ids, a max of 0 means no limit.
+func WithBatchFetcher(b BatchFetcher, window time.Duration, max int) Option {
+	return func(o *options) {
+		o.batchFetcher = b
+		o.batchWindow = window
+		o.batchMax = max
+	}
+}
@@ -0,0 +1,93 @@
+package resource
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNew(t *testing.T) {
+	fakeFetchID := "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	full := NewMapStore()
+	full.Set(fakeFetchID, Item{})
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr error
+	}{
+		{
+			name: "success create without options",
+		},
+		{
+			name: "success create with compatible options",
+			opts: []Option{
+				WithTTL(time.Minute), WithTTLJitter(0.1), WithMaxEntries(10),
+				WithExpirySweep(time.Minute, 0), WithExpiredBatches(make(chan []string, 1)),
+				WithL2(lockingL2Store{mapL2Store: newMapL2Store()}, nil), WithL2Lock(time.Second, 0),
+				WithReadiness(0.5), WithOriginQuota(10, time.Hour, RateLimitReject),
+			},
+		},
+		{
+			name:    "failed create with negative ttl",
+			opts:    []Option{WithTTL(-time.Second)},
+			wantErr: ErrInvalidOptions,
+		},
+		{
+			name:    "failed create with jitter of 1",
+			opts:    []Option{WithTTLJitter(1)},
+			wantErr: ErrInvalidOptions,
+		},
+		{
+			name:    "failed create with negative max entries",
+			opts:    []Option{WithMaxEntries(-1)},
+			wantErr: ErrInvalidOptions,
+		},
+		{
+			name:    "failed create with readiness above 1",
+			opts:    []Option{WithReadiness(1.5)},
+			wantErr: ErrInvalidOptions,
+		},
+		{
+			name:    "failed create with quota without window",
+			opts:    []Option{WithOriginQuota(10, 0, RateLimitReject)},
+			wantErr: ErrInvalidOptions,
+		},
+		{
+			name:    "failed create with peer replicas without AuthorizePut",
+			opts:    []Option{WithPeers(&HTTPPool{Replicas: 2})},
+			wantErr: ErrInvalidOptions,
+		},
+		{
+			name:    "failed create with expired batches without sweep",
+			opts:    []Option{WithExpiredBatches(make(chan []string, 1))},
+			wantErr: ErrInvalidOptions,
+		},
+		{
+			name:    "failed create with L2 lock without L2",
+			opts:    []Option{WithL2Lock(time.Second, 0)},
+			wantErr: ErrInvalidOptions,
+		},
+		{
+			name:    "failed create with L2 lock on a store without locks",
+			opts:    []Option{WithL2(newMapL2Store(), nil), WithL2Lock(time.Second, 0)},
+			wantErr: ErrInvalidOptions,
+		},
+		{
+			name:    "failed create with non empty store",
+			opts:    []Option{WithStore(full)},
+			wantErr: ErrInvalidOptions,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fc, err := New(&FetcherMock{}, tt.opts...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("New() expect error = %v, have %v", tt.wantErr, err)
+			}
+			if (fc == nil) != (tt.wantErr != nil) {
+				t.Errorf("New() expect cache = %v, have %v", tt.wantErr == nil, fc != nil)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+)
+
+// PanicError is returned by fetches whose Fetcher call panicked, see
+// WithPanicRecovery.
+type PanicError struct {
+	// ID is the id fetched, empty for a FetchBatch call of
+	// WithBatchFetcher, which fails every id of the batch.
+	ID string
+	// Value is the value the Fetcher panicked with and Stack the stack
+	// of the panic.
+	Value interface{}
+	Stack []byte
+}
+
+// Error implements error.
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("fetcher panic for %q: %v", e.ID, e.Value)
+}
+
+// WithPanicRecovery recovers the panics of the Fetcher and of the
+// BatchFetcher of WithBatchFetcher, the fetches fail with a PanicError
+// instead, which goes through WithErrorPolicy like any Fetcher error, and
+// the waiters of the fetch are released. report, if not nil, is called
+// with every PanicError, synchronously. Without it a panic of the Fetcher
+// crashes the process as usual.
+func WithPanicRecovery(report func(*PanicError)) Option {
+	return func(o *options) {
+		o.recoverPanics = true
+		o.panicReport = report
+	}
+}
+
+// recoverFetch calls fetch for id, turning a panic into a PanicError given
+// to report
+func recoverFetch(id string, report func(*PanicError), fetch func()) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			perr := &PanicError{ID: id, Value: v, Stack: debug.Stack()}
+			if report != nil {
+				report(perr)
+			}
+			err = perr
+		}
+	}()
+	fetch()
+	return nil
+}
+
+// callFetcher calls the Fetcher for id, recovering its panics with
+// WithPanicRecovery, and watched by WithStrictMode
+func (fc *FetchCache) callFetcher(ctx context.Context, id string) (model *Model, err error) {
+	defer fc.strict.watchFetch(ctx, id, fc.opts.synchronous)()
+	if !fc.opts.recoverPanics {
+		return fc.f.Fetch(ctx, id)
+	}
+	if perr := recoverFetch(id, fc.opts.panicReport, func() { model, err = fc.f.Fetch(ctx, id) }); perr != nil {
+		return nil, perr
+	}
+	return model, err
+}
@@ -0,0 +1,86 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFetchCache_WithPanicRecovery(t *testing.T) {
+	fakeFetchID := "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	panicking := func(fail *bool) Fetcher {
+		return &FetcherMock{
+			FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+				if *fail {
+					panic("boom")
+				}
+				return &Model{Name: id}, nil
+			},
+		}
+	}
+	batchPanicking := func(fail *bool) []Option {
+		return []Option{WithBatchFetcher(batchFetcherFunc(func(ctx context.Context, ids []string) (map[string]*Model, error) {
+			if *fail {
+				panic("boom")
+			}
+			models := map[string]*Model{}
+			for _, id := range ids {
+				models[id] = &Model{Name: id}
+			}
+			return models, nil
+		}), time.Millisecond, 0)}
+	}
+
+	tests := []struct {
+		name   string
+		opts   func(fail *bool) []Option
+		wantID string
+	}{
+		{
+			name:   "success recover fetcher panic",
+			opts:   func(fail *bool) []Option { return nil },
+			wantID: fakeFetchID,
+		},
+		{
+			name: "success recover batch fetcher panic",
+			opts: batchPanicking,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fail := true
+			var (
+				mu      sync.Mutex
+				reports []*PanicError
+			)
+			opts := append(tt.opts(&fail), WithMaxConcurrency(1), WithPanicRecovery(func(e *PanicError) {
+				mu.Lock()
+				reports = append(reports, e)
+				mu.Unlock()
+			}))
+			fc := NewCache(panicking(&fail), opts...)
+			defer fc.Close(context.Background())
+
+			_, err := fc.Fetch(context.Background(), fakeFetchID)
+			var perr *PanicError
+			if !errors.As(err, &perr) || perr.Value != "boom" || perr.ID != tt.wantID || len(perr.Stack) == 0 {
+				t.Fatalf("FetchCache.Fetch() expect error = PanicError, have %v", err)
+			}
+			mu.Lock()
+			if len(reports) != 1 {
+				t.Errorf("WithPanicRecovery() expect reports = 1, have %d", len(reports))
+			}
+			mu.Unlock()
+
+			// the key lock and the concurrency slot are released
+			fail = false
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			if m, err := fc.Fetch(ctx, fakeFetchID); err != nil || m.Name != fakeFetchID {
+				t.Errorf("FetchCache.Fetch() expect model = %s, have %v, %v", fakeFetchID, m, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,351 @@
+package resource
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Const list
+const (
+	defaultPeerBasePath = "/_resource/"
+	defaultPeerReplicas = 50
+	// peerBodyOverhead is the room left for the JSON of a model in the
+	// body of a PUT, see maxPeerBody
+	peerBodyOverhead = 1 << 10
+	// peerPutTimeout bounds the write of a model to another owner
+	peerPutTimeout = 5 * time.Second
+)
+
+// HTTPPool is a set of peer FetchCache instances talking over HTTP.
+//
+// Keys are consistently hashed across the peers, a miss on a peer which
+// doesn't own the key is forwarded to the owner so each model is fetched
+// from the origin by a single peer of the fleet. Every peer must serve the
+// pool with http.Handle(HTTPPool.BasePath, pool). The owners are picked by
+// the HashRing of the pool, see Ring, or by the PeerPicker of SetPicker.
+//
+// With Replicas above 1 every key is owned by several peers. A peer reads a
+// key from any of its owners, the first one answering, and an owner
+// missing it asks the other owners for their copy before fetching it from
+// the origin and writing it to all of them, so a restarting owner doesn't
+// send its whole key range to the origin.
+//
+// The writes are PUT requests caching their body, refused unless
+// AuthorizePut authenticates them as coming from a peer, e.g. by a shared
+// secret header added by Client. Their body is limited by WithMaxValueSize.
+type HTTPPool struct {
+	// BasePath is the path prefix peer requests are served on.
+	BasePath string
+	// Client is used for requests to other peers, http.DefaultClient if nil.
+	Client *http.Client
+	// Replicas is the number of peers owning each key, 1 if lower or if
+	// the PeerPicker isn't a ReplicaPicker.
+	Replicas int
+	// AuthorizePut reports whether the PUT request r of another owner may
+	// write to the cache, every PUT is refused if nil, so it must be set
+	// with Replicas above 1.
+	AuthorizePut func(r *http.Request) bool
+
+	self   string
+	ring   *HashRing
+	mu     sync.RWMutex
+	picker PeerPicker
+	fc     *FetchCache
+}
+
+// NewHTTPPool creates a pool for the peer reachable at base URL self,
+// e.g. "http://10.0.0.1:8080".
+func NewHTTPPool(self string) *HTTPPool {
+	p := &HTTPPool{
+		BasePath: defaultPeerBasePath,
+		self:     self,
+		ring:     NewHashRing(defaultPeerReplicas),
+	}
+	p.picker = p.ring
+	p.Set(self)
+	return p
+}
+
+// Set replaces the peers of the ring of the pool with the given base URLs,
+// which should include self.
+func (p *HTTPPool) Set(peers ...string) {
+	p.ring.Set(peers...)
+}
+
+// Ring returns the ring of the pool, peers can join, leave and be weighted
+// with it while the pool is in use.
+func (p *HTTPPool) Ring() *HashRing {
+	return p.ring
+}
+
+// SetPicker makes pp pick the owners of the keys in place of the ring of
+// the pool, e.g. a HashRing with another number of virtual nodes per peer
+// or a PeerPicker following service discovery. A nil pp restores the ring.
+func (p *HTTPPool) SetPicker(pp PeerPicker) {
+	if pp == nil {
+		pp = p.ring
+	}
+	p.mu.Lock()
+	p.picker = pp
+	p.mu.Unlock()
+}
+
+// owners returns the base URLs of the peers owning id, see Replicas
+func (p *HTTPPool) owners(id string) []string {
+	p.mu.RLock()
+	pp := p.picker
+	p.mu.RUnlock()
+	if rp, ok := pp.(ReplicaPicker); ok && p.Replicas > 1 {
+		return rp.PickPeers(id, p.Replicas)
+	}
+	if owner := pp.PickPeer(id); owner != "" {
+		return []string{owner}
+	}
+	return nil
+}
+
+// WithPeers makes the cache one of the peers of p, see HTTPPool.
+func WithPeers(p *HTTPPool) Option {
+	return func(o *options) {
+		o.peers = p
+	}
+}
+
+type fromPeerKey struct{}
+
+// ServeHTTP serves the keys owned by this peer to the other peers, and
+// with Replicas above 1 the copies of the other owners.
+func (p *HTTPPool) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.URL.Path, p.BasePath) {
+		http.NotFound(w, r)
+		return
+	}
+	id, err := url.PathUnescape(strings.TrimPrefix(r.URL.EscapedPath(), p.BasePath))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	p.mu.RLock()
+	fc := p.fc
+	p.mu.RUnlock()
+	if fc == nil {
+		http.Error(w, "no cache attached to pool", http.StatusServiceUnavailable)
+		return
+	}
+
+	if r.Method == http.MethodPut || r.URL.Query().Get("peek") != "" {
+		// resolved as by the fetches of id
+		if id, err = fc.resolve(r.Context(), id); err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+	}
+	key := fc.key(id)
+	switch {
+	case r.Method == http.MethodPut:
+		if p.AuthorizePut == nil || !p.AuthorizePut(r) {
+			http.Error(w, "peer write not authorized", http.StatusForbidden)
+			return
+		}
+		if !fc.life.enter() {
+			http.Error(w, ErrClosed.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		defer fc.life.leave()
+		body := io.Reader(r.Body)
+		if max := fc.opts.maxValueSize; max > 0 {
+			body = http.MaxBytesReader(w, r.Body, maxPeerBody(max))
+		}
+		var model Model
+		if err := json.NewDecoder(body).Decode(&model); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if fc.oversized(&model, 0) {
+			http.Error(w, "model too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		fc.lock(key)
+		fc.keep(key, id, fc.traceID(r.Context()), Result{Model: &model}, SourcePeer, Expiration{AfterWrite: fc.defaultTTL()}, 0, nil)
+		fc.unlock(key)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	case r.URL.Query().Get("peek") != "":
+		i, found := fc.peekitem(key)
+		if !found || !fc.current(key, i) || !fc.owns(i, id) {
+			http.Error(w, ErrNotFound.Error(), http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(i.Object)
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), fromPeerKey{}, true)
+	model, err := fc.Fetch(ctx, id)
+	switch {
+	case errors.Is(err, ErrNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(model)
+}
+
+// maxPeerBody returns the largest body of a PUT of a model of max bytes,
+// whose Data is base64 encoded in JSON
+func maxPeerBody(max int) int64 {
+	return int64(max)*4/3 + peerBodyOverhead
+}
+
+// peerFetcher forwards fetches of keys owned by other peers to them
+type peerFetcher struct {
+	pool  *HTTPPool
+	local Fetcher
+}
+
+// Fetch implements Fetcher.
+func (pf *peerFetcher) Fetch(ctx context.Context, id string) (*Model, error) {
+	owners := pf.pool.owners(id)
+	fromPeer, _ := ctx.Value(fromPeerKey{}).(bool)
+	owned := containsString(owners, pf.pool.self)
+	if len(owners) == 0 || fromPeer && !owned {
+		return pf.local.Fetch(ctx, id)
+	}
+	if owned {
+		return pf.fetchOwned(ctx, id, owners)
+	}
+
+	// read from any owner, in order
+	var err error
+	for _, owner := range owners {
+		var model *Model
+		model, err = pf.get(ctx, owner, id, false)
+		if err == nil {
+			setSource(ctx, SourcePeer)
+			return model, nil
+		}
+		if errors.Is(err, ErrNotFound) || ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, err
+}
+
+// fetchOwned fetches id owned by this peer among owners: from the cache of
+// another owner when one has it, and else from the origin writing the
+// model to the other owners
+func (pf *peerFetcher) fetchOwned(ctx context.Context, id string, owners []string) (*Model, error) {
+	others := make([]string, 0, len(owners)-1)
+	for _, owner := range owners {
+		if owner != pf.pool.self {
+			others = append(others, owner)
+		}
+	}
+	for _, peer := range others {
+		if model, err := pf.get(ctx, peer, id, true); err == nil {
+			setSource(ctx, SourcePeer)
+			return model, nil
+		}
+	}
+
+	model, err := pf.local.Fetch(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	pf.pool.mu.RLock()
+	fc := pf.pool.fc
+	pf.pool.mu.RUnlock()
+	for _, peer := range others {
+		peer := peer
+		if fc == nil || fc.opts.synchronous {
+			pf.put(nil, peer, id, model)
+			continue
+		}
+		fc.life.goBackground("peer-put", func(stop <-chan struct{}) { pf.put(stop, peer, id, model) })
+	}
+	return model, nil
+}
+
+// url returns the URL of id on peer
+func (pf *peerFetcher) url(peer, id string) string {
+	return strings.TrimSuffix(peer, "/") + pf.pool.BasePath + url.PathEscape(id)
+}
+
+func (pf *peerFetcher) client() *http.Client {
+	if pf.pool.Client == nil {
+		return http.DefaultClient
+	}
+	return pf.pool.Client
+}
+
+// get fetches id from peer, only from its cache when peek is true
+func (pf *peerFetcher) get(ctx context.Context, peer, id string, peek bool) (*Model, error) {
+	u := pf.url(peer, id)
+	if peek {
+		u += "?peek=1"
+	}
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := pf.client().Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, ErrNotFound
+	default:
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("peer %s: %s: %s", peer, resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	var model Model
+	if err := json.NewDecoder(resp.Body).Decode(&model); err != nil {
+		return nil, err
+	}
+	return &model, nil
+}
+
+// put writes model of id to the cache of peer, best effort, giving up once
+// stop is closed
+func (pf *peerFetcher) put(stop <-chan struct{}, peer, id string, model *Model) {
+	body, err := json.Marshal(model)
+	if err != nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), peerPutTimeout)
+	defer cancel()
+	go func() {
+		select {
+		case <-stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	req, err := http.NewRequest(http.MethodPut, pf.url(peer, id), bytes.NewReader(body))
+	if err != nil {
+		return
+	}
+	resp, err := pf.client().Do(req.WithContext(ctx))
+	if err != nil {
+		return
+	}
+	resp.Body.Close()
+}
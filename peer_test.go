@@ -0,0 +1,245 @@
+package resource
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFetchCache_Fetch_Peers(t *testing.T) {
+	var (
+		ids = []string{
+			"7e1588ab-2bf9-44b0-a7ae-41c9ef3c86af",
+			"6bff5837-c618-46ac-ae27-4544e08b099e",
+			"ecdcb84d-7c42-4242-9e46-bb3b9fbe4312",
+			"530bc2d6-3023-4206-aa0c-9d21dbb7d0a9",
+			"f76dd77f-a46d-45d9-a0cd-e2fe66a6820a",
+			"5634aeed-2106-43de-ab7d-c0ad4b1e195e",
+		}
+		notExistModelID = "5634aeed-2106-43de-ab7d-c0ad4b1e195e"
+	)
+
+	var mu sync.Mutex
+	originCallCount := make(map[string]int)
+	mockedFetcher := &FetcherMock{
+		FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+			mu.Lock()
+			originCallCount[id]++
+			mu.Unlock()
+			if id == notExistModelID {
+				return nil, ErrNotFound
+			}
+			return &Model{Name: id}, nil
+		},
+	}
+
+	peerCount := 3
+	var (
+		urls   []string
+		pools  []*HTTPPool
+		caches []*FetchCache
+	)
+	for i := 0; i < peerCount; i++ {
+		mux := http.NewServeMux()
+		srv := httptest.NewServer(mux)
+		defer srv.Close()
+		pool := NewHTTPPool(srv.URL)
+		mux.Handle(pool.BasePath, pool)
+		urls = append(urls, srv.URL)
+		pools = append(pools, pool)
+		caches = append(caches, NewCache(mockedFetcher, WithPeers(pool)))
+	}
+	for _, pool := range pools {
+		pool.Set(urls...)
+	}
+
+	for _, fc := range caches {
+		for _, id := range ids {
+			got, err := fc.Fetch(context.Background(), id)
+			if id == notExistModelID {
+				if err != ErrNotFound {
+					t.Errorf("FetchCache.Fetch() error = %v, want %v", err, ErrNotFound)
+				}
+				continue
+			}
+			if err != nil || got.Name != id {
+				t.Errorf("FetchCache.Fetch() = %v, %v, want %v", got, err, id)
+			}
+		}
+	}
+
+	for _, id := range ids {
+		want := 1
+		if id == notExistModelID {
+			want = peerCount
+		}
+		if originCallCount[id] != want {
+			t.Errorf("FetchCache.Fetch() expect origin call count for %v = %v, have %v", id, want, originCallCount[id])
+		}
+	}
+}
+
+func TestFetchCache_Fetch_PeerReplicas(t *testing.T) {
+	fakeFetchID := "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+
+	var originCallCount int32
+	mockedFetcher := &FetcherMock{
+		FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+			atomic.AddInt32(&originCallCount, 1)
+			return &Model{Name: id}, nil
+		},
+	}
+
+	var (
+		urls    []string
+		pools   []*HTTPPool
+		servers = make(map[string]*httptest.Server)
+		caches  = make(map[string]*FetchCache)
+	)
+	for i := 0; i < 3; i++ {
+		mux := http.NewServeMux()
+		srv := httptest.NewServer(mux)
+		defer srv.Close()
+		pool := NewHTTPPool(srv.URL)
+		pool.Replicas = 2
+		pool.AuthorizePut = func(r *http.Request) bool { return true }
+		mux.Handle(pool.BasePath, pool)
+		urls = append(urls, srv.URL)
+		pools = append(pools, pool)
+		servers[srv.URL] = srv
+		caches[srv.URL] = NewCache(mockedFetcher, WithPeers(pool))
+	}
+	for _, pool := range pools {
+		pool.Set(urls...)
+	}
+	owners := pools[0].owners(fakeFetchID)
+	if len(owners) != 2 {
+		t.Fatalf("HTTPPool.owners() expect 2 owners, have %v", owners)
+	}
+	var nonOwner string
+	for _, u := range urls {
+		if u != owners[0] && u != owners[1] {
+			nonOwner = u
+		}
+	}
+
+	if _, err := caches[nonOwner].Fetch(context.Background(), fakeFetchID); err != nil {
+		t.Fatalf("FetchCache.Fetch() error = %v", err)
+	}
+	// the first owner writes the model to the second one
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(time.Millisecond) {
+		if _, found := caches[owners[1]].peekitem(fakeFetchID); found {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("FetchCache.Fetch() expect model written to %v", owners[1])
+		}
+	}
+
+	// read from the second owner once the first one is down
+	servers[owners[0]].Close()
+	caches[nonOwner].Flush()
+	model, err := caches[nonOwner].Fetch(context.Background(), fakeFetchID)
+	if err != nil || model.Name != fakeFetchID {
+		t.Fatalf("FetchCache.Fetch() = %v, %v, want %v", model, err, fakeFetchID)
+	}
+	if have := atomic.LoadInt32(&originCallCount); have != 1 {
+		t.Errorf("FetchCache.Fetch() expect origin call count = %v, have %v", 1, have)
+	}
+}
+
+func TestHTTPPool_ServeHTTP_Put(t *testing.T) {
+	fakeFetchID := "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+
+	tests := []struct {
+		name       string
+		authorize  func(r *http.Request) bool
+		opts       []Option
+		id         string
+		body       string
+		closed     bool
+		wantStatus int
+		wantCached bool
+	}{
+		{
+			name:       "fail put without AuthorizePut",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "success put authorized",
+			authorize: func(r *http.Request) bool {
+				return r.Header.Get("X-Peer-Secret") == "lorem"
+			},
+			wantStatus: http.StatusNoContent,
+			wantCached: true,
+		},
+		{
+			name:       "success put of normalized id",
+			authorize:  func(r *http.Request) bool { return true },
+			opts:       []Option{WithKeyNormalizer(strings.ToLower)},
+			id:         strings.ToUpper(fakeFetchID),
+			wantStatus: http.StatusNoContent,
+			wantCached: true,
+		},
+		{
+			name:       "fail put too large",
+			authorize:  func(r *http.Request) bool { return true },
+			opts:       []Option{WithMaxValueSize(16, nil)},
+			body:       `{"Name":"lorem","Data":"` + strings.Repeat("A", 4<<10) + `"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "fail put of model too large",
+			authorize:  func(r *http.Request) bool { return true },
+			opts:       []Option{WithMaxValueSize(16, nil)},
+			body:       `{"Name":"lorem","Data":"` + strings.Repeat("A", 64) + `"}`,
+			wantStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:       "fail put not authorized",
+			authorize:  func(r *http.Request) bool { return false },
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "fail put after close",
+			authorize:  func(r *http.Request) bool { return true },
+			closed:     true,
+			wantStatus: http.StatusServiceUnavailable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pool := NewHTTPPool("http://localhost")
+			pool.AuthorizePut = tt.authorize
+			fc := NewCache(&FetcherMock{}, append(tt.opts, WithPeers(pool))...)
+			if tt.closed {
+				_ = fc.Close(context.Background())
+			} else {
+				defer fc.Close(context.Background())
+			}
+
+			id, body := fakeFetchID, `{"Name":"lorem"}`
+			if tt.id != "" {
+				id = tt.id
+			}
+			if tt.body != "" {
+				body = tt.body
+			}
+			req := httptest.NewRequest(http.MethodPut, pool.BasePath+id, strings.NewReader(body))
+			req.Header.Set("X-Peer-Secret", "lorem")
+			rec := httptest.NewRecorder()
+			pool.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("HTTPPool.ServeHTTP() expect status = %v, have %v", tt.wantStatus, rec.Code)
+			}
+			if _, found := fc.peekitem(fakeFetchID); found != tt.wantCached {
+				t.Errorf("HTTPPool.ServeHTTP() expect cached = %v, have %v", tt.wantCached, found)
+			}
+		})
+	}
+}
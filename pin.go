@@ -0,0 +1,69 @@
+package resource
+
+// WithHeadroom keeps the last n items stored out of eviction, besides the
+// pinned ones, so a model just fetched always has room and isn't evicted
+// by the next stores, which EvictCostAware would otherwise do to the items
+// not hit yet. When every item is pinned or among the last n stored the
+// cache grows past WithMaxEntries instead of evicting them.
+func WithHeadroom(n int) Option {
+	return func(o *options) {
+		o.headroom = n
+	}
+}
+
+// Pin keeps the item of id out of eviction until Unpin, including the
+// items of id cached after Pin. A pinned item still expires and is removed
+// by Clear and Flush.
+func (fc *FetchCache) Pin(id string) {
+	key := fc.key(fc.canonical(id))
+	fc.lockItems()
+	fc.pins.pinned[key] = struct{}{}
+	fc.itemsLock.Unlock()
+}
+
+// Unpin lets the item of id be evicted again.
+func (fc *FetchCache) Unpin(id string) {
+	key := fc.key(fc.canonical(id))
+	fc.lockItems()
+	delete(fc.pins.pinned, key)
+	fc.itemsLock.Unlock()
+}
+
+// pinSet is the keys kept out of eviction, guarded by fc.itemsLock
+type pinSet struct {
+	pinned map[string]struct{}
+	// recent is a ring of the keys last stored, see WithHeadroom, next the
+	// index of the oldest one
+	recent []string
+	next   int
+}
+
+func newPinSet(headroom int) *pinSet {
+	s := &pinSet{pinned: make(map[string]struct{})}
+	if headroom > 0 {
+		s.recent = make([]string, headroom)
+	}
+	return s
+}
+
+// stored records key as the last key stored
+func (s *pinSet) stored(key string) {
+	if len(s.recent) == 0 {
+		return
+	}
+	s.recent[s.next] = key
+	s.next = (s.next + 1) % len(s.recent)
+}
+
+// protected reports whether key is pinned or among the last keys stored
+func (s *pinSet) protected(key string) bool {
+	if _, ok := s.pinned[key]; ok {
+		return true
+	}
+	for _, k := range s.recent {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+package resource
+
+import (
+	"context"
+	"testing"
+)
+
+func TestFetchCache_Pin(t *testing.T) {
+	mockedFetcher := &FetcherMock{
+		FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+			return &Model{Name: id}, nil
+		},
+	}
+
+	tests := []struct {
+		name       string
+		opts       []Option
+		pin        []string
+		unpin      []string
+		fetch      []string
+		wantCached []string
+		wantGone   []string
+	}{
+		{
+			name:       "success evict oldest without pins",
+			opts:       []Option{WithMaxEntries(2)},
+			fetch:      []string{"a", "b", "c"},
+			wantCached: []string{"b", "c"},
+			wantGone:   []string{"a"},
+		},
+		{
+			name:       "success pinned item kept",
+			opts:       []Option{WithMaxEntries(2)},
+			pin:        []string{"a"},
+			fetch:      []string{"a", "b", "c"},
+			wantCached: []string{"a", "c"},
+			wantGone:   []string{"b"},
+		},
+		{
+			name:       "success unpinned item evicted",
+			opts:       []Option{WithMaxEntries(2)},
+			pin:        []string{"a"},
+			unpin:      []string{"a"},
+			fetch:      []string{"a", "b", "c"},
+			wantCached: []string{"b", "c"},
+			wantGone:   []string{"a"},
+		},
+		{
+			name:       "success grow past max when all pinned",
+			opts:       []Option{WithMaxEntries(2)},
+			pin:        []string{"a", "b"},
+			fetch:      []string{"a", "b", "c"},
+			wantCached: []string{"a", "b", "c"},
+		},
+		{
+			name:       "success headroom keeps the last stored",
+			opts:       []Option{WithMaxEntries(2), WithHeadroom(3)},
+			fetch:      []string{"a", "b", "c", "d"},
+			wantCached: []string{"b", "c", "d"},
+			wantGone:   []string{"a"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fc := NewCache(mockedFetcher, tt.opts...)
+			for _, id := range tt.pin {
+				fc.Pin(id)
+			}
+			for _, id := range tt.unpin {
+				fc.Unpin(id)
+			}
+			for _, id := range tt.fetch {
+				if _, err := fc.Fetch(context.Background(), id); err != nil {
+					t.Fatalf("FetchCache.Fetch() error = %v", err)
+				}
+			}
+			for _, id := range tt.wantCached {
+				if _, found := fc.Entry(id); !found {
+					t.Errorf("FetchCache.Pin() expect %v cached, have evicted", id)
+				}
+			}
+			for _, id := range tt.wantGone {
+				if _, found := fc.Entry(id); found {
+					t.Errorf("FetchCache.Pin() expect %v evicted, have cached", id)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package resource
+
+import (
+	"context"
+	"runtime/pprof"
+)
+
+// Profiler labels set on the goroutines doing cache work so CPU and
+// goroutine profiles attribute it to a subsystem and, for Fetcher calls,
+// to a key, see runtime/pprof.Do.
+const (
+	LabelOperation = "cache.operation"
+	LabelKey       = "cache.key"
+)
+
+// withLabels runs fn with the operation op and the key id, if not empty,
+// added to the profiler labels of ctx
+func withLabels(ctx context.Context, op, id string, fn func(ctx context.Context)) {
+	labels := pprof.Labels(LabelOperation, op)
+	if id != "" {
+		labels = pprof.Labels(LabelOperation, op, LabelKey, id)
+	}
+	pprof.Do(ctx, labels, fn)
+}
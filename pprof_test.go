@@ -0,0 +1,56 @@
+package resource
+
+import (
+	"context"
+	"runtime/pprof"
+	"testing"
+	"time"
+)
+
+func TestFetchCache_Fetch_ProfilerLabels(t *testing.T) {
+	var (
+		fakeFetchID = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	)
+
+	tests := []struct {
+		name   string
+		opts   []Option
+		wantOp string
+	}{
+		{
+			name:   "success fetch",
+			wantOp: "fetch",
+		},
+		{
+			name:   "success drift check",
+			opts:   []Option{WithSynchronous(), WithDriftCheck(func(a, b *Model) bool { return true }, time.Millisecond, 1, false)},
+			wantOp: "drift-check",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var op, key string
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					op, _ = pprof.Label(ctx, LabelOperation)
+					key, _ = pprof.Label(ctx, LabelKey)
+					return &Model{Name: "lorem"}, nil
+				},
+			}
+			fc := NewCache(mockedFetcher, tt.opts...)
+			defer fc.Close(context.Background())
+			if _, err := fc.Fetch(context.Background(), fakeFetchID); err != nil {
+				t.Fatalf("FetchCache.Fetch() error = %v", err)
+			}
+			time.Sleep(2 * time.Millisecond)
+			fc.Tick(context.Background())
+
+			if op != tt.wantOp {
+				t.Errorf("FetchCache.Fetch() expect label %s = %v, have %v", LabelOperation, tt.wantOp, op)
+			}
+			if key != fakeFetchID {
+				t.Errorf("FetchCache.Fetch() expect label %s = %v, have %v", LabelKey, fakeFetchID, key)
+			}
+		})
+	}
+}
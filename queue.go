@@ -0,0 +1,27 @@
+package resource
+
+import (
+	"errors"
+	"time"
+)
+
+// Error list
+var (
+	// ErrQueueFull is returned by misses arriving while the miss queue of
+	// WithMissQueue is full.
+	ErrQueueFull = errors.New("miss queue full")
+	// ErrQueueTimeout is returned by misses which waited in the miss queue
+	// of WithMissQueue for longer than its timeout.
+	ErrQueueTimeout = errors.New("miss queue timeout")
+)
+
+// WithMissQueue bounds the misses waiting for a Fetcher call slot of
+// WithMaxConcurrency to size, further misses fail with ErrQueueFull, and
+// fails misses which waited for timeout with ErrQueueTimeout. A size or a
+// timeout of 0 means no limit. The queue depth is reported in Stats.
+func WithMissQueue(size int, timeout time.Duration) Option {
+	return func(o *options) {
+		o.missQueueSize = size
+		o.missQueueTimeout = timeout
+	}
+}
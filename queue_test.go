@@ -0,0 +1,62 @@
+package resource
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestFetchCache_WithMissQueue(t *testing.T) {
+	tests := []struct {
+		name      string
+		size      int
+		timeout   time.Duration
+		wantDepth int
+		wantErr   error
+	}{
+		{
+			name:      "failed queue full",
+			size:      1,
+			wantDepth: 1,
+			wantErr:   ErrQueueFull,
+		},
+		{
+			name:    "failed queue timeout",
+			timeout: 10 * time.Millisecond,
+			wantErr: ErrQueueTimeout,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			started := make(chan struct{}, 3)
+			release := make(chan struct{})
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					started <- struct{}{}
+					<-release
+					return &Model{Name: id}, nil
+				},
+			}
+			fc := NewCache(mockedFetcher, WithMaxConcurrency(1), WithMissQueue(tt.size, tt.timeout))
+			defer close(release)
+
+			go fc.Fetch(context.Background(), "a")
+			<-started
+			if tt.size > 0 {
+				go fc.Fetch(context.Background(), "b")
+				for fc.Stats().QueueDepth != tt.wantDepth {
+					time.Sleep(time.Millisecond)
+				}
+			}
+
+			_, err := fc.Fetch(context.Background(), "c")
+			if err != tt.wantErr {
+				t.Errorf("FetchCache.Fetch() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			stats := fc.Stats()
+			if stats.QueueDepth != tt.wantDepth || stats.QueueRejected != 1 {
+				t.Errorf("FetchCache.Stats() expect depth %v and 1 rejected, have %v and %v", tt.wantDepth, stats.QueueDepth, stats.QueueRejected)
+			}
+		})
+	}
+}
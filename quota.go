@@ -0,0 +1,131 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrQuotaExhausted is returned by fetches refused by WithOriginQuota.
+var ErrQuotaExhausted = errors.New("origin quota exhausted")
+
+// WithOriginQuota bounds the calls to the Fetcher to n per window, for an
+// origin with a request quota such as a daily one. The windows start at the
+// multiples of window since the zero time, UTC midnight for 24h. Fetches
+// over the quota are handled according to policy: RateLimitWait waits for
+// the next window, RateLimitReject fails with ErrQuotaExhausted and
+// RateLimitStale serves the expired item if there is one. The models served
+// by the L2 store of WithL2 or by a peer don't count against the quota. The
+// usage is written by Dump and restored by Hydrate within the same window,
+// so it survives restarts when the cache is persisted, see Stats.Quota.
+func WithOriginQuota(n int64, window time.Duration, policy RateLimitPolicy) Option {
+	return func(o *options) {
+		o.quota = n
+		o.quotaWindow = window
+		o.quotaPolicy = policy
+	}
+}
+
+// QuotaUsage is the usage of the quota of WithOriginQuota in the current
+// window.
+type QuotaUsage struct {
+	Limit     int64     `json:"limit"`
+	Used      int64     `json:"used"`
+	Remaining int64     `json:"remaining"`
+	Reset     time.Time `json:"reset"`
+}
+
+// originQuota counts the Fetcher calls of the current window
+type originQuota struct {
+	mu     sync.Mutex
+	limit  int64
+	window time.Duration
+	start  time.Time
+	used   int64
+}
+
+func newOriginQuota(limit int64, window time.Duration) *originQuota {
+	if limit <= 0 || window <= 0 {
+		return nil
+	}
+	return &originQuota{limit: limit, window: window}
+}
+
+// rollLocked starts the window of now, q.mu must be held
+func (q *originQuota) rollLocked(now time.Time) {
+	if start := now.Truncate(q.window); start.After(q.start) {
+		q.start, q.used = start, 0
+	}
+}
+
+// take counts a call, or returns ErrQuotaExhausted if the policy doesn't
+// wait for the next window
+func (q *originQuota) take(ctx context.Context, policy RateLimitPolicy) error {
+	if q == nil {
+		return nil
+	}
+	for {
+		q.mu.Lock()
+		q.rollLocked(time.Now())
+		if q.used < q.limit {
+			q.used++
+			q.mu.Unlock()
+			return nil
+		}
+		reset := q.start.Add(q.window)
+		q.mu.Unlock()
+		if policy != RateLimitWait {
+			return ErrQuotaExhausted
+		}
+		timer := time.NewTimer(time.Until(reset))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// refund gives back a call which didn't reach the origin
+func (q *originQuota) refund() {
+	if q == nil {
+		return
+	}
+	q.mu.Lock()
+	if q.used > 0 {
+		q.used--
+	}
+	q.mu.Unlock()
+}
+
+// usage returns the usage of the current window
+func (q *originQuota) usage() QuotaUsage {
+	if q == nil {
+		return QuotaUsage{}
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.rollLocked(time.Now())
+	return QuotaUsage{
+		Limit:     q.limit,
+		Used:      q.used,
+		Remaining: q.limit - q.used,
+		Reset:     q.start.Add(q.window),
+	}
+}
+
+// restore adds the usage u of a previous process if it is of the current
+// window
+func (q *originQuota) restore(u QuotaUsage) {
+	if q == nil {
+		return
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.rollLocked(time.Now())
+	if u.Reset.Equal(q.start.Add(q.window)) && u.Used > q.used {
+		q.used = u.Used
+	}
+}
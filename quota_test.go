@@ -0,0 +1,119 @@
+package resource
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFetchCache_WithOriginQuota(t *testing.T) {
+	var (
+		fakeFetchID     = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+		notExistModelID = "5634aeed-2106-43de-ab7d-c0ad4b1e195e"
+	)
+	tests := []struct {
+		name      string
+		policy    RateLimitPolicy
+		wantErr   error
+		wantStale bool
+	}{
+		{
+			name:    "failed reject",
+			policy:  RateLimitReject,
+			wantErr: ErrQuotaExhausted,
+		},
+		{
+			name:      "success stale",
+			policy:    RateLimitStale,
+			wantStale: true,
+		},
+		{
+			name:    "failed wait canceled",
+			policy:  RateLimitWait,
+			wantErr: context.DeadlineExceeded,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					return &Model{Name: id}, nil
+				},
+			}
+			fc := NewCache(mockedFetcher, WithTTL(10*time.Millisecond), WithOriginQuota(2, time.Hour, tt.policy))
+			ctx := context.Background()
+			_, _ = fc.Fetch(ctx, fakeFetchID)
+			_, _ = fc.Fetch(ctx, notExistModelID)
+			time.Sleep(20 * time.Millisecond)
+
+			ctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+			defer cancel()
+			model, src, err := fc.FetchWithSource(ctx, fakeFetchID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("FetchCache.Fetch() expect error = %v, have %v", tt.wantErr, err)
+			}
+			if tt.wantStale && (src != SourceStale || model == nil) {
+				t.Errorf("FetchCache.Fetch() expect the stale item, have %v from %v", model, src)
+			}
+			if calls := len(mockedFetcher.FetchCalls()); calls != 2 {
+				t.Errorf("FetchCache.Fetch() expect 2 Fetcher calls, have %v", calls)
+			}
+			u := fc.Stats().Quota
+			if u.Limit != 2 || u.Used != 2 || u.Remaining != 0 || !u.Reset.Equal(time.Now().Truncate(time.Hour).Add(time.Hour)) {
+				t.Errorf("FetchCache.Stats() unexpected Quota = %+v", u)
+			}
+		})
+	}
+}
+
+func TestFetchCache_WithOriginQuota_persisted(t *testing.T) {
+	var fakeFetchID = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	mockedFetcher := &FetcherMock{
+		FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+			return &Model{Name: id}, nil
+		},
+	}
+	old := NewCache(mockedFetcher, WithOriginQuota(3, 24*time.Hour, RateLimitReject))
+	_, _ = old.Fetch(context.Background(), fakeFetchID)
+	_, _ = old.Fetch(context.Background(), "2")
+	var buf bytes.Buffer
+	if err := old.Dump(&buf, true); err != nil {
+		t.Fatalf("FetchCache.Dump() expect no error, have %v", err)
+	}
+
+	restarted := NewCache(mockedFetcher, WithOriginQuota(3, 24*time.Hour, RateLimitReject))
+	if _, err := restarted.Hydrate(&buf); err != nil {
+		t.Fatalf("FetchCache.Hydrate() expect no error, have %v", err)
+	}
+	if u := restarted.Stats().Quota; u.Used != 2 || u.Remaining != 1 {
+		t.Errorf("FetchCache.Hydrate() expect the usage restored, have %+v", u)
+	}
+	if _, err := restarted.Fetch(context.Background(), "3"); err != nil {
+		t.Errorf("FetchCache.Fetch() expect no error, have %v", err)
+	}
+	if _, err := restarted.Fetch(context.Background(), "4"); !errors.Is(err, ErrQuotaExhausted) {
+		t.Errorf("FetchCache.Fetch() expect error = %v, have %v", ErrQuotaExhausted, err)
+	}
+}
+
+func TestFetchCache_WithOriginQuota_l2(t *testing.T) {
+	var fakeFetchID = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	mockedFetcher := &FetcherMock{
+		FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+			return &Model{Name: id}, nil
+		},
+	}
+	store := newMapL2Store()
+	first := NewCache(mockedFetcher, WithL2(store, nil))
+	_, _ = first.Fetch(context.Background(), fakeFetchID)
+
+	fc := NewCache(mockedFetcher, WithL2(store, nil), WithOriginQuota(1, time.Hour, RateLimitReject))
+	if _, err := fc.Fetch(context.Background(), fakeFetchID); err != nil {
+		t.Errorf("FetchCache.Fetch() expect no error, have %v", err)
+	}
+	if u := fc.Stats().Quota; u.Used != 0 {
+		t.Errorf("FetchCache.Fetch() expect the L2 hit not counted, have %+v", u)
+	}
+}
@@ -0,0 +1,114 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited is returned by fetches refused by WithFetchRateLimit.
+var ErrRateLimited = errors.New("fetch rate limited")
+
+// RateLimitPolicy is what a miss over the rate of WithFetchRateLimit does.
+type RateLimitPolicy int
+
+// Rate limit policy list
+const (
+	// RateLimitWait waits for the rate to allow the fetch or for ctx to
+	// be done.
+	RateLimitWait RateLimitPolicy = iota
+	// RateLimitReject fails the fetch with ErrRateLimited.
+	RateLimitReject
+	// RateLimitStale serves the expired item if there is one, however old,
+	// and otherwise fails with ErrRateLimited.
+	RateLimitStale
+)
+
+// WithFetchRateLimit bounds the calls to the Fetcher to r per second on
+// average with bursts of up to burst calls, protecting fragile backends
+// when many items miss at once such as after a Flush. Fetches over the
+// rate are handled according to policy.
+func WithFetchRateLimit(r float64, burst int, policy RateLimitPolicy) Option {
+	return func(o *options) {
+		o.fetchRate = r
+		o.fetchBurst = burst
+		o.rateLimitPolicy = policy
+	}
+}
+
+// tokenBucket is a token bucket rate limiter
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	if rate <= 0 {
+		return nil
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &tokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// reserve takes a token, possibly in advance, and returns how long to
+// wait before using it. Without wait the token is only taken if there is
+// no need to wait.
+func (b *tokenBucket) reserve(wait bool) (time.Duration, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+	if b.tokens < 1 && !wait {
+		return 0, false
+	}
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0, true
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second)), true
+}
+
+// cancel gives back a reserved token
+func (b *tokenBucket) cancel() {
+	b.mu.Lock()
+	b.tokens++
+	b.mu.Unlock()
+}
+
+// take takes a token or returns ErrRateLimited if the policy doesn't wait
+func (b *tokenBucket) take(ctx context.Context, policy RateLimitPolicy) error {
+	if b == nil {
+		return nil
+	}
+	delay, ok := b.reserve(policy == RateLimitWait)
+	if !ok {
+		return ErrRateLimited
+	}
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		b.cancel()
+		return ctx.Err()
+	}
+}
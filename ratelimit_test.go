@@ -0,0 +1,83 @@
+package resource
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestFetchCache_WithFetchRateLimit(t *testing.T) {
+	var (
+		fakeFetchID     = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+		notExistModelID = "5634aeed-2106-43de-ab7d-c0ad4b1e195e"
+	)
+
+	tests := []struct {
+		name    string
+		rate    float64
+		policy  RateLimitPolicy
+		id      string
+		want    string
+		wantErr error
+	}{
+		{
+			name:   "success wait for the rate",
+			rate:   100,
+			policy: RateLimitWait,
+			id:     notExistModelID,
+			want:   notExistModelID,
+		},
+		{
+			name:    "failed wait past ctx deadline",
+			rate:    1,
+			policy:  RateLimitWait,
+			id:      notExistModelID,
+			wantErr: context.DeadlineExceeded,
+		},
+		{
+			name:    "failed rejected",
+			rate:    1,
+			policy:  RateLimitReject,
+			id:      notExistModelID,
+			wantErr: ErrRateLimited,
+		},
+		{
+			name:   "success served stale",
+			rate:   1,
+			policy: RateLimitStale,
+			id:     fakeFetchID,
+			want:   fakeFetchID,
+		},
+		{
+			name:    "failed stale without item",
+			rate:    1,
+			policy:  RateLimitStale,
+			id:      notExistModelID,
+			wantErr: ErrRateLimited,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					return &Model{Name: id}, nil
+				},
+			}
+			fc := NewCache(mockedFetcher, WithTTL(time.Millisecond), WithFetchRateLimit(tt.rate, 1, tt.policy))
+			if _, err := fc.Fetch(context.Background(), fakeFetchID); err != nil {
+				t.Fatalf("FetchCache.Fetch() error = %v", err)
+			}
+			time.Sleep(2 * time.Millisecond)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			model, err := fc.Fetch(ctx, tt.id)
+			if err != tt.wantErr {
+				t.Fatalf("FetchCache.Fetch() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && model.Name != tt.want {
+				t.Errorf("FetchCache.Fetch() expect model = %v, have %v", tt.want, model.Name)
+			}
+		})
+	}
+}
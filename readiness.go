@@ -0,0 +1,125 @@
+package resource
+
+import "sync"
+
+// WithReadiness keeps the cache not ready, see Ready, until its warm-up
+// loaded fraction of its keys: the hot keys of WithHotKeys fetched when the
+// cache is created and the entries of the Warm calls, whose number is only
+// known once they return. The cache is ready anyway once every warm-up
+// returned, and then stays ready. Without WithReadiness a cache is always
+// ready, with it a cache which is never warmed never is.
+func WithReadiness(fraction float64) Option {
+	return func(o *options) {
+		o.readiness = fraction
+		o.hasReadiness = true
+	}
+}
+
+// WarmupStatus is the progress of the warm-up of a cache, see
+// WithReadiness.
+type WarmupStatus struct {
+	// Total is the number of keys of the warm-ups, Loaded the number
+	// cached and Failed the number whose fetch failed.
+	Total  int `json:"total"`
+	Loaded int `json:"loaded"`
+	Failed int `json:"failed"`
+	// Running is the number of warm-ups in progress.
+	Running int  `json:"running"`
+	Ready   bool `json:"ready"`
+}
+
+// Ready reports whether the warm-up of WithReadiness reached its fraction,
+// e.g. for a readiness probe.
+func (fc *FetchCache) Ready() bool {
+	return fc.Warmup().Ready
+}
+
+// Warmed returns a channel closed once the cache is ready, see Ready.
+func (fc *FetchCache) Warmed() <-chan struct{} {
+	return fc.warmup.ready
+}
+
+// Warmup returns the progress of the warm-up.
+func (fc *FetchCache) Warmup() WarmupStatus {
+	fc.warmup.mu.Lock()
+	defer fc.warmup.mu.Unlock()
+	return fc.warmup.status
+}
+
+// warmupTracker follows the warm-ups of a cache for WithReadiness
+type warmupTracker struct {
+	mu       sync.Mutex
+	status   WarmupStatus
+	fraction float64
+	// unsized is the number of warm-ups whose number of keys isn't
+	// known yet and started whether any warm-up started
+	unsized int
+	started bool
+	ready   chan struct{}
+}
+
+// newWarmupTracker returns a tracker ready until a warm-up starts unless
+// hold
+func newWarmupTracker(fraction float64, hold bool) *warmupTracker {
+	w := &warmupTracker{fraction: fraction, ready: make(chan struct{})}
+	if !hold {
+		w.status.Ready = true
+		close(w.ready)
+	}
+	return w
+}
+
+// begin starts a warm-up of an unknown number of keys
+func (w *warmupTracker) begin() {
+	w.mu.Lock()
+	w.status.Running++
+	w.unsized++
+	w.started = true
+	w.mu.Unlock()
+}
+
+// sized sets the number of keys of a warm-up started by begin
+func (w *warmupTracker) sized(n int) {
+	w.mu.Lock()
+	w.unsized--
+	w.status.Total += n
+	w.checkLocked()
+	w.mu.Unlock()
+}
+
+// loaded counts a key of a warm-up cached, or failed unless ok
+func (w *warmupTracker) loaded(ok bool) {
+	w.mu.Lock()
+	if ok {
+		w.status.Loaded++
+	} else {
+		w.status.Failed++
+	}
+	w.checkLocked()
+	w.mu.Unlock()
+}
+
+// end ends a warm-up of n keys, which was sized unless unsized
+func (w *warmupTracker) end(n int, unsized bool) {
+	w.mu.Lock()
+	if unsized {
+		w.unsized--
+		w.status.Total += n
+	}
+	w.status.Running--
+	w.checkLocked()
+	w.mu.Unlock()
+}
+
+// checkLocked makes the cache ready once the warm-ups reached the fraction,
+// w.mu must be held
+func (w *warmupTracker) checkLocked() {
+	s := &w.status
+	if s.Ready || !w.started || w.unsized > 0 {
+		return
+	}
+	if s.Running == 0 || float64(s.Loaded) >= w.fraction*float64(s.Total) {
+		s.Ready = true
+		close(w.ready)
+	}
+}
@@ -0,0 +1,146 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFetchCache_Ready(t *testing.T) {
+	var (
+		fakeFetchID     = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+		notExistModelID = "5634aeed-2106-43de-ab7d-c0ad4b1e195e"
+	)
+	ids := []string{fakeFetchID, notExistModelID, "slow-1", "slow-2"}
+
+	tests := []struct {
+		name       string
+		fraction   float64
+		readiness  bool
+		hotKeys    bool
+		warm       bool
+		wantReady  bool
+		wantStatus WarmupStatus
+	}{
+		{
+			name:       "success without readiness",
+			wantReady:  true,
+			wantStatus: WarmupStatus{Ready: true},
+		},
+		{
+			name:       "success never warmed",
+			readiness:  true,
+			wantStatus: WarmupStatus{},
+		},
+		{
+			name:       "success hot keys fraction loaded",
+			readiness:  true,
+			fraction:   0.25,
+			hotKeys:    true,
+			wantReady:  true,
+			wantStatus: WarmupStatus{Total: 4, Loaded: 1, Failed: 1, Running: 1, Ready: true},
+		},
+		{
+			name:       "success hot keys fraction not loaded",
+			readiness:  true,
+			fraction:   0.5,
+			hotKeys:    true,
+			wantStatus: WarmupStatus{Total: 4, Loaded: 1, Failed: 1, Running: 1},
+		},
+		{
+			name:       "success warm",
+			readiness:  true,
+			fraction:   1,
+			warm:       true,
+			wantReady:  true,
+			wantStatus: WarmupStatus{Total: 4, Loaded: 4, Ready: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			release := make(chan struct{})
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					switch id {
+					case notExistModelID:
+						return nil, errors.New("unexpected error")
+					case fakeFetchID:
+						return &Model{Name: id}, nil
+					}
+					select {
+					case <-release:
+						return &Model{Name: id}, nil
+					case <-ctx.Done():
+						return nil, ctx.Err()
+					}
+				},
+			}
+			var opts []Option
+			if tt.readiness {
+				opts = append(opts, WithReadiness(tt.fraction))
+			}
+			if tt.hotKeys {
+				opts = append(opts, WithHotKeys(&memHotKeys{ids: ids}, len(ids), 0, len(ids)))
+			}
+			fc := NewCache(mockedFetcher, opts...)
+			defer fc.Close(context.Background())
+			defer close(release)
+
+			if tt.warm {
+				if fc.Ready() {
+					t.Errorf("FetchCache.Ready() expect not ready before the warm-up")
+				}
+				ch := make(chan WarmEntry, len(ids))
+				for _, id := range ids {
+					ch <- WarmEntry{ID: id, Model: &Model{Name: id}}
+				}
+				close(ch)
+				_, _ = fc.Warm(context.Background(), ChanWarmer(ch), nil)
+			}
+			if tt.hotKeys {
+				// the fast ids are fetched, the slow ones wait for release
+				for s := fc.Warmup(); s.Loaded+s.Failed < 2; s = fc.Warmup() {
+					time.Sleep(time.Millisecond)
+				}
+			}
+
+			if have := fc.Ready(); have != tt.wantReady {
+				t.Errorf("FetchCache.Ready() expect %v, have %v", tt.wantReady, have)
+			}
+			if have := fc.Warmup(); have != tt.wantStatus {
+				t.Errorf("FetchCache.Warmup() expect %+v, have %+v", tt.wantStatus, have)
+			}
+			select {
+			case <-fc.Warmed():
+				if !tt.wantReady {
+					t.Errorf("FetchCache.Warmed() expect open while not ready")
+				}
+			default:
+				if tt.wantReady {
+					t.Errorf("FetchCache.Warmed() expect closed once ready")
+				}
+			}
+		})
+	}
+}
+
+func TestFetchCache_Ready_warmupEnded(t *testing.T) {
+	var notExistModelID = "5634aeed-2106-43de-ab7d-c0ad4b1e195e"
+	mockedFetcher := &FetcherMock{
+		FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+			return nil, errors.New("unexpected error")
+		},
+	}
+	fc := NewCache(mockedFetcher, WithReadiness(1), WithHotKeys(&memHotKeys{ids: []string{notExistModelID}}, 1, 0, 1))
+	defer fc.Close(context.Background())
+
+	select {
+	case <-fc.Warmed():
+	case <-time.After(time.Second):
+		t.Fatalf("FetchCache.Warmed() expect closed once the warm-up ended")
+	}
+	if have, want := fc.Warmup(), (WarmupStatus{Total: 1, Failed: 1, Ready: true}); have != want {
+		t.Errorf("FetchCache.Warmup() expect %+v, have %+v", want, have)
+	}
+}
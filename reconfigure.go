@@ -0,0 +1,164 @@
+package resource
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Reconfigure changes the tunable settings of a running cache: WithTTL,
+// WithMaxEntries and WithMaxConcurrency. Other options are ignored.
+//
+// A new TTL applies to items cached from now on, a lower max entries evicts
+// the oldest items right away and a new concurrency limit applies to the
+// next fetches while in-flight ones complete. The options are checked like
+// by New, on ErrInvalidOptions none of them is applied.
+func (fc *FetchCache) Reconfigure(opts ...Option) error {
+	o := options{
+		ttl:            time.Duration(atomic.LoadInt64(&fc.ttl)),
+		maxEntries:     int(atomic.LoadInt64(&fc.maxEntries)),
+		maxConcurrency: fc.fetchLimit.getLimit(),
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if err := o.validate(); err != nil {
+		return err
+	}
+
+	atomic.StoreInt64(&fc.ttl, int64(o.ttl))
+	atomic.StoreInt64(&fc.maxEntries, int64(o.maxEntries))
+	fc.fetchLimit.setLimit(o.maxConcurrency)
+
+	if o.maxEntries <= 0 {
+		return nil
+	}
+	fc.lockItems()
+	evicted := fc.evictLocked(o.maxEntries, EvictedReconfigure)
+	fc.itemsChanged(false)
+	fc.itemsLock.Unlock()
+	for _, key := range evicted {
+		fc.stats.remove(key)
+		fc.events.publish(EventEvict, key)
+	}
+	return nil
+}
+
+// limiter is a semaphore whose limit can change at runtime
+type limiter struct {
+	rejected uint64 // accessed atomically
+	mu       sync.Mutex
+	limit    int
+	active   int
+	wake     chan struct{}
+	// waiting is the number of acquire calls waiting for a slot, at most
+	// maxWaiting when above 0, each for up to waitTimeout when above 0
+	waiting     int
+	maxWaiting  int
+	waitTimeout time.Duration
+	// fair queues the waiters per tenant with WithTenantFairness, they
+	// are then granted the slots by dispatchLocked instead of woken up
+	fair *fairQueue
+}
+
+func newLimiter(limit, maxWaiting int, waitTimeout time.Duration) *limiter {
+	return &limiter{
+		limit:       limit,
+		wake:        make(chan struct{}),
+		maxWaiting:  maxWaiting,
+		waitTimeout: waitTimeout,
+	}
+}
+
+// acquire waits for a free slot or ctx to be done, a limit of 0 means no
+// limit. tenant is the queue of the call with WithTenantFairness.
+func (l *limiter) acquire(ctx context.Context, tenant string) error {
+	if l.fair != nil {
+		return l.acquireFair(ctx, tenant)
+	}
+	var timeout <-chan time.Time
+	queued := false
+	defer func() {
+		if queued {
+			l.mu.Lock()
+			l.waiting--
+			l.mu.Unlock()
+		}
+	}()
+	for {
+		l.mu.Lock()
+		if l.limit <= 0 || l.active < l.limit {
+			l.active++
+			l.mu.Unlock()
+			return nil
+		}
+		if !queued {
+			if l.maxWaiting > 0 && l.waiting >= l.maxWaiting {
+				l.mu.Unlock()
+				atomic.AddUint64(&l.rejected, 1)
+				return ErrQueueFull
+			}
+			l.waiting++
+			queued = true
+			if l.waitTimeout > 0 {
+				timer := time.NewTimer(l.waitTimeout)
+				defer timer.Stop()
+				timeout = timer.C
+			}
+		}
+		wake := l.wake
+		l.mu.Unlock()
+
+		select {
+		case <-wake:
+		case <-timeout:
+			atomic.AddUint64(&l.rejected, 1)
+			return ErrQueueTimeout
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (l *limiter) release() {
+	l.mu.Lock()
+	l.active--
+	l.wakeLocked()
+	l.mu.Unlock()
+}
+
+// depth returns the number of acquire calls waiting for a slot
+func (l *limiter) depth() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.waiting
+}
+
+func (l *limiter) getLimit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.limit
+}
+
+func (l *limiter) setLimit(limit int) {
+	l.mu.Lock()
+	l.limit = limit
+	l.wakeLocked()
+	l.mu.Unlock()
+}
+
+// wakeLocked hands the free slots to the waiters, l.mu must be held
+func (l *limiter) wakeLocked() {
+	if l.fair != nil {
+		l.dispatchLocked()
+		return
+	}
+	l.broadcastLocked()
+}
+
+// broadcastLocked wakes up every waiter, l.mu must be held
+func (l *limiter) broadcastLocked() {
+	close(l.wake)
+	l.wake = make(chan struct{})
+}
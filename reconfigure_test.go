@@ -0,0 +1,142 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFetchCache_Reconfigure(t *testing.T) {
+	mockedFetcher := &FetcherMock{
+		FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+			return &Model{Name: id}, nil
+		},
+	}
+
+	tests := []struct {
+		name           string
+		opts           []Option
+		fetchFirst     bool
+		reconfigure    []Option
+		ids            []string
+		wantItemCount  int
+		wantExpiration bool
+		wantErr        error
+	}{
+		{
+			name:          "success keep all items without limit",
+			ids:           []string{"a", "b", "c"},
+			wantItemCount: 3,
+		},
+		{
+			name:          "success evict items over new max entries",
+			reconfigure:   []Option{WithMaxEntries(2)},
+			ids:           []string{"a", "b", "c"},
+			wantItemCount: 2,
+		},
+		{
+			name:          "success evict items when lowering max entries",
+			opts:          []Option{WithMaxEntries(3)},
+			fetchFirst:    true,
+			reconfigure:   []Option{WithMaxEntries(1)},
+			ids:           []string{"a", "b", "c"},
+			wantItemCount: 1,
+		},
+		{
+			name:           "success apply new ttl",
+			reconfigure:    []Option{WithTTL(time.Minute)},
+			ids:            []string{"a"},
+			wantItemCount:  1,
+			wantExpiration: true,
+		},
+		{
+			name:          "failed negative ttl applying nothing",
+			opts:          []Option{WithMaxEntries(3)},
+			fetchFirst:    true,
+			reconfigure:   []Option{WithMaxEntries(1), WithTTL(-time.Minute)},
+			ids:           []string{"a", "b", "c"},
+			wantItemCount: 3,
+			wantErr:       ErrInvalidOptions,
+		},
+		{
+			name:          "failed negative max entries",
+			reconfigure:   []Option{WithMaxEntries(-1)},
+			ids:           []string{"a", "b"},
+			wantItemCount: 2,
+			wantErr:       ErrInvalidOptions,
+		},
+		{
+			name:          "failed invalid jitter",
+			reconfigure:   []Option{WithTTL(time.Minute), WithTTLJitter(1.5)},
+			ids:           []string{"a"},
+			wantItemCount: 1,
+			wantErr:       ErrInvalidOptions,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fc := NewCache(mockedFetcher, tt.opts...)
+			fetch := func() {
+				for _, id := range tt.ids {
+					_, _ = fc.Fetch(context.Background(), id)
+				}
+			}
+			if tt.fetchFirst {
+				fetch()
+			}
+			if err := fc.Reconfigure(tt.reconfigure...); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("FetchCache.Reconfigure() expect error = %v, have %v", tt.wantErr, err)
+			}
+			if !tt.fetchFirst {
+				fetch()
+			}
+
+			if fc.items.Len() != tt.wantItemCount {
+				t.Errorf("FetchCache.Reconfigure() expect item count = %v, have %v", tt.wantItemCount, fc.items.Len())
+			}
+			if i, _ := fc.items.Get(tt.ids[len(tt.ids)-1]); (i.Expiration != 0) != tt.wantExpiration {
+				t.Errorf("FetchCache.Reconfigure() expect expiration = %v, have %v", tt.wantExpiration, i.Expiration)
+			}
+		})
+	}
+}
+
+func TestFetchCache_Reconfigure_MaxConcurrency(t *testing.T) {
+	var active, maxActive int32
+	mockedFetcher := &FetcherMock{
+		FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+			n := atomic.AddInt32(&active, 1)
+			for {
+				m := atomic.LoadInt32(&maxActive)
+				if n <= m || atomic.CompareAndSwapInt32(&maxActive, m, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			atomic.AddInt32(&active, -1)
+			return &Model{Name: id}, nil
+		},
+	}
+	fc := NewCache(mockedFetcher, WithMaxConcurrency(4))
+	if err := fc.Reconfigure(WithMaxConcurrency(2)); err != nil {
+		t.Fatalf("FetchCache.Reconfigure() expect error = %v, have %v", nil, err)
+	}
+
+	var wg sync.WaitGroup
+	ids := []string{"a", "b", "c", "d", "e", "f"}
+	wg.Add(len(ids))
+	for _, id := range ids {
+		go func(id string) {
+			defer wg.Done()
+			_, _ = fc.Fetch(context.Background(), id)
+		}(id)
+	}
+	wg.Wait()
+
+	if maxActive > 2 {
+		t.Errorf("FetchCache.Fetch() expect at most %v concurrent fetches, have %v", 2, maxActive)
+	}
+}
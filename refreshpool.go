@@ -0,0 +1,154 @@
+package resource
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// WithRefreshPool runs the background refreshes, of the Eager keys of
+// WithLoadPolicy and of the items served stale by WithStaleWhileRevalidate,
+// on workers goroutines (1 if lower) taking the ids from a queue of up to
+// queueSize ids (1 if lower). When the queue is full the oldest queued
+// refresh is dropped, and an id already queued isn't queued again, so a
+// burst of refreshes can't pile up goroutines or memory.
+//
+// Every fetch of a stale item is then served the item at once while the
+// refresh is queued, instead of one of them refreshing it.
+func WithRefreshPool(workers, queueSize int) Option {
+	return func(o *options) {
+		if workers < 1 {
+			workers = 1
+		}
+		if queueSize < 1 {
+			queueSize = 1
+		}
+		o.refreshWorkers = workers
+		o.refreshQueueSize = queueSize
+	}
+}
+
+// refreshEntry is a queued refresh of id, queued at in ns
+type refreshEntry struct {
+	id string
+	at int64
+}
+
+// refreshPool is the queue of WithRefreshPool
+type refreshPool struct {
+	dropped uint64 // accessed atomically
+	mu      sync.Mutex
+	queue   []refreshEntry
+	queued  map[string]struct{}
+	max     int
+	// ready has a token while the queue may not be empty
+	ready chan struct{}
+}
+
+func newRefreshPool(max int) *refreshPool {
+	return &refreshPool{
+		queued: make(map[string]struct{}),
+		max:    max,
+		ready:  make(chan struct{}, 1),
+	}
+}
+
+// push queues a refresh of id, dropping the oldest one if the queue is full
+func (p *refreshPool) push(id string) {
+	p.mu.Lock()
+	if _, ok := p.queued[id]; ok {
+		p.mu.Unlock()
+		return
+	}
+	if len(p.queue) >= p.max {
+		delete(p.queued, p.queue[0].id)
+		p.queue = p.queue[1:]
+		atomic.AddUint64(&p.dropped, 1)
+	}
+	p.queue = append(p.queue, refreshEntry{id: id, at: time.Now().UnixNano()})
+	p.queued[id] = struct{}{}
+	p.mu.Unlock()
+	p.signal()
+}
+
+// pop returns the oldest queued refresh
+func (p *refreshPool) pop() (refreshEntry, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.queue) == 0 {
+		return refreshEntry{}, false
+	}
+	e := p.queue[0]
+	p.queue[0] = refreshEntry{}
+	p.queue = p.queue[1:]
+	delete(p.queued, e.id)
+	if len(p.queue) > 0 {
+		p.signal()
+	}
+	return e, true
+}
+
+func (p *refreshPool) signal() {
+	select {
+	case p.ready <- struct{}{}:
+	default:
+	}
+}
+
+func (p *refreshPool) depth() int {
+	if p == nil {
+		return 0
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.queue)
+}
+
+func (p *refreshPool) droppedCount() uint64 {
+	if p == nil {
+		return 0
+	}
+	return atomic.LoadUint64(&p.dropped)
+}
+
+// queueRefresh refreshes id in the refresh pool, right away in
+// synchronous mode
+func (fc *FetchCache) queueRefresh(id string) {
+	if fc.opts.synchronous {
+		fc.refreshQueued(context.Background(), refreshEntry{id: id, at: time.Now().UnixNano()})
+		return
+	}
+	fc.refreshes.push(id)
+}
+
+// runRefreshWorker runs the queued refreshes until stop
+func (fc *FetchCache) runRefreshWorker(stop <-chan struct{}) {
+	ctx, cancel := fc.life.context()
+	defer cancel()
+
+	for {
+		e, ok := fc.refreshes.pop()
+		if !ok {
+			select {
+			case <-stop:
+				return
+			case <-fc.refreshes.ready:
+			}
+			continue
+		}
+		fc.refreshQueued(ctx, e)
+	}
+}
+
+// refreshQueued reloads the id of e unless it was cached again since e was
+// queued
+func (fc *FetchCache) refreshQueued(ctx context.Context, e refreshEntry) {
+	key := fc.key(e.id)
+	fc.lock(key)
+	defer fc.unlock(key)
+	if i, found := fc.peekitem(key); found && i.Created > e.at && !i.expired() {
+		return
+	}
+	_, _, _ = fc.fetchFromFetcher(fc.refreshContext(ctx, e.id), e.id, fetchOptions{})
+}
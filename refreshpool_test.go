@@ -0,0 +1,97 @@
+package resource
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_refreshPool(t *testing.T) {
+	tests := []struct {
+		name        string
+		push        []string
+		max         int
+		wantPop     []string
+		wantDropped uint64
+	}{
+		{
+			name:    "success in order",
+			push:    []string{"a", "b", "c"},
+			max:     3,
+			wantPop: []string{"a", "b", "c"},
+		},
+		{
+			name:    "success queued once",
+			push:    []string{"a", "b", "a"},
+			max:     3,
+			wantPop: []string{"a", "b"},
+		},
+		{
+			name:        "success drop oldest",
+			push:        []string{"a", "b", "c", "d"},
+			max:         2,
+			wantPop:     []string{"c", "d"},
+			wantDropped: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newRefreshPool(tt.max)
+			for _, id := range tt.push {
+				p.push(id)
+			}
+			if p.depth() != len(tt.wantPop) {
+				t.Errorf("refreshPool.depth() expect = %v, have %v", len(tt.wantPop), p.depth())
+			}
+			for _, want := range tt.wantPop {
+				if e, ok := p.pop(); !ok || e.id != want {
+					t.Errorf("refreshPool.pop() expect = %v, have %v, %v", want, e.id, ok)
+				}
+			}
+			if _, ok := p.pop(); ok {
+				t.Errorf("refreshPool.pop() expect empty queue")
+			}
+			if p.droppedCount() != tt.wantDropped {
+				t.Errorf("refreshPool.droppedCount() expect = %v, have %v", tt.wantDropped, p.droppedCount())
+			}
+		})
+	}
+}
+
+func TestFetchCache_WithRefreshPool(t *testing.T) {
+	fakeFetchID := "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	var serviceCallCount int32
+	mockedFetcher := &FetcherMock{
+		FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+			n := atomic.AddInt32(&serviceCallCount, 1)
+			return &Model{Name: strconv.Itoa(int(n))}, nil
+		},
+	}
+	fc := NewCache(mockedFetcher, WithTTL(time.Minute), WithStaleWhileRevalidate(time.Minute), WithRefreshPool(1, 10))
+	defer fc.Close(context.Background())
+
+	_, _ = fc.FetchWithOptions(context.Background(), fakeFetchID, OverrideTTL(time.Nanosecond))
+	time.Sleep(time.Millisecond)
+	model, src, err := fc.FetchWithSource(context.Background(), fakeFetchID)
+	if err != nil || model.Name != "1" || src != SourceStale {
+		t.Fatalf("FetchCache.FetchWithSource() expect stale model 1, have %v, %v, %v", model, src, err)
+	}
+
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(time.Millisecond) {
+		if i, found := fc.peekitem(fakeFetchID); found && !i.expired() {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("FetchCache.FetchWithSource() expect item refreshed in the background")
+		}
+	}
+	model, src, err = fc.FetchWithSource(context.Background(), fakeFetchID)
+	if err != nil || model.Name != "2" || src != SourceMemory {
+		t.Errorf("FetchCache.FetchWithSource() expect refreshed model 2, have %v, %v, %v", model, src, err)
+	}
+	if have := atomic.LoadInt32(&serviceCallCount); have != 2 {
+		t.Errorf("FetchCache.FetchWithSource() expect service call count = %v, have %v", 2, have)
+	}
+}
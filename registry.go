@@ -0,0 +1,200 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// Error list
+var (
+	// ErrKindExists is returned by Registry.Register for a kind already
+	// registered.
+	ErrKindExists = errors.New("kind already registered")
+)
+
+// Registry holds one FetchCache per kind of model, e.g. "weights",
+// "configs" and "indexes", sharing a memory budget.
+//
+// Each kind has its own options, so its own TTL and eviction policy, and
+// the registry arbitrates the budget: whenever the kinds together hold
+// more than the budget, items of the kind holding the most bytes are
+// evicted, chosen by the eviction policy of that kind.
+//
+// A Registry is safe for use by multiple goroutines simultaneously.
+type Registry struct {
+	budget int64
+	size   SizeFunc
+	// mu serializes the budget enforcement and guards kinds
+	mu    sync.Mutex
+	kinds map[string]*kind
+}
+
+// kind is a registered cache and the bytes it holds
+type kind struct {
+	fc    *FetchCache
+	store *sizedStore
+}
+
+// NewRegistry creates a Registry of kinds holding at most budget bytes in
+// total as measured by size, DefaultSize if nil. A budget of 0 means no
+// limit.
+func NewRegistry(budget int, size SizeFunc) *Registry {
+	if size == nil {
+		size = DefaultSize
+	}
+	return &Registry{
+		budget: int64(budget),
+		size:   size,
+		kinds:  map[string]*kind{},
+	}
+}
+
+// Register creates the cache of kind over f, opts configure it like
+// NewCache. WithStore is honored, the registry measures the items stored
+// in it.
+func (r *Registry) Register(name string, f Fetcher, opts ...Option) (*FetchCache, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, found := r.kinds[name]; found {
+		return nil, ErrKindExists
+	}
+
+	k := &kind{}
+	opts = append(opts[:len(opts):len(opts)], func(o *options) {
+		next := o.store
+		if next == nil {
+			next = NewMapStore()
+		}
+		k.store = &sizedStore{next: next, size: r.size}
+		o.store = k.store
+		o.stored = r.enforce
+	})
+	k.fc = NewCache(f, opts...)
+	r.kinds[name] = k
+	return k.fc, nil
+}
+
+// Kind returns the cache of kind and false if it isn't registered.
+func (r *Registry) Kind(name string) (*FetchCache, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	k, found := r.kinds[name]
+	if !found {
+		return nil, false
+	}
+	return k.fc, true
+}
+
+// Kinds returns the registered kinds in order.
+func (r *Registry) Kinds() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	names := make([]string, 0, len(r.kinds))
+	for name := range r.kinds {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Usage returns the bytes held by each kind.
+func (r *Registry) Usage() map[string]int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	usage := make(map[string]int, len(r.kinds))
+	for name, k := range r.kinds {
+		usage[name] = int(k.store.bytesHeld())
+	}
+	return usage
+}
+
+// Close closes the caches of all kinds and returns the first error.
+func (r *Registry) Close(ctx context.Context) error {
+	r.mu.Lock()
+	kinds := make([]*kind, 0, len(r.kinds))
+	for _, k := range r.kinds {
+		kinds = append(kinds, k)
+	}
+	r.mu.Unlock()
+
+	var err error
+	for _, k := range kinds {
+		if cerr := k.fc.Close(ctx); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// enforce evicts items of the largest kinds until the budget is met
+func (r *Registry) enforce() {
+	if r.budget <= 0 {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for {
+		var (
+			total   int64
+			largest *kind
+			max     int64
+		)
+		for _, k := range r.kinds {
+			n := k.store.bytesHeld()
+			total += n
+			if largest == nil || n > max {
+				largest, max = k, n
+			}
+		}
+		if total <= r.budget || largest == nil || !largest.fc.evictOne() {
+			return
+		}
+	}
+}
+
+// sizedStore is a Store counting the bytes of the models it holds
+type sizedStore struct {
+	bytes int64 // accessed atomically
+	next  Store
+	size  SizeFunc
+}
+
+// Get implements Store.
+func (s *sizedStore) Get(key string) (Item, bool) {
+	return s.next.Get(key)
+}
+
+// Set implements Store.
+func (s *sizedStore) Set(key string, i Item) {
+	delta := int64(s.size(i.Object))
+	if old, found := s.next.Get(key); found {
+		delta -= int64(s.size(old.Object))
+	}
+	s.next.Set(key, i)
+	atomic.AddInt64(&s.bytes, delta)
+}
+
+// Delete implements Store.
+func (s *sizedStore) Delete(key string) {
+	if old, found := s.next.Get(key); found {
+		s.next.Delete(key)
+		atomic.AddInt64(&s.bytes, -int64(s.size(old.Object)))
+	}
+}
+
+// Len implements Store.
+func (s *sizedStore) Len() int {
+	return s.next.Len()
+}
+
+// Range implements Store.
+func (s *sizedStore) Range(fn func(key string, i Item) bool) {
+	s.next.Range(fn)
+}
+
+func (s *sizedStore) bytesHeld() int64 {
+	return atomic.LoadInt64(&s.bytes)
+}
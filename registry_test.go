@@ -0,0 +1,83 @@
+package resource
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestRegistry_Register(t *testing.T) {
+	tests := []struct {
+		name      string
+		budget    int
+		weights   []string
+		configs   []string
+		wantUsage map[string]int
+		wantItems map[string]int
+	}{
+		{
+			name:      "success within budget",
+			budget:    1000,
+			weights:   []string{"w1", "w2"},
+			configs:   []string{"c1"},
+			wantUsage: map[string]int{"weights": 204, "configs": 22},
+			wantItems: map[string]int{"weights": 2, "configs": 1},
+		},
+		{
+			name:      "success largest kind evicted",
+			budget:    240,
+			weights:   []string{"w1", "w2"},
+			configs:   []string{"c1", "c2"},
+			wantUsage: map[string]int{"weights": 102, "configs": 44},
+			wantItems: map[string]int{"weights": 1, "configs": 2},
+		},
+		{
+			name:      "success no limit",
+			weights:   []string{"w1", "w2", "w3"},
+			wantUsage: map[string]int{"weights": 306, "configs": 0},
+			wantItems: map[string]int{"weights": 3, "configs": 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRegistry(tt.budget, nil)
+			defer r.Close(context.Background())
+
+			sized := func(n int) *FetcherMock {
+				return &FetcherMock{
+					FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+						return &Model{Name: id, Data: bytes.Repeat([]byte{'x'}, n)}, nil
+					},
+				}
+			}
+			weights, err := r.Register("weights", sized(100), WithEvictionPolicy(EvictCostAware))
+			if err != nil {
+				t.Fatalf("Registry.Register() expect error = nil, have %v", err)
+			}
+			configs, _ := r.Register("configs", sized(20), WithTTL(0))
+			if _, err := r.Register("configs", sized(20)); err != ErrKindExists {
+				t.Errorf("Registry.Register() expect error = %v, have %v", ErrKindExists, err)
+			}
+
+			for _, id := range tt.weights {
+				_, _ = weights.Fetch(context.Background(), id)
+			}
+			for _, id := range tt.configs {
+				_, _ = configs.Fetch(context.Background(), id)
+			}
+			if usage := r.Usage(); !reflect.DeepEqual(usage, tt.wantUsage) {
+				t.Errorf("Registry.Usage() expect = %v, have %v", tt.wantUsage, usage)
+			}
+			for name, want := range tt.wantItems {
+				fc, _ := r.Kind(name)
+				if have := fc.Stats().Items; have != want {
+					t.Errorf("Registry.Kind(%q) expect items = %v, have %v", name, want, have)
+				}
+			}
+			if kinds := r.Kinds(); !reflect.DeepEqual(kinds, []string{"configs", "weights"}) {
+				t.Errorf("Registry.Kinds() expect = [configs weights], have %v", kinds)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package resource
+
+import (
+	"context"
+	"time"
+)
+
+// Result is a fetched Model with the caching directives of its response,
+// see ResultFetcher. The zero directives keep the configuration of the
+// cache.
+type Result struct {
+	Model *Model
+	// TTL, when positive, is how long the model is cached instead of the
+	// ttl of the cache. A TTL given to the fetch still takes precedence.
+	TTL time.Duration
+	// NoStore returns the model without caching it, see NoStore.
+	NoStore bool
+	// Size, when positive, is the size of the model for WithMaxValueSize
+	// instead of the SizeFunc.
+	Size int
+	// Version is the version of the model at the origin, such as an ETag,
+	// kept as EntryInfo.OriginVersion.
+	Version string
+	// Meta is added to the metadata of the item, see WithMetadata.
+	Meta map[string]string
+}
+
+// ResultFetcher is implemented by the Fetchers which drive the caching of
+// every response. NewCache calls FetchResult instead of Fetch when the
+// Fetcher implements it. The directives only apply to the models fetched by
+// this cache: the models received from peers are cached as configured.
+type ResultFetcher interface {
+	// FetchResult retrieves the Model for id with its caching directives.
+	FetchResult(ctx context.Context, id string) (Result, error)
+}
+
+type resultKey struct{}
+
+// resultFetcher calls FetchResult and hands the directives to the fetch
+// which made the call through its context, see withResult
+type resultFetcher struct {
+	f ResultFetcher
+}
+
+func (f resultFetcher) Fetch(ctx context.Context, id string) (*Model, error) {
+	r, err := f.f.FetchResult(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if dst, ok := ctx.Value(resultKey{}).(*Result); ok {
+		*dst = r
+	}
+	return r.Model, nil
+}
+
+// withResult returns ctx in which a resultFetcher stores the directives of
+// its response in r
+func withResult(ctx context.Context, r *Result) context.Context {
+	return context.WithValue(ctx, resultKey{}, r)
+}
+
+// ttl returns the TTL of r or d if r has none
+func (r Result) ttl(d time.Duration) time.Duration {
+	if r.TTL > 0 {
+		return r.TTL
+	}
+	return d
+}
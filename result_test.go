@@ -0,0 +1,88 @@
+package resource
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// resultFetcherMock is a ResultFetcher returning result for every id
+type resultFetcherMock struct {
+	FetcherMock
+	result Result
+}
+
+func (f *resultFetcherMock) FetchResult(ctx context.Context, id string) (Result, error) {
+	r := f.result
+	r.Model = &Model{Name: id}
+	return r, nil
+}
+
+func TestFetchCache_Fetch_ResultFetcher(t *testing.T) {
+	var (
+		fakeFetchID = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	)
+
+	tests := []struct {
+		name        string
+		result      Result
+		opts        []Option
+		fetchOpts   []FetchOption
+		wantCached  bool
+		wantTTL     time.Duration
+		wantVersion string
+	}{
+		{
+			name:       "success default directives",
+			opts:       []Option{WithTTL(time.Minute)},
+			wantCached: true,
+			wantTTL:    time.Minute,
+		},
+		{
+			name:        "success ttl and version of the response",
+			result:      Result{TTL: time.Hour, Version: `"v1"`},
+			opts:        []Option{WithTTL(time.Minute)},
+			wantCached:  true,
+			wantTTL:     time.Hour,
+			wantVersion: `"v1"`,
+		},
+		{
+			name:       "success fetch ttl takes precedence",
+			result:     Result{TTL: time.Hour},
+			fetchOpts:  []FetchOption{OverrideTTL(time.Second)},
+			wantCached: true,
+			wantTTL:    time.Second,
+		},
+		{
+			name:   "success no store response",
+			result: Result{NoStore: true},
+		},
+		{
+			name:   "success oversized response",
+			result: Result{Size: 1 << 20},
+			opts:   []Option{WithMaxValueSize(1024, nil)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fc := NewCache(&resultFetcherMock{result: tt.result}, tt.opts...)
+			model, err := fc.FetchWithOptions(context.Background(), fakeFetchID, tt.fetchOpts...)
+			if err != nil || model == nil || model.Name != fakeFetchID {
+				t.Fatalf("FetchCache.Fetch() expect model %v, have %v, %v", fakeFetchID, model, err)
+			}
+			info, cached := fc.Entry(fakeFetchID)
+			if cached != tt.wantCached {
+				t.Fatalf("FetchCache.Entry() expect cached = %v, have %v", tt.wantCached, cached)
+			}
+			if !cached {
+				return
+			}
+			if ttl := info.Expires.Sub(info.Created); ttl != tt.wantTTL {
+				t.Errorf("FetchCache.Entry() expect ttl = %v, have %v", tt.wantTTL, ttl)
+			}
+			if info.OriginVersion != tt.wantVersion {
+				t.Errorf("FetchCache.Entry() expect origin version = %v, have %v", tt.wantVersion, info.OriginVersion)
+			}
+		})
+	}
+}
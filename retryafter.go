@@ -0,0 +1,55 @@
+package resource
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// RateLimitedError is returned by a Fetcher whose origin asked to be called
+// again after RetryAfter, e.g. on a 429 response with a Retry-After header.
+// The cache doesn't call the Fetcher for the id until then: its fetches are
+// served the expired item if there is one and fail with a BackoffError
+// otherwise, whether WithFailureBackoff is set or not.
+type RateLimitedError struct {
+	RetryAfter time.Duration
+	// Err is the error of the origin, if any.
+	Err error
+}
+
+// Error implements error.
+func (e *RateLimitedError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("rate limited, retry after %s", e.RetryAfter)
+	}
+	return fmt.Sprintf("rate limited, retry after %s: %v", e.RetryAfter, e.Err)
+}
+
+// Unwrap returns the error of the origin.
+func (e *RateLimitedError) Unwrap() error {
+	return e.Err
+}
+
+// retryAfter returns the RetryAfter of the RateLimitedError of err, if any
+func retryAfter(err error) time.Duration {
+	var rl *RateLimitedError
+	if !errors.As(err, &rl) {
+		return 0
+	}
+	return rl.RetryAfter
+}
+
+// holdOff extends the backoff of key to the RetryAfter of err, if any
+func (fc *FetchCache) holdOff(key string, err error, now time.Time) {
+	d := retryAfter(err)
+	if d <= 0 {
+		return
+	}
+	atomic.StoreInt32(&fc.retryHints, 1)
+	ks := fc.stats.get(key)
+	if until := now.Add(d).UnixNano(); until > atomic.LoadInt64(&ks.retryAt) {
+		ks.lastErr.Store(errBox{err})
+		atomic.StoreInt64(&ks.retryAt, until)
+	}
+}
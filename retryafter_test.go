@@ -0,0 +1,76 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFetchCache_Fetch_RetryAfter(t *testing.T) {
+	fakeFetchID := "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+
+	tests := []struct {
+		name      string
+		cached    bool
+		wantModel bool
+	}{
+		{
+			name: "success fail without calling the fetcher until the hint elapses",
+		},
+		{
+			name:      "success serve the expired item until the hint elapses",
+			cached:    true,
+			wantModel: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limited := !tt.cached
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					if limited {
+						return nil, &RateLimitedError{RetryAfter: 100 * time.Millisecond, Err: errors.New("429")}
+					}
+					return &Model{Name: id}, nil
+				},
+			}
+			fc := NewCache(mockedFetcher, WithTTL(10*time.Millisecond))
+			if tt.cached {
+				fc.Fetch(context.Background(), fakeFetchID)
+				time.Sleep(20 * time.Millisecond)
+				limited = true
+			}
+			calls := len(mockedFetcher.FetchCalls())
+
+			m, err := fc.Fetch(context.Background(), fakeFetchID)
+			if (m != nil) != tt.wantModel {
+				t.Fatalf("FetchCache.Fetch() expect model = %v, have %v, %v", tt.wantModel, m, err)
+			}
+			var rl *RateLimitedError
+			if !tt.wantModel && !errors.As(err, &rl) {
+				t.Fatalf("FetchCache.Fetch() expect error = RateLimitedError, have %v", err)
+			}
+			m, err = fc.Fetch(context.Background(), fakeFetchID)
+			var backoff *BackoffError
+			if tt.wantModel && m == nil || !tt.wantModel && !errors.As(err, &backoff) {
+				t.Fatalf("FetchCache.Fetch() expect held off, have %v, %v", m, err)
+			}
+			if have := len(mockedFetcher.FetchCalls()) - calls; have != 1 {
+				t.Errorf("FetchCache.Fetch() expect fetcher calls = 1, have %d", have)
+			}
+			if s, _ := fc.KeyStats(fakeFetchID); s.RetryAt.IsZero() {
+				t.Errorf("FetchCache.KeyStats() expect RetryAt set")
+			}
+
+			limited = false
+			time.Sleep(120 * time.Millisecond)
+			if m, err := fc.Fetch(context.Background(), fakeFetchID); err != nil || m.Name != fakeFetchID {
+				t.Errorf("FetchCache.Fetch() expect model = %s, have %v, %v", fakeFetchID, m, err)
+			}
+			if have := len(mockedFetcher.FetchCalls()) - calls; have != 2 {
+				t.Errorf("FetchCache.Fetch() expect fetcher calls = 2, have %d", have)
+			}
+		})
+	}
+}
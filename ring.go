@@ -0,0 +1,169 @@
+package resource
+
+import (
+	"hash/crc32"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// PeerPicker is an interface that picks the peer owning a key, see
+// HTTPPool.SetPicker.
+type PeerPicker interface {
+	// PickPeer returns the base URL of the peer owning id, "" if there is
+	// none.
+	PickPeer(id string) string
+}
+
+// ReplicaPicker is a PeerPicker picking several owners per key, see
+// HTTPPool.Replicas.
+type ReplicaPicker interface {
+	PeerPicker
+	// PickPeers returns up to n distinct peers owning id, the first one
+	// being the peer of PickPeer.
+	PickPeers(id string, n int) []string
+}
+
+// HashRing is a ReplicaPicker consistently hashing keys to weighted peers.
+//
+// Every peer is placed on the ring replicas times its weight as virtual
+// nodes, so keys spread evenly and in proportion of the weights. A peer
+// joining or leaving only moves the keys of its virtual nodes, the others
+// keep their owner. A HashRing is safe for concurrent use and can be
+// updated while in use.
+type HashRing struct {
+	replicas int
+
+	mu      sync.RWMutex
+	weights map[string]int
+	hashes  []uint32
+	nodes   map[uint32]string
+}
+
+// NewHashRing creates an empty ring placing replicas virtual nodes per
+// unit of weight (1 if lower).
+func NewHashRing(replicas int) *HashRing {
+	if replicas < 1 {
+		replicas = 1
+	}
+	return &HashRing{
+		replicas: replicas,
+		weights:  make(map[string]int),
+		nodes:    make(map[uint32]string),
+	}
+}
+
+// Add adds peer with weight (1 if lower) or changes its weight.
+func (r *HashRing) Add(peer string, weight int) {
+	if weight < 1 {
+		weight = 1
+	}
+	r.mu.Lock()
+	r.weights[peer] = weight
+	r.buildLocked()
+	r.mu.Unlock()
+}
+
+// Remove removes peer, removing a missing peer is a no-op.
+func (r *HashRing) Remove(peer string) {
+	r.mu.Lock()
+	delete(r.weights, peer)
+	r.buildLocked()
+	r.mu.Unlock()
+}
+
+// Set replaces the peers of the ring with peers of weight 1.
+func (r *HashRing) Set(peers ...string) {
+	r.mu.Lock()
+	r.weights = make(map[string]int, len(peers))
+	for _, peer := range peers {
+		r.weights[peer] = 1
+	}
+	r.buildLocked()
+	r.mu.Unlock()
+}
+
+// Peers returns the peers of the ring and their weight.
+func (r *HashRing) Peers() map[string]int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	peers := make(map[string]int, len(r.weights))
+	for peer, weight := range r.weights {
+		peers[peer] = weight
+	}
+	return peers
+}
+
+// buildLocked places the virtual nodes of the peers, r.mu must be held.
+// The hash of a virtual node only depends on its peer and index so the
+// other peers keep their place when one is added or removed, and on a
+// collision the smallest peer wins whatever the order of the peers.
+func (r *HashRing) buildLocked() {
+	r.hashes = r.hashes[:0]
+	r.nodes = make(map[uint32]string, len(r.nodes))
+	for peer, weight := range r.weights {
+		for i := 0; i < r.replicas*weight; i++ {
+			h := crc32.ChecksumIEEE([]byte(strconv.Itoa(i) + peer))
+			if owner, ok := r.nodes[h]; ok {
+				if peer < owner {
+					r.nodes[h] = peer
+				}
+				continue
+			}
+			r.hashes = append(r.hashes, h)
+			r.nodes[h] = peer
+		}
+	}
+	sort.Slice(r.hashes, func(a, b int) bool { return r.hashes[a] < r.hashes[b] })
+}
+
+// PickPeer implements PeerPicker.
+func (r *HashRing) PickPeer(id string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if len(r.hashes) == 0 {
+		return ""
+	}
+	return r.nodes[r.hashes[r.searchLocked(id)]]
+}
+
+// PickPeers implements ReplicaPicker, the owners are the peers of the
+// virtual nodes following the key on the ring.
+func (r *HashRing) PickPeers(id string, n int) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if n > len(r.weights) {
+		n = len(r.weights)
+	}
+	if n <= 0 || len(r.hashes) == 0 {
+		return nil
+	}
+	peers := make([]string, 0, n)
+	for i, start := 0, r.searchLocked(id); i < len(r.hashes) && len(peers) < n; i++ {
+		peer := r.nodes[r.hashes[(start+i)%len(r.hashes)]]
+		if !containsString(peers, peer) {
+			peers = append(peers, peer)
+		}
+	}
+	return peers
+}
+
+// searchLocked returns the index of the virtual node owning id, r.mu must
+// be held and the ring not empty
+func (r *HashRing) searchLocked(id string) int {
+	h := crc32.ChecksumIEEE([]byte(id))
+	i := sort.Search(len(r.hashes), func(i int) bool { return r.hashes[i] >= h })
+	if i == len(r.hashes) {
+		i = 0
+	}
+	return i
+}
+
+func containsString(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,126 @@
+package resource
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestHashRing(t *testing.T) {
+	const keys = 10000
+	owners := func(r *HashRing) map[string]string {
+		m := make(map[string]string, keys)
+		for i := 0; i < keys; i++ {
+			key := "key-" + strconv.Itoa(i)
+			m[key] = r.PickPeer(key)
+		}
+		return m
+	}
+	count := func(m map[string]string) map[string]int {
+		c := make(map[string]int)
+		for _, peer := range m {
+			c[peer]++
+		}
+		return c
+	}
+
+	tests := []struct {
+		name string
+		// update changes the ring of peers a, b and c of weight 1
+		update    func(r *HashRing)
+		wantShare map[string]float64
+		// maxMoved is the share of keys allowed to change owner
+		maxMoved float64
+	}{
+		{
+			name:      "success join",
+			update:    func(r *HashRing) { r.Add("d", 1) },
+			wantShare: map[string]float64{"a": 0.25, "b": 0.25, "c": 0.25, "d": 0.25},
+			maxMoved:  0.35,
+		},
+		{
+			name:      "success leave",
+			update:    func(r *HashRing) { r.Remove("c") },
+			wantShare: map[string]float64{"a": 0.5, "b": 0.5},
+			maxMoved:  0.45,
+		},
+		{
+			name:      "success weighted",
+			update:    func(r *HashRing) { r.Add("c", 2) },
+			wantShare: map[string]float64{"a": 0.25, "b": 0.25, "c": 0.5},
+			maxMoved:  0.3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewHashRing(100)
+			r.Set("a", "b", "c")
+			before := owners(r)
+			tt.update(r)
+			after := owners(r)
+
+			for peer, share := range tt.wantShare {
+				have := float64(count(after)[peer]) / keys
+				if have < share-0.1 || have > share+0.1 {
+					t.Errorf("HashRing.PickPeer() expect share of %s = %v, have %v", peer, share, have)
+				}
+			}
+			if n := len(count(after)); n != len(tt.wantShare) {
+				t.Errorf("HashRing.PickPeer() expect %v peers, have %v", len(tt.wantShare), n)
+			}
+			moved := 0
+			for key, peer := range before {
+				if after[key] != peer {
+					moved++
+				}
+			}
+			if share := float64(moved) / keys; share > tt.maxMoved {
+				t.Errorf("HashRing expect at most %v of the keys moved, have %v", tt.maxMoved, share)
+			}
+		})
+	}
+}
+
+func TestHashRing_PickPeers(t *testing.T) {
+	r := NewHashRing(10)
+	r.Set("a", "b", "c")
+	for i := 0; i < 100; i++ {
+		key := "key-" + strconv.Itoa(i)
+		peers := r.PickPeers(key, 5)
+		if len(peers) != 3 || peers[0] != r.PickPeer(key) || peers[0] == peers[1] || peers[1] == peers[2] || peers[0] == peers[2] {
+			t.Fatalf("HashRing.PickPeers() expect the 3 peers starting with the owner, have %v", peers)
+		}
+	}
+}
+
+func TestHashRing_Empty(t *testing.T) {
+	r := NewHashRing(10)
+	if peer := r.PickPeer("lorem"); peer != "" {
+		t.Errorf("HashRing.PickPeer() expect no peer, have %q", peer)
+	}
+	r.Add("a", 1)
+	r.Remove("a")
+	if peer := r.PickPeer("lorem"); peer != "" || len(r.Peers()) != 0 {
+		t.Errorf("HashRing.PickPeer() expect no peer, have %q of %v", peer, r.Peers())
+	}
+}
+
+func TestHTTPPool_SetPicker(t *testing.T) {
+	p := NewHTTPPool("http://a")
+	p.Ring().Add("http://b", 1)
+	p.SetPicker(peerPickerFunc(func(id string) string { return "http://c" }))
+	if owners := p.owners("lorem"); len(owners) != 1 || owners[0] != "http://c" {
+		t.Errorf("HTTPPool.owners() expect http://c, have %q", owners)
+	}
+	p.SetPicker(nil)
+	p.Replicas = 2
+	if owners := p.owners("lorem"); len(owners) != 2 || owners[0] == owners[1] {
+		t.Errorf("HTTPPool.owners() expect both peers of the ring, have %q", owners)
+	}
+}
+
+// peerPickerFunc adapts a func to PeerPicker
+type peerPickerFunc func(id string) string
+
+func (f peerPickerFunc) PickPeer(id string) string {
+	return f(id)
+}
@@ -0,0 +1,223 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule is an interface that defines when a scheduled job runs next.
+type Schedule interface {
+	// Next returns the first activation time strictly after t.
+	Next(t time.Time) time.Time
+}
+
+// Every returns a Schedule activating every d.
+func Every(d time.Duration) Schedule {
+	return every(d)
+}
+
+type every time.Duration
+
+// Next implements Schedule.
+func (e every) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(e))
+}
+
+// cronSchedule is a parsed 5 field cron spec, each field is the set of
+// values it matches
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	domStar, dowStar              bool
+}
+
+var cronFields = []struct {
+	name     string
+	min, max int
+}{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 6},
+}
+
+// ParseCron parses a standard 5 field cron spec "minute hour day-of-month
+// month day-of-week" supporting *, lists, ranges and steps, e.g.
+// "*/15 * * * *" or "0 3 * * 1-5". Times are in the location of the time
+// given to Next.
+func ParseCron(spec string) (Schedule, error) {
+	fields := strings.Fields(spec)
+	if len(fields) != len(cronFields) {
+		return nil, fmt.Errorf("cron spec %q: expected %d fields, have %d", spec, len(cronFields), len(fields))
+	}
+
+	var sets [5]uint64
+	for i, field := range fields {
+		set, err := parseCronField(field, cronFields[i].min, cronFields[i].max)
+		if err != nil {
+			return nil, fmt.Errorf("cron spec %q: %s: %v", spec, cronFields[i].name, err)
+		}
+		sets[i] = set
+	}
+	return &cronSchedule{
+		minute:  sets[0],
+		hour:    sets[1],
+		dom:     sets[2],
+		month:   sets[3],
+		dow:     sets[4],
+		domStar: fields[2] == "*",
+		dowStar: fields[4] == "*",
+	}, nil
+}
+
+func parseCronField(field string, min, max int) (uint64, error) {
+	var set uint64
+	for _, part := range strings.Split(field, ",") {
+		rng, step := part, 1
+		if i := strings.IndexByte(part, '/'); i >= 0 {
+			s, err := strconv.Atoi(part[i+1:])
+			if err != nil || s <= 0 {
+				return 0, fmt.Errorf("invalid step %q", part)
+			}
+			rng, step = part[:i], s
+		}
+
+		lo, hi := min, max
+		if rng != "*" {
+			bounds := strings.SplitN(rng, "-", 2)
+			var err error
+			if lo, err = strconv.Atoi(bounds[0]); err != nil {
+				return 0, fmt.Errorf("invalid value %q", part)
+			}
+			hi = lo
+			if len(bounds) == 2 {
+				if hi, err = strconv.Atoi(bounds[1]); err != nil {
+					return 0, fmt.Errorf("invalid value %q", part)
+				}
+			} else if step > 1 {
+				hi = max
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("value %q out of range [%d, %d]", part, min, max)
+		}
+		for v := lo; v <= hi; v += step {
+			set |= 1 << uint(v)
+		}
+	}
+	return set, nil
+}
+
+// Next implements Schedule.
+func (c *cronSchedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	// a matching time exists within 5 years for any valid spec
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		switch {
+		case c.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !c.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case c.hour&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case c.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// dayMatches follows cron: when both day fields are restricted either may match
+func (c *cronSchedule) dayMatches(t time.Time) bool {
+	dom := c.dom&(1<<uint(t.Day())) != 0
+	dow := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.domStar || c.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// WithScheduledRefresh re-fetches every cached key at each activation of s,
+// with at most concurrency fetches at a time (1 if lower). Items which fail
+// to refresh keep their current model.
+func WithScheduledRefresh(s Schedule, concurrency int) Option {
+	return func(o *options) {
+		if concurrency < 1 {
+			concurrency = 1
+		}
+		o.refreshSchedule = s
+		o.refreshConcurrency = concurrency
+	}
+}
+
+// cachedIDs returns the ids of the cached items, with WithHashedKeys only
+// the eager keys are known
+func (fc *FetchCache) cachedIDs() []string {
+	if fc.opts.keyHash != 0 {
+		return fc.eager.list()
+	}
+	fc.rlockItems()
+	ids := make([]string, 0, fc.items.Len())
+	fc.items.Range(func(id string, _ item) bool {
+		ids = append(ids, id)
+		return true
+	})
+	fc.itemsLock.RUnlock()
+	return ids
+}
+
+// runScheduledRefresh refreshes all keys at every activation until stop
+func (fc *FetchCache) runScheduledRefresh(stop <-chan struct{}) {
+	ctx, cancel := fc.life.context()
+	defer cancel()
+
+	for {
+		next := fc.opts.refreshSchedule.Next(time.Now())
+		if next.IsZero() {
+			return
+		}
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		if !fc.Degraded() {
+			fc.refreshAll(ctx, fc.opts.refreshConcurrency)
+		}
+	}
+}
+
+// refreshAll reloads every cached key with bounded concurrency
+func (fc *FetchCache) refreshAll(ctx context.Context, concurrency int) {
+	fc.reloadAll(ctx, fc.cachedIDs(), concurrency, nil)
+}
+
+// reloadAll reloads ids with bounded concurrency until ctx is done or the
+// cache closed, calling done, if not nil, with the error of every reload
+func (fc *FetchCache) reloadAll(ctx context.Context, ids []string, concurrency int, done func(err error)) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	g := fc.newGroup(ctx, concurrency, false)
+	for _, id := range ids {
+		id := id
+		if !g.Go(func(ctx context.Context) error {
+			err := fc.reload(ctx, id)
+			if done != nil {
+				done(err)
+			}
+			return nil
+		}) {
+			break
+		}
+	}
+	_ = g.Wait()
+}
@@ -0,0 +1,94 @@
+package resource
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestParseCron(t *testing.T) {
+	from := time.Date(2024, time.March, 15, 10, 7, 30, 0, time.UTC) // a Friday
+	tests := []struct {
+		name    string
+		spec    string
+		want    time.Time
+		wantErr bool
+	}{
+		{
+			name: "success every 15 minutes",
+			spec: "*/15 * * * *",
+			want: time.Date(2024, time.March, 15, 10, 15, 0, 0, time.UTC),
+		},
+		{
+			name: "success daily at 3am",
+			spec: "0 3 * * *",
+			want: time.Date(2024, time.March, 16, 3, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "success weekdays only",
+			spec: "30 9 * * 1-5",
+			want: time.Date(2024, time.March, 18, 9, 30, 0, 0, time.UTC),
+		},
+		{
+			name: "success first of month list",
+			spec: "0 0 1 1,6 *",
+			want: time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:    "failed wrong field count",
+			spec:    "* * * *",
+			wantErr: true,
+		},
+		{
+			name:    "failed out of range",
+			spec:    "61 * * * *",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := ParseCron(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseCron() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := s.Next(from); !got.Equal(tt.want) {
+				t.Errorf("Schedule.Next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchCache_WithScheduledRefresh(t *testing.T) {
+	var (
+		mu        sync.Mutex
+		callCount = make(map[string]int)
+	)
+	mockedFetcher := &FetcherMock{
+		FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+			mu.Lock()
+			callCount[id]++
+			mu.Unlock()
+			return &Model{Name: id}, nil
+		},
+	}
+	fc := NewCache(mockedFetcher, WithScheduledRefresh(Every(20*time.Millisecond), 2))
+	for _, id := range []string{"a", "b", "c"} {
+		_, _ = fc.Fetch(context.Background(), id)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if err := fc.Close(context.Background()); err != nil {
+		t.Fatalf("FetchCache.Close() error = %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, id := range []string{"a", "b", "c"} {
+		if callCount[id] < 2 {
+			t.Errorf("FetchCache expect %v refreshed on schedule, have %v fetches", id, callCount[id])
+		}
+	}
+}
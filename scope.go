@@ -0,0 +1,66 @@
+package resource
+
+import (
+	"context"
+	"sync"
+)
+
+type requestScopeKey struct{}
+
+// requestScope holds the models fetched within a request
+type requestScope struct {
+	models sync.Map
+}
+
+// scopedKey identifies an id of a cache in a requestScope, a request may
+// fetch from several caches
+type scopedKey struct {
+	fc *FetchCache
+	id string
+}
+
+// NewRequestScope returns a copy of ctx carrying a cache for the lifetime
+// of a request: the fetches made with it serve again the model fetched the
+// first time for an id from an unlocked map, so the request sees a single
+// model per id even when the shared cache refreshes or clears it meanwhile.
+// Fetches with BypassCache still go to the shared cache and their result is
+// served to the next fetches of the request. Errors aren't kept.
+func NewRequestScope(ctx context.Context) context.Context {
+	return context.WithValue(ctx, requestScopeKey{}, &requestScope{})
+}
+
+func requestScopeFromContext(ctx context.Context) *requestScope {
+	if ctx == nil {
+		return nil
+	}
+	scope, _ := ctx.Value(requestScopeKey{}).(*requestScope)
+	return scope
+}
+
+// fetch implements FetchWithOptions and FetchWithSource, through the
+// request scope of ctx if any
+func (fc *FetchCache) fetch(ctx context.Context, id string, opts []FetchOption) (*Model, Source, error) {
+	id, err := fc.resolve(ctx, id)
+	if err != nil {
+		return nil, 0, err
+	}
+	scope := requestScopeFromContext(ctx)
+	if scope == nil {
+		model, src, err := fc.fetchShared(ctx, id, opts)
+		fc.strict.servedFrom(fc.key(id), model, src)
+		return model, src, err
+	}
+
+	sk := scopedKey{fc: fc, id: id}
+	if o := newFetchOptions(fetchOptionsFromContext(ctx), opts); !o.bypass {
+		if v, ok := scope.models.Load(sk); ok {
+			return v.(*Model), SourceMemory, nil
+		}
+	}
+	model, src, err := fc.fetchShared(ctx, id, opts)
+	fc.strict.servedFrom(fc.key(id), model, src)
+	if err == nil {
+		scope.models.Store(sk, model)
+	}
+	return model, src, err
+}
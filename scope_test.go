@@ -0,0 +1,66 @@
+package resource
+
+import (
+	"context"
+	"strconv"
+	"testing"
+)
+
+func TestNewRequestScope(t *testing.T) {
+	fakeFetchID := "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+
+	tests := []struct {
+		name             string
+		scoped           bool
+		opts             []FetchOption
+		wantName         string
+		serviceCallCount int
+	}{
+		{
+			name:             "success consistent within the request",
+			scoped:           true,
+			wantName:         "1",
+			serviceCallCount: 1,
+		},
+		{
+			name:             "success bypass",
+			scoped:           true,
+			opts:             []FetchOption{BypassCache()},
+			wantName:         "2",
+			serviceCallCount: 2,
+		},
+		{
+			name:             "success without scope",
+			wantName:         "2",
+			serviceCallCount: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serviceCallCount := 0
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					serviceCallCount++
+					return &Model{Name: strconv.Itoa(serviceCallCount)}, nil
+				},
+			}
+			fc := NewCache(mockedFetcher)
+			defer fc.Close(context.Background())
+
+			ctx := context.Background()
+			if tt.scoped {
+				ctx = NewRequestScope(ctx)
+			}
+			_, _ = fc.Fetch(ctx, fakeFetchID)
+			fc.Clear(fakeFetchID)
+			_, _ = fc.FetchWithOptions(ctx, fakeFetchID, tt.opts...)
+			model, err := fc.Fetch(ctx, fakeFetchID)
+			if err != nil || model.Name != tt.wantName {
+				t.Errorf("FetchCache.Fetch() expect = %v, have %v, %v", tt.wantName, model, err)
+			}
+			if serviceCallCount != tt.serviceCallCount {
+				t.Errorf("FetchCache.Fetch() expect service call count = %v, have %v", tt.serviceCallCount, serviceCallCount)
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package resource
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"sort"
+)
+
+// ErrSeedCorrupted is returned when opening a file which isn't a seed
+// written by BuildSeed, or a truncated one.
+var ErrSeedCorrupted = errors.New("seed corrupted")
+
+// Const list
+const (
+	// seedMagic starts every seed
+	seedMagic = "RCSEED1\n"
+	// seedRecordSize is the size of an index record: the offset of the
+	// key, the length of the key and the length of the value following it
+	seedRecordSize = 16
+	// seedHeaderSize is the magic followed by the number of records
+	seedHeaderSize = len(seedMagic) + 8
+)
+
+// Seed is a read-only set of models prebuilt offline by BuildSeed, e.g.
+// static metadata shipped with every release, served on the misses of a
+// cache before its Fetcher, see WithSeed. OpenSeed maps the file in memory
+// so the models are only read when fetched, and the pages are shared by
+// the processes opening the same file. The models are looked up by their
+// cache key, so a seed built by a cache WithHashedKeys is to be used with
+// the same hash.
+type Seed struct {
+	data  []byte
+	n     int
+	close func() error
+}
+
+// NewSeed returns the seed in data, written by BuildSeed.
+func NewSeed(data []byte) (*Seed, error) {
+	if len(data) < seedHeaderSize || string(data[:len(seedMagic)]) != seedMagic {
+		return nil, ErrSeedCorrupted
+	}
+	n := binary.LittleEndian.Uint64(data[len(seedMagic):])
+	if n > uint64(len(data)-seedHeaderSize)/seedRecordSize {
+		return nil, ErrSeedCorrupted
+	}
+	s := &Seed{data: data, n: int(n)}
+	for i := 0; i < s.n; i++ {
+		off, keyLen, valLen := s.record(i)
+		if off > uint64(len(data)) || uint64(keyLen)+uint64(valLen) > uint64(len(data))-off {
+			return nil, ErrSeedCorrupted
+		}
+	}
+	return s, nil
+}
+
+// Len returns the number of models of the seed.
+func (s *Seed) Len() int {
+	return s.n
+}
+
+// Get returns the model of key, decoded on every call.
+func (s *Seed) Get(key string) (*Model, bool, error) {
+	i := sort.Search(s.n, func(i int) bool { return s.key(i) >= key })
+	if i == s.n || s.key(i) != key {
+		return nil, false, nil
+	}
+	off, keyLen, valLen := s.record(i)
+	start := off + uint64(keyLen)
+	model, err := JSONCodec{}.Decode(s.data[start : start+uint64(valLen)])
+	if err != nil {
+		return nil, false, err
+	}
+	return model, true, nil
+}
+
+// Close releases the memory of the seed, which must not be used after.
+func (s *Seed) Close() error {
+	if s.close == nil {
+		return nil
+	}
+	return s.close()
+}
+
+// record returns the index record i
+func (s *Seed) record(i int) (off uint64, keyLen, valLen uint32) {
+	r := s.data[seedHeaderSize+i*seedRecordSize:]
+	return binary.LittleEndian.Uint64(r), binary.LittleEndian.Uint32(r[8:]), binary.LittleEndian.Uint32(r[12:])
+}
+
+// key returns the key of the record i
+func (s *Seed) key(i int) string {
+	off, keyLen, _ := s.record(i)
+	return string(s.data[off : off+uint64(keyLen)])
+}
+
+// BuildSeed writes models by key to w as a seed, see OpenSeed. The models
+// are encoded with JSONCodec, their Value is left out.
+func BuildSeed(w io.Writer, models map[string]*Model) error {
+	keys := make([]string, 0, len(models))
+	for key, model := range models {
+		if model != nil {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	values := make([][]byte, len(keys))
+	for i, key := range keys {
+		v, err := JSONCodec{}.Encode(models[key])
+		if err != nil {
+			return err
+		}
+		values[i] = v
+	}
+
+	bw := bufio.NewWriter(w)
+	bw.WriteString(seedMagic)
+	var buf [seedRecordSize]byte
+	binary.LittleEndian.PutUint64(buf[:], uint64(len(keys)))
+	bw.Write(buf[:8])
+	off := uint64(seedHeaderSize + len(keys)*seedRecordSize)
+	for i, key := range keys {
+		binary.LittleEndian.PutUint64(buf[:], off)
+		binary.LittleEndian.PutUint32(buf[8:], uint32(len(key)))
+		binary.LittleEndian.PutUint32(buf[12:], uint32(len(values[i])))
+		bw.Write(buf[:])
+		off += uint64(len(key) + len(values[i]))
+	}
+	for i, key := range keys {
+		bw.WriteString(key)
+		bw.Write(values[i])
+	}
+	return bw.Flush()
+}
+
+// BuildSeed writes the cached models which haven't expired to w as a seed,
+// see the BuildSeed function, and returns their number. Together with
+// Hydrate it turns a dump into a seed.
+func (fc *FetchCache) BuildSeed(w io.Writer) (int, error) {
+	models := map[string]*Model{}
+	fc.rlockItems()
+	fc.items.Range(func(key string, i Item) bool {
+		if !i.expired() && i.Object != nil {
+			models[key] = i.Object
+		}
+		return true
+	})
+	fc.itemsLock.RUnlock()
+	return len(models), BuildSeed(w, models)
+}
+
+// WithSeed layers s under the cache: the misses are looked up in s before
+// the L2 store, the peers and the Fetcher, and the models found are cached
+// like fetched ones, see SourceSeed. The refreshes of the items past their
+// WithMaxLifetime go to the Fetcher. s is closed by the owner, after the
+// cache.
+func WithSeed(s *Seed) Option {
+	return func(o *options) {
+		o.seed = s
+	}
+}
+
+// seedFetcher answers the fetches of the ids of the seed
+type seedFetcher struct {
+	seed *Seed
+	key  func(id string) string
+	next Fetcher
+}
+
+// Fetch implements Fetcher.
+func (sf *seedFetcher) Fetch(ctx context.Context, id string) (*Model, error) {
+	if origin, _ := ctx.Value(originKey{}).(bool); !origin {
+		if model, found, err := sf.seed.Get(sf.key(id)); err == nil && found {
+			setSource(ctx, SourceSeed)
+			return model, nil
+		}
+	}
+	return sf.next.Fetch(ctx, id)
+}
@@ -0,0 +1,36 @@
+//go:build unix
+
+package resource
+
+import (
+	"os"
+	"syscall"
+)
+
+// OpenSeed maps the seed file at path in memory, see Seed. It is closed by
+// Seed.Close.
+func OpenSeed(path string) (*Seed, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() < int64(seedHeaderSize) {
+		return nil, ErrSeedCorrupted
+	}
+	data, err := syscall.Mmap(int(file.Fd()), 0, int(info.Size()), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, err
+	}
+	s, err := NewSeed(data)
+	if err != nil {
+		syscall.Munmap(data)
+		return nil, err
+	}
+	s.close = func() error { return syscall.Munmap(data) }
+	return s, nil
+}
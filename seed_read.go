@@ -0,0 +1,15 @@
+//go:build !unix
+
+package resource
+
+import "os"
+
+// OpenSeed reads the seed file at path in memory, see Seed. Seeds are only
+// mapped on unix systems.
+func OpenSeed(path string) (*Seed, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewSeed(data)
+}
@@ -0,0 +1,160 @@
+package resource
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSeed(t *testing.T) {
+	fakeFetchID := "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	notExistModelID := "5634aeed-2106-43de-ab7d-c0ad4b1e195e"
+	var buf bytes.Buffer
+	err := BuildSeed(&buf, map[string]*Model{
+		fakeFetchID: {Name: "lorem", Data: []byte("ipsum")},
+		"a":         {Name: "a"},
+		"z":         {Name: "z"},
+	})
+	if err != nil {
+		t.Fatalf("BuildSeed() expect error = nil, have %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		data      []byte
+		id        string
+		wantName  string
+		wantErr   error
+		wantFound bool
+	}{
+		{
+			name:      "success get seeded model",
+			data:      buf.Bytes(),
+			id:        fakeFetchID,
+			wantName:  "lorem",
+			wantFound: true,
+		},
+		{
+			name:      "success get the first model",
+			data:      buf.Bytes(),
+			id:        "a",
+			wantName:  "a",
+			wantFound: true,
+		},
+		{
+			name: "success miss unknown id",
+			data: buf.Bytes(),
+			id:   notExistModelID,
+		},
+		{
+			name:    "failed open truncated seed",
+			data:    buf.Bytes()[:buf.Len()-3],
+			wantErr: ErrSeedCorrupted,
+		},
+		{
+			name:    "failed open not a seed",
+			data:    []byte(`{"entries": []}`),
+			wantErr: ErrSeedCorrupted,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "seed")
+			if err := os.WriteFile(path, tt.data, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			s, err := OpenSeed(path)
+			if err != tt.wantErr {
+				t.Fatalf("OpenSeed() expect error = %v, have %v", tt.wantErr, err)
+			}
+			if err != nil {
+				return
+			}
+			defer s.Close()
+			if s.Len() != 3 {
+				t.Errorf("Seed.Len() expect 3, have %d", s.Len())
+			}
+			m, found, err := s.Get(tt.id)
+			if err != nil || found != tt.wantFound || found && m.Name != tt.wantName {
+				t.Errorf("Seed.Get() expect %v %s, have %v %v %v", tt.wantFound, tt.wantName, found, m, err)
+			}
+		})
+	}
+}
+
+func TestFetchCache_WithSeed(t *testing.T) {
+	fakeFetchID := "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	notExistModelID := "5634aeed-2106-43de-ab7d-c0ad4b1e195e"
+	var buf bytes.Buffer
+	BuildSeed(&buf, map[string]*Model{fakeFetchID: {Name: "seeded"}})
+	seed, err := NewSeed(buf.Bytes())
+	if err != nil {
+		t.Fatalf("NewSeed() expect error = nil, have %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		id         string
+		wantName   string
+		wantSource Source
+		wantCalls  int
+	}{
+		{
+			name:       "success serve seeded model without the fetcher",
+			id:         fakeFetchID,
+			wantName:   "seeded",
+			wantSource: SourceSeed,
+		},
+		{
+			name:       "success fetch unseeded model",
+			id:         notExistModelID,
+			wantName:   notExistModelID,
+			wantSource: SourceOrigin,
+			wantCalls:  1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					return &Model{Name: id}, nil
+				},
+			}
+			fc := NewCache(mockedFetcher, WithSeed(seed))
+			m, src, err := fc.FetchWithSource(context.Background(), tt.id)
+			if err != nil || m.Name != tt.wantName || src != tt.wantSource {
+				t.Fatalf("FetchCache.FetchWithSource() expect %s from %s, have %v from %s, %v", tt.wantName, tt.wantSource, m, src, err)
+			}
+			if _, src, _ = fc.FetchWithSource(context.Background(), tt.id); src != SourceMemory {
+				t.Errorf("FetchCache.FetchWithSource() expect cached, have %s", src)
+			}
+			if len(mockedFetcher.FetchCalls()) != tt.wantCalls {
+				t.Errorf("FetchCache.Fetch() expect fetcher calls = %d, have %d", tt.wantCalls, len(mockedFetcher.FetchCalls()))
+			}
+			if tt.wantSource == SourceSeed && fc.Stats().SeedHits != 1 {
+				t.Errorf("FetchCache.Stats() expect SeedHits = 1, have %d", fc.Stats().SeedHits)
+			}
+		})
+	}
+}
+
+func TestFetchCache_BuildSeed(t *testing.T) {
+	fakeFetchID := "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	fc := NewCache(&FetcherMock{
+		FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+			return &Model{Name: id}, nil
+		},
+	})
+	fc.Fetch(context.Background(), fakeFetchID)
+	var buf bytes.Buffer
+	n, err := fc.BuildSeed(&buf)
+	if err != nil || n != 1 {
+		t.Fatalf("FetchCache.BuildSeed() expect 1, have %d, %v", n, err)
+	}
+	seed, _ := NewSeed(buf.Bytes())
+	if m, found, _ := seed.Get(fakeFetchID); !found || m.Name != fakeFetchID {
+		t.Errorf("Seed.Get() expect %s, have %v", fakeFetchID, m)
+	}
+}
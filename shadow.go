@@ -0,0 +1,78 @@
+package resource
+
+import (
+	"context"
+)
+
+// Mismatch is a difference between the Fetcher and the shadow Fetcher of
+// WithShadowFetcher.
+type Mismatch struct {
+	ID          string
+	Model       *Model
+	Err         error
+	ShadowModel *Model
+	ShadowErr   error
+	// TraceID is the id of the request of the miss, see WithTraceID.
+	TraceID string
+}
+
+// WithShadowFetcher sends every miss to shadow as well, for instance a new
+// backend under evaluation, and calls report with the results which differ:
+// one call failed and not the other or equal returned false. The shadow
+// call runs in the background once the miss is served, its result is never
+// cached nor returned.
+func WithShadowFetcher(shadow Fetcher, equal func(model, shadow *Model) bool, report func(Mismatch)) Option {
+	return func(o *options) {
+		o.shadow = shadow
+		o.shadowEqual = equal
+		o.shadowReport = report
+	}
+}
+
+// shadowFetcher compares the results of next with those of shadow
+type shadowFetcher struct {
+	next        Fetcher
+	shadow      Fetcher
+	equal       func(model, shadow *Model) bool
+	report      func(Mismatch)
+	life        *lifecycle
+	synchronous bool
+	traceID     func(ctx context.Context) string
+}
+
+// Fetch implements Fetcher.
+func (f *shadowFetcher) Fetch(ctx context.Context, id string) (*Model, error) {
+	model, err := f.next.Fetch(ctx, id)
+	var trace string
+	if f.traceID != nil {
+		trace = f.traceID(ctx)
+	}
+	if f.synchronous {
+		f.compare(ctx, id, trace, model, err)
+		return model, err
+	}
+	f.life.goBackground("shadow-compare", func(stop <-chan struct{}) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			select {
+			case <-stop:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+		f.compare(ctx, id, trace, model, err)
+	})
+	return model, err
+}
+
+func (f *shadowFetcher) compare(ctx context.Context, id, trace string, model *Model, err error) {
+	shadow, shadowErr := f.shadow.Fetch(ctx, id)
+	switch {
+	case err != nil && shadowErr != nil:
+		return
+	case err == nil && shadowErr == nil && f.equal(model, shadow):
+		return
+	}
+	f.report(Mismatch{ID: id, Model: model, Err: err, ShadowModel: shadow, ShadowErr: shadowErr, TraceID: trace})
+}
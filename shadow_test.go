@@ -0,0 +1,83 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestFetchCache_WithShadowFetcher(t *testing.T) {
+	var (
+		fakeFetchID = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+		errShadow   = errors.New("shadow unavailable")
+	)
+
+	tests := []struct {
+		name         string
+		shadowModel  *Model
+		shadowErr    error
+		wantMismatch bool
+	}{
+		{
+			name:        "success same model",
+			shadowModel: &Model{Name: "lorem"},
+		},
+		{
+			name:         "success different model reported",
+			shadowModel:  &Model{Name: "ipsum"},
+			wantMismatch: true,
+		},
+		{
+			name:         "success shadow error reported",
+			shadowErr:    errShadow,
+			wantMismatch: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					return &Model{Name: "lorem"}, nil
+				},
+			}
+			shadowFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					return tt.shadowModel, tt.shadowErr
+				},
+			}
+			var (
+				mu         sync.Mutex
+				mismatches []Mismatch
+			)
+			equal := func(model, shadow *Model) bool { return model.Name == shadow.Name }
+			report := func(m Mismatch) {
+				mu.Lock()
+				mismatches = append(mismatches, m)
+				mu.Unlock()
+			}
+			fc := NewCache(mockedFetcher, WithShadowFetcher(shadowFetcher, equal, report))
+
+			for i := 0; i < 3; i++ {
+				model, err := fc.Fetch(context.Background(), fakeFetchID)
+				if err != nil || model.Name != "lorem" {
+					t.Fatalf("FetchCache.Fetch() expect primary model, have %v, %v", model, err)
+				}
+			}
+			// Close waits for the shadow calls
+			if err := fc.Close(context.Background()); err != nil {
+				t.Fatalf("FetchCache.Close() error = %v", err)
+			}
+
+			if calls := len(shadowFetcher.FetchCalls()); calls != 1 {
+				t.Errorf("FetchCache.Fetch() expect 1 shadow call per miss, have %v", calls)
+			}
+			if (len(mismatches) == 1) != tt.wantMismatch {
+				t.Fatalf("WithShadowFetcher() expect mismatch = %v, have %v", tt.wantMismatch, mismatches)
+			}
+			if tt.wantMismatch && (mismatches[0].ID != fakeFetchID || mismatches[0].ShadowErr != tt.shadowErr) {
+				t.Errorf("WithShadowFetcher() expect mismatch of %v, have %+v", fakeFetchID, mismatches[0])
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package resource
+
+import (
+	"hash/fnv"
+	"hash/maphash"
+	"math/rand"
+	"sync/atomic"
+)
+
+// HashFunc hashes the keys a ShardedStore routes to its shards, e.g. FNV,
+// maphash or xxhash.
+type HashFunc func(key string) uint64
+
+// ShardRouter returns the shard in [0, n) of a key hashed to hash.
+type ShardRouter func(hash uint64, n int) int
+
+// FNVHash is the HashFunc of 64 bit FNV-1a.
+func FNVHash(key string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+	return h.Sum64()
+}
+
+// MapHash returns a HashFunc of hash/maphash with a random seed, faster
+// than FNVHash and resistant to keys chosen to collide.
+func MapHash() HashFunc {
+	seed := maphash.MakeSeed()
+	return func(key string) uint64 {
+		var h maphash.Hash
+		h.SetSeed(seed)
+		_, _ = h.WriteString(key)
+		return h.Sum64()
+	}
+}
+
+// ModuloRouter is the ShardRouter taking hash modulo n.
+func ModuloRouter(hash uint64, n int) int {
+	return int(hash % uint64(n))
+}
+
+// JumpRouter is the ShardRouter of the jump consistent hash, which moves
+// the fewest keys when n changes.
+func JumpRouter(hash uint64, n int) int {
+	b, j := int64(-1), int64(0)
+	for j < int64(n) {
+		b = j
+		hash = hash*2862933555777941757 + 1
+		j = int64(float64(b+1) * (float64(int64(1)<<31) / float64((hash>>33)+1)))
+	}
+	return int(b)
+}
+
+// ShardedStore is a Store spreading the items over several Go maps, so
+// growing one rehashes a fraction of the items, see NewShardedStore.
+type ShardedStore struct {
+	shards []mapStore
+	// counts are the item counts of the shards for Occupancy
+	counts []int64 // accessed atomically
+	hash   HashFunc
+	route  ShardRouter
+	// peaks are the most items of the shards since they were last rebuilt
+	peaks []int
+}
+
+// NewShardedStore returns a Store of n shards, at least 1, routing the keys
+// hashed by hash with route. A nil hash is FNVHash and a nil route is
+// ModuloRouter.
+func NewShardedStore(n int, hash HashFunc, route ShardRouter) *ShardedStore {
+	if n < 1 {
+		n = 1
+	}
+	if hash == nil {
+		hash = FNVHash
+	}
+	if route == nil {
+		route = ModuloRouter
+	}
+	s := &ShardedStore{
+		shards: make([]mapStore, n),
+		counts: make([]int64, n),
+		peaks:  make([]int, n),
+		hash:   hash,
+		route:  route,
+	}
+	for i := range s.shards {
+		s.shards[i] = mapStore{}
+	}
+	return s
+}
+
+// shard returns the index of the shard of key
+func (s *ShardedStore) shard(key string) int {
+	return s.route(s.hash(key), len(s.shards))
+}
+
+// Get implements Store.
+func (s *ShardedStore) Get(key string) (Item, bool) {
+	return s.shards[s.shard(key)].Get(key)
+}
+
+// Set implements Store.
+func (s *ShardedStore) Set(key string, i Item) {
+	n := s.shard(key)
+	if _, found := s.shards[n][key]; !found {
+		atomic.AddInt64(&s.counts[n], 1)
+	}
+	s.shards[n][key] = i
+	if l := len(s.shards[n]); l > s.peaks[n] {
+		s.peaks[n] = l
+	}
+}
+
+// Delete implements Store.
+func (s *ShardedStore) Delete(key string) {
+	n := s.shard(key)
+	if _, found := s.shards[n][key]; found {
+		delete(s.shards[n], key)
+		atomic.AddInt64(&s.counts[n], -1)
+	}
+}
+
+// Len implements Store.
+func (s *ShardedStore) Len() int {
+	n := 0
+	for _, m := range s.shards {
+		n += len(m)
+	}
+	return n
+}
+
+// Range implements Store, starting at a random shard.
+func (s *ShardedStore) Range(fn func(key string, i Item) bool) {
+	start := rand.Intn(len(s.shards))
+	for n := range s.shards {
+		for key, i := range s.shards[(start+n)%len(s.shards)] {
+			if !fn(key, i) {
+				return
+			}
+		}
+	}
+}
+
+// Occupancy returns the number of items of every shard. Unlike the Store
+// methods it may be called at any time, e.g. by a metrics collector.
+func (s *ShardedStore) Occupancy() []int {
+	occupancy := make([]int, len(s.counts))
+	for n := range s.counts {
+		occupancy[n] = int(atomic.LoadInt64(&s.counts[n]))
+	}
+	return occupancy
+}
+
+// Skew returns the ratio of the items of the fullest shard to the mean of
+// the shards, 1 for an even distribution and 0 when empty. A skew well
+// above 1 calls for another HashFunc or ShardRouter.
+func (s *ShardedStore) Skew() float64 {
+	total, most := 0, 0
+	for _, n := range s.Occupancy() {
+		total += n
+		if n > most {
+			most = n
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(most) * float64(len(s.shards)) / float64(total)
+}
+
+// compact rebuilds shard n if it holds less than minLoad of its peak and
+// returns whether it did, see FetchCache.Compact
+func (s *ShardedStore) compact(n int, minLoad float64) bool {
+	m, ok := compactMap(s.shards[n], s.peaks[n], minLoad)
+	if !ok {
+		return false
+	}
+	s.shards[n], s.peaks[n] = m, len(m)
+	return true
+}
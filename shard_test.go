@@ -0,0 +1,79 @@
+package resource
+
+import (
+	"context"
+	"strconv"
+	"testing"
+)
+
+func TestShardedStore(t *testing.T) {
+	// firstShard routes every key to shard 0
+	firstShard := func(hash uint64, n int) int { return 0 }
+
+	tests := []struct {
+		name     string
+		hash     HashFunc
+		route    ShardRouter
+		wantSkew func(skew float64) bool
+	}{
+		{
+			name:     "success fnv modulo",
+			wantSkew: func(skew float64) bool { return skew < 1.5 },
+		},
+		{
+			name:     "success maphash jump",
+			hash:     MapHash(),
+			route:    JumpRouter,
+			wantSkew: func(skew float64) bool { return skew < 1.5 },
+		},
+		{
+			name:     "success skewed router",
+			route:    firstShard,
+			wantSkew: func(skew float64) bool { return skew == 4 },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					return &Model{Name: id}, nil
+				},
+			}
+			s := NewShardedStore(4, tt.hash, tt.route)
+			fc := NewCache(mockedFetcher, WithStore(s))
+			defer fc.Close(context.Background())
+
+			for i := 0; i < 1000; i++ {
+				_, _ = fc.Fetch(context.Background(), strconv.Itoa(i))
+			}
+			fc.Clear("0")
+			total := 0
+			for _, n := range s.Occupancy() {
+				total += n
+			}
+			if total != 999 || s.Len() != 999 {
+				t.Errorf("ShardedStore.Occupancy() expect 999 items, have %v (Len %v)", total, s.Len())
+			}
+			if skew := s.Skew(); !tt.wantSkew(skew) {
+				t.Errorf("ShardedStore.Skew() unexpected skew = %v of %v", skew, s.Occupancy())
+			}
+			if _, found := fc.Entry("42"); !found {
+				t.Errorf("FetchCache.Entry() expect found = true, have false")
+			}
+		})
+	}
+}
+
+func TestJumpRouter(t *testing.T) {
+	// growing from 10 to 11 shards moves about 1/11 of the keys
+	moved := 0
+	for i := 0; i < 10000; i++ {
+		h := FNVHash(strconv.Itoa(i))
+		if JumpRouter(h, 10) != JumpRouter(h, 11) {
+			moved++
+		}
+	}
+	if moved > 1500 {
+		t.Errorf("JumpRouter() expect about 909 keys moved, have %v", moved)
+	}
+}
@@ -0,0 +1,126 @@
+// Package simulate replays a recorded trace of key accesses against caches
+// of several capacities and eviction policies, so they can be chosen from
+// the hit ratios of real traffic instead of guesswork.
+//
+// The replay runs offline on real synchronous FetchCaches whose Fetcher
+// answers at once. As fetch costs aren't part of a trace, EvictCostAware
+// ranks items by their hits and size only.
+package simulate
+
+import (
+	"context"
+	"io"
+	"time"
+
+	resource "github.com/hieunmce/cache"
+)
+
+// Access is an access to a key recorded in a trace.
+type Access struct {
+	Key  string
+	Time time.Time
+}
+
+// FromEvents returns the accesses of the hit and miss events of a
+// subscription, see resource.FetchCache.Subscribe.
+func FromEvents(events []resource.Event) []Access {
+	var trace []Access
+	for _, e := range events {
+		if e.Type == resource.EventHit || e.Type == resource.EventMiss {
+			trace = append(trace, Access{Key: e.Key, Time: e.Time})
+		}
+	}
+	return trace
+}
+
+// ReadTrace returns the accesses of an access trace, see
+// resource.WithAccessTrace.
+func ReadTrace(r io.Reader) ([]Access, error) {
+	tr := resource.NewTraceReader(r)
+	var trace []Access
+	for {
+		rec, err := tr.Next()
+		if err == io.EOF {
+			return trace, nil
+		}
+		if err != nil {
+			return trace, err
+		}
+		trace = append(trace, Access{Key: rec.Key, Time: rec.Time})
+	}
+}
+
+// Config is a cache configuration to replay a trace against.
+type Config struct {
+	// Capacity is the max entries of the cache, see resource.WithMaxEntries.
+	Capacity int
+	// Policy is the eviction policy of the cache.
+	Policy resource.EvictionPolicy
+}
+
+// Grid returns the configurations of every capacity with every policy.
+func Grid(capacities []int, policies ...resource.EvictionPolicy) []Config {
+	configs := make([]Config, 0, len(capacities)*len(policies))
+	for _, capacity := range capacities {
+		for _, policy := range policies {
+			configs = append(configs, Config{Capacity: capacity, Policy: policy})
+		}
+	}
+	return configs
+}
+
+// Result is the outcome of replaying a trace against a Config.
+type Result struct {
+	Config
+	Hits   uint64
+	Misses uint64
+}
+
+// HitRatio returns the fraction of the accesses which were hits.
+func (r Result) HitRatio() float64 {
+	if r.Hits+r.Misses == 0 {
+		return 0
+	}
+	return float64(r.Hits) / float64(r.Hits+r.Misses)
+}
+
+// Replay replays trace against each of configs and returns their results
+// in the same order. Items never expire, only evictions cause misses
+// besides the first access of every key.
+func Replay(ctx context.Context, trace []Access, configs ...Config) ([]Result, error) {
+	results := make([]Result, 0, len(configs))
+	for _, c := range configs {
+		r, err := replay(ctx, trace, c)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
+
+func replay(ctx context.Context, trace []Access, c Config) (Result, error) {
+	fc := resource.NewCache(fetcher{},
+		resource.WithSynchronous(),
+		resource.WithTTL(0),
+		resource.WithMaxEntries(c.Capacity),
+		resource.WithEvictionPolicy(c.Policy),
+	)
+	defer fc.Close(context.Background())
+
+	for _, a := range trace {
+		if _, err := fc.Fetch(ctx, a.Key); err != nil {
+			return Result{Config: c}, err
+		}
+	}
+	stats := fc.Stats()
+	return Result{Config: c, Hits: stats.Hits, Misses: stats.Misses}, nil
+}
+
+// fetcher answers every id with an empty model
+type fetcher struct{}
+
+// Fetch implements resource.Fetcher.
+func (fetcher) Fetch(ctx context.Context, id string) (*resource.Model, error) {
+	return &resource.Model{Name: id}, nil
+}
@@ -0,0 +1,107 @@
+package simulate
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	resource "github.com/hieunmce/cache"
+)
+
+func TestReplay(t *testing.T) {
+	trace := FromEvents([]resource.Event{
+		{Type: resource.EventMiss, Key: "a"},
+		{Type: resource.EventSet, Key: "a"},
+		{Type: resource.EventMiss, Key: "b"},
+		{Type: resource.EventHit, Key: "a"},
+		{Type: resource.EventMiss, Key: "c"},
+		{Type: resource.EventHit, Key: "a"},
+		{Type: resource.EventHit, Key: "b"},
+		{Type: resource.EventEvict, Key: "c"},
+	})
+
+	tests := []struct {
+		name    string
+		configs []Config
+		want    []Result
+	}{
+		{
+			name:    "success unbounded",
+			configs: []Config{{}},
+			want:    []Result{{Hits: 3, Misses: 3}},
+		},
+		{
+			name:    "success capacity grid",
+			configs: Grid([]int{1, 2}, resource.EvictOldest),
+			want: []Result{
+				{Config: Config{Capacity: 1}, Hits: 0, Misses: 6},
+				{Config: Config{Capacity: 2}, Hits: 1, Misses: 5},
+			},
+		},
+		{
+			name:    "success no config",
+			configs: nil,
+			want:    []Result{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := Replay(context.Background(), trace, tt.configs...)
+			if err != nil {
+				t.Fatalf("Replay() expect error = nil, have %v", err)
+			}
+			if !reflect.DeepEqual(results, tt.want) {
+				t.Errorf("Replay() expect = %v, have %v", tt.want, results)
+			}
+		})
+	}
+}
+
+func TestResult_HitRatio(t *testing.T) {
+	tests := []struct {
+		name   string
+		result Result
+		want   float64
+	}{
+		{name: "success", result: Result{Hits: 3, Misses: 1}, want: 0.75},
+		{name: "success empty", result: Result{}, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if have := tt.result.HitRatio(); have != tt.want {
+				t.Errorf("Result.HitRatio() expect = %v, have %v", tt.want, have)
+			}
+		})
+	}
+}
+
+func TestFromEvents(t *testing.T) {
+	now := time.Now()
+	trace := FromEvents([]resource.Event{
+		{Type: resource.EventHit, Key: "a", Time: now},
+		{Type: resource.EventFlush},
+	})
+	if want := []Access{{Key: "a", Time: now}}; !reflect.DeepEqual(trace, want) {
+		t.Errorf("FromEvents() expect = %v, have %v", want, trace)
+	}
+}
+
+func TestReadTrace(t *testing.T) {
+	var buf bytes.Buffer
+	fc := resource.NewCache(fetcher{}, resource.WithSynchronous(), resource.WithAccessTrace(&buf, 1))
+	for _, id := range []string{"a", "b", "a"} {
+		_, _ = fc.Fetch(context.Background(), id)
+	}
+	fc.Close(context.Background())
+
+	trace, err := ReadTrace(&buf)
+	if err != nil {
+		t.Fatalf("ReadTrace() expect error = nil, have %v", err)
+	}
+	results, _ := Replay(context.Background(), trace, Config{Capacity: 1})
+	if want := (Result{Config: Config{Capacity: 1}, Misses: 3}); len(results) != 1 || results[0] != want {
+		t.Errorf("Replay() expect = %v, have %v", want, results)
+	}
+}
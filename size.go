@@ -0,0 +1,42 @@
+package resource
+
+// SizeFunc is a func that returns the size of a model in bytes, see
+// WithMaxValueSize.
+type SizeFunc func(m *Model) int
+
+// DefaultSize returns the size of the Name and Data of m.
+func DefaultSize(m *Model) int {
+	if m == nil {
+		return 0
+	}
+	return len(m.Name) + len(m.Data)
+}
+
+// WithMaxValueSize never caches the fetched models larger than max bytes
+// as measured by size, DefaultSize if nil. They are returned to the caller
+// but fetched again on the next call, and counted in Stats.
+func WithMaxValueSize(max int, size SizeFunc) Option {
+	return func(o *options) {
+		if size == nil {
+			size = DefaultSize
+		}
+		o.maxValueSize = max
+		o.sizeFunc = size
+	}
+}
+
+// oversized reports whether model is too large to be cached, measured by
+// the SizeFunc unless size is positive
+func (fc *FetchCache) oversized(model *Model, size int) bool {
+	if fc.opts.maxValueSize <= 0 {
+		return false
+	}
+	if size <= 0 {
+		size = fc.opts.sizeFunc(model)
+	}
+	if size <= fc.opts.maxValueSize {
+		return false
+	}
+	fc.metrics.oversized()
+	return true
+}
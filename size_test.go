@@ -0,0 +1,63 @@
+package resource
+
+import (
+	"context"
+	"testing"
+)
+
+func TestFetchCache_WithMaxValueSize(t *testing.T) {
+	var (
+		fakeFetchID = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	)
+
+	tests := []struct {
+		name             string
+		data             []byte
+		size             SizeFunc
+		serviceCallCount int
+		wantOversized    uint64
+	}{
+		{
+			name:             "success cache small value",
+			data:             make([]byte, 10),
+			serviceCallCount: 1,
+		},
+		{
+			name:             "success skip oversized value",
+			data:             make([]byte, 100),
+			serviceCallCount: 2,
+			wantOversized:    2,
+		},
+		{
+			name:             "success custom size func",
+			data:             make([]byte, 100),
+			size:             func(m *Model) int { return 1 },
+			serviceCallCount: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serviceCallCount := 0
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					serviceCallCount++
+					return &Model{Name: "lorem", Data: tt.data}, nil
+				},
+			}
+			fc := NewCache(mockedFetcher, WithMaxValueSize(50, tt.size))
+			defer fc.Close(context.Background())
+			for i := 0; i < 2; i++ {
+				model, err := fc.Fetch(context.Background(), fakeFetchID)
+				if err != nil || len(model.Data) != len(tt.data) {
+					t.Fatalf("FetchCache.Fetch() = %v, %v, want the fetched model", model, err)
+				}
+			}
+			if serviceCallCount != tt.serviceCallCount {
+				t.Errorf("FetchCache.Fetch() expect service call count = %v, have %v", tt.serviceCallCount, serviceCallCount)
+			}
+			if s := fc.Stats(); s.Oversized != tt.wantOversized {
+				t.Errorf("FetchCache.Stats() expect oversized = %v, have %v", tt.wantOversized, s.Oversized)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package resource
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// WithSnapshotReads serves hits from an immutable copy of the cached items
+// swapped atomically, so hits take no lock at all, for workloads made
+// almost only of hits. Every write copies the items, or with an interval
+// above 0 the writes of each interval are published together by a
+// background goroutine: until then hits of new items go through the
+// per-key lock instead. Clear and Flush are published immediately.
+func WithSnapshotReads(interval time.Duration) Option {
+	return func(o *options) {
+		o.snapshotReads = true
+		o.snapshotInterval = interval
+	}
+}
+
+// snapshot is the published copy of the cached items
+type snapshot struct {
+	dirty int32 // accessed atomically
+	items atomic.Value
+}
+
+// loadSnapshot returns the item of id from the published snapshot
+func (fc *FetchCache) loadSnapshot(id string) (item, bool) {
+	m, _ := fc.snap.items.Load().(map[string]item)
+	i, found := m[id]
+	return i, found
+}
+
+// itemsChanged publishes the items again, now when immediate or the
+// interval is 0 and else at the next republish. fc.itemsLock must be held.
+func (fc *FetchCache) itemsChanged(immediate bool) {
+	if !fc.opts.snapshotReads {
+		return
+	}
+	if !immediate && fc.opts.snapshotInterval > 0 {
+		atomic.StoreInt32(&fc.snap.dirty, 1)
+		return
+	}
+	atomic.StoreInt32(&fc.snap.dirty, 0)
+	m := make(map[string]item, fc.items.Len())
+	fc.items.Range(func(id string, i item) bool {
+		m[id] = i
+		return true
+	})
+	fc.snap.items.Store(m)
+}
+
+// republish publishes the items if they changed since the last snapshot
+func (fc *FetchCache) republish() {
+	if atomic.LoadInt32(&fc.snap.dirty) == 0 {
+		return
+	}
+	fc.rlockItems()
+	fc.itemsChanged(true)
+	fc.itemsLock.RUnlock()
+}
+
+// runRepublish republishes the items every interval until stop
+func (fc *FetchCache) runRepublish(stop <-chan struct{}) {
+	ticker := time.NewTicker(fc.opts.snapshotInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+		fc.republish()
+	}
+}
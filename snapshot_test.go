@@ -0,0 +1,67 @@
+package resource
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFetchCache_WithSnapshotReads(t *testing.T) {
+	var (
+		fakeFetchID = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	)
+
+	tests := []struct {
+		name     string
+		interval time.Duration
+	}{
+		{
+			name: "success publish every write",
+		},
+		{
+			name:     "success publish every interval",
+			interval: 5 * time.Millisecond,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var callCount int32
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					atomic.AddInt32(&callCount, 1)
+					return &Model{Name: "lorem"}, nil
+				},
+			}
+			fc := NewCache(mockedFetcher, WithSnapshotReads(tt.interval))
+			defer fc.Close(context.Background())
+
+			for i := 0; i < 2; i++ {
+				if _, err := fc.Fetch(context.Background(), fakeFetchID); err != nil {
+					t.Fatalf("FetchCache.Fetch() error = %v", err)
+				}
+			}
+			if callCount != 1 {
+				t.Errorf("FetchCache.Fetch() expect 1 fetcher call before publish, have %v", callCount)
+			}
+
+			if tt.interval > 0 {
+				time.Sleep(4 * tt.interval)
+			}
+			if _, found := fc.loadSnapshot(fakeFetchID); !found {
+				t.Errorf("FetchCache.loadSnapshot() expect item published")
+			}
+
+			fc.Clear(fakeFetchID)
+			if _, found := fc.loadSnapshot(fakeFetchID); found {
+				t.Errorf("FetchCache.Clear() expect item removed from the snapshot immediately")
+			}
+			if _, err := fc.Fetch(context.Background(), fakeFetchID); err != nil {
+				t.Fatalf("FetchCache.Fetch() error = %v", err)
+			}
+			if callCount != 2 {
+				t.Errorf("FetchCache.Fetch() expect 2 fetcher calls after Clear, have %v", callCount)
+			}
+		})
+	}
+}
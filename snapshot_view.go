@@ -0,0 +1,146 @@
+package resource
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"time"
+)
+
+// SnapshotEntry is a cached item in a Snapshot.
+type SnapshotEntry struct {
+	Key string
+	EntryInfo
+}
+
+// Snapshot is an immutable point-in-time view of the cached items, sorted
+// by key, see FetchCache.Snapshot.
+type Snapshot struct {
+	time    time.Time
+	entries []SnapshotEntry
+	// enc encrypts the written values, see WithEncrypter
+	enc Encrypter
+	// lifetime is the lifetime stats of the cache when the snapshot was
+	// taken
+	lifetime LifetimeStats
+	// quota is the usage of WithOriginQuota, nil without
+	quota *QuotaUsage
+}
+
+// Snapshot returns a view of the items cached now, expired ones left out,
+// which can be iterated and serialized without blocking the cache. The
+// models are shared with the cache unless cloneValues is true, which copies
+// Data while Value stays shared. With
+// WithSnapshotReads the view is taken from the last published snapshot.
+func (fc *FetchCache) Snapshot(cloneValues bool) *Snapshot {
+	var items map[string]item
+	if fc.opts.snapshotReads {
+		items, _ = fc.snap.items.Load().(map[string]item)
+	} else {
+		fc.rlockItems()
+		items = make(map[string]item, fc.items.Len())
+		fc.items.Range(func(key string, i item) bool {
+			items[key] = i
+			return true
+		})
+		fc.itemsLock.RUnlock()
+	}
+
+	s := &Snapshot{
+		time:     time.Now(),
+		entries:  make([]SnapshotEntry, 0, len(items)),
+		enc:      fc.opts.encrypter,
+		lifetime: fc.metrics.lifetimeStats(),
+	}
+	if fc.quota != nil {
+		u := fc.quota.usage()
+		s.quota = &u
+	}
+	for key, i := range items {
+		if i.expired() {
+			continue
+		}
+		e := SnapshotEntry{Key: key, EntryInfo: fc.info(key, i)}
+		if cloneValues && e.Model != nil {
+			e.Model = &Model{Name: e.Model.Name, Data: append([]byte(nil), e.Model.Data...), Value: e.Model.Value}
+		}
+		s.entries = append(s.entries, e)
+	}
+	sort.Slice(s.entries, func(a, b int) bool {
+		return s.entries[a].Key < s.entries[b].Key
+	})
+	return s
+}
+
+// Time returns when the snapshot was taken.
+func (s *Snapshot) Time() time.Time {
+	return s.time
+}
+
+// Len returns the number of entries.
+func (s *Snapshot) Len() int {
+	return len(s.entries)
+}
+
+// Range calls fn for every entry in key order until fn returns false.
+func (s *Snapshot) Range(fn func(e SnapshotEntry) bool) {
+	for _, e := range s.entries {
+		if !fn(e) {
+			return
+		}
+	}
+}
+
+// WriteTo writes the snapshot to w in the format of Dump, with the models,
+// so it can be restored with Hydrate. The models are encrypted with
+// WithEncrypter.
+func (s *Snapshot) WriteTo(w io.Writer) (int64, error) {
+	return s.write(w, true)
+}
+
+// write writes the snapshot as a dump document, with the models when
+// withValues is true
+func (s *Snapshot) write(w io.Writer, withValues bool) (int64, error) {
+	d := dump{
+		Version: dumpVersion,
+		Time:    s.time,
+		Entries: make([]DumpEntry, 0, len(s.entries)),
+		Stats:   &s.lifetime,
+		Quota:   s.quota,
+	}
+	for _, e := range s.entries {
+		de := DumpEntry{
+			Key:       e.Key,
+			Created:   e.Created,
+			FetchCost: Duration(e.FetchCost),
+		}
+		if !e.Expires.IsZero() {
+			expires := e.Expires
+			de.Expires = &expires
+		}
+		if withValues && s.enc != nil {
+			sealed, err := seal(e.Key, e.Model, s.enc)
+			if err != nil {
+				return 0, err
+			}
+			de.Sealed = sealed
+		} else if withValues {
+			de.Value = e.Model
+		}
+		d.Entries = append(d.Entries, de)
+	}
+	entries, err := json.Marshal(d.Entries)
+	if err != nil {
+		return 0, err
+	}
+	if d.Checksum, err = dumpChecksum(entries); err != nil {
+		return 0, err
+	}
+
+	b, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(append(b, '\n'))
+	return int64(n), err
+}
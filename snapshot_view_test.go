@@ -0,0 +1,71 @@
+package resource
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestFetchCache_Snapshot(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []Option
+		cloneValues bool
+	}{
+		{
+			name: "success shared values",
+		},
+		{
+			name:        "success cloned values",
+			cloneValues: true,
+		},
+		{
+			name: "success with snapshot reads",
+			opts: []Option{WithSnapshotReads(0)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					return &Model{Name: id, Data: []byte("lorem")}, nil
+				},
+			}
+			fc := NewCache(mockedFetcher, append(tt.opts, WithTTL(time.Minute))...)
+			for _, id := range []string{"b", "a"} {
+				if _, err := fc.Fetch(context.Background(), id); err != nil {
+					t.Fatalf("FetchCache.Fetch() error = %v", err)
+				}
+			}
+
+			s := fc.Snapshot(tt.cloneValues)
+			if _, err := fc.Fetch(context.Background(), "c"); err != nil {
+				t.Fatalf("FetchCache.Fetch() error = %v", err)
+			}
+			fc.Clear("a")
+
+			var keys []string
+			s.Range(func(e SnapshotEntry) bool {
+				keys = append(keys, e.Key)
+				cached, found := fc.Entry(e.Key)
+				if shared := found && cached.Model == e.Model; found && shared == tt.cloneValues {
+					t.Errorf("FetchCache.Snapshot() expect shared values = %v", !tt.cloneValues)
+				}
+				return true
+			})
+			if s.Len() != 2 || keys[0] != "a" || keys[1] != "b" {
+				t.Errorf("FetchCache.Snapshot() expect entries [a b], have %v", keys)
+			}
+
+			var buf bytes.Buffer
+			if _, err := s.WriteTo(&buf); err != nil {
+				t.Fatalf("Snapshot.WriteTo() error = %v", err)
+			}
+			restored := NewCache(mockedFetcher)
+			if n, err := restored.Hydrate(&buf); err != nil || n != 2 {
+				t.Errorf("FetchCache.Hydrate() expect 2 entries, have %v, %v", n, err)
+			}
+		})
+	}
+}
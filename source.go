@@ -0,0 +1,68 @@
+package resource
+
+import "context"
+
+// Source tells where the model returned by a fetch came from.
+type Source int
+
+// Source list
+const (
+	// SourceMemory is a cached item.
+	SourceMemory Source = iota + 1
+	// SourceStale is an expired cached item, see WithStaleWhileRevalidate
+	// and RateLimitStale.
+	SourceStale
+	// SourceL2 is the L2 store, see WithL2.
+	SourceL2
+	// SourcePeer is the peer owning the id, see WithPeers.
+	SourcePeer
+	// SourceOrigin is the Fetcher.
+	SourceOrigin
+	// SourceSnapshot, SourceSet and SourceWarm are only the sources of
+	// entries, see EntryInfo.Source: a restored dump, see Hydrate, a model
+	// set by Update, CompareAndSwap, WithEntry or CacheAside.Write, and
+	// Warm.
+	SourceSnapshot
+	SourceSet
+	SourceWarm
+	// SourceSeed is the Seed of WithSeed.
+	SourceSeed
+)
+
+var sourceNames = map[Source]string{
+	SourceMemory:   "memory",
+	SourceStale:    "stale",
+	SourceL2:       "l2",
+	SourcePeer:     "peer",
+	SourceOrigin:   "origin",
+	SourceSnapshot: "snapshot",
+	SourceSet:      "set",
+	SourceWarm:     "warm",
+	SourceSeed:     "seed",
+}
+
+// String returns the lower case name of the source.
+func (s Source) String() string {
+	if name, ok := sourceNames[s]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// FetchWithSource is FetchWithOptions also reporting where the model came
+// from, to tell fast path responses from slow path ones. The Source is 0
+// on error.
+func (fc *FetchCache) FetchWithSource(ctx context.Context, id string, opts ...FetchOption) (*Model, Source, error) {
+	return fc.fetch(ctx, id, opts)
+}
+
+// sourceKey carries the *Source of a miss, set by the Fetcher layers
+// answering it in place of the Fetcher
+type sourceKey struct{}
+
+// setSource records s as the source of the miss of ctx
+func setSource(ctx context.Context, s Source) {
+	if src, ok := ctx.Value(sourceKey{}).(*Source); ok {
+		*src = s
+	}
+}
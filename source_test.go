@@ -0,0 +1,136 @@
+package resource
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestFetchCache_FetchWithSource(t *testing.T) {
+	var (
+		fakeFetchID = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	)
+
+	tests := []struct {
+		name       string
+		prefetch   bool
+		l2         bool
+		opts       []FetchOption
+		wantSource Source
+		wantL2Hits uint64
+	}{
+		{
+			name:       "success origin",
+			wantSource: SourceOrigin,
+		},
+		{
+			name:       "success memory",
+			prefetch:   true,
+			wantSource: SourceMemory,
+		},
+		{
+			name:       "success l2",
+			l2:         true,
+			wantSource: SourceL2,
+			wantL2Hits: 1,
+		},
+		{
+			name:       "success origin on bypass",
+			prefetch:   true,
+			opts:       []FetchOption{BypassCache()},
+			wantSource: SourceOrigin,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					return &Model{Name: "lorem"}, nil
+				},
+			}
+			var opts []Option
+			if tt.l2 {
+				store := newMapL2Store()
+				_ = store.Set(context.Background(), fakeFetchID, []byte(`{"Name":"lorem"}`), time.Minute)
+				opts = append(opts, WithL2(store, nil))
+			}
+			fc := NewCache(mockedFetcher, opts...)
+			defer fc.Close(context.Background())
+			if tt.prefetch {
+				if _, err := fc.Fetch(context.Background(), fakeFetchID); err != nil {
+					t.Fatalf("FetchCache.Fetch() error = %v", err)
+				}
+			}
+
+			model, src, err := fc.FetchWithSource(context.Background(), fakeFetchID, tt.opts...)
+			if err != nil || model.Name != "lorem" {
+				t.Fatalf("FetchCache.FetchWithSource() = %v, %v, want %v", model, err, "lorem")
+			}
+			if src != tt.wantSource {
+				t.Errorf("FetchCache.FetchWithSource() expect source = %v, have %v", tt.wantSource, src)
+			}
+			if s := fc.Stats(); s.L2Hits != tt.wantL2Hits {
+				t.Errorf("FetchCache.Stats() expect l2 hits = %v, have %v", tt.wantL2Hits, s.L2Hits)
+			}
+		})
+	}
+}
+
+func TestFetchCache_Entry_Source(t *testing.T) {
+	var (
+		fakeFetchID = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	)
+	mockedFetcher := &FetcherMock{
+		FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+			return &Model{Name: id}, nil
+		},
+	}
+
+	tests := []struct {
+		name  string
+		store func(fc *FetchCache) error
+		want  Source
+	}{
+		{
+			name: "success fetched from origin",
+			store: func(fc *FetchCache) error {
+				_, err := fc.Fetch(context.Background(), fakeFetchID)
+				return err
+			},
+			want: SourceOrigin,
+		},
+		{
+			name: "success set by update",
+			store: func(fc *FetchCache) error {
+				_, err := fc.Update(context.Background(), fakeFetchID, func(current *Model) (*Model, error) {
+					return &Model{Name: "updated"}, nil
+				})
+				return err
+			},
+			want: SourceSet,
+		},
+		{
+			name: "success warmed",
+			store: func(fc *FetchCache) error {
+				ch := make(chan WarmEntry, 1)
+				ch <- WarmEntry{ID: fakeFetchID, Model: &Model{Name: "warm"}}
+				close(ch)
+				_, err := fc.Warm(context.Background(), ChanWarmer(ch), nil)
+				return err
+			},
+			want: SourceWarm,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fc := NewCache(mockedFetcher)
+			if err := tt.store(fc); err != nil {
+				t.Fatalf("FetchCache store error = %v", err)
+			}
+			info, found := fc.Entry(fakeFetchID)
+			if !found || info.Source != tt.want {
+				t.Errorf("FetchCache.Entry() expect source = %v, have %v", tt.want, info.Source)
+			}
+		})
+	}
+}
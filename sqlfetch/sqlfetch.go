@@ -0,0 +1,69 @@
+// Package sqlfetch implements resource.Fetcher over a database/sql query,
+// so database-backed resources are coalesced and cached like any other.
+//
+// The query is prepared once and run with the id as its only argument, the
+// row it returns is scanned into a model by a ScanFunc:
+//
+//	f, err := sqlfetch.New(ctx, db, "SELECT body FROM pages WHERE id = $1", sqlfetch.ScanData)
+//	cache := resource.NewCache(f)
+package sqlfetch
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	resource "github.com/hieunmce/cache"
+)
+
+// Scanner is the row of a query, *sql.Row.
+type Scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// ScanFunc scans the row of id into a model. The sql.ErrNoRows of a
+// missing row is reported as resource.ErrNotFound.
+type ScanFunc func(id string, row Scanner) (*resource.Model, error)
+
+// ScanData scans a single column into the Data of a model named id.
+func ScanData(id string, row Scanner) (*resource.Model, error) {
+	var data []byte
+	if err := row.Scan(&data); err != nil {
+		return nil, err
+	}
+	return &resource.Model{Name: id, Data: data}, nil
+}
+
+// Fetcher is a resource.Fetcher running a prepared statement per id.
+type Fetcher struct {
+	stmt *sql.Stmt
+	scan ScanFunc
+}
+
+// New prepares query on db and returns a Fetcher scanning its rows with
+// scan, ScanData if nil. The statement is released by Close.
+func New(ctx context.Context, db *sql.DB, query string, scan ScanFunc) (*Fetcher, error) {
+	stmt, err := db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if scan == nil {
+		scan = ScanData
+	}
+	return &Fetcher{stmt: stmt, scan: scan}, nil
+}
+
+// Fetch runs the statement for id and scans its row.
+func (f *Fetcher) Fetch(ctx context.Context, id string) (*resource.Model, error) {
+	model, err := f.scan(id, f.stmt.QueryRowContext(ctx, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("sqlfetch: %s: %w", id, resource.ErrNotFound)
+	}
+	return model, err
+}
+
+// Close releases the prepared statement.
+func (f *Fetcher) Close() error {
+	return f.stmt.Close()
+}
@@ -0,0 +1,133 @@
+package sqlfetch
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+
+	resource "github.com/hieunmce/cache"
+)
+
+// fakeDriver serves the rows of its table to any query, by the id argument
+type fakeDriver struct {
+	table map[string]string
+}
+
+func (d fakeDriver) Open(name string) (driver.Conn, error) { return fakeConn(d), nil }
+
+type fakeConn fakeDriver
+
+func (c fakeConn) Prepare(query string) (driver.Stmt, error) { return fakeStmt(c), nil }
+func (c fakeConn) Close() error                              { return nil }
+func (c fakeConn) Begin() (driver.Tx, error)                 { return nil, errors.New("not supported") }
+
+type fakeStmt fakeConn
+
+func (s fakeStmt) Close() error  { return nil }
+func (s fakeStmt) NumInput() int { return 1 }
+func (s fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return nil, errors.New("not supported")
+}
+func (s fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	rows := &fakeRows{}
+	if v, ok := s.table[args[0].(string)]; ok {
+		rows.values = [][]driver.Value{{[]byte(v)}}
+	}
+	return rows, nil
+}
+
+type fakeRows struct {
+	values [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return []string{"body"} }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+func init() {
+	sql.Register("sqlfetch-fake", fakeDriver{table: map[string]string{
+		"dca76878-a8f6-4ff5-b263-1e8c7e61bc20": "content",
+	}})
+}
+
+func TestFetcher_Fetch(t *testing.T) {
+	var (
+		fakeFetchID     = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+		notExistModelID = "5634aeed-2106-43de-ab7d-c0ad4b1e195e"
+		errScan         = errors.New("bad row")
+	)
+
+	tests := []struct {
+		name     string
+		id       string
+		scan     ScanFunc
+		wantData string
+		wantErr  error
+	}{
+		{
+			name:     "success scan data",
+			id:       fakeFetchID,
+			wantData: "content",
+		},
+		{
+			name: "success custom scan",
+			id:   fakeFetchID,
+			scan: func(id string, row Scanner) (*resource.Model, error) {
+				var body string
+				if err := row.Scan(&body); err != nil {
+					return nil, err
+				}
+				return &resource.Model{Name: id, Data: []byte("<" + body + ">")}, nil
+			},
+			wantData: "<content>",
+		},
+		{
+			name:    "failed not found",
+			id:      notExistModelID,
+			wantErr: resource.ErrNotFound,
+		},
+		{
+			name: "failed scan error",
+			id:   fakeFetchID,
+			scan: func(id string, row Scanner) (*resource.Model, error) {
+				return nil, errScan
+			},
+			wantErr: errScan,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, err := sql.Open("sqlfetch-fake", "")
+			if err != nil {
+				t.Fatalf("sql.Open() error = %v", err)
+			}
+			defer db.Close()
+			f, err := New(context.Background(), db, "SELECT body FROM pages WHERE id = ?", tt.scan)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			defer f.Close()
+
+			model, err := f.Fetch(context.Background(), tt.id)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Fetcher.Fetch() expect error = %v, have %v", tt.wantErr, err)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if model.Name != tt.id || string(model.Data) != tt.wantData {
+				t.Errorf("Fetcher.Fetch() expect model %v %q, have %v %q", tt.id, tt.wantData, model.Name, model.Data)
+			}
+		})
+	}
+}
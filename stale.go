@@ -0,0 +1,102 @@
+package resource
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// defaultStaleRetry is how long an expired item is served after its
+// refresh failed, see WithStaleRetry
+const defaultStaleRetry = time.Second
+
+// WithStaleWhileRevalidate keeps serving an expired item for up to window
+// past its expiration while one caller refreshes it, instead of making
+// every concurrent caller wait for the refresh. When the refresh fails the
+// callers are served the expired item a little longer, see WithStaleRetry,
+// before the next one refreshes it.
+func WithStaleWhileRevalidate(window time.Duration) Option {
+	return func(o *options) {
+		o.staleWindow = window
+	}
+}
+
+// WithStaleRetry sets how long the callers are served an expired item
+// after its refresh failed before the next caller refreshes it again,
+// within the window of WithStaleWhileRevalidate. It is the min of
+// WithFailureBackoff if set, 1s otherwise.
+func WithStaleRetry(d time.Duration) Option {
+	return func(o *options) {
+		o.staleRetry = d
+	}
+}
+
+// staleRetryDelay returns the delay of WithStaleRetry
+func (fc *FetchCache) staleRetryDelay() time.Duration {
+	switch {
+	case fc.opts.staleRetry > 0:
+		return fc.opts.staleRetry
+	case fc.opts.backoffMin > 0:
+		return fc.opts.backoffMin
+	}
+	return defaultStaleRetry
+}
+
+// staleRefreshFailed delays the next refresh of the expired item of key
+func (fc *FetchCache) staleRefreshFailed(key string, now time.Time) {
+	atomic.StoreInt64(&fc.stats.get(key).staleRetryAt, now.Add(fc.staleRetryDelay()).UnixNano())
+}
+
+// staleRetrying reports whether the refresh of the expired item of key
+// failed less than the delay of WithStaleRetry ago
+func (fc *FetchCache) staleRetrying(key string, now time.Time) bool {
+	v, ok := fc.stats.m.Load(key)
+	return ok && now.UnixNano() < atomic.LoadInt64(&v.(*keyStats).staleRetryAt)
+}
+
+// MarkAllStale makes every cached item stale without removing it, as if it
+// had expired within the window of WithStaleWhileRevalidate: the next
+// fetch of an item refreshes it, skipping the L2 store, while the
+// concurrent fetches are served the item. Unlike Flush, a refresh of the
+// whole cache after a change of the backend doesn't make every caller
+// wait for the Fetcher at once.
+func (fc *FetchCache) MarkAllStale() {
+	atomic.StoreInt64(&fc.staleBefore, time.Now().UnixNano())
+}
+
+// markedStale reports whether i was cached before the last MarkAllStale
+func (fc *FetchCache) markedStale(i item) bool {
+	return i.Created < atomic.LoadInt64(&fc.staleBefore)
+}
+
+// current reports whether the item i of key can be served as a hit,
+// neither expired, idle nor marked stale
+func (fc *FetchCache) current(key string, i item) bool {
+	return !i.expired() && !fc.idled(key, i) && !fc.markedStale(i)
+}
+
+// staleItem returns the item of id if it has expired less than the stale
+// window ago or was marked stale
+func (fc *FetchCache) staleItem(id string) (item, bool) {
+	window := fc.staleWindow()
+	if window <= 0 && atomic.LoadInt64(&fc.staleBefore) == 0 {
+		return item{}, false
+	}
+	i, found := fc.peekitem(id)
+	if !found {
+		return item{}, false
+	}
+	now := time.Now().UnixNano()
+	if !i.expired() {
+		if fc.markedStale(i) && !fc.outlived(i, now) {
+			return i, true
+		}
+		return item{}, false
+	}
+	if window <= 0 {
+		return item{}, false
+	}
+	if now > i.Expiration+int64(window) || fc.outlived(i, now) {
+		return item{}, false
+	}
+	return i, true
+}
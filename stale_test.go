@@ -0,0 +1,215 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Test that an item expiring under concurrent load is re-fetched by a single
+// Fetcher call, with callers either waiting for it or served stale
+func TestFetchCache_Fetch_ExpiryStampede(t *testing.T) {
+	var (
+		fakeFetchID = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	)
+
+	tests := []struct {
+		name      string
+		opts      []Option
+		wantStale bool
+	}{
+		{
+			name: "success wait for refresh",
+			opts: []Option{WithTTL(5 * time.Millisecond)},
+		},
+		{
+			name:      "success serve stale during refresh",
+			opts:      []Option{WithTTL(5 * time.Millisecond), WithStaleWhileRevalidate(time.Minute)},
+			wantStale: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var callCount int32
+			entered := make(chan struct{})
+			release := make(chan struct{})
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					if atomic.AddInt32(&callCount, 1) == 1 {
+						return &Model{Name: "old"}, nil
+					}
+					close(entered)
+					<-release
+					return &Model{Name: "new"}, nil
+				},
+			}
+			fc := NewCache(mockedFetcher, tt.opts...)
+			if _, err := fc.Fetch(context.Background(), fakeFetchID); err != nil {
+				t.Fatalf("FetchCache.Fetch() error = %v", err)
+			}
+			time.Sleep(10 * time.Millisecond)
+
+			// the first caller after expiry refreshes the item and blocks
+			refreshed := make(chan *Model, 1)
+			go func() {
+				model, _ := fc.Fetch(context.Background(), fakeFetchID)
+				refreshed <- model
+			}()
+			<-entered
+
+			const callers = 50
+			results := make(chan *Model, callers)
+			var wg sync.WaitGroup
+			for i := 0; i < callers; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					model, err := fc.Fetch(context.Background(), fakeFetchID)
+					if err != nil {
+						t.Errorf("FetchCache.Fetch() error = %v", err)
+						return
+					}
+					results <- model
+				}()
+			}
+			if tt.wantStale {
+				wg.Wait()
+			}
+			close(release)
+			wg.Wait()
+			close(results)
+
+			want := "new"
+			if tt.wantStale {
+				want = "old"
+			}
+			for model := range results {
+				if model.Name != want {
+					t.Errorf("FetchCache.Fetch() expect model = %v, have %v", want, model.Name)
+				}
+			}
+			if model := <-refreshed; model == nil || model.Name != "new" {
+				t.Errorf("FetchCache.Fetch() expect refreshed model = new, have %v", model)
+			}
+			if callCount != 2 {
+				t.Errorf("FetchCache.Fetch() expect 2 fetcher calls, have %v", callCount)
+			}
+			if stats := fc.Stats(); (stats.StaleHits == callers) != tt.wantStale {
+				t.Errorf("FetchCache.Stats() expect stale hits = %v, have %v", tt.wantStale, stats.StaleHits)
+			}
+		})
+	}
+}
+
+func TestFetchCache_Fetch_StaleRetry(t *testing.T) {
+	var (
+		fakeFetchID = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+		errBackend  = errors.New("backend down")
+	)
+
+	tests := []struct {
+		name      string
+		retry     time.Duration
+		wait      time.Duration
+		wantCalls int32
+		wantName  string
+	}{
+		{
+			name:      "success stale served after a failed refresh",
+			retry:     time.Minute,
+			wantCalls: 2,
+			wantName:  "old",
+		},
+		{
+			name:      "success next caller refreshes after the retry delay",
+			retry:     5 * time.Millisecond,
+			wait:      10 * time.Millisecond,
+			wantCalls: 3,
+			wantName:  "new",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var callCount int32
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					switch atomic.AddInt32(&callCount, 1) {
+					case 1:
+						return &Model{Name: "old"}, nil
+					case 2:
+						return nil, errBackend
+					}
+					return &Model{Name: "new"}, nil
+				},
+			}
+			fc := NewCache(mockedFetcher, WithTTL(5*time.Millisecond), WithStaleWhileRevalidate(time.Minute), WithStaleRetry(tt.retry))
+			if _, err := fc.Fetch(context.Background(), fakeFetchID); err != nil {
+				t.Fatalf("FetchCache.Fetch() error = %v", err)
+			}
+			time.Sleep(10 * time.Millisecond)
+
+			// the refresher fails
+			if _, err := fc.Fetch(context.Background(), fakeFetchID); !errors.Is(err, errBackend) {
+				t.Fatalf("FetchCache.Fetch() expect error = %v, have %v", errBackend, err)
+			}
+			time.Sleep(tt.wait)
+			model, err := fc.Fetch(context.Background(), fakeFetchID)
+			if err != nil {
+				t.Fatalf("FetchCache.Fetch() error = %v", err)
+			}
+			if model.Name != tt.wantName {
+				t.Errorf("FetchCache.Fetch() expect model = %v, have %v", tt.wantName, model.Name)
+			}
+			if got := atomic.LoadInt32(&callCount); got != tt.wantCalls {
+				t.Errorf("FetchCache.Fetch() expect fetcher calls = %v, have %v", tt.wantCalls, got)
+			}
+		})
+	}
+}
+
+func TestFetchCache_MarkAllStale(t *testing.T) {
+	var fakeFetchID = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	var calls int64
+	release := make(chan struct{})
+	mockedFetcher := &FetcherMock{
+		FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+			if atomic.AddInt64(&calls, 1) == 1 {
+				return &Model{Name: "old"}, nil
+			}
+			<-release
+			return &Model{Name: "new"}, nil
+		},
+	}
+	fc := NewCache(mockedFetcher, WithTTL(time.Hour))
+	if _, err := fc.Fetch(context.Background(), fakeFetchID); err != nil {
+		t.Fatalf("FetchCache.Fetch() expect no error, have %v", err)
+	}
+	fc.MarkAllStale()
+
+	refreshed := make(chan *Model, 1)
+	go func() {
+		model, _ := fc.Fetch(context.Background(), fakeFetchID)
+		refreshed <- model
+	}()
+	for atomic.LoadInt64(&calls) < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	model, src, err := fc.FetchWithSource(context.Background(), fakeFetchID)
+	if err != nil || model.Name != "old" || src != SourceStale {
+		t.Errorf("FetchCache.FetchWithSource() expect old stale model while refreshed, have %v %v %v", model, src, err)
+	}
+	close(release)
+	if model := <-refreshed; model.Name != "new" {
+		t.Errorf("FetchCache.Fetch() expect the refresh to return new, have %v", model)
+	}
+	model, src, _ = fc.FetchWithSource(context.Background(), fakeFetchID)
+	if model.Name != "new" || src != SourceMemory {
+		t.Errorf("FetchCache.FetchWithSource() expect new cached model, have %v %v", model, src)
+	}
+	if calls != 2 {
+		t.Errorf("MarkAllStale() expect 2 Fetcher calls, have %v", calls)
+	}
+}
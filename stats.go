@@ -0,0 +1,300 @@
+package resource
+
+import (
+	"math/bits"
+	"sync/atomic"
+	"time"
+)
+
+// Stats is a point-in-time summary of the cache, see FetchCache.Stats and
+// FetchCache.StatsDelta. It marshals to JSON with snake case keys and the
+// durations in nanoseconds.
+type Stats struct {
+	// Items is the number of cached items, including expired ones not yet removed.
+	Items int `json:"items"`
+	// Hits is the number of fetches served from the cache.
+	Hits uint64 `json:"hits"`
+	// Misses is the number of fetches which went to the Fetcher.
+	Misses uint64 `json:"misses"`
+	// Coalesced is the number of fetches served by a concurrent fetch of
+	// the same key instead of calling the Fetcher and FetcherCalls the
+	// number of Fetcher calls made by fetches and refreshes.
+	Coalesced    uint64 `json:"coalesced"`
+	FetcherCalls uint64 `json:"fetcher_calls"`
+	// L2Hits, PeerHits and SeedHits are the number of misses answered by
+	// the L2 store, by peers and by the Seed of WithSeed instead of the
+	// Fetcher, see FetchWithSource.
+	L2Hits   uint64 `json:"l2_hits"`
+	PeerHits uint64 `json:"peer_hits"`
+	SeedHits uint64 `json:"seed_hits"`
+	// StaleHits is the number of hits served an expired item while it was
+	// being refreshed, see WithStaleWhileRevalidate.
+	StaleHits uint64 `json:"stale_hits"`
+	// HitLatency is the latency of fetches served straight from the cache.
+	HitLatency LatencyStats `json:"hit_latency"`
+	// CoalescedLatency is the latency of fetches served from the cache after
+	// waiting for a concurrent fetch of the same key.
+	CoalescedLatency LatencyStats `json:"coalesced_latency"`
+	// FetchLatency is the latency of fetches which went to the Fetcher.
+	FetchLatency LatencyStats `json:"fetch_latency"`
+	// ServedAge is how long ago the models served from the cache were
+	// fetched, stale ones included, and StaleServedAge the same for the
+	// stale ones only, served by WithStaleWhileRevalidate or on errors.
+	// Models fresh from the Fetcher aren't counted.
+	ServedAge      LatencyStats `json:"served_age"`
+	StaleServedAge LatencyStats `json:"stale_served_age"`
+	// DriftChecks is the number of cached items compared with the Fetcher
+	// and Drifts the number which differed, see WithDriftCheck.
+	DriftChecks uint64 `json:"drift_checks"`
+	Drifts      uint64 `json:"drifts"`
+	// QueueDepth is the number of misses waiting for a Fetcher call slot
+	// and QueueRejected the number which failed with ErrQueueFull or
+	// ErrQueueTimeout, see WithMissQueue.
+	QueueDepth    int    `json:"queue_depth"`
+	QueueRejected uint64 `json:"queue_rejected"`
+	// Expired is the number of expired items removed by WithExpirySweep
+	// or WithExpiryWheel.
+	Expired uint64 `json:"expired"`
+	// Oversized is the number of fetched models not cached because of
+	// WithMaxValueSize.
+	Oversized uint64 `json:"oversized"`
+	// CardinalityRejected is the number of fetched models of new keys not
+	// cached because of WithCardinalityGuard.
+	CardinalityRejected uint64 `json:"cardinality_rejected"`
+	// RefreshQueueDepth is the number of queued background refreshes and
+	// RefreshDropped the number dropped from the full queue, see
+	// WithRefreshPool.
+	RefreshQueueDepth int    `json:"refresh_queue_depth"`
+	RefreshDropped    uint64 `json:"refresh_dropped"`
+	// Corrupted is the number of L2 values and stream files which failed
+	// their checksum, see WithChecksums.
+	Corrupted uint64 `json:"corrupted"`
+	// KeyLockWait is the time taken to acquire the per-key locks and
+	// ItemsLockWait the read and write locks of the items, one acquisition
+	// in lockSampleRate is timed. The items are guarded by a single lock,
+	// not one per shard, so ItemsLockWait covers every shard of a
+	// ShardedStore. KeyLockContended is the number of key lock
+	// acquisitions which waited for another holder and JoinsAbandoned the
+	// number of fetches whose context was done while waiting for the
+	// Fetcher call of another fetch.
+	KeyLockWait      LatencyStats `json:"key_lock_wait"`
+	ItemsLockWait    LatencyStats `json:"items_lock_wait"`
+	KeyLockContended uint64       `json:"key_lock_contended"`
+	JoinsAbandoned   uint64       `json:"joins_abandoned"`
+	// Discarded is the number of fetched models not cached because the
+	// cache was flushed or closed during the Fetcher call.
+	Discarded uint64 `json:"discarded"`
+	// InFlightBytes is the bytes reserved by the Fetcher calls, see
+	// WithInFlightMemory.
+	InFlightBytes int64 `json:"in_flight_bytes"`
+	// Quota is the usage of the quota of WithOriginQuota, zero without.
+	Quota QuotaUsage `json:"quota"`
+	// Classes is the part of the hits, misses and latencies of every class
+	// of ids, see WithKeyClassifier.
+	Classes map[string]ClassStats `json:"classes,omitempty"`
+	// InFlight is the Fetcher calls in progress, see FetchCache.InFlight.
+	InFlight []InFlightFetch `json:"in_flight"`
+	// Lifetime is the cumulative counters restored by Hydrate plus those
+	// counted since, see LifetimeStats.
+	Lifetime LifetimeStats `json:"lifetime"`
+}
+
+// LatencyStats summarizes a latency distribution. Percentiles are
+// estimated from power of two buckets.
+type LatencyStats struct {
+	Count uint64        `json:"count"`
+	P50   time.Duration `json:"p50"`
+	P95   time.Duration `json:"p95"`
+	P99   time.Duration `json:"p99"`
+}
+
+// Stats returns the statistics of the cache.
+func (fc *FetchCache) Stats() Stats {
+	fc.rlockItems()
+	n := fc.items.Len()
+	fc.itemsLock.RUnlock()
+
+	return Stats{
+		Items:               n,
+		Hits:                atomic.LoadUint64(&fc.metrics.hits),
+		Misses:              atomic.LoadUint64(&fc.metrics.misses),
+		Coalesced:           atomic.LoadUint64(&fc.metrics.coalesced),
+		FetcherCalls:        atomic.LoadUint64(&fc.metrics.fetcherCalls),
+		L2Hits:              atomic.LoadUint64(&fc.metrics.l2Hits),
+		PeerHits:            atomic.LoadUint64(&fc.metrics.peerHits),
+		SeedHits:            atomic.LoadUint64(&fc.metrics.seedHits),
+		StaleHits:           atomic.LoadUint64(&fc.metrics.staleHits),
+		HitLatency:          fc.metrics.hitLatency.stats(),
+		CoalescedLatency:    fc.metrics.coalescedLatency.stats(),
+		FetchLatency:        fc.metrics.fetchLatency.stats(),
+		ServedAge:           fc.metrics.servedAge.stats(),
+		StaleServedAge:      fc.metrics.staleServedAge.stats(),
+		DriftChecks:         atomic.LoadUint64(&fc.metrics.driftChecks),
+		Drifts:              atomic.LoadUint64(&fc.metrics.drifts),
+		QueueDepth:          fc.fetchLimit.depth(),
+		QueueRejected:       atomic.LoadUint64(&fc.fetchLimit.rejected),
+		Expired:             atomic.LoadUint64(&fc.metrics.expired),
+		Oversized:           atomic.LoadUint64(&fc.metrics.oversize),
+		CardinalityRejected: atomic.LoadUint64(&fc.metrics.cardinalityRejected),
+		Corrupted:           atomic.LoadUint64(&fc.metrics.corrupt),
+		RefreshQueueDepth:   fc.refreshes.depth(),
+		RefreshDropped:      fc.refreshes.droppedCount(),
+		KeyLockWait:         fc.metrics.keyLockWait.stats(),
+		ItemsLockWait:       fc.metrics.itemsLockWait.stats(),
+		KeyLockContended:    atomic.LoadUint64(&fc.metrics.keyLockContended),
+		JoinsAbandoned:      atomic.LoadUint64(&fc.metrics.joinsAbandoned),
+		Discarded:           atomic.LoadUint64(&fc.metrics.discarded),
+		InFlightBytes:       fc.memory.reserved(),
+		Quota:               fc.quota.usage(),
+		Classes:             fc.classes.stats(),
+		InFlight:            fc.InFlight(),
+		Lifetime:            fc.metrics.lifetimeStats(),
+	}
+}
+
+// metrics are the cache wide counters behind Stats
+type metrics struct {
+	hits                uint64 // accessed atomically
+	misses              uint64 // accessed atomically
+	coalesced           uint64 // accessed atomically
+	fetcherCalls        uint64 // accessed atomically
+	l2Hits              uint64 // accessed atomically
+	peerHits            uint64 // accessed atomically
+	seedHits            uint64 // accessed atomically
+	staleHits           uint64 // accessed atomically
+	driftChecks         uint64 // accessed atomically
+	drifts              uint64 // accessed atomically
+	expired             uint64 // accessed atomically
+	oversize            uint64 // accessed atomically
+	cardinalityRejected uint64 // accessed atomically
+	corrupt             uint64 // accessed atomically
+	hitLatency          histogram
+	coalescedLatency    histogram
+	fetchLatency        histogram
+	servedAge           histogram
+	staleServedAge      histogram
+	keyLockContended    uint64 // accessed atomically
+	joinsAbandoned      uint64 // accessed atomically
+	discarded           uint64 // accessed atomically
+	lockSamples         uint64 // accessed atomically
+	itemsLockSamples    uint64 // accessed atomically
+	keyLockWait         histogram
+	itemsLockWait       histogram
+	lifetime            lifetimeCounters
+	delta               statsDelta
+}
+
+func (m *metrics) hit(class *classCounters, latency time.Duration, coalesced bool, age time.Duration) {
+	class.hit(latency, false)
+	atomic.AddUint64(&m.hits, 1)
+	m.servedAge.observe(age)
+	if coalesced {
+		atomic.AddUint64(&m.coalesced, 1)
+		m.coalescedLatency.observe(latency)
+		return
+	}
+	m.hitLatency.observe(latency)
+}
+
+func (m *metrics) staleHit(class *classCounters, latency, age time.Duration) {
+	class.hit(latency, true)
+	atomic.AddUint64(&m.hits, 1)
+	atomic.AddUint64(&m.staleHits, 1)
+	m.servedAge.observe(age)
+	m.staleServedAge.observe(age)
+	m.hitLatency.observe(latency)
+}
+
+func (m *metrics) driftCheck(drifted bool) {
+	atomic.AddUint64(&m.driftChecks, 1)
+	if drifted {
+		atomic.AddUint64(&m.drifts, 1)
+	}
+}
+
+func (m *metrics) swept(n int) {
+	if n > 0 {
+		atomic.AddUint64(&m.expired, uint64(n))
+	}
+}
+
+func (m *metrics) oversized() {
+	atomic.AddUint64(&m.oversize, 1)
+}
+
+func (m *metrics) corrupted() {
+	atomic.AddUint64(&m.corrupt, 1)
+}
+
+func (m *metrics) miss(class *classCounters, latency time.Duration, src Source) {
+	class.miss(latency)
+	atomic.AddUint64(&m.misses, 1)
+	switch src {
+	case SourceL2:
+		atomic.AddUint64(&m.l2Hits, 1)
+	case SourcePeer:
+		atomic.AddUint64(&m.peerHits, 1)
+	case SourceSeed:
+		atomic.AddUint64(&m.seedHits, 1)
+	}
+	m.fetchLatency.observe(latency)
+}
+
+// histogram counts durations in power of two nanosecond buckets,
+// bucket i holds durations in [2^(i-1), 2^i)
+type histogram struct {
+	buckets [65]uint64 // accessed atomically
+}
+
+func (h *histogram) observe(d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+	atomic.AddUint64(&h.buckets[bits.Len64(uint64(d))], 1)
+}
+
+func (h *histogram) stats() LatencyStats {
+	var counts [65]uint64
+	for i := range h.buckets {
+		counts[i] = atomic.LoadUint64(&h.buckets[i])
+	}
+	return latencyStats(&counts)
+}
+
+// latencyStats summarizes the bucket counts of a histogram
+func latencyStats(counts *[65]uint64) LatencyStats {
+	var total uint64
+	for _, c := range counts {
+		total += c
+	}
+	return LatencyStats{
+		Count: total,
+		P50:   quantile(counts, total, 0.50),
+		P95:   quantile(counts, total, 0.95),
+		P99:   quantile(counts, total, 0.99),
+	}
+}
+
+// quantile estimates the q quantile by interpolating inside its bucket
+func quantile(counts *[65]uint64, total uint64, q float64) time.Duration {
+	if total == 0 {
+		return 0
+	}
+
+	rank := q * float64(total)
+	var seen float64
+	for i, c := range counts {
+		if c == 0 {
+			continue
+		}
+		if seen+float64(c) >= rank {
+			if i == 0 {
+				return 0
+			}
+			lower := float64(uint64(1) << uint(i-1))
+			return time.Duration(lower + lower*(rank-seen)/float64(c))
+		}
+		seen += float64(c)
+	}
+	return time.Duration(1<<63 - 1)
+}
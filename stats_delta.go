@@ -0,0 +1,91 @@
+package resource
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// statsDelta holds the counters and histograms returned by the last
+// StatsDelta
+type statsDelta struct {
+	mu         sync.Mutex
+	last       Stats
+	histograms map[*histogram]*[65]uint64
+}
+
+// StatsDelta returns the statistics of the cache with the counters and
+// latencies of the interval since the previous StatsDelta call, since the
+// cache was created for the first one, and starts a new interval. Gauges
+// such as Items, QueueDepth or InFlight and Lifetime are current values.
+// It lets a system polling the stats collect them per interval without
+// double counting, there should be a single such system per cache.
+func (fc *FetchCache) StatsDelta() Stats {
+	d := &fc.metrics.delta
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	s := fc.Stats()
+	last := d.last
+	d.last = s
+	s.Hits -= last.Hits
+	s.Misses -= last.Misses
+	s.Coalesced -= last.Coalesced
+	s.FetcherCalls -= last.FetcherCalls
+	s.L2Hits -= last.L2Hits
+	s.PeerHits -= last.PeerHits
+	s.SeedHits -= last.SeedHits
+	s.StaleHits -= last.StaleHits
+	s.DriftChecks -= last.DriftChecks
+	s.Drifts -= last.Drifts
+	s.QueueRejected -= last.QueueRejected
+	s.Expired -= last.Expired
+	s.Oversized -= last.Oversized
+	s.CardinalityRejected -= last.CardinalityRejected
+	s.RefreshDropped -= last.RefreshDropped
+	s.Corrupted -= last.Corrupted
+	s.KeyLockContended -= last.KeyLockContended
+	s.JoinsAbandoned -= last.JoinsAbandoned
+	s.Discarded -= last.Discarded
+
+	m := fc.metrics
+	s.HitLatency = d.histogram(&m.hitLatency)
+	s.CoalescedLatency = d.histogram(&m.coalescedLatency)
+	s.FetchLatency = d.histogram(&m.fetchLatency)
+	s.ServedAge = d.histogram(&m.servedAge)
+	s.StaleServedAge = d.histogram(&m.staleServedAge)
+	s.KeyLockWait = d.histogram(&m.keyLockWait)
+	s.ItemsLockWait = d.histogram(&m.itemsLockWait)
+	classes := s.Classes
+	if classes != nil {
+		s.Classes = make(map[string]ClassStats, len(classes))
+	}
+	fc.classes.each(func(class string, c *classCounters) {
+		cs, prev := classes[class], last.Classes[class]
+		cs.Hits -= prev.Hits
+		cs.Misses -= prev.Misses
+		cs.StaleHits -= prev.StaleHits
+		cs.HitLatency = d.histogram(&c.hitLatency)
+		cs.FetchLatency = d.histogram(&c.fetchLatency)
+		s.Classes[class] = cs
+	})
+	return s
+}
+
+// histogram returns the stats of the observations of h since the last
+// call and keeps its current counts, d.mu is held
+func (d *statsDelta) histogram(h *histogram) LatencyStats {
+	if d.histograms == nil {
+		d.histograms = make(map[*histogram]*[65]uint64)
+	}
+	last, ok := d.histograms[h]
+	if !ok {
+		last = new([65]uint64)
+		d.histograms[h] = last
+	}
+	var counts [65]uint64
+	for i := range h.buckets {
+		c := atomic.LoadUint64(&h.buckets[i])
+		counts[i], last[i] = c-last[i], c
+	}
+	return latencyStats(&counts)
+}
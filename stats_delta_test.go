@@ -0,0 +1,78 @@
+package resource
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestFetchCache_StatsDelta(t *testing.T) {
+	var (
+		fakeFetchID     = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+		notExistModelID = "5634aeed-2106-43de-ab7d-c0ad4b1e195e"
+	)
+	mockedFetcher := &FetcherMock{
+		FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+			return &Model{Name: id}, nil
+		},
+	}
+	fc := NewCache(mockedFetcher)
+
+	tests := []struct {
+		name      string
+		ids       []string
+		wantHits  uint64
+		wantMiss  uint64
+		wantItems int
+	}{
+		{
+			name:      "success first interval",
+			ids:       []string{fakeFetchID, fakeFetchID, fakeFetchID},
+			wantHits:  2,
+			wantMiss:  1,
+			wantItems: 1,
+		},
+		{
+			name:      "success next interval",
+			ids:       []string{fakeFetchID, notExistModelID},
+			wantHits:  1,
+			wantMiss:  1,
+			wantItems: 2,
+		},
+		{
+			name:      "success empty interval",
+			wantItems: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, id := range tt.ids {
+				_, _ = fc.Fetch(context.Background(), id)
+			}
+			s := fc.StatsDelta()
+			if s.Hits != tt.wantHits || s.Misses != tt.wantMiss || s.Items != tt.wantItems {
+				t.Errorf("FetchCache.StatsDelta() expect hits, misses, items = %v, %v, %v, have %v, %v, %v", tt.wantHits, tt.wantMiss, tt.wantItems, s.Hits, s.Misses, s.Items)
+			}
+			if s.HitLatency.Count != tt.wantHits || s.FetchLatency.Count != tt.wantMiss {
+				t.Errorf("FetchCache.StatsDelta() expect latency counts = %v, %v, have %v, %v", tt.wantHits, tt.wantMiss, s.HitLatency.Count, s.FetchLatency.Count)
+			}
+		})
+	}
+
+	t.Run("success json keys", func(t *testing.T) {
+		b, err := json.Marshal(fc.Stats())
+		if err != nil {
+			t.Fatalf("json.Marshal() expect no error, have %v", err)
+		}
+		var have map[string]interface{}
+		_ = json.Unmarshal(b, &have)
+		for _, key := range []string{"items", "hits", "fetch_latency", "lifetime"} {
+			if _, ok := have[key]; !ok {
+				t.Errorf("json.Marshal(Stats) expect key %v, have %s", key, b)
+			}
+		}
+		if have["hits"] != float64(3) {
+			t.Errorf("json.Marshal(Stats) expect hits = 3 whatever StatsDelta, have %v", have["hits"])
+		}
+	})
+}
@@ -0,0 +1,176 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFetchCache_Stats(t *testing.T) {
+	var (
+		fakeFetchID = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	)
+
+	sleepDuration := 10 * time.Millisecond
+	mockedFetcher := &FetcherMock{
+		FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+			time.Sleep(sleepDuration)
+			return &Model{Name: "lorem"}, nil
+		},
+	}
+	fc := NewCache(mockedFetcher)
+
+	callCount := 10
+	var wg sync.WaitGroup
+	wg.Add(callCount)
+	for i := 0; i < callCount; i++ {
+		go func() {
+			_, _ = fc.Fetch(context.Background(), fakeFetchID)
+			wg.Done()
+		}()
+	}
+	wg.Wait()
+	_, _ = fc.Fetch(context.Background(), fakeFetchID)
+
+	got := fc.Stats()
+	if got.Items != 1 || got.Hits != uint64(callCount) || got.Misses != 1 {
+		t.Errorf("FetchCache.Stats() expect 1 item, %v hits and 1 miss, have %+v", callCount, got)
+	}
+	if got.FetchLatency.Count != 1 || got.FetchLatency.P50 < sleepDuration/2 {
+		t.Errorf("FetchCache.Stats() expect fetch latency around %v, have %+v", sleepDuration, got.FetchLatency)
+	}
+	if got.HitLatency.Count+got.CoalescedLatency.Count != uint64(callCount) || got.CoalescedLatency.Count == 0 {
+		t.Errorf("FetchCache.Stats() expect coalesced hits, have hit %+v coalesced %+v", got.HitLatency, got.CoalescedLatency)
+	}
+	if got.HitLatency.P99 > sleepDuration {
+		t.Errorf("FetchCache.Stats() expect hit latency below %v, have %+v", sleepDuration, got.HitLatency)
+	}
+}
+
+func TestFetchCache_Stats_ServedAge(t *testing.T) {
+	fakeFetchID := "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	errFetch := errors.New("fetch failed")
+	sleepDuration := 20 * time.Millisecond
+
+	tests := []struct {
+		name           string
+		ttl            time.Duration
+		fetchErr       error
+		wantServed     uint64
+		wantStaleServe uint64
+	}{
+		{
+			name:       "success fresh hit",
+			ttl:        time.Hour,
+			wantServed: 1,
+		},
+		{
+			name:           "success stale on error",
+			ttl:            sleepDuration / 2,
+			fetchErr:       errFetch,
+			wantServed:     1,
+			wantStaleServe: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fetchErr error
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					if fetchErr != nil {
+						return nil, fetchErr
+					}
+					return &Model{Name: "lorem"}, nil
+				},
+			}
+			fc := NewCache(mockedFetcher, WithTTL(tt.ttl), WithErrorPolicy(ErrorPolicyFunc(func(id string, err error) ErrorAction {
+				return ErrorServeStale
+			}), 0, 0))
+			defer fc.Close(context.Background())
+
+			_, _ = fc.Fetch(context.Background(), fakeFetchID)
+			time.Sleep(sleepDuration)
+			fetchErr = tt.fetchErr
+			if _, err := fc.Fetch(context.Background(), fakeFetchID); err != nil {
+				t.Fatalf("FetchCache.Fetch() expect error = nil, have %v", err)
+			}
+
+			got := fc.Stats()
+			if got.ServedAge.Count != tt.wantServed || got.StaleServedAge.Count != tt.wantStaleServe {
+				t.Errorf("FetchCache.Stats() expect served %v stale %v, have %+v %+v", tt.wantServed, tt.wantStaleServe, got.ServedAge, got.StaleServedAge)
+			}
+			if got.ServedAge.P50 < sleepDuration/2 {
+				t.Errorf("FetchCache.Stats() expect served age around %v, have %+v", sleepDuration, got.ServedAge)
+			}
+		})
+	}
+}
+
+func TestFetchCache_Stats_LockWait(t *testing.T) {
+	fakeFetchID := "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	mockedFetcher := &FetcherMock{
+		FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+			time.Sleep(time.Millisecond)
+			return &Model{Name: id}, nil
+		},
+	}
+	fc := NewCache(mockedFetcher)
+	defer fc.Close(context.Background())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 64; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = fc.Fetch(context.Background(), fakeFetchID)
+		}()
+	}
+	wg.Wait()
+
+	got := fc.Stats()
+	if got.KeyLockContended == 0 || got.KeyLockWait.Count == 0 {
+		t.Errorf("FetchCache.Stats() expect contended key locks, have %v contended and %+v", got.KeyLockContended, got.KeyLockWait)
+	}
+
+	before := got.ItemsLockWait.Count
+	for i := 0; i < 2*lockSampleRate; i++ {
+		_, _ = fc.Entry(fakeFetchID)
+	}
+	if got := fc.Stats().ItemsLockWait.Count; got <= before {
+		t.Errorf("FetchCache.Stats() expect timed items read locks, have %v after %v", got, before)
+	}
+}
+
+func Test_histogram_stats(t *testing.T) {
+	tests := []struct {
+		name      string
+		durations []time.Duration
+		want      LatencyStats
+	}{
+		{
+			name: "success empty histogram",
+		},
+		{
+			name:      "success estimate percentiles within a factor of two",
+			durations: []time.Duration{time.Millisecond, time.Millisecond, time.Millisecond, time.Second},
+			want:      LatencyStats{Count: 4, P50: time.Millisecond, P95: time.Second, P99: time.Second},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var h histogram
+			for _, d := range tt.durations {
+				h.observe(d)
+			}
+			got := h.stats()
+			within := func(have, want time.Duration) bool {
+				return have >= want/2 && have <= want*2
+			}
+			if got.Count != tt.want.Count || !within(got.P50, tt.want.P50) || !within(got.P95, tt.want.P95) || !within(got.P99, tt.want.P99) {
+				t.Errorf("histogram.stats() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
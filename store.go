@@ -0,0 +1,91 @@
+package resource
+
+// Store is an interface for the storage engine holding the cached items by
+// key, e.g. a sharded map or an off-heap store, see WithStore.
+//
+// The cache serializes the calls: Get, Len and Range may be made
+// concurrently with each other but never with Set or Delete, except the
+// Get of a LockFreeStore.
+type Store interface {
+	// Get returns the item of key and false if there is none.
+	Get(key string) (Item, bool)
+	// Set stores i for key, replacing the previous item of key.
+	Set(key string, i Item)
+	// Delete removes key, deleting a missing key is not an error.
+	Delete(key string)
+	// Len returns the number of items.
+	Len() int
+	// Range calls fn for every item until fn returns false, fn doesn't
+	// modify the store. The expiry sweep samples the items at the start
+	// of Range, which should start at a random item like a Go map does.
+	Range(fn func(key string, i Item) bool)
+}
+
+// WithStore holds the cached items in s instead of a Go map, s should be
+// empty.
+func WithStore(s Store) Option {
+	return func(o *options) {
+		o.store = s
+	}
+}
+
+// NewMapStore returns the default Store, a Go map.
+func NewMapStore() Store {
+	return mapStore{}
+}
+
+// mapStore is a Store over a Go map
+type mapStore map[string]Item
+
+// Get implements Store.
+func (m mapStore) Get(key string) (Item, bool) {
+	i, found := m[key]
+	return i, found
+}
+
+// Set implements Store.
+func (m mapStore) Set(key string, i Item) {
+	m[key] = i
+}
+
+// Delete implements Store.
+func (m mapStore) Delete(key string) {
+	delete(m, key)
+}
+
+// Len implements Store.
+func (m mapStore) Len() int {
+	return len(m)
+}
+
+// Range implements Store.
+func (m mapStore) Range(fn func(key string, i Item) bool) {
+	for key, i := range m {
+		if !fn(key, i) {
+			return
+		}
+	}
+}
+
+// clearLocked removes all items and returns how many there were,
+// fc.itemsLock must be held
+func (fc *FetchCache) clearLocked() int {
+	switch s := fc.items.(type) {
+	case mapStore:
+		// a new map releases the memory of the old one
+		fc.items = mapStore{}
+		fc.peak = 0
+		return len(s)
+	case *LockFreeStore:
+		return s.reset()
+	}
+	var keys []string
+	fc.items.Range(func(key string, _ Item) bool {
+		keys = append(keys, key)
+		return true
+	})
+	for _, key := range keys {
+		fc.items.Delete(key)
+	}
+	return len(keys)
+}
@@ -0,0 +1,88 @@
+package resource
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+)
+
+// countingStore is a map Store counting the writes
+type countingStore struct {
+	mapStore
+	sets, deletes int32
+}
+
+func (s *countingStore) Set(key string, i Item) {
+	atomic.AddInt32(&s.sets, 1)
+	s.mapStore.Set(key, i)
+}
+
+func (s *countingStore) Delete(key string) {
+	atomic.AddInt32(&s.deletes, 1)
+	s.mapStore.Delete(key)
+}
+
+func TestFetchCache_WithStore(t *testing.T) {
+	ids := []string{
+		"dca76878-a8f6-4ff5-b263-1e8c7e61bc20",
+		"5634aeed-2106-43de-ab7d-c0ad4b1e195e",
+		"7e1588ab-2bf9-44b0-a7ae-41c9ef3c86af",
+	}
+
+	tests := []struct {
+		name        string
+		op          func(fc *FetchCache)
+		wantLen     int
+		wantDeletes int32
+	}{
+		{
+			name:    "success fetch",
+			op:      func(fc *FetchCache) {},
+			wantLen: 3,
+		},
+		{
+			name:        "success clear",
+			op:          func(fc *FetchCache) { fc.Clear(ids[0]) },
+			wantLen:     2,
+			wantDeletes: 1,
+		},
+		{
+			name:        "success flush",
+			op:          func(fc *FetchCache) { fc.Flush() },
+			wantDeletes: 3,
+		},
+		{
+			name:        "success evict",
+			op:          func(fc *FetchCache) { fc.Reconfigure(WithMaxEntries(1)) },
+			wantLen:     1,
+			wantDeletes: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					return &Model{Name: id}, nil
+				},
+			}
+			store := &countingStore{mapStore: mapStore{}}
+			fc := NewCache(mockedFetcher, WithStore(store))
+			defer fc.Close(context.Background())
+
+			for _, id := range ids {
+				_, _ = fc.Fetch(context.Background(), id)
+			}
+			tt.op(fc)
+			if store.sets != int32(len(ids)) || store.deletes != tt.wantDeletes {
+				t.Errorf("FetchCache expect %v sets and %v deletes, have %v and %v", len(ids), tt.wantDeletes, store.sets, store.deletes)
+			}
+			if store.Len() != tt.wantLen || fc.Stats().Items != tt.wantLen {
+				t.Errorf("FetchCache expect %v items, have %v", tt.wantLen, store.Len())
+			}
+			model, err := fc.Fetch(context.Background(), ids[2])
+			if err != nil || model.Name != ids[2] {
+				t.Errorf("FetchCache.Fetch() expect %v, have %v, %v", ids[2], model, err)
+			}
+		})
+	}
+}
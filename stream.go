@@ -0,0 +1,211 @@
+package resource
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// streamSweepGrace is how old an unreferenced file must be for Sweep to
+// remove it, so files just fetched and not yet cached are kept
+const streamSweepGrace = time.Minute
+
+// StreamingFetcher is an interface that fetches payloads too large to be
+// held in memory, see StreamCache.
+type StreamingFetcher interface {
+	// FetchStream returns the payload of id and its size, -1 if unknown.
+	FetchStream(ctx context.Context, id string) (io.ReadCloser, int64, error)
+}
+
+// StreamCache caches the payloads of a StreamingFetcher in files of a
+// directory, handing back readers of the files so a payload never has to
+// fit in the heap. It is a FetchCache of the file paths: the options set
+// the TTL, limits and the rest as for NewCache, concurrent opens of an id
+// share a single FetchStream call and Cache gives access to invalidation.
+// With WithEncrypter the files are encrypted.
+type StreamCache struct {
+	seq uint64 // accessed atomically
+	f   StreamingFetcher
+	dir string
+	fc  *FetchCache
+}
+
+// NewStreamCache creates a StreamCache of f storing the payloads in dir,
+// which is created if needed.
+func NewStreamCache(f StreamingFetcher, dir string, opts ...Option) (*StreamCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	sc := &StreamCache{f: f, dir: dir}
+	sc.fc = NewCache(streamFetcher{sc}, opts...)
+	return sc, nil
+}
+
+// Cache returns the underlying cache, the Name of its models being the
+// path of the file holding the payload and with WithChecksums the Data
+// its checksum.
+func (sc *StreamCache) Cache() *FetchCache {
+	return sc.fc
+}
+
+// Open returns a reader of the payload of id and its size, fetching it if
+// it isn't cached. The reader must be closed, it stays valid when the
+// entry is replaced or removed meanwhile.
+func (sc *StreamCache) Open(ctx context.Context, id string) (io.ReadCloser, int64, error) {
+	for retried := false; ; retried = true {
+		model, err := sc.fc.Fetch(ctx, id)
+		if err != nil {
+			return nil, 0, err
+		}
+		file, err := os.Open(model.Name)
+		if os.IsNotExist(err) && !retried {
+			// the file was removed from under the cache
+			sc.fc.Clear(id)
+			continue
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		var (
+			r    io.ReadCloser = file
+			size int64
+		)
+		if enc := sc.fc.opts.encrypter; enc != nil {
+			dr, err := newDecryptReader(file, enc, []byte(id))
+			if err != nil {
+				file.Close()
+				return nil, 0, err
+			}
+			r, size = dr, dr.size
+		} else {
+			info, err := file.Stat()
+			if err != nil {
+				file.Close()
+				return nil, 0, err
+			}
+			size = info.Size()
+		}
+		if sc.fc.opts.checksums && len(model.Data) == checksumSize {
+			r = &checksumReader{
+				r:    r,
+				h:    crc32.New(castagnoli),
+				want: binary.BigEndian.Uint32(model.Data),
+				err:  &CorruptionError{ID: id, Tier: "stream"},
+				corrupted: func() {
+					sc.fc.metrics.corrupted()
+					sc.fc.Clear(id)
+				},
+			}
+		}
+		return r, size, nil
+	}
+}
+
+// Sweep removes the files of the directory no cached entry refers to any
+// more, such as the payloads replaced, evicted or expired, and returns how
+// many it removed. It is meant to be called periodically.
+func (sc *StreamCache) Sweep() (int, error) {
+	used := make(map[string]struct{})
+	sc.fc.Snapshot(false).Range(func(e SnapshotEntry) bool {
+		used[filepath.Base(e.Model.Name)] = struct{}{}
+		return true
+	})
+	entries, err := os.ReadDir(sc.dir)
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, e := range entries {
+		if _, ok := used[e.Name()]; ok || e.IsDir() {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil || time.Since(info.ModTime()) < streamSweepGrace {
+			continue
+		}
+		if os.Remove(filepath.Join(sc.dir, e.Name())) == nil {
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+// Close closes the underlying cache and removes the cached files.
+func (sc *StreamCache) Close(ctx context.Context) error {
+	err := sc.fc.Close(ctx)
+	entries, _ := os.ReadDir(sc.dir)
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), streamFilePrefix) {
+			os.Remove(filepath.Join(sc.dir, e.Name()))
+		}
+	}
+	return err
+}
+
+// streamFilePrefix starts the names of the files of a StreamCache
+const streamFilePrefix = "stream-"
+
+// streamFetcher downloads payloads to files, the models returned name them
+type streamFetcher struct {
+	sc *StreamCache
+}
+
+// Fetch implements Fetcher.
+func (f streamFetcher) Fetch(ctx context.Context, id string) (*Model, error) {
+	r, size, err := f.sc.f.FetchStream(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	sum := sha256.Sum256([]byte(id))
+	name := fmt.Sprintf("%s%s-%d", streamFilePrefix, hex.EncodeToString(sum[:8]), atomic.AddUint64(&f.sc.seq, 1))
+	tmp, err := os.CreateTemp(f.sc.dir, name+".*.tmp")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+
+	var (
+		src   io.Reader = r
+		check hash.Hash32
+		n     int64
+	)
+	if f.sc.fc.opts.checksums {
+		check = crc32.New(castagnoli)
+		src = io.TeeReader(r, check)
+	}
+	if enc := f.sc.fc.opts.encrypter; enc != nil {
+		n, err = encryptStream(tmp, src, enc, []byte(id))
+	} else {
+		n, err = io.Copy(tmp, src)
+	}
+	if err == nil && size >= 0 && n != size {
+		err = fmt.Errorf("stream of %q: %w, %d of %d bytes", id, io.ErrUnexpectedEOF, n, size)
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(f.sc.dir, name)
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return nil, err
+	}
+	model := &Model{Name: path}
+	if check != nil {
+		model.Data = check.Sum(nil)
+	}
+	return model, nil
+}
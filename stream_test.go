@@ -0,0 +1,133 @@
+package resource
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// streamingFetcherFunc adapts a func to StreamingFetcher
+type streamingFetcherFunc func(ctx context.Context, id string) (io.ReadCloser, int64, error)
+
+func (f streamingFetcherFunc) FetchStream(ctx context.Context, id string) (io.ReadCloser, int64, error) {
+	return f(ctx, id)
+}
+
+func TestStreamCache_Open(t *testing.T) {
+	var (
+		fakeFetchID = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+		payload     = strings.Repeat("lorem ipsum ", 1000)
+	)
+
+	tests := []struct {
+		name      string
+		size      int64
+		removed   bool
+		wantErr   error
+		wantCalls int32
+	}{
+		{
+			name:      "success known size",
+			size:      int64(len(payload)),
+			wantCalls: 1,
+		},
+		{
+			name:      "success unknown size",
+			size:      -1,
+			wantCalls: 1,
+		},
+		{
+			name:      "success refetch removed file",
+			size:      -1,
+			removed:   true,
+			wantCalls: 2,
+		},
+		{
+			name:      "failed truncated stream",
+			size:      int64(len(payload)) + 1,
+			wantErr:   io.ErrUnexpectedEOF,
+			wantCalls: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+			f := streamingFetcherFunc(func(ctx context.Context, id string) (io.ReadCloser, int64, error) {
+				atomic.AddInt32(&calls, 1)
+				return io.NopCloser(strings.NewReader(payload)), tt.size, nil
+			})
+			dir := t.TempDir()
+			sc, err := NewStreamCache(f, dir)
+			if err != nil {
+				t.Fatalf("NewStreamCache() error = %v", err)
+			}
+			defer sc.Close(context.Background())
+
+			for i := 0; i < 2; i++ {
+				if tt.removed && i == 1 {
+					model, _ := sc.Cache().Fetch(context.Background(), fakeFetchID)
+					os.Remove(model.Name)
+				}
+				r, size, err := sc.Open(context.Background(), fakeFetchID)
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("StreamCache.Open() expect error = %v, have %v", tt.wantErr, err)
+				}
+				if err != nil {
+					continue
+				}
+				var buf bytes.Buffer
+				_, _ = io.Copy(&buf, r)
+				r.Close()
+				if buf.String() != payload || size != int64(len(payload)) {
+					t.Errorf("StreamCache.Open() expect %v bytes of payload, have %v of size %v", len(payload), buf.Len(), size)
+				}
+			}
+			if n := atomic.LoadInt32(&calls); n != tt.wantCalls {
+				t.Errorf("StreamCache.Open() expect %v FetchStream calls, have %v", tt.wantCalls, n)
+			}
+			if entries, _ := os.ReadDir(dir); tt.wantErr != nil && len(entries) != 0 {
+				t.Errorf("StreamCache.Open() expect no file left after error, have %v", len(entries))
+			}
+		})
+	}
+}
+
+func TestStreamCache_Sweep(t *testing.T) {
+	var (
+		fakeFetchID = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	)
+	f := streamingFetcherFunc(func(ctx context.Context, id string) (io.ReadCloser, int64, error) {
+		return io.NopCloser(strings.NewReader("lorem")), -1, nil
+	})
+	sc, err := NewStreamCache(f, t.TempDir())
+	if err != nil {
+		t.Fatalf("NewStreamCache() error = %v", err)
+	}
+	defer sc.Close(context.Background())
+	r, _, err := sc.Open(context.Background(), fakeFetchID)
+	if err != nil {
+		t.Fatalf("StreamCache.Open() error = %v", err)
+	}
+	r.Close()
+	model, _ := sc.Cache().Fetch(context.Background(), fakeFetchID)
+
+	// a cached file is kept, an orphan only once past the grace period
+	if n, err := sc.Sweep(); err != nil || n != 0 {
+		t.Errorf("StreamCache.Sweep() = %v, %v, want 0 with the file cached", n, err)
+	}
+	sc.Cache().Clear(fakeFetchID)
+	if n, _ := sc.Sweep(); n != 0 {
+		t.Errorf("StreamCache.Sweep() expect recent orphan kept, have %v removed", n)
+	}
+	old := time.Now().Add(-2 * streamSweepGrace)
+	os.Chtimes(model.Name, old, old)
+	if n, _ := sc.Sweep(); n != 1 {
+		t.Errorf("StreamCache.Sweep() expect orphan removed, have %v removed", n)
+	}
+}
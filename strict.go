@@ -0,0 +1,223 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"hash/crc32"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// strictCancelGrace is how long a Fetcher may run on after its context is
+// done before strict mode reports it
+const strictCancelGrace = 100 * time.Millisecond
+
+// MisuseKind is the kind of a Misuse.
+type MisuseKind int
+
+// Misuse kind list
+const (
+	// MisuseMutatedModel is a cached model modified by a caller, the
+	// models returned by the cache are shared and must not be modified.
+	MisuseMutatedModel MisuseKind = iota + 1
+	// MisuseUnbalancedLock is an Unlock of a key which isn't locked, or a
+	// key locked by Lock or LockContext still locked when the cache is
+	// closed.
+	MisuseUnbalancedLock
+	// MisuseIgnoredCancel is a Fetcher call returning well after its
+	// context was done.
+	MisuseIgnoredCancel
+	// MisuseStoreAfterClose is a model set by Update, CompareAndSwap,
+	// WithEntry or CacheAside.Write after Close.
+	MisuseStoreAfterClose
+)
+
+var misuseKindNames = map[MisuseKind]string{
+	MisuseMutatedModel:    "mutated_model",
+	MisuseUnbalancedLock:  "unbalanced_lock",
+	MisuseIgnoredCancel:   "ignored_cancel",
+	MisuseStoreAfterClose: "store_after_close",
+}
+
+// String returns the lower case name of the kind.
+func (k MisuseKind) String() string {
+	if name, ok := misuseKindNames[k]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// Misuse is a misuse of the cache detected by WithStrictMode.
+type Misuse struct {
+	Kind MisuseKind
+	// Key is the key involved, if any.
+	Key    string
+	Detail string
+	// Stack is the stack of the goroutine the misuse was detected in.
+	Stack []byte
+}
+
+// Error implements error.
+func (m Misuse) Error() string {
+	return fmt.Sprintf("cache misuse %s of %q: %s", m.Kind, m.Key, m.Detail)
+}
+
+// WithStrictMode detects the common misuses of the cache at runtime, see
+// MisuseKind, and calls report with them, or panics with the Misuse when
+// report is nil. A modified model is detected by a copy of its Name and a
+// checksum of its Data taken when it is cached and compared on every hit of
+// it. It costs a checksum per hit and a goroutine per Fetcher call with a
+// cancelable context, but WithSynchronous, for tests and debug builds: building with the
+// cachestrict tag turns it on, panicking, for every cache not setting it.
+func WithStrictMode(report func(Misuse)) Option {
+	return func(o *options) {
+		o.strict = true
+		o.strictReport = report
+	}
+}
+
+// strictChecker implements WithStrictMode
+type strictChecker struct {
+	report func(Misuse)
+	// sums are the modelSum of the cached models by key and locked the
+	// keys locked by Lock and LockContext
+	sums   sync.Map
+	locked sync.Map
+}
+
+// modelSum is the checksum of a cached model
+type modelSum struct {
+	model *Model
+	name  string
+	sum   uint32
+}
+
+func newModelSum(m *Model) modelSum {
+	return modelSum{model: m, name: m.Name, sum: crc32.Checksum(m.Data, castagnoli)}
+}
+
+// modified reports whether the model was modified since s was taken
+func (s modelSum) modified() bool {
+	return s.model.Name != s.name || crc32.Checksum(s.model.Data, castagnoli) != s.sum
+}
+
+func newStrictChecker(o options) *strictChecker {
+	if !o.strict && !strictBuild {
+		return nil
+	}
+	report := o.strictReport
+	if report == nil {
+		report = func(m Misuse) { panic(m) }
+	}
+	return &strictChecker{report: report}
+}
+
+// misuse reports a misuse of kind
+func (s *strictChecker) misuse(kind MisuseKind, key interface{}, format string, args ...interface{}) {
+	s.report(Misuse{Kind: kind, Key: fmt.Sprint(key), Detail: fmt.Sprintf(format, args...), Stack: debug.Stack()})
+}
+
+// stored records the checksum of the model cached under key
+func (s *strictChecker) stored(key string, m *Model) {
+	if s == nil || m == nil {
+		return
+	}
+	s.sums.Store(key, newModelSum(m))
+}
+
+// servedFrom checks the model of key served from src wasn't modified since
+// it was cached
+func (s *strictChecker) servedFrom(key string, m *Model, src Source) {
+	if s == nil || m == nil || (src != SourceMemory && src != SourceStale) {
+		return
+	}
+	v, ok := s.sums.Load(key)
+	if !ok || v.(modelSum).model != m {
+		return
+	}
+	if v.(modelSum).modified() {
+		s.sums.Store(key, newModelSum(m))
+		s.misuse(MisuseMutatedModel, key, "the cached model was modified after it was returned")
+	}
+}
+
+// watchFetch watches ctx during the Fetcher call of key, the returned func
+// is called once the call returns. A synchronous cache starts no goroutine,
+// only the calls returning past the deadline of ctx are reported.
+func (s *strictChecker) watchFetch(ctx context.Context, key string, synchronous bool) func() {
+	if s == nil || ctx.Done() == nil {
+		return func() {}
+	}
+	if synchronous {
+		return func() {
+			deadline, ok := ctx.Deadline()
+			if !ok || ctx.Err() == nil {
+				return
+			}
+			if late := time.Since(deadline); late > strictCancelGrace {
+				s.misuse(MisuseIgnoredCancel, key, "the fetcher returned %s after its context was done", late)
+			}
+		}
+	}
+	stop := make(chan struct{})
+	done := make(chan time.Time, 1)
+	go func() {
+		select {
+		case <-ctx.Done():
+			done <- time.Now()
+		case <-stop:
+		}
+	}()
+	return func() {
+		close(stop)
+		select {
+		case at := <-done:
+			if late := time.Since(at); late > strictCancelGrace {
+				s.misuse(MisuseIgnoredCancel, key, "the fetcher returned %s after its context was done", late)
+			}
+		default:
+		}
+	}
+}
+
+// lockedKey records key locked by Lock or LockContext
+func (s *strictChecker) lockedKey(key interface{}) {
+	if s != nil {
+		s.locked.Store(key, struct{}{})
+	}
+}
+
+// unlockedKey checks key unlocked by Unlock was locked
+func (s *strictChecker) unlockedKey(key interface{}, held bool) {
+	if s == nil {
+		return
+	}
+	s.locked.Delete(key)
+	if !held {
+		s.misuse(MisuseUnbalancedLock, key, "Unlock of a key which isn't locked")
+	}
+}
+
+// closed checks no key is left locked by Lock or LockContext and forgets
+// the checksums
+func (s *strictChecker) closed() {
+	if s == nil {
+		return
+	}
+	s.sums.Range(func(key, _ interface{}) bool {
+		s.sums.Delete(key)
+		return true
+	})
+	s.locked.Range(func(key, _ interface{}) bool {
+		s.misuse(MisuseUnbalancedLock, key, "key still locked when the cache is closed")
+		return true
+	})
+}
+
+// setAfterClose checks the cache isn't closed when key is set
+func (s *strictChecker) setAfterClose(key string, closed bool) {
+	if s != nil && closed {
+		s.misuse(MisuseStoreAfterClose, key, "model set after Close")
+	}
+}
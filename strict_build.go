@@ -0,0 +1,7 @@
+//go:build cachestrict
+// +build cachestrict
+
+package resource
+
+// strictBuild turns WithStrictMode on for every cache
+const strictBuild = true
@@ -0,0 +1,8 @@
+//go:build !cachestrict
+// +build !cachestrict
+
+package resource
+
+// strictBuild turns WithStrictMode on for every cache, see the cachestrict
+// build tag
+const strictBuild = false
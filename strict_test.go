@@ -0,0 +1,135 @@
+package resource
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFetchCache_WithStrictMode(t *testing.T) {
+	fakeFetchID := "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	slow := &FetcherMock{
+		FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+			if id == fakeFetchID {
+				time.Sleep(2 * strictCancelGrace)
+			}
+			return &Model{Name: id, Data: []byte("data")}, nil
+		},
+	}
+
+	tests := []struct {
+		name     string
+		async    bool
+		misuse   func(fc *FetchCache)
+		wantKind MisuseKind
+	}{
+		{
+			name: "success report mutated model",
+			misuse: func(fc *FetchCache) {
+				model, _ := fc.Fetch(context.Background(), "other")
+				model.Data[0] = 'D'
+				_, _ = fc.Fetch(context.Background(), "other")
+			},
+			wantKind: MisuseMutatedModel,
+		},
+		{
+			name: "success report unlock of unlocked key",
+			misuse: func(fc *FetchCache) {
+				fc.Unlock(fakeFetchID)
+			},
+			wantKind: MisuseUnbalancedLock,
+		},
+		{
+			name: "success report key locked on close",
+			misuse: func(fc *FetchCache) {
+				_ = fc.LockContext(context.Background(), fakeFetchID)
+				_ = fc.Close(context.Background())
+			},
+			wantKind: MisuseUnbalancedLock,
+		},
+		{
+			name: "success report fetcher ignoring cancellation",
+			misuse: func(fc *FetchCache) {
+				ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+				defer cancel()
+				_, _ = fc.Fetch(ctx, fakeFetchID)
+			},
+			wantKind: MisuseIgnoredCancel,
+		},
+		{
+			name:  "success report fetcher ignoring cancellation without synchronous",
+			async: true,
+			misuse: func(fc *FetchCache) {
+				ctx, cancel := context.WithCancel(context.Background())
+				time.AfterFunc(time.Millisecond, cancel)
+				_, _ = fc.Fetch(ctx, fakeFetchID)
+			},
+			wantKind: MisuseIgnoredCancel,
+		},
+		{
+			name: "success report update after close",
+			misuse: func(fc *FetchCache) {
+				_ = fc.Close(context.Background())
+				_, _ = fc.Update(context.Background(), fakeFetchID, func(*Model) (*Model, error) {
+					return &Model{Name: fakeFetchID}, nil
+				})
+			},
+			wantKind: MisuseStoreAfterClose,
+		},
+		{
+			name: "success report nothing on proper use",
+			misuse: func(fc *FetchCache) {
+				model, _ := fc.Fetch(context.Background(), "other")
+				_, _ = fc.Fetch(context.Background(), model.Name)
+				fc.Lock(fakeFetchID)
+				fc.Unlock(fakeFetchID)
+				_ = fc.Close(context.Background())
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				mu      sync.Mutex
+				misuses []Misuse
+			)
+			opts := []Option{WithStrictMode(func(m Misuse) {
+				mu.Lock()
+				misuses = append(misuses, m)
+				mu.Unlock()
+			})}
+			if !tt.async {
+				opts = append(opts, WithSynchronous())
+			}
+			fc := NewCache(slow, opts...)
+			tt.misuse(fc)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if tt.wantKind == 0 {
+				if len(misuses) != 0 {
+					t.Errorf("FetchCache.WithStrictMode() expect no misuse, have %v", misuses)
+				}
+				return
+			}
+			if len(misuses) != 1 || misuses[0].Kind != tt.wantKind {
+				t.Fatalf("FetchCache.WithStrictMode() expect misuse = %v, have %v", tt.wantKind, misuses)
+			}
+			if len(misuses[0].Stack) == 0 {
+				t.Errorf("FetchCache.WithStrictMode() expect the stack of the misuse")
+			}
+		})
+	}
+}
+
+func TestFetchCache_WithStrictModePanics(t *testing.T) {
+	fc := NewCache(&FetcherMock{}, WithStrictMode(nil))
+	defer func() {
+		m, ok := recover().(Misuse)
+		if !ok || m.Kind != MisuseUnbalancedLock {
+			t.Errorf("FetchCache.Unlock() expect panic = %v, have %v", MisuseUnbalancedLock, m)
+		}
+	}()
+	fc.Unlock("key")
+}
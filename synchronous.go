@@ -0,0 +1,142 @@
+package resource
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// WithSynchronous makes the cache start no goroutine nor timer of its own,
+// for GOOS=js/wasm and other environments without threads. Expiry stays
+// lazy and eviction synchronous as always, and in addition:
+//
+//   - FetchMany fetches the ids one after the other,
+//   - a BatchFetcher is called with a batch of one on every miss,
+//   - health checks, eager and scheduled refreshes, drift checks, expiry
+//     sweeps and removals of WithExpiryWheel, the warming and saves of
+//     WithHotKeys and the republish of WithSnapshotReads only run when Tick
+//     is called, the hot keys are also saved by Close.
+func WithSynchronous() Option {
+	return func(o *options) {
+		o.synchronous = true
+	}
+}
+
+// Tick runs the background work which is due, in the calling goroutine:
+// the health check, eager refresh, drift check and expiry sweep once their
+// interval has elapsed, the scheduled refresh once its activation time has
+// passed, the removals due with WithExpiryWheel, the warming on the first
+// call and the saves of WithHotKeys and the republish of the writes pending
+// with WithSnapshotReads.
+// It is meant to be called periodically on caches created WithSynchronous,
+// other caches run this work in the background and Tick does nothing.
+func (fc *FetchCache) Tick(ctx context.Context) {
+	if !fc.opts.synchronous || !fc.life.enter() {
+		return
+	}
+	defer fc.life.leave()
+
+	due := fc.ticks.due(time.Now(), fc.opts)
+	if due.health {
+		fc.checkHealth(ctx)
+	}
+	if due.eager && !fc.Degraded() {
+		for _, id := range fc.eager.list() {
+			if ctx.Err() != nil {
+				return
+			}
+			_ = fc.reload(ctx, id)
+		}
+	}
+	if due.scheduled && !fc.Degraded() {
+		fc.refreshAll(ctx, 1)
+	}
+	if due.drift && !fc.Degraded() {
+		fc.checkDrift(ctx)
+	}
+	if due.sweep {
+		fc.sweepExpired()
+	}
+	if fc.wheel != nil {
+		fc.expireDue(time.Now())
+	}
+	if due.warm {
+		fc.warmHotKeys(ctx)
+	}
+	if due.hotKeys {
+		_ = fc.saveHotKeys(ctx)
+	}
+	fc.republish()
+}
+
+// ticks tracks when the work run by Tick is next due, a zero time means
+// never
+type ticks struct {
+	mu            sync.Mutex
+	nextHealth    time.Time
+	nextEager     time.Time
+	nextScheduled time.Time
+	nextDrift     time.Time
+	nextSweep     time.Time
+	nextHotKeys   time.Time
+	// warm is true until the hot keys were loaded
+	warm bool
+}
+
+// dueWork is the work Tick has to run
+type dueWork struct {
+	health, eager, scheduled, drift, sweep, warm, hotKeys bool
+}
+
+func newTicks(now time.Time, o options) *ticks {
+	t := &ticks{}
+	if o.health != nil && o.healthInterval > 0 {
+		t.nextHealth = now
+	}
+	if o.loadPolicy != nil && o.eagerInterval > 0 {
+		t.nextEager = now.Add(o.eagerInterval)
+	}
+	if o.refreshSchedule != nil {
+		t.nextScheduled = o.refreshSchedule.Next(now)
+	}
+	if o.driftEqual != nil && o.driftInterval > 0 {
+		t.nextDrift = now.Add(o.driftInterval)
+	}
+	if o.sweepInterval > 0 {
+		t.nextSweep = now.Add(o.sweepInterval)
+	}
+	if o.hotKeys != nil {
+		t.warm = true
+		if o.hotKeysInterval > 0 {
+			t.nextHotKeys = now.Add(o.hotKeysInterval)
+		}
+	}
+	return t
+}
+
+// due reports which work is due at now and schedules its next run
+func (t *ticks) due(now time.Time, o options) dueWork {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var d dueWork
+	d.health = passed(&t.nextHealth, now, o.healthInterval)
+	d.eager = passed(&t.nextEager, now, o.eagerInterval)
+	d.drift = passed(&t.nextDrift, now, o.driftInterval)
+	d.sweep = passed(&t.nextSweep, now, o.sweepInterval)
+	d.hotKeys = passed(&t.nextHotKeys, now, o.hotKeysInterval)
+	d.warm, t.warm = t.warm, false
+	if !t.nextScheduled.IsZero() && !now.Before(t.nextScheduled) {
+		d.scheduled = true
+		t.nextScheduled = o.refreshSchedule.Next(now)
+	}
+	return d
+}
+
+// passed reports whether next has passed at now and moves it interval later
+func passed(next *time.Time, now time.Time, interval time.Duration) bool {
+	if next.IsZero() || now.Before(*next) {
+		return false
+	}
+	*next = now.Add(interval)
+	return true
+}
@@ -0,0 +1,104 @@
+package resource
+
+import (
+	"context"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFetchCache_WithSynchronous(t *testing.T) {
+	var (
+		fakeFetchID     = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+		notExistModelID = "5634aeed-2106-43de-ab7d-c0ad4b1e195e"
+	)
+
+	var callCount int32
+	mockedFetcher := &FetcherMock{
+		FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+			atomic.AddInt32(&callCount, 1)
+			if id == notExistModelID {
+				return nil, ErrNotFound
+			}
+			return &Model{Name: id}, nil
+		},
+	}
+	before := runtime.NumGoroutine()
+	fc := NewCache(mockedFetcher,
+		WithSynchronous(),
+		WithTTL(time.Minute),
+		WithLoadPolicy(func(id string) LoadMode { return Eager }, 5*time.Millisecond),
+		WithScheduledRefresh(Every(time.Hour), 4),
+	)
+
+	models, errs := fc.FetchMany(context.Background(), []string{fakeFetchID, notExistModelID})
+	if models[fakeFetchID] == nil || errs[notExistModelID] != ErrNotFound {
+		t.Errorf("FetchCache.FetchMany() expect model and ErrNotFound, have %v, %v", models, errs)
+	}
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("NewCache() expect no goroutines started, have %v", after-before)
+	}
+
+	fc.Tick(context.Background())
+	if callCount != 2 {
+		t.Errorf("FetchCache.Tick() expect no refresh before the interval, have %v fetcher calls", callCount)
+	}
+	time.Sleep(10 * time.Millisecond)
+	fc.Tick(context.Background())
+	if callCount != 3 {
+		t.Errorf("FetchCache.Tick() expect eager key refreshed, have %v fetcher calls", callCount)
+	}
+
+	if err := fc.Close(context.Background()); err != nil {
+		t.Errorf("FetchCache.Close() error = %v", err)
+	}
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("FetchCache.Close() expect no goroutines started, have %v", after-before)
+	}
+}
+
+func TestFetchCache_WithSynchronous_BatchFetcher(t *testing.T) {
+	var (
+		fakeFetchID     = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+		notExistModelID = "5634aeed-2106-43de-ab7d-c0ad4b1e195e"
+	)
+
+	tests := []struct {
+		name    string
+		id      string
+		wantErr error
+	}{
+		{
+			name: "success",
+			id:   fakeFetchID,
+		},
+		{
+			name:    "failed not found",
+			id:      notExistModelID,
+			wantErr: ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := batchFetcherFunc(func(ctx context.Context, ids []string) (map[string]*Model, error) {
+				models := make(map[string]*Model)
+				for _, id := range ids {
+					if id != notExistModelID {
+						models[id] = &Model{Name: id}
+					}
+				}
+				return models, nil
+			})
+			fc := NewCache(&FetcherMock{}, WithSynchronous(), WithBatchFetcher(b, time.Hour, 0))
+			before := runtime.NumGoroutine()
+			_, err := fc.Fetch(context.Background(), tt.id)
+			if err != tt.wantErr {
+				t.Errorf("FetchCache.Fetch() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if after := runtime.NumGoroutine(); after > before {
+				t.Errorf("FetchCache.Fetch() expect no goroutines started, have %v", after-before)
+			}
+		})
+	}
+}
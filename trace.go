@@ -0,0 +1,166 @@
+package resource
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+// Error list
+var (
+	// ErrTraceFormat is returned by TraceReader for data which isn't an
+	// access trace.
+	ErrTraceFormat = errors.New("invalid access trace")
+)
+
+// Const list
+const (
+	// traceMagic starts every access trace
+	traceMagic = "ctr1"
+	// maxTraceKey bounds the key size a TraceReader accepts
+	maxTraceKey = 1 << 20
+)
+
+// WithAccessTrace records the hits and misses of a sample of the keys with
+// their time to w, in the compact format read by TraceReader. Keys are
+// sampled by hash so every access of a sampled key is recorded, a rate of
+// 1 records them all. Writes are buffered and flushed by Close, the
+// recording stops at the first write error.
+func WithAccessTrace(w io.Writer, rate float64) Option {
+	return func(o *options) {
+		o.traceWriter = w
+		o.traceRate = rate
+	}
+}
+
+// TraceRecord is an access recorded by WithAccessTrace.
+type TraceRecord struct {
+	Key  string
+	Time time.Time
+	// Hit is true when the access was served from the cache.
+	Hit bool
+}
+
+// accessTrace writes the records of WithAccessTrace, a record being the
+// time since the previous one in ns, a hit flag and the key, as uvarints
+// but for the flag
+type accessTrace struct {
+	mu        sync.Mutex
+	w         *bufio.Writer
+	threshold uint32
+	last      int64
+	err       error
+	buf       [2*binary.MaxVarintLen64 + 1]byte
+}
+
+func newAccessTrace(w io.Writer, rate float64) *accessTrace {
+	t := &accessTrace{w: bufio.NewWriter(w), threshold: 1<<32 - 1}
+	if rate < 1 {
+		t.threshold = uint32(rate * (1 << 32))
+	}
+	_, t.err = t.w.WriteString(traceMagic)
+	return t
+}
+
+// record writes an access to key if it is sampled
+func (t *accessTrace) record(key string, hit bool, now time.Time) {
+	if t == nil || fnv32(key) > t.threshold {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.err != nil {
+		return
+	}
+	ns := now.UnixNano()
+	n := binary.PutUvarint(t.buf[:], uint64(ns-t.last))
+	t.last = ns
+	t.buf[n] = 0
+	if hit {
+		t.buf[n] = 1
+	}
+	n++
+	n += binary.PutUvarint(t.buf[n:], uint64(len(key)))
+	if _, t.err = t.w.Write(t.buf[:n]); t.err == nil {
+		_, t.err = t.w.WriteString(key)
+	}
+}
+
+// flush writes the buffered records
+func (t *accessTrace) flush() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	if t.err == nil {
+		t.err = t.w.Flush()
+	}
+	t.mu.Unlock()
+}
+
+// fnv32 returns the FNV-1a hash of s
+func fnv32(s string) uint32 {
+	h := uint32(2166136261)
+	for i := 0; i < len(s); i++ {
+		h ^= uint32(s[i])
+		h *= 16777619
+	}
+	return h
+}
+
+// TraceReader reads the records of an access trace written by
+// WithAccessTrace.
+type TraceReader struct {
+	r    *bufio.Reader
+	last int64
+	err  error
+}
+
+// NewTraceReader creates a TraceReader of the trace read from r.
+func NewTraceReader(r io.Reader) *TraceReader {
+	tr := &TraceReader{r: bufio.NewReader(r)}
+	magic := make([]byte, len(traceMagic))
+	if _, err := io.ReadFull(tr.r, magic); err != nil || string(magic) != traceMagic {
+		tr.err = ErrTraceFormat
+	}
+	return tr
+}
+
+// Next returns the next record, or io.EOF once there are no more.
+func (tr *TraceReader) Next() (TraceRecord, error) {
+	if tr.err != nil {
+		return TraceRecord{}, tr.err
+	}
+	rec, err := tr.next()
+	if err != nil {
+		tr.err = err
+	}
+	return rec, err
+}
+
+func (tr *TraceReader) next() (TraceRecord, error) {
+	delta, err := binary.ReadUvarint(tr.r)
+	if err == io.EOF {
+		return TraceRecord{}, io.EOF
+	}
+	if err != nil {
+		return TraceRecord{}, ErrTraceFormat
+	}
+	flag, err := tr.r.ReadByte()
+	if err != nil || flag > 1 {
+		return TraceRecord{}, ErrTraceFormat
+	}
+	size, err := binary.ReadUvarint(tr.r)
+	if err != nil || size > maxTraceKey {
+		return TraceRecord{}, ErrTraceFormat
+	}
+	key := make([]byte, size)
+	if _, err := io.ReadFull(tr.r, key); err != nil {
+		return TraceRecord{}, ErrTraceFormat
+	}
+	tr.last += int64(delta)
+	return TraceRecord{Key: string(key), Time: time.Unix(0, tr.last), Hit: flag == 1}, nil
+}
@@ -0,0 +1,93 @@
+package resource
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWithAccessTrace(t *testing.T) {
+	fakeFetchID := "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	notExistModelID := "5634aeed-2106-43de-ab7d-c0ad4b1e195e"
+
+	tests := []struct {
+		name     string
+		rate     float64
+		wantKeys []string
+		wantHits []bool
+	}{
+		{
+			name:     "success all keys",
+			rate:     1,
+			wantKeys: []string{fakeFetchID, notExistModelID, fakeFetchID, notExistModelID},
+			wantHits: []bool{false, false, true, false},
+		},
+		{
+			name: "success no key sampled",
+			rate: 1e-9,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					if id == notExistModelID {
+						return nil, ErrNotFound
+					}
+					return &Model{Name: id}, nil
+				},
+			}
+			var buf bytes.Buffer
+			fc := NewCache(mockedFetcher, WithAccessTrace(&buf, tt.rate))
+			for i := 0; i < 2; i++ {
+				_, _ = fc.Fetch(context.Background(), fakeFetchID)
+				_, _ = fc.Fetch(context.Background(), notExistModelID)
+			}
+			fc.Close(context.Background())
+
+			var (
+				keys []string
+				hits []bool
+			)
+			tr := NewTraceReader(&buf)
+			for {
+				rec, err := tr.Next()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatalf("TraceReader.Next() expect error = nil, have %v", err)
+				}
+				if rec.Time.IsZero() {
+					t.Errorf("TraceReader.Next() expect a time, have %v", rec.Time)
+				}
+				keys, hits = append(keys, rec.Key), append(hits, rec.Hit)
+			}
+			if !reflect.DeepEqual(keys, tt.wantKeys) || !reflect.DeepEqual(hits, tt.wantHits) {
+				t.Errorf("WithAccessTrace() expect = %v %v, have %v %v", tt.wantKeys, tt.wantHits, keys, hits)
+			}
+		})
+	}
+}
+
+func TestNewTraceReader(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr error
+	}{
+		{name: "success empty trace", data: traceMagic, wantErr: io.EOF},
+		{name: "fail not a trace", data: "{}", wantErr: ErrTraceFormat},
+		{name: "fail truncated record", data: traceMagic + "\x01\x01\x05ab", wantErr: ErrTraceFormat},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewTraceReader(strings.NewReader(tt.data)).Next(); err != tt.wantErr {
+				t.Errorf("TraceReader.Next() expect error = %v, have %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package resource
+
+import "context"
+
+// WithTraceID tags the events of the fetches, see Subscribe, the records of
+// WithAuditHook and the Mismatch of WithShadowFetcher with the id extract
+// returns for the context of the request which caused them, such as the id of its trace
+// span or a request id header, so a request can be correlated with the
+// cache decisions it triggered. The background work, e.g. the refreshes and
+// the expiry, isn't tagged.
+func WithTraceID(extract func(ctx context.Context) string) Option {
+	return func(o *options) {
+		o.traceID = extract
+	}
+}
+
+// traceID returns the trace id of ctx, empty without WithTraceID
+func (fc *FetchCache) traceID(ctx context.Context) string {
+	if fc.opts.traceID == nil || ctx == nil {
+		return ""
+	}
+	return fc.opts.traceID(ctx)
+}
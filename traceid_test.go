@@ -0,0 +1,123 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type traceKey struct{}
+
+func TestFetchCache_WithTraceID(t *testing.T) {
+	fakeFetchID := "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	extract := func(ctx context.Context) string {
+		trace, _ := ctx.Value(traceKey{}).(string)
+		return trace
+	}
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		trace   string
+		fetches int
+		want    []Event
+	}{
+		{
+			name:    "success tag the miss, set and hit",
+			opts:    []Option{WithTraceID(extract)},
+			trace:   "req-1",
+			fetches: 2,
+			want: []Event{
+				{Type: EventMiss, Key: fakeFetchID, TraceID: "req-1"},
+				{Type: EventSet, Key: fakeFetchID, TraceID: "req-1"},
+				{Type: EventHit, Key: fakeFetchID, TraceID: "req-1"},
+			},
+		},
+		{
+			name:    "success tag the buffered set",
+			opts:    []Option{WithTraceID(extract), WithWriteBuffer(1)},
+			trace:   "req-2",
+			fetches: 1,
+			want: []Event{
+				{Type: EventMiss, Key: fakeFetchID, TraceID: "req-2"},
+				{Type: EventSet, Key: fakeFetchID, TraceID: "req-2"},
+			},
+		},
+		{
+			name:    "success leave untagged without extractor",
+			trace:   "req-3",
+			fetches: 2,
+			want: []Event{
+				{Type: EventMiss, Key: fakeFetchID},
+				{Type: EventSet, Key: fakeFetchID},
+				{Type: EventHit, Key: fakeFetchID},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					return &Model{Name: id}, nil
+				},
+			}
+			fc := NewCache(mockedFetcher, tt.opts...)
+			defer fc.Close(context.Background())
+			sub := fc.Subscribe(10)
+			ctx := context.WithValue(context.Background(), traceKey{}, tt.trace)
+			for i := 0; i < tt.fetches; i++ {
+				if _, err := fc.Fetch(ctx, fakeFetchID); err != nil {
+					t.Fatalf("FetchCache.Fetch() expect error = nil, have %v", err)
+				}
+			}
+			var have []Event
+			for len(have) < len(tt.want) {
+				e := <-sub.Events()
+				e.Time = tt.want[0].Time
+				have = append(have, e)
+			}
+			if !reflect.DeepEqual(have, tt.want) {
+				t.Errorf("FetchCache.Subscribe() expect events = %v, have %v", tt.want, have)
+			}
+		})
+	}
+}
+
+func TestFetchCache_WithTraceID_Hooks(t *testing.T) {
+	fakeFetchID := "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	extract := func(ctx context.Context) string {
+		trace, _ := ctx.Value(traceKey{}).(string)
+		return trace
+	}
+	mockedFetcher := &FetcherMock{
+		FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+			return &Model{Name: id}, nil
+		},
+	}
+	shadow := &FetcherMock{
+		FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+			return nil, errors.New("shadow failed")
+		},
+	}
+	var (
+		records    []AuditRecord
+		mismatches []Mismatch
+	)
+	fc := NewCache(mockedFetcher,
+		WithTraceID(extract),
+		WithSynchronous(),
+		WithAuditHook(func(r AuditRecord) { records = append(records, r) }),
+		WithShadowFetcher(shadow, nil, func(m Mismatch) { mismatches = append(mismatches, m) }),
+	)
+	ctx := context.WithValue(context.Background(), traceKey{}, "req-1")
+	fc.Fetch(ctx, fakeFetchID)
+	fc.ClearContext(ctx, fakeFetchID)
+
+	if len(mismatches) != 1 || mismatches[0].TraceID != "req-1" {
+		t.Errorf("WithShadowFetcher() expect mismatch trace = req-1, have %v", mismatches)
+	}
+	if len(records) != 1 || records[0].TraceID != "req-1" {
+		t.Errorf("WithAuditHook() expect record trace = req-1, have %v", records)
+	}
+}
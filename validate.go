@@ -0,0 +1,57 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+)
+
+// ValidationError is returned by fetches whose model was rejected by the
+// validator of WithValidator.
+type ValidationError struct {
+	ID string
+	// Err is the error of the validator.
+	Err error
+}
+
+// Error implements error.
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid model %q: %v", e.ID, e.Err)
+}
+
+// Unwrap returns the error of the validator.
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// WithValidator checks every model returned by the Fetcher with v before
+// it is cached. A model v rejects is fetched again up to retries times,
+// and when still rejected the fetch fails with a ValidationError and
+// nothing is cached, so a corrupt or empty model isn't served for the
+// full TTL.
+func WithValidator(v func(id string, m *Model) error, retries int) Option {
+	return func(o *options) {
+		if retries < 0 {
+			retries = 0
+		}
+		o.validator = v
+		o.validatorRetries = retries
+	}
+}
+
+// fetchValid calls the Fetcher for id until it returns a model accepted by
+// the validator, an error or the retries run out
+func (fc *FetchCache) fetchValid(ctx context.Context, id string) (*Model, error) {
+	for attempt := 0; ; attempt++ {
+		model, err := fc.callFetcher(ctx, id)
+		if err != nil || fc.opts.validator == nil {
+			return model, fc.normalizeNotFound(err)
+		}
+		verr := fc.opts.validator(id, model)
+		if verr == nil {
+			return model, nil
+		}
+		if attempt >= fc.opts.validatorRetries || ctx.Err() != nil {
+			return nil, &ValidationError{ID: id, Err: verr}
+		}
+	}
+}
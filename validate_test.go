@@ -0,0 +1,82 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestFetchCache_WithValidator(t *testing.T) {
+	var (
+		fakeFetchID = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+		errEmpty    = errors.New("empty model")
+	)
+
+	tests := []struct {
+		name             string
+		models           []*Model
+		retries          int
+		wantErr          bool
+		serviceCallCount int
+		wantCached       bool
+	}{
+		{
+			name:             "success valid",
+			models:           []*Model{{Name: "lorem", Data: []byte("ipsum")}},
+			serviceCallCount: 1,
+			wantCached:       true,
+		},
+		{
+			name:             "success rejected",
+			models:           []*Model{{Name: "lorem"}},
+			wantErr:          true,
+			serviceCallCount: 1,
+		},
+		{
+			name:             "success retried",
+			models:           []*Model{{Name: "lorem"}, {Name: "lorem", Data: []byte("ipsum")}},
+			retries:          2,
+			serviceCallCount: 2,
+			wantCached:       true,
+		},
+		{
+			name:             "success retries run out",
+			models:           []*Model{{Name: "lorem"}, {Name: "lorem"}, {Name: "lorem"}},
+			retries:          2,
+			wantErr:          true,
+			serviceCallCount: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serviceCallCount := 0
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					m := tt.models[serviceCallCount]
+					serviceCallCount++
+					return m, nil
+				},
+			}
+			validator := func(id string, m *Model) error {
+				if len(m.Data) == 0 {
+					return errEmpty
+				}
+				return nil
+			}
+			fc := NewCache(mockedFetcher, WithValidator(validator, tt.retries))
+			defer fc.Close(context.Background())
+
+			_, err := fc.Fetch(context.Background(), fakeFetchID)
+			var verr *ValidationError
+			if (err != nil) != tt.wantErr || tt.wantErr && (!errors.As(err, &verr) || !errors.Is(err, errEmpty)) {
+				t.Errorf("FetchCache.Fetch() expect ValidationError = %v, have %v", tt.wantErr, err)
+			}
+			if serviceCallCount != tt.serviceCallCount {
+				t.Errorf("FetchCache.Fetch() expect service call count = %v, have %v", tt.serviceCallCount, serviceCallCount)
+			}
+			if _, cached := fc.peekitem(fakeFetchID); cached != tt.wantCached {
+				t.Errorf("FetchCache.Fetch() expect cached = %v, have %v", tt.wantCached, cached)
+			}
+		})
+	}
+}
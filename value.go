@@ -0,0 +1,72 @@
+package resource
+
+import "context"
+
+// ValueFetcher is an interface that defines the FetchValue method.
+type ValueFetcher interface {
+	// FetchValue retrieves the value of any type for a given identifier id.
+	FetchValue(ctx context.Context, id string) (interface{}, error)
+}
+
+// ValueFetcherFunc is an adapter to use ordinary functions as ValueFetcher.
+type ValueFetcherFunc func(ctx context.Context, id string) (interface{}, error)
+
+// FetchValue implements ValueFetcher.
+func (f ValueFetcherFunc) FetchValue(ctx context.Context, id string) (interface{}, error) {
+	return f(ctx, id)
+}
+
+// ValueCache caches values of any type, so several kinds of resources
+// share one implementation instead of a Fetcher of Models each. It is a
+// FetchCache whose models hold the values in Value: the options are those
+// of NewCache and Cache gives access to the rest of the API.
+//
+// Values only live in memory. With WithL2, WithPeers or Hydrate the models
+// carry Name and Data only, a Codec for WithL2 must restore Value from
+// Data for the values to survive the L2 store.
+type ValueCache struct {
+	fc *FetchCache
+}
+
+// NewValueCache creates a ValueCache of the values of f.
+func NewValueCache(f ValueFetcher, opts ...Option) *ValueCache {
+	return &ValueCache{fc: NewCache(valueFetcher{f}, opts...)}
+}
+
+// Cache returns the underlying cache.
+func (vc *ValueCache) Cache() *FetchCache {
+	return vc.fc
+}
+
+// Fetch returns the value of id, fetching it if it isn't cached.
+func (vc *ValueCache) Fetch(ctx context.Context, id string) (interface{}, error) {
+	return vc.FetchWithOptions(ctx, id)
+}
+
+// FetchWithOptions is Fetch with per-call options, see FetchOption.
+func (vc *ValueCache) FetchWithOptions(ctx context.Context, id string, opts ...FetchOption) (interface{}, error) {
+	model, err := vc.fc.FetchWithOptions(ctx, id, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return model.Value, nil
+}
+
+// Close closes the underlying cache, see FetchCache.Close.
+func (vc *ValueCache) Close(ctx context.Context) error {
+	return vc.fc.Close(ctx)
+}
+
+// valueFetcher fetches the models holding the values of f
+type valueFetcher struct {
+	f ValueFetcher
+}
+
+// Fetch implements Fetcher.
+func (vf valueFetcher) Fetch(ctx context.Context, id string) (*Model, error) {
+	v, err := vf.f.FetchValue(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &Model{Name: id, Value: v}, nil
+}
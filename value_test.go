@@ -0,0 +1,74 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestValueCache_Fetch(t *testing.T) {
+	type user struct {
+		ID   string
+		Name string
+	}
+	fakeFetchID := "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	notExistModelID := "5634aeed-2106-43de-ab7d-c0ad4b1e195e"
+	countID := "count"
+
+	tests := []struct {
+		name             string
+		id               string
+		want             interface{}
+		wantErr          error
+		serviceCallCount int
+	}{
+		{
+			name:             "success struct value",
+			id:               fakeFetchID,
+			want:             &user{ID: fakeFetchID, Name: "Alice"},
+			serviceCallCount: 1,
+		},
+		{
+			name:             "success int value",
+			id:               countID,
+			want:             42,
+			serviceCallCount: 1,
+		},
+		{
+			name:             "fail not found",
+			id:               notExistModelID,
+			wantErr:          ErrNotFound,
+			serviceCallCount: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serviceCallCount := 0
+			vc := NewValueCache(ValueFetcherFunc(func(ctx context.Context, id string) (interface{}, error) {
+				serviceCallCount++
+				switch id {
+				case fakeFetchID:
+					return &user{ID: id, Name: "Alice"}, nil
+				case countID:
+					return 42, nil
+				}
+				return nil, ErrNotFound
+			}))
+			defer vc.Close(context.Background())
+
+			for i := 0; i < 2; i++ {
+				v, err := vc.Fetch(context.Background(), tt.id)
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("ValueCache.Fetch() expect error = %v, have %v", tt.wantErr, err)
+				}
+				if !reflect.DeepEqual(v, tt.want) {
+					t.Errorf("ValueCache.Fetch() expect = %v, have %v", tt.want, v)
+				}
+			}
+			if serviceCallCount != tt.serviceCallCount {
+				t.Errorf("ValueCache.Fetch() expect service call count = %v, have %v", tt.serviceCallCount, serviceCallCount)
+			}
+		})
+	}
+}
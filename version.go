@@ -0,0 +1,141 @@
+package resource
+
+import (
+	"context"
+	"time"
+)
+
+// EntryInfo describes a cached entry.
+type EntryInfo struct {
+	Model *Model
+	// Version increases every time the entry is stored, by a fetch or a
+	// CompareAndSwap, and is never reused within a cache.
+	Version uint64
+	Created time.Time
+	// LastAccessed is when the entry was last served from the cache, within
+	// 100ms, or Created if it never was.
+	LastAccessed time.Time
+	// Expires is zero when the entry never expires.
+	Expires time.Time
+	// FetchCost is how long the entry took to fetch, 0 if it was not
+	// fetched.
+	FetchCost time.Duration
+	// OriginVersion is the version of the model at the origin given by a
+	// ResultFetcher, empty if none.
+	OriginVersion string
+	// Meta is the metadata of the entry, see WithMetadata, not to be
+	// modified.
+	Meta map[string]string
+	// Source is where the model came from at Created: the Fetcher, the L2
+	// store, a peer, a dump, a set or Warm.
+	Source Source
+}
+
+// Entry returns the cached entry of id and false if id is not cached or
+// has expired.
+func (fc *FetchCache) Entry(id string) (EntryInfo, bool) {
+	id = fc.canonical(id)
+	key := fc.key(id)
+	fc.rlockItems()
+	i, found := fc.items.Get(key)
+	fc.itemsLock.RUnlock()
+	if !found || i.expired() || !fc.owns(i, id) {
+		return EntryInfo{}, false
+	}
+	return fc.info(key, i), true
+}
+
+// GetWithExpiration returns the cached model of id without fetching it,
+// with when it expires, zero if it never does, and false if id is not
+// cached or has expired. HTTP handlers can derive Cache-Control max-age or
+// Expires headers from it.
+func (fc *FetchCache) GetWithExpiration(id string) (*Model, time.Time, bool) {
+	info, found := fc.Entry(id)
+	return info.Model, info.Expires, found
+}
+
+// info returns the EntryInfo of the item i cached under key
+func (fc *FetchCache) info(key string, i item) EntryInfo {
+	accessed := fc.stats.lastAccess(key)
+	if accessed < i.Created {
+		accessed = i.Created
+	}
+	info := EntryInfo{
+		Model:         i.Object,
+		Version:       i.Version,
+		Created:       time.Unix(0, i.Created),
+		LastAccessed:  time.Unix(0, accessed),
+		FetchCost:     time.Duration(i.Cost),
+		OriginVersion: i.OriginVersion,
+		Meta:          i.Meta,
+		Source:        i.Source,
+	}
+	if i.Expiration != 0 {
+		info.Expires = time.Unix(0, i.Expiration)
+	}
+	return info
+}
+
+// CompareAndSwap caches model for id if the cached entry of id is still at
+// version expected, an expected of 0 meaning id must not be cached, and
+// returns the new version. Otherwise the entry is left as is and
+// CompareAndSwap returns its current version, or 0 if it isn't cached,
+// and false. A Fetch of id in flight completes first, so its result is
+// never clobbered by a writer which didn't see it. Like Clear, a swap drops
+// id from the L2 store and other caches. The actor of ctx is recorded, see
+// WithAuditHook.
+func (fc *FetchCache) CompareAndSwap(ctx context.Context, id string, expected uint64, model *Model) (uint64, bool) {
+	id = fc.canonical(id)
+	key := fc.key(id)
+	fc.lock(key)
+	defer fc.unlock(key)
+
+	var current uint64
+	if i, found := fc.fetchFromCache(key); found && fc.owns(i, id) {
+		current = i.Version
+	}
+	if current != expected {
+		return current, false
+	}
+	return fc.writeLocked(ctx, key, id, model, fc.defaultTTL()), true
+}
+
+// Update caches the model returned by fn for id, fn being called with the
+// cached model of id or nil if it isn't cached. It holds the lock of id
+// while fn runs so no fetch or other update of id interleaves, which makes
+// it the safe way to read-modify-write an entry. If fn returns an error or
+// a nil model, or ctx is done while waiting for the lock, the entry is
+// left as is. Like CompareAndSwap, an update drops id from the L2 store
+// and other caches and the actor of ctx is recorded.
+func (fc *FetchCache) Update(ctx context.Context, id string, fn func(current *Model) (*Model, error)) (*Model, error) {
+	id = fc.canonical(id)
+	key := fc.key(id)
+	if _, err := fc.lockContext(ctx, key); err != nil {
+		return nil, err
+	}
+	defer fc.unlock(key)
+
+	var current *Model
+	if i, found := fc.fetchFromCache(key); found && fc.owns(i, id) {
+		current = i.Object
+	}
+	model, err := fn(current)
+	if err != nil || model == nil {
+		return current, err
+	}
+	fc.writeLocked(ctx, key, id, model, fc.defaultTTL())
+	return model, nil
+}
+
+// writeLocked caches model written for id under key for ttl and returns its
+// version, dropping id from the L2 store and the other caches and auditing
+// the write by the actor of ctx. The lock of key must be held.
+func (fc *FetchCache) writeLocked(ctx context.Context, key, id string, model *Model, ttl time.Duration) uint64 {
+	version := fc.cacheitem(key, id, model, SourceSet, ttl, 0)
+	if fc.opts.l2 != nil {
+		_ = fc.opts.l2.Delete(context.Background(), id)
+	}
+	fc.broadcast(Invalidation{Key: id})
+	fc.audit(AuditSet, ActorFromContext(ctx), fc.traceID(ctx), []string{id}, 0)
+	return version
+}
@@ -0,0 +1,268 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFetchCache_CompareAndSwap(t *testing.T) {
+	var (
+		fakeFetchID     = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+		notExistModelID = "5634aeed-2106-43de-ab7d-c0ad4b1e195e"
+	)
+
+	tests := []struct {
+		name        string
+		id          string
+		expected    func(fetched uint64) uint64
+		wantSwapped bool
+		wantModel   string
+	}{
+		{
+			name:        "success swap fetched version",
+			id:          fakeFetchID,
+			expected:    func(fetched uint64) uint64 { return fetched },
+			wantSwapped: true,
+			wantModel:   "swapped",
+		},
+		{
+			name:        "success swap not cached",
+			id:          notExistModelID,
+			expected:    func(fetched uint64) uint64 { return 0 },
+			wantSwapped: true,
+			wantModel:   "swapped",
+		},
+		{
+			name:      "failed stale version",
+			id:        fakeFetchID,
+			expected:  func(fetched uint64) uint64 { return fetched - 1 },
+			wantModel: "lorem",
+		},
+		{
+			name:      "failed already cached",
+			id:        fakeFetchID,
+			expected:  func(fetched uint64) uint64 { return 0 },
+			wantModel: "lorem",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					return &Model{Name: "lorem"}, nil
+				},
+			}
+			fc := NewCache(mockedFetcher, WithTTL(time.Minute))
+			// bump the version counter past 1
+			fc.cacheitem("other", "other", &Model{}, SourceSet, 0, 0)
+			if _, err := fc.Fetch(context.Background(), fakeFetchID); err != nil {
+				t.Fatalf("FetchCache.Fetch() error = %v", err)
+			}
+			fetched, _ := fc.Entry(fakeFetchID)
+
+			version, swapped := fc.CompareAndSwap(context.Background(), tt.id, tt.expected(fetched.Version), &Model{Name: "swapped"})
+			if swapped != tt.wantSwapped {
+				t.Fatalf("FetchCache.CompareAndSwap() expect swapped = %v, have %v", tt.wantSwapped, swapped)
+			}
+			entry, found := fc.Entry(tt.id)
+			if !found {
+				t.Fatalf("FetchCache.Entry() expect entry found")
+			}
+			if entry.Model.Name != tt.wantModel {
+				t.Errorf("FetchCache.Entry() expect model = %v, have %v", tt.wantModel, entry.Model.Name)
+			}
+			if entry.Version != version {
+				t.Errorf("FetchCache.CompareAndSwap() expect version = %v, have %v", entry.Version, version)
+			}
+			if swapped && version <= fetched.Version {
+				t.Errorf("FetchCache.CompareAndSwap() expect version above %v, have %v", fetched.Version, version)
+			}
+			if entry.Expires.Sub(entry.Created) != time.Minute {
+				t.Errorf("FetchCache.Entry() expect expiry after ttl, have %v", entry.Expires.Sub(entry.Created))
+			}
+		})
+	}
+}
+
+func TestFetchCache_Update(t *testing.T) {
+	var (
+		fakeFetchID     = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+		notExistModelID = "5634aeed-2106-43de-ab7d-c0ad4b1e195e"
+		errUpdate       = errors.New("lorem")
+	)
+
+	tests := []struct {
+		name      string
+		id        string
+		fn        func(current *Model) (*Model, error)
+		wantModel string
+		wantErr   error
+	}{
+		{
+			name: "success update cached",
+			id:   fakeFetchID,
+			fn: func(current *Model) (*Model, error) {
+				return &Model{Name: current.Name + " ipsum"}, nil
+			},
+			wantModel: "lorem ipsum",
+		},
+		{
+			name: "success update not cached",
+			id:   notExistModelID,
+			fn: func(current *Model) (*Model, error) {
+				if current != nil {
+					return nil, errUpdate
+				}
+				return &Model{Name: "ipsum"}, nil
+			},
+			wantModel: "ipsum",
+		},
+		{
+			name: "failed fn error",
+			id:   fakeFetchID,
+			fn: func(current *Model) (*Model, error) {
+				return nil, errUpdate
+			},
+			wantModel: "lorem",
+			wantErr:   errUpdate,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					return &Model{Name: "lorem"}, nil
+				},
+			}
+			fc := NewCache(mockedFetcher)
+			defer fc.Close(context.Background())
+			if _, err := fc.Fetch(context.Background(), fakeFetchID); err != nil {
+				t.Fatalf("FetchCache.Fetch() error = %v", err)
+			}
+
+			model, err := fc.Update(context.Background(), tt.id, tt.fn)
+			if err != tt.wantErr {
+				t.Fatalf("FetchCache.Update() expect error = %v, have %v", tt.wantErr, err)
+			}
+			if err == nil && model.Name != tt.wantModel {
+				t.Errorf("FetchCache.Update() expect model = %v, have %v", tt.wantModel, model.Name)
+			}
+			info, _ := fc.Entry(tt.id)
+			if info.Model.Name != tt.wantModel {
+				t.Errorf("FetchCache.Update() expect cached = %v, have %v", tt.wantModel, info.Model.Name)
+			}
+		})
+	}
+}
+
+func TestFetchCache_Entry_LastAccessed(t *testing.T) {
+	var (
+		fakeFetchID = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	)
+
+	tests := []struct {
+		name        string
+		hits        int
+		wantCreated bool
+	}{
+		{
+			name:        "success never hit",
+			wantCreated: true,
+		},
+		{
+			name: "success hit",
+			hits: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					return &Model{Name: "lorem"}, nil
+				},
+			}
+			fc := NewCache(mockedFetcher)
+			defer fc.Close(context.Background())
+			for i := 0; i <= tt.hits; i++ {
+				if _, err := fc.Fetch(context.Background(), fakeFetchID); err != nil {
+					t.Fatalf("FetchCache.Fetch() error = %v", err)
+				}
+			}
+
+			info, _ := fc.Entry(fakeFetchID)
+			if info.LastAccessed.Equal(info.Created) != tt.wantCreated {
+				t.Errorf("FetchCache.Entry() expect last accessed = created %v, have %v and %v", tt.wantCreated, info.LastAccessed, info.Created)
+			}
+			fc.Snapshot(false).Range(func(e SnapshotEntry) bool {
+				if !e.LastAccessed.Equal(info.LastAccessed) {
+					t.Errorf("FetchCache.Snapshot() expect last accessed = %v, have %v", info.LastAccessed, e.LastAccessed)
+				}
+				return true
+			})
+		})
+	}
+}
+
+func TestFetchCache_GetWithExpiration(t *testing.T) {
+	var (
+		fakeFetchID     = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+		notExistModelID = "5634aeed-2106-43de-ab7d-c0ad4b1e195e"
+	)
+
+	tests := []struct {
+		name        string
+		id          string
+		ttl         time.Duration
+		wantFound   bool
+		wantExpires bool
+	}{
+		{
+			name:        "success expiring entry",
+			id:          fakeFetchID,
+			ttl:         time.Minute,
+			wantFound:   true,
+			wantExpires: true,
+		},
+		{
+			name:      "success entry never expiring",
+			id:        fakeFetchID,
+			wantFound: true,
+		},
+		{
+			name: "failed not cached",
+			id:   notExistModelID,
+			ttl:  time.Minute,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serviceCallCount := 0
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					serviceCallCount++
+					return &Model{Name: "lorem"}, nil
+				},
+			}
+			fc := NewCache(mockedFetcher, WithTTL(tt.ttl))
+			defer fc.Close(context.Background())
+			_, _ = fc.Fetch(context.Background(), fakeFetchID)
+
+			before := time.Now()
+			model, expires, found := fc.GetWithExpiration(tt.id)
+			if found != tt.wantFound || (model != nil) != tt.wantFound {
+				t.Fatalf("FetchCache.GetWithExpiration() expect found = %v, have %v %v", tt.wantFound, model, found)
+			}
+			if expires.IsZero() == tt.wantExpires {
+				t.Errorf("FetchCache.GetWithExpiration() expect expires = %v, have %v", tt.wantExpires, expires)
+			}
+			if tt.wantExpires && (expires.Before(before) || expires.After(before.Add(tt.ttl))) {
+				t.Errorf("FetchCache.GetWithExpiration() expect expires within %v, have %v", tt.ttl, expires)
+			}
+			if serviceCallCount != 1 {
+				t.Errorf("FetchCache.GetWithExpiration() expect service call count = 1, have %v", serviceCallCount)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Error list
+var (
+	ErrEmptyVersion = errors.New("empty version")
+)
+
+// VersionFetcher is an interface that fetches the immutable versions of
+// resources, as a model registry does.
+type VersionFetcher interface {
+	// Latest returns the latest version of id.
+	Latest(ctx context.Context, id string) (string, error)
+	// FetchVersion retrieves version of id, which never changes.
+	FetchVersion(ctx context.Context, id, version string) (*Model, error)
+}
+
+// VersionedCache caches resources by id and version: the versions are
+// immutable and cached until evicted or cleared, and the latest version
+// of every id is cached for a short TTL.
+type VersionedCache struct {
+	fc        *FetchCache
+	latestTTL time.Duration
+}
+
+// NewVersionedCache creates a VersionedCache over f caching the latest
+// versions for latestTTL. opts configure the underlying FetchCache, whose
+// keys are the ids joined with the versions, so they shouldn't hold a key
+// normalizer nor an alias resolver.
+func NewVersionedCache(f VersionFetcher, latestTTL time.Duration, opts ...Option) *VersionedCache {
+	return &VersionedCache{
+		fc:        NewCache(versionFetcher{f: f}, opts...),
+		latestTTL: latestTTL,
+	}
+}
+
+// Fetch returns the latest version of id and its model.
+func (c *VersionedCache) Fetch(ctx context.Context, id string) (*Model, string, error) {
+	latest, err := c.fc.FetchWithOptions(ctx, versionKey(id, ""), OverrideTTL(c.latestTTL))
+	if err != nil {
+		return nil, "", err
+	}
+	model, err := c.FetchVersion(ctx, id, latest.Name)
+	if err != nil {
+		return nil, "", err
+	}
+	return model, latest.Name, nil
+}
+
+// FetchVersion returns version of id, cached without expiration.
+func (c *VersionedCache) FetchVersion(ctx context.Context, id, version string) (*Model, error) {
+	if version == "" {
+		return nil, ErrEmptyVersion
+	}
+	return c.fc.FetchWithOptions(ctx, versionKey(id, version), OverrideTTL(0))
+}
+
+// ClearLatest forgets the latest version of id, the cached versions stay.
+func (c *VersionedCache) ClearLatest(id string) {
+	c.fc.Clear(versionKey(id, ""))
+}
+
+// ClearVersion removes version of id.
+func (c *VersionedCache) ClearVersion(id, version string) {
+	c.fc.Clear(versionKey(id, version))
+}
+
+// Cache returns the underlying FetchCache, for its Stats or Close.
+func (c *VersionedCache) Cache() *FetchCache {
+	return c.fc
+}
+
+// versionKey returns the key of version of id, the latest version if empty
+func versionKey(id, version string) string {
+	return id + "\x00" + version
+}
+
+// versionFetcher is the Fetcher of the keys of a VersionedCache, the model
+// of the key of the latest version is named after the version
+type versionFetcher struct {
+	f VersionFetcher
+}
+
+func (f versionFetcher) Fetch(ctx context.Context, key string) (*Model, error) {
+	sep := strings.LastIndexByte(key, 0)
+	id, version := key[:sep], key[sep+1:]
+	if version != "" {
+		return f.f.FetchVersion(ctx, id, version)
+	}
+	latest, err := f.f.Latest(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if latest == "" {
+		return nil, ErrEmptyVersion
+	}
+	return &Model{Name: latest}, nil
+}
@@ -0,0 +1,85 @@
+package resource
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// registryMock is a VersionFetcher whose latest version is latest
+type registryMock struct {
+	latest       atomic.Value
+	latestCalls  int32
+	versionCalls int32
+}
+
+func (r *registryMock) Latest(ctx context.Context, id string) (string, error) {
+	atomic.AddInt32(&r.latestCalls, 1)
+	return r.latest.Load().(string), nil
+}
+
+func (r *registryMock) FetchVersion(ctx context.Context, id, version string) (*Model, error) {
+	atomic.AddInt32(&r.versionCalls, 1)
+	return &Model{Name: id, Data: []byte(version)}, nil
+}
+
+func TestVersionedCache(t *testing.T) {
+	var (
+		fakeFetchID = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	)
+
+	tests := []struct {
+		name             string
+		clearLatest      bool
+		wantVersion      string
+		wantLatestCalls  int32
+		wantVersionCalls int32
+	}{
+		{
+			name:             "success latest cached",
+			wantVersion:      "v1",
+			wantLatestCalls:  1,
+			wantVersionCalls: 1,
+		},
+		{
+			name:             "success clear latest keeps the versions",
+			clearLatest:      true,
+			wantVersion:      "v2",
+			wantLatestCalls:  2,
+			wantVersionCalls: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &registryMock{}
+			r.latest.Store("v1")
+			c := NewVersionedCache(r, time.Minute)
+			defer c.Cache().Close(context.Background())
+
+			if _, _, err := c.Fetch(context.Background(), fakeFetchID); err != nil {
+				t.Fatalf("VersionedCache.Fetch() error = %v", err)
+			}
+			r.latest.Store("v2")
+			if tt.clearLatest {
+				c.ClearLatest(fakeFetchID)
+			}
+			model, version, err := c.Fetch(context.Background(), fakeFetchID)
+			if err != nil {
+				t.Fatalf("VersionedCache.Fetch() error = %v", err)
+			}
+			if version != tt.wantVersion || string(model.Data) != tt.wantVersion {
+				t.Errorf("VersionedCache.Fetch() expect version = %v, have %v %s", tt.wantVersion, version, model.Data)
+			}
+			if _, err := c.FetchVersion(context.Background(), fakeFetchID, "v1"); err != nil {
+				t.Fatalf("VersionedCache.FetchVersion() error = %v", err)
+			}
+			if info, _ := c.Cache().Entry(versionKey(fakeFetchID, "v1")); !info.Expires.IsZero() {
+				t.Errorf("VersionedCache.FetchVersion() expect no expiration, have %v", info.Expires)
+			}
+			if r.latestCalls != tt.wantLatestCalls || r.versionCalls != tt.wantVersionCalls {
+				t.Errorf("VersionedCache.Fetch() expect %v latest and %v version calls, have %v and %v", tt.wantLatestCalls, tt.wantVersionCalls, r.latestCalls, r.versionCalls)
+			}
+		})
+	}
+}
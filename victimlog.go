@@ -0,0 +1,95 @@
+package resource
+
+import "time"
+
+// EvictionReason tells why an item was evicted.
+type EvictionReason int
+
+// Eviction reason list
+const (
+	// EvictedCapacity makes room for an item stored at WithMaxEntries.
+	EvictedCapacity EvictionReason = iota + 1
+	// EvictedReconfigure enforces a lower MaxEntries, see Reconfigure.
+	EvictedReconfigure
+	// EvictedBudget makes room in the memory budget of a Registry.
+	EvictedBudget
+)
+
+var evictionReasonNames = map[EvictionReason]string{
+	EvictedCapacity:    "capacity",
+	EvictedReconfigure: "reconfigure",
+	EvictedBudget:      "budget",
+}
+
+// String returns the lower case name of the reason.
+func (r EvictionReason) String() string {
+	if name, ok := evictionReasonNames[r]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// Eviction is an eviction decision, see WithEvictionLog.
+type Eviction struct {
+	Key    string
+	Reason EvictionReason
+	Time   time.Time
+	// Age is how long the item was cached, Hits how many fetches it
+	// served and Cost how long it took to fetch.
+	Age  time.Duration
+	Hits uint64
+	Cost time.Duration
+}
+
+// WithEvictionLog keeps the last n eviction decisions, see Evictions, to
+// tell why an entry keeps disappearing.
+func WithEvictionLog(n int) Option {
+	return func(o *options) {
+		o.evictionLog = n
+	}
+}
+
+// evictionLog is a ring of the last evictions, guarded by itemsLock
+type evictionLog struct {
+	entries []Eviction
+	next    int
+}
+
+// Evictions returns the eviction decisions kept by WithEvictionLog, most
+// recent first.
+func (fc *FetchCache) Evictions() []Eviction {
+	fc.rlockItems()
+	defer fc.itemsLock.RUnlock()
+	l := &fc.evictions
+	out := make([]Eviction, 0, len(l.entries))
+	for n := 1; n <= len(l.entries); n++ {
+		out = append(out, l.entries[(l.next-n+len(l.entries))%len(l.entries)])
+	}
+	return out
+}
+
+// logEvictionLocked records the eviction of i for reason, fc.itemsLock
+// must be held
+func (fc *FetchCache) logEvictionLocked(key string, i item, reason EvictionReason) {
+	max := fc.opts.evictionLog
+	if max <= 0 {
+		return
+	}
+	now := time.Now()
+	e := Eviction{
+		Key:    key,
+		Reason: reason,
+		Time:   now,
+		Age:    now.Sub(time.Unix(0, i.Created)),
+		Hits:   fc.stats.hits(key),
+		Cost:   time.Duration(i.Cost),
+	}
+	l := &fc.evictions
+	if len(l.entries) < max {
+		l.entries = append(l.entries, e)
+		l.next = len(l.entries) % max
+		return
+	}
+	l.entries[l.next] = e
+	l.next = (l.next + 1) % max
+}
@@ -0,0 +1,57 @@
+package resource
+
+import (
+	"context"
+	"strconv"
+	"testing"
+)
+
+func TestFetchCache_Evictions(t *testing.T) {
+	mockedFetcher := &FetcherMock{
+		FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+			return &Model{Name: id}, nil
+		},
+	}
+	tests := []struct {
+		name     string
+		opts     []Option
+		fetches  int
+		wantKeys []string
+	}{
+		{
+			name:     "success last evictions first",
+			opts:     []Option{WithMaxEntries(2), WithEvictionLog(3)},
+			fetches:  7,
+			wantKeys: []string{"4", "3", "2"},
+		},
+		{
+			name:     "success fewer evictions than kept",
+			opts:     []Option{WithMaxEntries(2), WithEvictionLog(3)},
+			fetches:  4,
+			wantKeys: []string{"1", "0"},
+		},
+		{
+			name:    "success no log",
+			opts:    []Option{WithMaxEntries(2)},
+			fetches: 4,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fc := NewCache(mockedFetcher, tt.opts...)
+			for i := 0; i < tt.fetches; i++ {
+				_, _ = fc.Fetch(context.Background(), strconv.Itoa(i))
+				_, _ = fc.Fetch(context.Background(), strconv.Itoa(i))
+			}
+			have := fc.Evictions()
+			if len(have) != len(tt.wantKeys) {
+				t.Fatalf("FetchCache.Evictions() expect %v, have %+v", tt.wantKeys, have)
+			}
+			for i, e := range have {
+				if e.Key != tt.wantKeys[i] || e.Reason != EvictedCapacity || e.Hits != 1 {
+					t.Errorf("FetchCache.Evictions() expect %v evicted for capacity after 1 hit, have %+v", tt.wantKeys[i], e)
+				}
+			}
+		})
+	}
+}
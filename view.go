@@ -0,0 +1,88 @@
+package resource
+
+import (
+	"bytes"
+	"context"
+	"io"
+)
+
+// ModelView is a read-only view of a cached Model, see FetchView. It
+// gives access to the model without exposing the shared Data, so it can't
+// be modified by accident, and without copying it either.
+type ModelView struct {
+	m *Model
+}
+
+// FetchView is FetchWithOptions returning a read-only view of the model,
+// for the callers which only read it.
+func (fc *FetchCache) FetchView(ctx context.Context, id string, opts ...FetchOption) (ModelView, error) {
+	model, err := fc.FetchWithOptions(ctx, id, opts...)
+	if err != nil {
+		return ModelView{}, err
+	}
+	return ModelView{m: model}, nil
+}
+
+// Valid reports whether the view has a model, false for the zero view.
+func (v ModelView) Valid() bool {
+	return v.m != nil
+}
+
+// Name returns the name of the model.
+func (v ModelView) Name() string {
+	if v.m == nil {
+		return ""
+	}
+	return v.m.Name
+}
+
+// Len returns the length of the Data of the model.
+func (v ModelView) Len() int {
+	if v.m == nil {
+		return 0
+	}
+	return len(v.m.Data)
+}
+
+// Reader returns a reader of the Data of the model, which shares it.
+func (v ModelView) Reader() *bytes.Reader {
+	if v.m == nil {
+		return bytes.NewReader(nil)
+	}
+	return bytes.NewReader(v.m.Data)
+}
+
+// WriteTo writes the Data of the model to w, which must not retain it.
+func (v ModelView) WriteTo(w io.Writer) (int64, error) {
+	return v.Reader().WriteTo(w)
+}
+
+// Equal reports whether the Data of the model is b.
+func (v ModelView) Equal(b []byte) bool {
+	if v.m == nil {
+		return b == nil
+	}
+	return bytes.Equal(v.m.Data, b)
+}
+
+// Value returns the decoded value of the model, see ValueCache, which is
+// shared and must not be modified.
+func (v ModelView) Value() interface{} {
+	if v.m == nil {
+		return nil
+	}
+	return v.m.Value
+}
+
+// Model returns a copy of the model with its own Data, for the callers
+// which need to modify it.
+func (v ModelView) Model() *Model {
+	if v.m == nil {
+		return nil
+	}
+	m := *v.m
+	if v.m.Data != nil {
+		m.Data = append([]byte(nil), v.m.Data...)
+	}
+	return &m
+}
@@ -0,0 +1,71 @@
+package resource
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestFetchCache_FetchView(t *testing.T) {
+	var (
+		fakeFetchID     = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+		notExistModelID = "5634aeed-2106-43de-ab7d-c0ad4b1e195e"
+	)
+	mockedFetcher := &FetcherMock{
+		FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+			if id == notExistModelID {
+				return nil, ErrNotFound
+			}
+			return &Model{Name: id, Data: []byte("content")}, nil
+		},
+	}
+
+	tests := []struct {
+		name     string
+		id       string
+		wantData string
+		wantErr  error
+	}{
+		{
+			name:     "success view of the model",
+			id:       fakeFetchID,
+			wantData: "content",
+		},
+		{
+			name:    "failed not found",
+			id:      notExistModelID,
+			wantErr: ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fc := NewCache(mockedFetcher)
+			v, err := fc.FetchView(context.Background(), tt.id)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("FetchCache.FetchView() expect error = %v, have %v", tt.wantErr, err)
+			}
+			if tt.wantErr != nil {
+				if v.Valid() || v.Model() != nil || v.Len() != 0 {
+					t.Errorf("FetchCache.FetchView() expect the zero view, have %v", v.Model())
+				}
+				return
+			}
+			if v.Name() != tt.id || v.Len() != len(tt.wantData) || !v.Equal([]byte(tt.wantData)) {
+				t.Errorf("FetchCache.FetchView() expect %v %q, have %v %v", tt.id, tt.wantData, v.Name(), v.Model())
+			}
+			var buf bytes.Buffer
+			if _, err := v.WriteTo(&buf); err != nil || buf.String() != tt.wantData {
+				t.Errorf("ModelView.WriteTo() expect %q, have %q, %v", tt.wantData, buf.String(), err)
+			}
+
+			// changing the copy leaves the cached model as is
+			m := v.Model()
+			m.Data[0] = 'X'
+			again, _ := fc.FetchView(context.Background(), tt.id)
+			if !again.Equal([]byte(tt.wantData)) {
+				t.Errorf("ModelView.Model() expect a copy, have the cached model changed to %q", again.Model().Data)
+			}
+		})
+	}
+}
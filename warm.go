@@ -0,0 +1,125 @@
+package resource
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// warmProgressEvery is the number of entries between progress reports
+const warmProgressEvery = 1000
+
+// WarmEntry is an entry to load in a cache, see FetchCache.Warm.
+type WarmEntry struct {
+	ID    string
+	Model *Model
+}
+
+// Warmer is an interface that iterates over entries to load in a cache,
+// e.g. from a bulk export or a message stream.
+type Warmer interface {
+	// Next returns the next entry, or io.EOF once there are no more.
+	Next(ctx context.Context) (WarmEntry, error)
+}
+
+// ChanWarmer returns a Warmer receiving the entries from ch until it is
+// closed.
+func ChanWarmer(ch <-chan WarmEntry) Warmer {
+	return chanWarmer(ch)
+}
+
+type chanWarmer <-chan WarmEntry
+
+// Next implements Warmer.
+func (ch chanWarmer) Next(ctx context.Context) (WarmEntry, error) {
+	select {
+	case e, ok := <-ch:
+		if !ok {
+			return WarmEntry{}, io.EOF
+		}
+		return e, nil
+	case <-ctx.Done():
+		return WarmEntry{}, ctx.Err()
+	}
+}
+
+// WarmProgress counts the entries handled by FetchCache.Warm.
+type WarmProgress struct {
+	// Loaded is the number of entries cached.
+	Loaded int
+	// Skipped is the number of entries left out because they had no model,
+	// an oversized one, see WithMaxValueSize, a new id over
+	// WithCardinalityGuard, or their id was cached or being fetched, the
+	// Fetcher result being at least as fresh.
+	Skipped int
+}
+
+// Warm caches the entries of w with the default TTL until it returns
+// io.EOF, then returns nil, or another error, which is returned. Entries
+// are pulled one at a time so a producer never runs ahead of the cache,
+// and cached WithWarmConcurrency at a time. Close cancels the context
+// given to w. progress, if not nil, is called every 1000 entries and at
+// the end.
+func (fc *FetchCache) Warm(ctx context.Context, w Warmer, progress func(WarmProgress)) (WarmProgress, error) {
+	var (
+		mu sync.Mutex
+		p  WarmProgress
+	)
+	limit := fc.opts.warmConcurrency
+	if limit < 1 {
+		limit = 1
+	}
+	g := fc.newGroup(ctx, limit, false)
+	fc.warmup.begin()
+	finish := func(err error) (WarmProgress, error) {
+		_ = g.Wait()
+		fc.warmup.end(p.Loaded+p.Skipped, true)
+		if progress != nil {
+			progress(p)
+		}
+		return p, err
+	}
+	for {
+		e, err := w.Next(g.ctx)
+		if err == io.EOF {
+			return finish(nil)
+		}
+		if err != nil {
+			return finish(err)
+		}
+
+		if !g.Go(func(context.Context) error {
+			loaded := fc.warm(e)
+			if loaded {
+				fc.warmup.loaded(true)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if loaded {
+				p.Loaded++
+			} else {
+				p.Skipped++
+			}
+			if progress != nil && (p.Loaded+p.Skipped)%warmProgressEvery == 0 {
+				progress(p)
+			}
+			return nil
+		}) {
+			return finish(g.ctx.Err())
+		}
+	}
+}
+
+// warm caches e unless its id is cached or locked by a fetch
+func (fc *FetchCache) warm(e WarmEntry) bool {
+	e.ID = fc.canonical(e.ID)
+	key := fc.key(e.ID)
+	if !fc.tryLock(key) {
+		return false
+	}
+	defer fc.unlock(key)
+	if _, found := fc.fetchFromCache(key); found || e.Model == nil {
+		return false
+	}
+	return fc.keep(key, e.ID, "", Result{Model: e.Model}, SourceWarm, Expiration{AfterWrite: fc.defaultTTL()}, 0, nil)
+}
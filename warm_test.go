@@ -0,0 +1,114 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strconv"
+	"testing"
+)
+
+// sliceWarmer is a Warmer over a slice, failing with err at the end
+type sliceWarmer struct {
+	entries []WarmEntry
+	err     error
+}
+
+func (w *sliceWarmer) Next(ctx context.Context) (WarmEntry, error) {
+	if len(w.entries) == 0 {
+		return WarmEntry{}, w.err
+	}
+	e := w.entries[0]
+	w.entries = w.entries[1:]
+	return e, nil
+}
+
+func TestFetchCache_Warm(t *testing.T) {
+	var (
+		fakeFetchID = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+		errStream   = errors.New("stream broken")
+	)
+
+	entries := func(n int) []WarmEntry {
+		var e []WarmEntry
+		for i := 0; i < n; i++ {
+			e = append(e, WarmEntry{ID: strconv.Itoa(i), Model: &Model{Name: "ipsum"}})
+		}
+		return e
+	}
+	tests := []struct {
+		name         string
+		entries      []WarmEntry
+		streamErr    error
+		wantProgress WarmProgress
+		wantReports  int
+		wantErr      error
+	}{
+		{
+			name:         "success load entries",
+			entries:      entries(1500),
+			wantProgress: WarmProgress{Loaded: 1500},
+			wantReports:  2,
+		},
+		{
+			name:         "success skip cached id",
+			entries:      []WarmEntry{{ID: fakeFetchID, Model: &Model{Name: "ipsum"}}},
+			wantProgress: WarmProgress{Skipped: 1},
+			wantReports:  1,
+		},
+		{
+			name:         "failed stream error",
+			entries:      entries(2),
+			streamErr:    errStream,
+			wantProgress: WarmProgress{Loaded: 2},
+			wantReports:  1,
+			wantErr:      errStream,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					return &Model{Name: "lorem"}, nil
+				},
+			}
+			fc := NewCache(mockedFetcher)
+			defer fc.Close(context.Background())
+			if _, err := fc.Fetch(context.Background(), fakeFetchID); err != nil {
+				t.Fatalf("FetchCache.Fetch() error = %v", err)
+			}
+
+			streamErr := tt.streamErr
+			if streamErr == nil {
+				streamErr = io.EOF
+			}
+			reports := 0
+			p, err := fc.Warm(context.Background(), &sliceWarmer{entries: tt.entries, err: streamErr}, func(WarmProgress) { reports++ })
+			if err != tt.wantErr {
+				t.Fatalf("FetchCache.Warm() expect error = %v, have %v", tt.wantErr, err)
+			}
+			if p != tt.wantProgress || reports != tt.wantReports {
+				t.Errorf("FetchCache.Warm() expect %+v in %v reports, have %+v in %v", tt.wantProgress, tt.wantReports, p, reports)
+			}
+			if model, _ := fc.Fetch(context.Background(), fakeFetchID); model.Name != "lorem" {
+				t.Errorf("FetchCache.Warm() expect fetched model kept, have %v", model.Name)
+			}
+			if s := fc.Stats(); s.Items != 1+tt.wantProgress.Loaded {
+				t.Errorf("FetchCache.Warm() expect items = %v, have %v", 1+tt.wantProgress.Loaded, s.Items)
+			}
+		})
+	}
+}
+
+func TestChanWarmer(t *testing.T) {
+	ch := make(chan WarmEntry, 1)
+	ch <- WarmEntry{ID: "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"}
+	close(ch)
+	w := ChanWarmer(ch)
+	if e, err := w.Next(context.Background()); err != nil || e.ID != "dca76878-a8f6-4ff5-b263-1e8c7e61bc20" {
+		t.Errorf("ChanWarmer.Next() = %v, %v", e, err)
+	}
+	if _, err := w.Next(context.Background()); err != io.EOF {
+		t.Errorf("ChanWarmer.Next() expect error = %v, have %v", io.EOF, err)
+	}
+}
@@ -0,0 +1,175 @@
+package resource
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	wheelBits   = 6
+	wheelSlots  = 1 << wheelBits
+	wheelLevels = 4
+)
+
+// WithExpiryWheel removes items once they expire, within resolution of
+// their expiration or of the end of their stale window, instead of leaving
+// them until their key is fetched again. Expirations are indexed in a
+// hierarchical timing wheel so scheduling and removing an item costs O(1)
+// whatever the size of the cache, see WithOnExpire.
+func WithExpiryWheel(resolution time.Duration) Option {
+	return func(o *options) {
+		o.wheelResolution = resolution
+	}
+}
+
+// WithOnExpire calls fn with the key and the model of every expired item
+// removed by WithExpiryWheel or WithExpirySweep. The key is the id unless
+// WithHashedKeys is set. fn must not block.
+func WithOnExpire(fn func(key string, model *Model)) Option {
+	return func(o *options) {
+		o.onExpire = fn
+	}
+}
+
+// runExpiryWheel removes the items whose time has come every resolution
+// until stop
+func (fc *FetchCache) runExpiryWheel(stop <-chan struct{}) {
+	ticker := time.NewTicker(fc.opts.wheelResolution)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			fc.expireDue(now)
+		}
+	}
+}
+
+// expireDue removes the items the wheel has due at now which weren't
+// replaced since they were scheduled
+func (fc *FetchCache) expireDue(now time.Time) {
+	due := fc.wheel.advance(now)
+	if len(due) == 0 {
+		return
+	}
+	window := int64(fc.staleWindow())
+	var expired []expiredItem
+	fc.lockItems()
+	for _, e := range due {
+		i, found := fc.items.Get(e.key)
+		if !found || i.Version != e.version {
+			continue
+		}
+		if end := i.Expiration + window; now.UnixNano() < end {
+			// the stale window grew meanwhile
+			fc.wheel.add(e.key, end, e.version)
+			continue
+		}
+		fc.items.Delete(e.key)
+		expired = append(expired, expiredItem{e.key, i.Object})
+	}
+	if len(expired) > 0 {
+		fc.itemsChanged(false)
+	}
+	fc.itemsLock.Unlock()
+	fc.expired(expired)
+	fc.notifyExpired(expired)
+}
+
+// expiredItem is an item removed at expiry
+type expiredItem struct {
+	key   string
+	model *Model
+}
+
+// expired reports the expired items removed
+func (fc *FetchCache) expired(items []expiredItem) {
+	for _, i := range items {
+		fc.events.publish(EventExpire, i.key)
+		if fc.opts.onExpire != nil {
+			fc.opts.onExpire(i.key, i.model)
+		}
+	}
+	fc.metrics.swept(len(items))
+}
+
+// wheelEntry is an item scheduled to expire at tick, unless its version
+// changed meanwhile
+type wheelEntry struct {
+	key     string
+	tick    int64
+	version uint64
+}
+
+// timingWheel is a hierarchical timing wheel: slot s of level l holds the
+// entries due in the span of 64^l ticks numbered s modulo 64, which are
+// moved to the lower levels when their span begins
+type timingWheel struct {
+	mu         sync.Mutex
+	resolution int64
+	// cur is the last tick processed
+	cur   int64
+	slots [wheelLevels][wheelSlots][]wheelEntry
+}
+
+func newTimingWheel(resolution time.Duration, now time.Time) *timingWheel {
+	return &timingWheel{
+		resolution: int64(resolution),
+		cur:        now.UnixNano() / int64(resolution),
+	}
+}
+
+// add schedules key at version to expire at expiration in ns
+func (w *timingWheel) add(key string, expiration int64, version uint64) {
+	tick := (expiration + w.resolution - 1) / w.resolution
+	w.mu.Lock()
+	if tick <= w.cur {
+		tick = w.cur + 1
+	}
+	w.insertLocked(wheelEntry{key: key, tick: tick, version: version})
+	w.mu.Unlock()
+}
+
+// insertLocked puts e in the lowest level spanning it, entries beyond the
+// last level wait in it and are placed again when their slot comes,
+// w.mu must be held
+func (w *timingWheel) insertLocked(e wheelEntry) {
+	delta := e.tick - w.cur
+	level := 0
+	for level < wheelLevels-1 && delta >= 1<<(wheelBits*uint(level+1)) {
+		level++
+	}
+	slot := &w.slots[level][(e.tick>>(wheelBits*uint(level)))&(wheelSlots-1)]
+	*slot = append(*slot, e)
+}
+
+// advance processes the ticks up to now and returns the entries due
+func (w *timingWheel) advance(now time.Time) []wheelEntry {
+	target := now.UnixNano() / w.resolution
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var due []wheelEntry
+	for w.cur < target {
+		w.cur++
+		for level := 1; level < wheelLevels; level++ {
+			if w.cur&(1<<(wheelBits*uint(level))-1) != 0 {
+				break
+			}
+			slot := &w.slots[level][(w.cur>>(wheelBits*uint(level)))&(wheelSlots-1)]
+			entries := *slot
+			*slot = nil
+			for _, e := range entries {
+				if e.tick <= w.cur {
+					due = append(due, e)
+				} else {
+					w.insertLocked(e)
+				}
+			}
+		}
+		slot := &w.slots[0][w.cur&(wheelSlots-1)]
+		due = append(due, *slot...)
+		*slot = nil
+	}
+	return due
+}
@@ -0,0 +1,113 @@
+package resource
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTimingWheel_Advance(t *testing.T) {
+	var (
+		fakeFetchID = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+		start       = time.Unix(1000, 0)
+		resolution  = time.Millisecond
+	)
+
+	tests := []struct {
+		name  string
+		ticks int64
+	}{
+		{
+			name:  "success level 0",
+			ticks: 1,
+		},
+		{
+			name:  "success last slot of level 0",
+			ticks: wheelSlots - 1,
+		},
+		{
+			name:  "success level 1",
+			ticks: wheelSlots + 3,
+		},
+		{
+			name:  "success level 2",
+			ticks: wheelSlots*wheelSlots + 5,
+		},
+		{
+			name:  "success level 3",
+			ticks: wheelSlots*wheelSlots*wheelSlots + 7,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newTimingWheel(resolution, start)
+			expiration := start.Add(time.Duration(tt.ticks) * resolution)
+			w.add(fakeFetchID, expiration.UnixNano(), 1)
+
+			if due := w.advance(expiration.Add(-resolution)); len(due) != 0 {
+				t.Fatalf("timingWheel.advance() expect nothing due before expiration, have %v", due)
+			}
+			due := w.advance(expiration)
+			if len(due) != 1 || due[0].key != fakeFetchID {
+				t.Errorf("timingWheel.advance() expect %v due at expiration, have %v", fakeFetchID, due)
+			}
+		})
+	}
+}
+
+func TestFetchCache_WithExpiryWheel(t *testing.T) {
+	var (
+		fakeFetchID = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	)
+
+	tests := []struct {
+		name        string
+		refetch     bool
+		wantExpired int
+	}{
+		{
+			name:        "success remove at expiration",
+			wantExpired: 1,
+		},
+		{
+			name:    "success keep replaced item",
+			refetch: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					return &Model{Name: "lorem"}, nil
+				},
+			}
+			var expired []string
+			fc := NewCache(mockedFetcher,
+				WithSynchronous(),
+				WithTTL(5*time.Millisecond),
+				WithExpiryWheel(time.Millisecond),
+				WithOnExpire(func(key string, model *Model) {
+					expired = append(expired, key)
+				}),
+			)
+			defer fc.Close(context.Background())
+			if _, err := fc.Fetch(context.Background(), fakeFetchID); err != nil {
+				t.Fatalf("FetchCache.Fetch() error = %v", err)
+			}
+			if tt.refetch {
+				if _, err := fc.FetchWithOptions(context.Background(), fakeFetchID, BypassCache(), OverrideTTL(time.Minute)); err != nil {
+					t.Fatalf("FetchCache.FetchWithOptions() error = %v", err)
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			fc.Tick(context.Background())
+
+			if len(expired) != tt.wantExpired {
+				t.Fatalf("FetchCache.Tick() expect %v expired, have %v", tt.wantExpired, expired)
+			}
+			if s := fc.Stats(); s.Items != 1-tt.wantExpired || int(s.Expired) != tt.wantExpired {
+				t.Errorf("FetchCache.Tick() expect items = %v, expired = %v, have %v and %v", 1-tt.wantExpired, tt.wantExpired, s.Items, s.Expired)
+			}
+		})
+	}
+}
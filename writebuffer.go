@@ -0,0 +1,166 @@
+package resource
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// writeBatchMax is the most writes the writer of WithWriteBuffer stores
+// under one acquisition of the items lock
+const writeBatchMax = 64
+
+// WithWriteBuffer funnels the models fetched into a single writer goroutine
+// through a buffer of size, which stores them by batches under one write
+// lock of the items, so the reads of a busy cache rarely wait for the lock.
+// A fetched model is returned before it is stored: the fetches of its id
+// are served from the pending writes meanwhile, but Entry, Stats and the
+// other views of the items see it slightly later. A model is stored right
+// away when the buffer is full. The hits take no lock either way. It has
+// no effect WithSynchronous.
+func WithWriteBuffer(size int) Option {
+	return func(o *options) {
+		o.writeBuffer = size
+	}
+}
+
+// pendingWrite is a fetched item waiting for the writer
+type pendingWrite struct {
+	key     string
+	i       item
+	trace   string
+	discard func() bool
+}
+
+// writeBuffer is the queue of WithWriteBuffer. The pending writes are
+// looked up before the items so a reader never misses an item between its
+// store and the removal of its pending write.
+type writeBuffer struct {
+	ch chan *pendingWrite
+	// n is the number of pending writes, so reads skip them without any
+	n       int64 // accessed atomically
+	pending sync.Map
+}
+
+func newWriteBuffer(size int) *writeBuffer {
+	return &writeBuffer{ch: make(chan *pendingWrite, size)}
+}
+
+// get returns the item of the write of key pending
+func (b *writeBuffer) get(key string) (item, bool) {
+	if b == nil || atomic.LoadInt64(&b.n) == 0 {
+		return item{}, false
+	}
+	v, ok := b.pending.Load(key)
+	if !ok {
+		return item{}, false
+	}
+	return v.(*pendingWrite).i, true
+}
+
+// cancel drops the write of key pending
+func (b *writeBuffer) cancel(key string) {
+	if b == nil {
+		return
+	}
+	if _, loaded := b.pending.LoadAndDelete(key); loaded {
+		atomic.AddInt64(&b.n, -1)
+	}
+}
+
+// cancelAll drops every pending write
+func (b *writeBuffer) cancelAll() {
+	if b == nil {
+		return
+	}
+	b.pending.Range(func(key, _ interface{}) bool {
+		b.cancel(key.(string))
+		return true
+	})
+}
+
+// done removes w from the pending writes and reports whether it still was,
+// not canceled nor replaced by a later write
+func (b *writeBuffer) done(w *pendingWrite) bool {
+	if v, ok := b.pending.Load(w.key); !ok || v != w {
+		return false
+	}
+	b.cancel(w.key)
+	return true
+}
+
+// bufferWrite queues the store of i for key, it returns false if the
+// buffer is full or the cache closed, i must then be stored directly
+func (fc *FetchCache) bufferWrite(key string, i item, trace string, discard func() bool) bool {
+	b := fc.writes
+	if b == nil {
+		return false
+	}
+	w := &pendingWrite{key: key, i: i, trace: trace, discard: discard}
+	if _, loaded := b.pending.Swap(key, w); !loaded {
+		atomic.AddInt64(&b.n, 1)
+	}
+	select {
+	case <-fc.life.stop:
+	case b.ch <- w:
+		return true
+	default:
+	}
+	b.done(w)
+	return false
+}
+
+// runWriter stores the buffered writes until stop
+func (fc *FetchCache) runWriter(stop <-chan struct{}) {
+	batch := make([]*pendingWrite, 0, writeBatchMax)
+	for {
+		select {
+		case <-stop:
+			return
+		case w := <-fc.writes.ch:
+			batch = append(batch[:0], w)
+		}
+	drain:
+		for len(batch) < writeBatchMax {
+			select {
+			case w := <-fc.writes.ch:
+				batch = append(batch, w)
+			default:
+				break drain
+			}
+		}
+		fc.storeWrites(batch)
+	}
+}
+
+// storeWrites stores batch under one lock of the items
+func (fc *FetchCache) storeWrites(batch []*pendingWrite) {
+	type store struct {
+		key     string
+		i       item
+		evicted []string
+		trace   string
+	}
+	stores := make([]store, 0, len(batch))
+	fc.lockItems()
+	for _, w := range batch {
+		if w.discard != nil && w.discard() {
+			if fc.writes.done(w) {
+				atomic.AddUint64(&fc.metrics.discarded, 1)
+			}
+			continue
+		}
+		if v, ok := fc.writes.pending.Load(w.key); !ok || v != w {
+			continue
+		}
+		i, evicted := fc.storeLocked(w.key, w.i)
+		fc.writes.done(w)
+		stores = append(stores, store{key: w.key, i: i, evicted: evicted, trace: w.trace})
+	}
+	if len(stores) > 0 {
+		fc.itemsChanged(false)
+	}
+	fc.itemsLock.Unlock()
+	for _, s := range stores {
+		fc.afterStore(s.key, s.i, s.evicted, s.trace)
+	}
+}
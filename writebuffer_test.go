@@ -0,0 +1,105 @@
+package resource
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFetchCache_WithWriteBuffer(t *testing.T) {
+	var (
+		fakeFetchID = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	)
+
+	tests := []struct {
+		name       string
+		clear      bool
+		flush      bool
+		invalidate bool
+		wantCached bool
+	}{
+		{
+			name:       "success pending write stored",
+			wantCached: true,
+		},
+		{
+			name:  "success clear drops the pending write",
+			clear: true,
+		},
+		{
+			name:  "success flush drops the pending write",
+			flush: true,
+		},
+		{
+			name:       "success invalidate set drops the pending write",
+			invalidate: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var callCount int32
+			mockedFetcher := &FetcherMock{
+				FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+					atomic.AddInt32(&callCount, 1)
+					return &Model{Name: id}, nil
+				},
+			}
+			// a buffer without writer, drained by hand
+			fc := NewCache(mockedFetcher, WithSynchronous())
+			fc.writes = newWriteBuffer(4)
+
+			for i := 0; i < 2; i++ {
+				if _, err := fc.Fetch(context.Background(), fakeFetchID); err != nil {
+					t.Fatalf("FetchCache.Fetch() error = %v", err)
+				}
+			}
+			if callCount != 1 {
+				t.Errorf("FetchCache.Fetch() expect the pending write served, have %v fetcher calls", callCount)
+			}
+			if _, found := fc.Entry(fakeFetchID); found {
+				t.Errorf("FetchCache.Entry() expect the write pending, have it stored")
+			}
+			if tt.clear {
+				fc.Clear(fakeFetchID)
+			}
+			if tt.flush {
+				fc.Flush()
+			}
+			if tt.invalidate {
+				fc.InvalidateSet(context.Background(), fakeFetchID)
+			}
+			fc.storeWrites([]*pendingWrite{<-fc.writes.ch})
+			if _, found := fc.Entry(fakeFetchID); found != tt.wantCached {
+				t.Errorf("FetchCache.Entry() expect cached = %v, have %v", tt.wantCached, found)
+			}
+		})
+	}
+}
+
+func TestFetchCache_WithWriteBuffer_Writer(t *testing.T) {
+	var (
+		fakeFetchID = "dca76878-a8f6-4ff5-b263-1e8c7e61bc20"
+	)
+	mockedFetcher := &FetcherMock{
+		FetchFunc: func(ctx context.Context, id string) (*Model, error) {
+			return &Model{Name: id}, nil
+		},
+	}
+	fc := NewCache(mockedFetcher, WithWriteBuffer(16))
+	defer fc.Close(context.Background())
+
+	if _, err := fc.Fetch(context.Background(), fakeFetchID); err != nil {
+		t.Fatalf("FetchCache.Fetch() error = %v", err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for {
+		if _, found := fc.Entry(fakeFetchID); found {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("FetchCache.Entry() expect the write stored by the writer")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}